@@ -0,0 +1,262 @@
+package jsonrpc
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The prefix of the methods added by EnableAdmin.
+const adminPrefix = "admin."
+
+// The number of errors kept for admin.errors.
+const maxRecentErrors = 100
+
+// EnableAdmin adds management methods to the server, so that instances can be
+// managed over JSON-RPC:
+//
+//     admin.stats        Returns the statistics of the server (see StatReporter).
+//     admin.methods      Returns the MethodInfo of every method.
+//     admin.maintenance  Returns if the server is in maintenance mode, or
+//                        changes it with {"enabled": true}. See SetMaintenance.
+//     admin.disconnect   Closes the event streams of {"client": "abc123"} and
+//                        returns the number that were closed. See EventsHandler.
+//     admin.faults       Returns the faults of each method, or changes the fault
+//                        for a method with {"method": "getUser", "rate": 0.1,
+//                        "delay": 0.5, "code": -32000, "message": "Oops."}.
+//                        delay is in seconds. A rate of 0 removes the fault.
+//                        See SetFault.
+//     admin.errors       Returns the most recent error responses (up to 100),
+//                        newest first.
+//
+// Every call must be allowed by authorize, otherwise it receives a ServerError
+// with the message "Unauthorized.". A nil authorize rejects every call. The
+// decision is recorded as the "admin" check (see SetAudit). These methods are
+// never put into maintenance mode and never have faults injected, but other
+// methods that start with "admin." are treated like any other method. For
+// example, to
+// check a token passed in the State:
+//
+//     server.EnableAdmin(func(request jsonrpc.Request) bool {
+//         return request.State("token") == adminToken
+//     })
+func (server *SimpleServer) EnableAdmin(authorize func(request Request) bool) {
+	handlers := map[string]RequestHandler{
+		"stats":       server.adminStats,
+		"methods":     server.adminMethods,
+		"maintenance": server.adminMaintenance,
+		"disconnect":  server.adminDisconnect,
+		"faults":      server.adminFaults,
+		"errors":      server.adminErrors,
+	}
+
+	server.handlerMutex.Lock()
+	server.adminNames = make(map[string]bool, len(handlers))
+	for name := range handlers {
+		server.adminNames[adminPrefix+name] = true
+	}
+	server.handlerMutex.Unlock()
+
+	server.recentErrors.enable()
+
+	for name, handler := range handlers {
+		server.SetHandler(adminPrefix+name, adminHandler(authorize, handler))
+	}
+}
+
+// isAdminMethod returns true if the method was added by EnableAdmin.
+func (server *SimpleServer) isAdminMethod(methodName string) bool {
+	server.handlerMutex.RLock()
+	defer server.handlerMutex.RUnlock()
+
+	return server.adminNames[methodName]
+}
+
+func adminHandler(authorize func(request Request) bool, handler RequestHandler) RequestHandler {
+	return func(request RequestResponder) Response {
+		allowed := authorize != nil && authorize(request)
+		RecordAuditCheck(request, AuditCheck{Name: "admin", Allowed: allowed})
+
+		if !allowed {
+			return request.NewErrorResponse(ServerError, "Unauthorized.")
+		}
+
+		return handler(request)
+	}
+}
+
+func (server *SimpleServer) adminStats(request RequestResponder) Response {
+	return request.NewSuccessResponse(map[string]interface{}{
+		"totalPayloads":              server.TotalPayloads(),
+		"totalRequests":              server.TotalRequests(),
+		"totalSuccessResponses":      server.TotalSuccessResponses(),
+		"totalErrorResponses":        server.TotalErrorResponses(),
+		"totalNotificationSuccesses": server.TotalNotificationSuccesses(),
+		"totalNotificationErrors":    server.TotalNotificationErrors(),
+		"currentActiveRequests":      server.CurrentActiveRequests(),
+		"limitViolations":            server.LimitViolations(),
+		"uptime":                     server.Uptime().Seconds(),
+		"maintenance":                server.Maintenance(),
+	})
+}
+
+func (server *SimpleServer) adminMethods(request RequestResponder) Response {
+	return request.NewSuccessResponse(server.catalog())
+}
+
+func (server *SimpleServer) adminMaintenance(request RequestResponder) Response {
+	if params, ok := request.Params().(map[string]interface{}); ok {
+		if _, ok := params["enabled"]; ok {
+			enabled, ok := params["enabled"].(bool)
+			if !ok {
+				return request.NewErrorResponse(InvalidParams, "enabled must be a boolean.")
+			}
+
+			server.SetMaintenance(enabled)
+		}
+	}
+
+	return request.NewSuccessResponse(server.Maintenance())
+}
+
+func (server *SimpleServer) adminDisconnect(request RequestResponder) Response {
+	params, _ := request.Params().(map[string]interface{})
+	clientID, ok := params["client"].(string)
+	if !ok {
+		return request.NewErrorResponse(InvalidParams, "client must be a string.")
+	}
+
+	return request.NewSuccessResponse(server.DisconnectClient(clientID))
+}
+
+func (server *SimpleServer) adminFaults(request RequestResponder) Response {
+	if request.Params() != nil {
+		var params struct {
+			Method  string  `json:"method"`
+			Rate    float64 `json:"rate"`
+			Delay   float64 `json:"delay"`
+			Code    int     `json:"code"`
+			Message string  `json:"message"`
+		}
+		if response := request.BindParams(&params); response != nil {
+			return response
+		}
+
+		if params.Method == "" {
+			return request.NewErrorResponse(InvalidParams, "method must be a string.")
+		}
+
+		server.SetFault(params.Method, Fault{
+			Rate:         params.Rate,
+			Delay:        time.Duration(params.Delay * float64(time.Second)),
+			ErrorCode:    params.Code,
+			ErrorMessage: params.Message,
+		})
+	}
+
+	result := map[string]interface{}{}
+	for methodName, fault := range server.Faults() {
+		result[methodName] = map[string]interface{}{
+			"rate":    fault.Rate,
+			"delay":   fault.Delay.Seconds(),
+			"code":    fault.ErrorCode,
+			"message": fault.ErrorMessage,
+		}
+	}
+
+	return request.NewSuccessResponse(result)
+}
+
+func (server *SimpleServer) adminErrors(request RequestResponder) Response {
+	return request.NewSuccessResponse(server.recentErrors.list())
+}
+
+// recentErrors is a ring buffer of the last maxRecentErrors error responses.
+// Nothing is recorded until it is enabled by EnableAdmin.
+type recentErrors struct {
+	mutex   sync.Mutex
+	entries []map[string]interface{}
+	next    int
+	full    bool
+}
+
+func (errors *recentErrors) enable() {
+	errors.mutex.Lock()
+	defer errors.mutex.Unlock()
+
+	if errors.entries == nil {
+		errors.entries = make([]map[string]interface{}, maxRecentErrors)
+	}
+}
+
+// record keeps the error of response, if it is one.
+func (errors *recentErrors) record(at time.Time, methodName string, response Response) {
+	if response == nil || response.ErrorCode() == Success {
+		return
+	}
+
+	errors.mutex.Lock()
+	defer errors.mutex.Unlock()
+
+	if errors.entries == nil {
+		return
+	}
+
+	errors.entries[errors.next] = map[string]interface{}{
+		"time":    at.Format(time.RFC3339Nano),
+		"method":  methodName,
+		"id":      response.Id(),
+		"code":    response.ErrorCode(),
+		"message": response.ErrorMessage(),
+	}
+
+	errors.next = (errors.next + 1) % len(errors.entries)
+	if errors.next == 0 {
+		errors.full = true
+	}
+}
+
+// list returns the recorded errors, newest first.
+func (errors *recentErrors) list() []map[string]interface{} {
+	errors.mutex.Lock()
+	defer errors.mutex.Unlock()
+
+	count := errors.next
+	if errors.full {
+		count = len(errors.entries)
+	}
+
+	result := make([]map[string]interface{}, 0, count)
+	for i := 1; i <= count; i++ {
+		index := (errors.next - i + len(errors.entries)) % len(errors.entries)
+		result = append(result, errors.entries[index])
+	}
+
+	return result
+}
+
+// SetMaintenance puts the server into (or takes it out of) maintenance mode.
+// While in maintenance mode all calls receive a ServerError with the message
+// "Server is in maintenance mode.", except for the methods added by
+// EnableAdmin.
+//
+// It is safe to call SetMaintenance while requests are being handled.
+func (server *SimpleServer) SetMaintenance(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+
+	atomic.StoreInt32(&server.maintenance, value)
+}
+
+// Maintenance returns true if the server is in maintenance mode.
+func (server *SimpleServer) Maintenance() bool {
+	return atomic.LoadInt32(&server.maintenance) != 0
+}
+
+// inMaintenance returns true if the method must be rejected because of
+// maintenance mode.
+func (server *SimpleServer) inMaintenance(methodName string) bool {
+	return server.Maintenance() && !server.isAdminMethod(methodName)
+}
@@ -0,0 +1,191 @@
+package jsonrpc_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAdminServer() *jsonrpc.SimpleServer {
+	server := newTestServer()
+	server.EnableAdmin(func(request jsonrpc.Request) bool {
+		return request.State("token") == "secret"
+	})
+
+	return server
+}
+
+func callAdmin(server *jsonrpc.SimpleServer, method string, params interface{}) jsonrpc.Response {
+	request := jsonrpc.NewRequestResponderWithState("2.0", 1, method, params,
+		jsonrpc.State{"token": "secret"})
+
+	return server.HandleRequest(request)[0]
+}
+
+func TestSimpleServer_EnableAdmin(t *testing.T) {
+	t.Run("Unauthorized", func(t *testing.T) {
+		server := newAdminServer()
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"admin.stats","id":1}`))
+
+		assert.Equal(t, jsonrpc.ServerError, responses[0].ErrorCode())
+		assert.Equal(t, "Unauthorized.", responses[0].ErrorMessage())
+	})
+
+	t.Run("NilAuthorize", func(t *testing.T) {
+		server := newTestServer()
+		server.EnableAdmin(nil)
+
+		assert.Equal(t, "Unauthorized.", callAdmin(server, "admin.stats", nil).ErrorMessage())
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		server := newAdminServer()
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+
+		stats := callAdmin(server, "admin.stats", nil).Result().(map[string]interface{})
+		assert.Equal(t, uint64(2), stats["totalRequests"])
+		assert.Equal(t, uint64(1), stats["totalSuccessResponses"])
+		assert.Equal(t, uint64(1), stats["currentActiveRequests"])
+		assert.Equal(t, false, stats["maintenance"])
+	})
+
+	t.Run("Methods", func(t *testing.T) {
+		server := newAdminServer()
+		server.SetMethodInfo("sum", jsonrpc.MethodInfo{Description: "Adds numbers."})
+
+		catalog := callAdmin(server, "admin.methods", nil).Result().([]jsonrpc.MethodInfo)
+		assert.Contains(t, catalog, jsonrpc.MethodInfo{Name: "sum", Description: "Adds numbers."})
+		assert.Contains(t, catalog, jsonrpc.MethodInfo{Name: "admin.stats"})
+	})
+
+	t.Run("Maintenance", func(t *testing.T) {
+		server := newAdminServer()
+
+		assert.Equal(t, false, callAdmin(server, "admin.maintenance", nil).Result())
+		assert.Equal(t, true, callAdmin(server, "admin.maintenance",
+			map[string]interface{}{"enabled": true}).Result())
+		assert.True(t, server.Maintenance())
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+		assert.Equal(t, "Server is in maintenance mode.", responses[0].ErrorMessage())
+
+		// Admin methods can still be called.
+		assert.Equal(t, false, callAdmin(server, "admin.maintenance",
+			map[string]interface{}{"enabled": false}).Result())
+
+		responses = server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+		assert.Equal(t, 3.0, responses[0].Result())
+	})
+
+	t.Run("MaintenanceInvalidParams", func(t *testing.T) {
+		response := callAdmin(newAdminServer(), "admin.maintenance",
+			map[string]interface{}{"enabled": "yes"})
+
+		assert.Equal(t, jsonrpc.InvalidParams, response.ErrorCode())
+	})
+
+	t.Run("Disconnect", func(t *testing.T) {
+		server := newAdminServer()
+
+		assert.Equal(t, 0, callAdmin(server, "admin.disconnect",
+			map[string]interface{}{"client": "abc"}).Result())
+		assert.Equal(t, jsonrpc.InvalidParams, callAdmin(server, "admin.disconnect", nil).ErrorCode())
+	})
+
+	t.Run("Faults", func(t *testing.T) {
+		server := newAdminServer()
+
+		assert.Equal(t, map[string]interface{}{}, callAdmin(server, "admin.faults", nil).Result())
+
+		expected := map[string]interface{}{
+			"sum": map[string]interface{}{
+				"rate":    1.0,
+				"delay":   0.5,
+				"code":    jsonrpc.ServerError,
+				"message": "Oops.",
+			},
+		}
+		assert.Equal(t, expected, callAdmin(server, "admin.faults", map[string]interface{}{
+			"method": "sum", "rate": 1, "delay": 0.5, "code": jsonrpc.ServerError, "message": "Oops.",
+		}).Result())
+		assert.Equal(t, jsonrpc.Fault{
+			Rate:         1,
+			Delay:        500 * time.Millisecond,
+			ErrorCode:    jsonrpc.ServerError,
+			ErrorMessage: "Oops.",
+		}, server.Faults()["sum"])
+
+		// Faults are never injected into admin methods.
+		server.SetFault("admin.faults", jsonrpc.Fault{Rate: 1, ErrorCode: jsonrpc.ServerError})
+		response := callAdmin(server, "admin.faults",
+			map[string]interface{}{"method": "sum", "rate": 0})
+		assert.Equal(t, jsonrpc.Success, response.ErrorCode())
+		assert.NotContains(t, response.Result(), "sum")
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		server := newAdminServer()
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"nope","id":1}`))
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":2}`))
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":"foo","id":3}`))
+
+		errors := callAdmin(server, "admin.errors", nil).Result().([]map[string]interface{})
+		if assert.Len(t, errors, 2) {
+			assert.Equal(t, "sum", errors[0]["method"])
+			assert.Equal(t, 3.0, errors[0]["id"])
+			assert.Equal(t, "nope", errors[1]["method"])
+			assert.Equal(t, jsonrpc.MethodNotFound, errors[1]["code"])
+			assert.Equal(t, "Method not found", errors[1]["message"])
+		}
+	})
+
+	t.Run("ErrorsAreBounded", func(t *testing.T) {
+		server := newAdminServer()
+		for i := 0; i < 150; i++ {
+			server.Handle([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","method":"nope","id":%d}`, i)))
+		}
+
+		errors := callAdmin(server, "admin.errors", nil).Result().([]map[string]interface{})
+		assert.Len(t, errors, 100)
+		assert.Equal(t, 149.0, errors[0]["id"])
+		assert.Equal(t, 50.0, errors[99]["id"])
+	})
+
+	t.Run("OtherAdminPrefixedMethods", func(t *testing.T) {
+		server := newAdminServer()
+		server.SetHandler("admin.custom", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+			return request.NewSuccessResponse("ok")
+		})
+
+		server.SetFault("admin.custom", jsonrpc.Fault{Rate: 1, ErrorCode: jsonrpc.ServerError})
+		assert.Equal(t, "Injected fault.", callAdmin(server, "admin.custom", nil).ErrorMessage())
+
+		server.SetFault("admin.custom", jsonrpc.Fault{})
+		server.SetMaintenance(true)
+		assert.Equal(t, "Server is in maintenance mode.", callAdmin(server, "admin.custom", nil).ErrorMessage())
+		assert.Equal(t, true, callAdmin(server, "admin.maintenance", nil).Result())
+	})
+
+	t.Run("FaultsInvalidParams", func(t *testing.T) {
+		server := newAdminServer()
+
+		assert.Equal(t, jsonrpc.InvalidParams, callAdmin(server, "admin.faults",
+			map[string]interface{}{"rate": 1}).ErrorCode())
+		assert.Equal(t, jsonrpc.InvalidParams, callAdmin(server, "admin.faults",
+			map[string]interface{}{"method": "sum", "rate": "high"}).ErrorCode())
+	})
+}
+
+func TestSimpleServer_SetMaintenance(t *testing.T) {
+	server := newTestServer()
+	server.SetMaintenance(true)
+
+	responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+	assert.Equal(t, jsonrpc.ServerError, responses[0].ErrorCode())
+
+	server.SetMaintenance(false)
+	assert.False(t, server.Maintenance())
+}
@@ -0,0 +1,83 @@
+package jsonrpc
+
+// AMQPDelivery is a single message consumed from an AMQP queue (such as
+// RabbitMQ). It is usually a small wrapper around the delivery type of the
+// AMQP client being used.
+type AMQPDelivery interface {
+	Body() []byte
+	ReplyTo() string
+	CorrelationId() string
+	Headers() map[string]interface{}
+	Ack() error
+	Nack(requeue bool) error
+}
+
+// AMQPPublisher sends a reply to the replyTo queue with the correlationId of
+// the original delivery.
+type AMQPPublisher func(replyTo, correlationId string, body []byte) error
+
+// AckPolicy decides what to do with a delivery after it has been handled. If
+// ack is false the delivery will be nacked and requeue is passed to Nack.
+type AckPolicy func(responses Responses) (ack bool, requeue bool)
+
+// DefaultAckPolicy acknowledges every delivery except for payloads that could
+// not be parsed, which are nacked without being requeued. A payload that cannot
+// be parsed will never succeed so there is no point retrying it.
+func DefaultAckPolicy(responses Responses) (ack bool, requeue bool) {
+	if len(responses) == 1 && responses[0].ErrorCode() == ParseError {
+		return false, false
+	}
+
+	return true, false
+}
+
+// ServeAMQP handles deliveries until the channel is closed. This follows the
+// standard AMQP RPC convention where replies are published to the "reply_to"
+// queue of the delivery with the same "correlation_id".
+//
+// The delivery metadata is available to handlers through State:
+//
+//     request.State("amqp.replyTo")       // string
+//     request.State("amqp.correlationId") // string
+//     request.State("amqp.headers")       // map[string]interface{}
+//
+// Nothing is published for notifications or when the delivery does not have a
+// reply_to. After the payload has been handled the delivery is acknowledged
+// based on ackPolicy. If ackPolicy is nil then DefaultAckPolicy is used.
+//
+// ServeAMQP returns the first error from publishing or acknowledging a
+// delivery.
+func (server *SimpleServer) ServeAMQP(deliveries <-chan AMQPDelivery, publish AMQPPublisher, ackPolicy AckPolicy) error {
+	if ackPolicy == nil {
+		ackPolicy = DefaultAckPolicy
+	}
+
+	for delivery := range deliveries {
+		payload := delivery.Body()
+		responses, info := server.handlePayload(payload, State{
+			"amqp.replyTo":       delivery.ReplyTo(),
+			"amqp.correlationId": delivery.CorrelationId(),
+			"amqp.headers":       delivery.Headers(),
+			transportKey:         TransportAMQP,
+		})
+
+		if data := server.encodeResponses(info, responses); data != nil && delivery.ReplyTo() != "" {
+			if err := publish(delivery.ReplyTo(), delivery.CorrelationId(), data); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		if ack, requeue := ackPolicy(responses); ack {
+			err = delivery.Ack()
+		} else {
+			err = delivery.Nack(requeue)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package jsonrpc_test
+
+import (
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+type testDelivery struct {
+	body          string
+	replyTo       string
+	correlationId string
+	acked         bool
+	nacked        bool
+	requeued      bool
+}
+
+func (d *testDelivery) Body() []byte                    { return []byte(d.body) }
+func (d *testDelivery) ReplyTo() string                 { return d.replyTo }
+func (d *testDelivery) CorrelationId() string           { return d.correlationId }
+func (d *testDelivery) Headers() map[string]interface{} { return nil }
+
+func (d *testDelivery) Ack() error {
+	d.acked = true
+
+	return nil
+}
+
+func (d *testDelivery) Nack(requeue bool) error {
+	d.nacked = true
+	d.requeued = requeue
+
+	return nil
+}
+
+type testPublished struct {
+	replyTo, correlationId, body string
+}
+
+func serveAMQP(t *testing.T, server *jsonrpc.SimpleServer, ackPolicy jsonrpc.AckPolicy, deliveries ...*testDelivery) []testPublished {
+	ch := make(chan jsonrpc.AMQPDelivery, len(deliveries))
+	for _, delivery := range deliveries {
+		ch <- delivery
+	}
+	close(ch)
+
+	var published []testPublished
+	err := server.ServeAMQP(ch, func(replyTo, correlationId string, body []byte) error {
+		published = append(published, testPublished{replyTo, correlationId, string(body)})
+
+		return nil
+	}, ackPolicy)
+	assert.NoError(t, err)
+
+	return published
+}
+
+func TestSimpleServer_ServeAMQP(t *testing.T) {
+	t.Run("Reply", func(t *testing.T) {
+		delivery := &testDelivery{
+			body:          `{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}`,
+			replyTo:       "replies",
+			correlationId: "abc",
+		}
+		published := serveAMQP(t, newTestServer(), nil, delivery)
+
+		assert.Equal(t, []testPublished{
+			{"replies", "abc", `{"jsonrpc":"2.0","id":1,"result":7}`},
+		}, published)
+		assert.True(t, delivery.acked)
+	})
+
+	t.Run("State", func(t *testing.T) {
+		server := jsonrpc.NewSimpleServer()
+		server.SetHandler("correlation", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+			return request.NewSuccessResponse(request.State("amqp.correlationId"))
+		})
+		delivery := &testDelivery{
+			body:          `{"jsonrpc": "2.0", "method": "correlation", "id": 1}`,
+			replyTo:       "replies",
+			correlationId: "abc",
+		}
+		published := serveAMQP(t, server, nil, delivery)
+
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":"abc"}`, published[0].body)
+	})
+
+	t.Run("NoReplyTo", func(t *testing.T) {
+		delivery := &testDelivery{
+			body: `{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}`,
+		}
+		published := serveAMQP(t, newTestServer(), nil, delivery)
+
+		assert.Empty(t, published)
+		assert.True(t, delivery.acked)
+	})
+
+	t.Run("ParseErrorIsNacked", func(t *testing.T) {
+		delivery := &testDelivery{body: `{"jsonrpc"`, replyTo: "replies"}
+		serveAMQP(t, newTestServer(), nil, delivery)
+
+		assert.True(t, delivery.nacked)
+		assert.False(t, delivery.requeued)
+	})
+
+	t.Run("CustomAckPolicy", func(t *testing.T) {
+		delivery := &testDelivery{
+			body: `{"jsonrpc": "2.0", "method": "panic", "id": 1}`,
+		}
+		serveAMQP(t, newTestServer(), func(responses jsonrpc.Responses) (bool, bool) {
+			return responses[0].ErrorCode() == jsonrpc.Success, true
+		}, delivery)
+
+		assert.True(t, delivery.nacked)
+		assert.True(t, delivery.requeued)
+	})
+}
@@ -0,0 +1,193 @@
+package jsonrpc
+
+import (
+	"sync"
+	"time"
+)
+
+// The outcomes of an AuditRecord.
+const (
+	AuditSuccess = "success"
+	AuditDenied  = "denied"
+	AuditError   = "error"
+)
+
+// State key for the *auditTrail of a request.
+const auditKey = "jsonrpc.audit"
+
+// AuditCheck is the decision of a single authorization check, such as a role
+// or permission. See RecordAuditCheck.
+type AuditCheck struct {
+	Name    string `json:"name"`
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// AuditRecord describes a single call to a handler. See SetAudit.
+type AuditRecord struct {
+	Time     time.Time     `json:"time"`
+	Duration time.Duration `json:"duration"`
+
+	// Caller is the identity of the caller, as returned by the caller function
+	// given to SetAudit.
+	Caller string      `json:"caller,omitempty"`
+	Method string      `json:"method"`
+	ID     interface{} `json:"id,omitempty"`
+
+	// Checks are all of the authorization checks in the order they were made.
+	Checks []AuditCheck `json:"checks,omitempty"`
+
+	// Outcome is AuditDenied if any check was not allowed, otherwise
+	// AuditSuccess or AuditError depending on the response.
+	Outcome      string `json:"outcome"`
+	ErrorCode    int    `json:"errorCode,omitempty"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// AuditSink receives an AuditRecord after each call to a handler. Audit is
+// called before the response is sent, so it should not block for long.
+type AuditSink interface {
+	Audit(record AuditRecord)
+}
+
+// AuditSinkFunc allows a function to be used as an AuditSink.
+type AuditSinkFunc func(record AuditRecord)
+
+func (f AuditSinkFunc) Audit(record AuditRecord) {
+	f(record)
+}
+
+// auditTrail collects the checks for a single request while it is handled.
+type auditTrail struct {
+	mutex   sync.Mutex
+	checks  []AuditCheck
+	request Request
+}
+
+// SetAudit sends an AuditRecord to sink for every request that calls a
+// handler, including those that were stopped by middleware or a rate limit.
+// Raw and notification handlers are also audited, as well as requests that are
+// rejected before a handler is called (such as a Method not found error, or
+// when the server is overloaded). A request that is rejected in maintenance
+// mode fails the "maintenance" check. A nil sink disables auditing.
+//
+// caller returns the identity of the caller. It receives the request as seen
+// by the handler, so it can use State added by middleware:
+//
+//     server.SetAudit(sink, func(request jsonrpc.Request) string {
+//         user, _ := request.State("user").(string)
+//         return user
+//     })
+//
+// If the handler was not reached, caller receives the request as seen by the
+// deepest middleware that recorded a check, otherwise the original request. A
+// nil caller leaves Caller empty.
+func (server *SimpleServer) SetAudit(sink AuditSink, caller func(request Request) string) {
+	server.auditSink = sink
+	server.auditCaller = caller
+}
+
+// RecordAuditCheck adds the decision of an authorization check to the
+// AuditRecord of the request. It is intended to be called from middleware and
+// handlers:
+//
+//     allowed := user.HasRole("billing")
+//     jsonrpc.RecordAuditCheck(request, jsonrpc.AuditCheck{
+//         Name:    "role:billing",
+//         Allowed: allowed,
+//     })
+//
+// It does nothing if auditing is not enabled. See SetAudit.
+func RecordAuditCheck(request Request, check AuditCheck) {
+	trail, ok := request.State(auditKey).(*auditTrail)
+	if !ok {
+		return
+	}
+
+	trail.mutex.Lock()
+	defer trail.mutex.Unlock()
+
+	trail.checks = append(trail.checks, check)
+	trail.request = request
+}
+
+// auditHandler records an AuditRecord for each call to handler.
+func (server *SimpleServer) auditHandler(handler RequestHandler) RequestHandler {
+	if server.auditSink == nil {
+		return handler
+	}
+
+	return func(request RequestResponder) (response Response) {
+		trail := &auditTrail{request: request}
+		start := server.now()
+
+		defer func() {
+			trail.mutex.Lock()
+			defer trail.mutex.Unlock()
+
+			server.audit(request.Method(), request.Id(), trail.request, start,
+				trail.checks, response)
+		}()
+
+		return handler(WithState(request, State{auditKey: trail}))
+	}
+}
+
+// auditRejected records an AuditRecord for a request that was rejected before
+// a handler was called.
+func (server *SimpleServer) auditRejected(request Request, response Response, checks ...AuditCheck) {
+	if server.auditSink != nil {
+		server.audit(request.Method(), request.Id(), request, server.now(),
+			checks, response)
+	}
+}
+
+// audit sends an AuditRecord to the sink. The caller is resolved from
+// callerRequest.
+func (server *SimpleServer) audit(methodName string, id interface{}, callerRequest Request, start time.Time, checks []AuditCheck, response Response) {
+	record := AuditRecord{
+		Time:     start,
+		Duration: server.now().Sub(start),
+		Method:   methodName,
+		ID:       id,
+		Checks:   checks,
+		Outcome:  AuditSuccess,
+	}
+
+	if server.auditCaller != nil {
+		record.Caller = server.auditCaller(callerRequest)
+	}
+
+	// A nil response is from a panic, or a handler that did not return a
+	// response. Both are sent back as a ServerError.
+	if response == nil {
+		record.Outcome = AuditError
+		record.ErrorCode = ServerError
+	} else if response.ErrorCode() != Success {
+		record.Outcome = AuditError
+		record.ErrorCode = response.ErrorCode()
+		record.ErrorMessage = response.ErrorMessage()
+	}
+
+	for _, check := range checks {
+		if !check.Allowed {
+			record.Outcome = AuditDenied
+		}
+	}
+
+	server.auditSink.Audit(record)
+}
+
+// auditReached records the request as seen by the handler, so that the caller
+// can be resolved from the State added by middleware.
+func auditReached(request Request) {
+	trail, ok := request.State(auditKey).(*auditTrail)
+	if !ok {
+		return
+	}
+
+	trail.mutex.Lock()
+	defer trail.mutex.Unlock()
+
+	trail.request = request
+}
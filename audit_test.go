@@ -0,0 +1,213 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/elliotchance/jsonrpc/jsonrpctest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAuditServer(records *[]jsonrpc.AuditRecord) *jsonrpc.SimpleServer {
+	server := newTestServer()
+	server.SetAudit(jsonrpc.AuditSinkFunc(func(record jsonrpc.AuditRecord) {
+		*records = append(*records, record)
+	}), func(request jsonrpc.Request) string {
+		user, _ := request.State("user").(string)
+		return user
+	})
+
+	return server
+}
+
+func authenticate(next jsonrpc.RequestHandler) jsonrpc.RequestHandler {
+	return func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		return next(jsonrpc.WithState(request, jsonrpc.State{"user": "bob"}))
+	}
+}
+
+func requireRole(role string, allowed bool) jsonrpc.Middleware {
+	return func(next jsonrpc.RequestHandler) jsonrpc.RequestHandler {
+		return func(request jsonrpc.RequestResponder) jsonrpc.Response {
+			jsonrpc.RecordAuditCheck(request, jsonrpc.AuditCheck{
+				Name:    "role:" + role,
+				Allowed: allowed,
+			})
+
+			if !allowed {
+				return request.NewErrorResponse(jsonrpc.ServerError, "Forbidden.")
+			}
+
+			return next(request)
+		}
+	}
+}
+
+func TestSimpleServer_SetAudit(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		var records []jsonrpc.AuditRecord
+		clock := jsonrpctest.NewClock(time.Unix(1000, 0))
+		server := newAuditServer(&records)
+		server.SetClock(clock)
+		server.Use(authenticate)
+		server.SetHandler("slow", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+			clock.Advance(time.Second)
+			return request.NewSuccessResponse(true)
+		}, jsonrpc.WithMiddleware(requireRole("reader", true)))
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"slow","id":1}`))
+
+		assert.Equal(t, []jsonrpc.AuditRecord{{
+			Time:     time.Unix(1000, 0),
+			Duration: time.Second,
+			Caller:   "bob",
+			Method:   "slow",
+			ID:       1.0,
+			Checks:   []jsonrpc.AuditCheck{{Name: "role:reader", Allowed: true}},
+			Outcome:  jsonrpc.AuditSuccess,
+		}}, records)
+	})
+
+	t.Run("Denied", func(t *testing.T) {
+		var records []jsonrpc.AuditRecord
+		server := newAuditServer(&records)
+		server.Use(authenticate, requireRole("reader", true), requireRole("admin", false))
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+
+		assert.Len(t, records, 1)
+		assert.Equal(t, "bob", records[0].Caller)
+		assert.Equal(t, []jsonrpc.AuditCheck{
+			{Name: "role:reader", Allowed: true},
+			{Name: "role:admin", Allowed: false},
+		}, records[0].Checks)
+		assert.Equal(t, jsonrpc.AuditDenied, records[0].Outcome)
+		assert.Equal(t, "Forbidden.", records[0].ErrorMessage)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		var records []jsonrpc.AuditRecord
+		server := newAuditServer(&records)
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"panic","id":1}`))
+
+		assert.Len(t, records, 1)
+		assert.Equal(t, "", records[0].Caller)
+		assert.Equal(t, jsonrpc.AuditError, records[0].Outcome)
+		assert.Equal(t, jsonrpc.ServerError, records[0].ErrorCode)
+	})
+
+	t.Run("MethodNotFound", func(t *testing.T) {
+		var records []jsonrpc.AuditRecord
+		server := newAuditServer(&records)
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"missing","id":1}`))
+
+		assert.Len(t, records, 1)
+		assert.Equal(t, "missing", records[0].Method)
+		assert.Equal(t, jsonrpc.AuditError, records[0].Outcome)
+		assert.Equal(t, jsonrpc.MethodNotFound, records[0].ErrorCode)
+	})
+
+	t.Run("Maintenance", func(t *testing.T) {
+		var records []jsonrpc.AuditRecord
+		server := newAuditServer(&records)
+		server.SetMaintenance(true)
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+
+		assert.Len(t, records, 1)
+		assert.Equal(t, []jsonrpc.AuditCheck{{Name: "maintenance", Allowed: false}}, records[0].Checks)
+		assert.Equal(t, jsonrpc.AuditDenied, records[0].Outcome)
+		assert.Equal(t, "Server is in maintenance mode.", records[0].ErrorMessage)
+	})
+
+	t.Run("Overloaded", func(t *testing.T) {
+		var records []jsonrpc.AuditRecord
+		release := make(chan struct{})
+		server := newAuditServer(&records)
+		server.SetMaxConcurrentRequests(1, 0)
+		server.SetHandler("block", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+			<-release
+
+			return request.NewSuccessResponse(true)
+		})
+
+		first := make(chan jsonrpc.Responses)
+		go func() {
+			first <- server.Handle([]byte(`{"jsonrpc":"2.0","method":"block","id":1}`))
+		}()
+		assert.Eventually(t, func() bool {
+			return server.CurrentActiveRequests() == 1
+		}, time.Second, time.Millisecond)
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"block","id":2}`))
+		close(release)
+		<-first
+
+		assert.Len(t, records, 2)
+		assert.Equal(t, 2.0, records[0].ID)
+		assert.Equal(t, jsonrpc.AuditError, records[0].Outcome)
+		assert.Equal(t, "Server is overloaded.", records[0].ErrorMessage)
+		assert.Equal(t, jsonrpc.AuditSuccess, records[1].Outcome)
+	})
+
+	t.Run("RawHandler", func(t *testing.T) {
+		var records []jsonrpc.AuditRecord
+		server := newAuditServer(&records)
+		server.SetRawHandler("raw", func(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, *jsonrpc.RPCError) {
+			return nil, &jsonrpc.RPCError{Code: jsonrpc.InvalidParams, Message: "Nope."}
+		})
+
+		server.HandleWithState([]byte(`{"jsonrpc":"2.0","method":"raw","id":1}`),
+			jsonrpc.State{"user": "bob"})
+
+		assert.Len(t, records, 1)
+		assert.Equal(t, "bob", records[0].Caller)
+		assert.Equal(t, "raw", records[0].Method)
+		assert.Equal(t, jsonrpc.AuditError, records[0].Outcome)
+		assert.Equal(t, jsonrpc.InvalidParams, records[0].ErrorCode)
+	})
+
+	t.Run("NotificationHandler", func(t *testing.T) {
+		audited := make(chan jsonrpc.AuditRecord, 1)
+		server := jsonrpc.NewSimpleServer()
+		server.SetAudit(jsonrpc.AuditSinkFunc(func(record jsonrpc.AuditRecord) {
+			audited <- record
+		}), nil)
+		server.SetNotificationHandler("event", func(ctx context.Context, request jsonrpc.Request) {
+			jsonrpc.RecordAuditCheck(request, jsonrpc.AuditCheck{Name: "role:reader", Allowed: true})
+		})
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"event"}`))
+
+		record := <-audited
+		assert.Equal(t, "event", record.Method)
+		assert.Nil(t, record.ID)
+		assert.Equal(t, []jsonrpc.AuditCheck{{Name: "role:reader", Allowed: true}}, record.Checks)
+		assert.Equal(t, jsonrpc.AuditSuccess, record.Outcome)
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		var records []jsonrpc.AuditRecord
+		server := newAuditServer(&records)
+		server.EnableAdmin(func(request jsonrpc.Request) bool {
+			return false
+		})
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"admin.stats","id":1}`))
+
+		assert.Len(t, records, 1)
+		assert.Equal(t, []jsonrpc.AuditCheck{{Name: "admin", Allowed: false}}, records[0].Checks)
+		assert.Equal(t, jsonrpc.AuditDenied, records[0].Outcome)
+	})
+}
+
+func TestRecordAuditCheck(t *testing.T) {
+	// Without auditing the check is ignored.
+	request := jsonrpc.NewRequestResponder("2.0", 1, "sum", nil)
+	jsonrpc.RecordAuditCheck(request, jsonrpc.AuditCheck{Name: "role:reader"})
+}
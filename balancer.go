@@ -0,0 +1,192 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// BalanceStrategy decides which endpoint of a LoadBalancer receives a request.
+type BalanceStrategy int
+
+const (
+	// RoundRobin sends each request to the next endpoint in turn.
+	RoundRobin BalanceStrategy = iota
+
+	// Random sends each request to a random endpoint.
+	Random
+
+	// Failover sends requests to the first endpoint. If it fails, the next
+	// endpoint is tried, and so on.
+	Failover
+)
+
+// ErrNoEndpoints is returned by a LoadBalancer without any endpoints.
+var ErrNoEndpoints = errors.New("No endpoints")
+
+// LoadBalancer is a ClientTransport that spreads requests across (or fails over
+// between) several servers:
+//
+//     client := jsonrpc.NewClient(&jsonrpc.LoadBalancer{
+//         Endpoints: []jsonrpc.ClientTransport{
+//             &jsonrpc.HTTPTransport{URL: "http://a.example.com/rpc"},
+//             &jsonrpc.HTTPTransport{URL: "http://b.example.com/rpc"},
+//         },
+//         Strategy: jsonrpc.Failover,
+//     })
+//
+// With RoundRobin and Random, a request is only sent once. An error from the
+// chosen endpoint is returned even if other endpoints are available because
+// the request may have been processed. The exception is ErrCircuitOpen (see
+// CircuitBreaker), which means that the request was not sent, so the next
+// endpoint is used instead.
+//
+// HedgeAfter can be used to reduce tail latency. If the endpoint has not
+// responded within HedgeAfter, the same request is also sent to the next
+// endpoint and whichever response arrives first is used. Since a request may
+// be processed twice, this should only be used with idempotent methods.
+type LoadBalancer struct {
+	Endpoints  []ClientTransport
+	Strategy   BalanceStrategy
+	HedgeAfter time.Duration
+
+	next uint64
+}
+
+// NewLoadBalancedHTTPClient creates a client that sends requests to several
+// JSON-RPC over HTTP servers.
+func NewLoadBalancedHTTPClient(strategy BalanceStrategy, urls ...string) *Client {
+	balancer := &LoadBalancer{
+		Strategy: strategy,
+	}
+
+	for _, url := range urls {
+		balancer.Endpoints = append(balancer.Endpoints, &HTTPTransport{URL: url})
+	}
+
+	return NewClient(balancer)
+}
+
+func (balancer *LoadBalancer) RoundTrip(ctx context.Context, payload []byte, expectResponse bool) ([]byte, error) {
+	if len(balancer.Endpoints) == 0 {
+		return nil, ErrNoEndpoints
+	}
+
+	var first int
+	switch balancer.Strategy {
+	case Random:
+		first = rand.Intn(len(balancer.Endpoints))
+
+	case Failover:
+		if balancer.HedgeAfter <= 0 || !expectResponse {
+			return balancer.failover(ctx, payload, expectResponse)
+		}
+
+	default:
+		i := atomic.AddUint64(&balancer.next, 1) - 1
+		first = int(i % uint64(len(balancer.Endpoints)))
+	}
+
+	if balancer.HedgeAfter > 0 && expectResponse && len(balancer.Endpoints) > 1 {
+		return balancer.hedge(ctx, payload, first)
+	}
+
+	return balancer.send(ctx, payload, expectResponse, first)
+}
+
+// send sends the payload to the endpoint at first, or the next endpoint that
+// does not have an open circuit.
+func (balancer *LoadBalancer) send(ctx context.Context, payload []byte, expectResponse bool, first int) ([]byte, error) {
+	var data []byte
+	var err error
+	for i := range balancer.Endpoints {
+		endpoint := balancer.Endpoints[(first+i)%len(balancer.Endpoints)]
+		data, err = endpoint.RoundTrip(ctx, payload, expectResponse)
+		if !errors.Is(err, ErrCircuitOpen) {
+			break
+		}
+	}
+
+	return data, err
+}
+
+func (balancer *LoadBalancer) failover(ctx context.Context, payload []byte, expectResponse bool) ([]byte, error) {
+	var err error
+	for _, endpoint := range balancer.Endpoints {
+		var data []byte
+		data, err = endpoint.RoundTrip(ctx, payload, expectResponse)
+		if err == nil || ctx.Err() != nil {
+			return data, err
+		}
+	}
+
+	return nil, err
+}
+
+// hedge sends the payload to the first endpoint, and also to the next
+// endpoint if there is no response within HedgeAfter. The first successful
+// response for the request is returned.
+func (balancer *LoadBalancer) hedge(ctx context.Context, payload []byte, first int) ([]byte, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		data []byte
+		err  error
+	}
+
+	key, _ := payloadKey(payload)
+	results := make(chan result, 2)
+	send := func(endpoint ClientTransport) {
+		data, err := endpoint.RoundTrip(ctx, payload, true)
+		if err == nil {
+			if responseKey, ok := payloadKey(data); ok && responseKey != key {
+				err = fmt.Errorf("Expected response for %s but received %s", key, responseKey)
+			}
+		}
+
+		results <- result{data, err}
+	}
+
+	go send(balancer.Endpoints[first])
+	pending, hedged := 1, false
+	sendHedge := func() {
+		hedged = true
+		pending++
+		go send(balancer.Endpoints[(first+1)%len(balancer.Endpoints)])
+	}
+
+	timer := time.NewTimer(balancer.HedgeAfter)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if !hedged {
+				sendHedge()
+			}
+
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				return r.data, nil
+			}
+
+			if pending > 0 {
+				continue
+			}
+
+			// The first endpoint failed before the hedged request was sent,
+			// so send it now rather than waiting.
+			if !hedged && ctx.Err() == nil {
+				sendHedge()
+				continue
+			}
+
+			return nil, r.err
+		}
+	}
+}
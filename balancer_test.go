@@ -0,0 +1,172 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// namedTransport responds to every request with its name, or fails.
+type namedTransport struct {
+	name  string
+	err   error
+	calls int
+}
+
+func (transport *namedTransport) RoundTrip(ctx context.Context, payload []byte, expectResponse bool) ([]byte, error) {
+	transport.calls++
+	if transport.err != nil {
+		return nil, transport.err
+	}
+
+	request, _ := jsonrpc.NewRequestFromJSON(payload)
+
+	return jsonrpc.NewSuccessResponse(request.Id(), transport.name).Bytes(), nil
+}
+
+func TestLoadBalancer(t *testing.T) {
+	call := func(client *jsonrpc.Client) (interface{}, error) {
+		response, err := client.Call("foo", nil)
+		if err != nil {
+			return nil, err
+		}
+
+		return response.Result(), nil
+	}
+
+	t.Run("RoundRobin", func(t *testing.T) {
+		client := jsonrpc.NewClient(&jsonrpc.LoadBalancer{
+			Endpoints: []jsonrpc.ClientTransport{
+				&namedTransport{name: "a"},
+				&namedTransport{name: "b"},
+				&namedTransport{name: "c"},
+			},
+		})
+
+		var results []interface{}
+		for i := 0; i < 4; i++ {
+			result, err := call(client)
+			assert.NoError(t, err)
+			results = append(results, result)
+		}
+
+		assert.Equal(t, []interface{}{"a", "b", "c", "a"}, results)
+	})
+
+	t.Run("Random", func(t *testing.T) {
+		a, b := &namedTransport{name: "a"}, &namedTransport{name: "b"}
+		client := jsonrpc.NewClient(&jsonrpc.LoadBalancer{
+			Endpoints: []jsonrpc.ClientTransport{a, b},
+			Strategy:  jsonrpc.Random,
+		})
+
+		for i := 0; i < 100; i++ {
+			_, err := call(client)
+			assert.NoError(t, err)
+		}
+
+		assert.Equal(t, 100, a.calls+b.calls)
+		assert.NotZero(t, a.calls)
+		assert.NotZero(t, b.calls)
+	})
+
+	t.Run("Failover", func(t *testing.T) {
+		a := &namedTransport{name: "a", err: errors.New("a is down")}
+		b := &namedTransport{name: "b"}
+		client := jsonrpc.NewClient(&jsonrpc.LoadBalancer{
+			Endpoints: []jsonrpc.ClientTransport{a, b},
+			Strategy:  jsonrpc.Failover,
+		})
+
+		result, err := call(client)
+		assert.NoError(t, err)
+		assert.Equal(t, "b", result)
+
+		b.err = errors.New("b is down")
+		_, err = call(client)
+		assert.EqualError(t, err, "b is down")
+	})
+
+	t.Run("NoEndpoints", func(t *testing.T) {
+		_, err := call(jsonrpc.NewLoadBalancedHTTPClient(jsonrpc.RoundRobin))
+		assert.ErrorIs(t, err, jsonrpc.ErrNoEndpoints)
+	})
+}
+
+// slowTransport responds with its name after a delay.
+type slowTransport struct {
+	namedTransport
+	delay time.Duration
+	sent  int32
+}
+
+func (transport *slowTransport) RoundTrip(ctx context.Context, payload []byte, expectResponse bool) ([]byte, error) {
+	atomic.AddInt32(&transport.sent, 1)
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(transport.delay):
+	}
+
+	if transport.err != nil {
+		return nil, transport.err
+	}
+
+	request, _ := jsonrpc.NewRequestFromJSON(payload)
+
+	return jsonrpc.NewSuccessResponse(request.Id(), transport.name).Bytes(), nil
+}
+
+func TestLoadBalancer_HedgeAfter(t *testing.T) {
+	newClient := func(a, b *slowTransport) *jsonrpc.Client {
+		return jsonrpc.NewClient(&jsonrpc.LoadBalancer{
+			Endpoints:  []jsonrpc.ClientTransport{a, b},
+			Strategy:   jsonrpc.Failover,
+			HedgeAfter: 20 * time.Millisecond,
+		})
+	}
+
+	t.Run("FastPrimary", func(t *testing.T) {
+		a := &slowTransport{namedTransport: namedTransport{name: "a"}}
+		b := &slowTransport{namedTransport: namedTransport{name: "b"}}
+
+		response, err := newClient(a, b).Call("foo", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "a", response.Result())
+		assert.Equal(t, int32(0), atomic.LoadInt32(&b.sent))
+	})
+
+	t.Run("SlowPrimary", func(t *testing.T) {
+		a := &slowTransport{namedTransport: namedTransport{name: "a"}, delay: time.Second}
+		b := &slowTransport{namedTransport: namedTransport{name: "b"}}
+
+		start := time.Now()
+		response, err := newClient(a, b).Call("foo", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "b", response.Result())
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	})
+
+	t.Run("FailedPrimary", func(t *testing.T) {
+		a := &slowTransport{namedTransport: namedTransport{name: "a", err: errors.New("a is down")}}
+		b := &slowTransport{namedTransport: namedTransport{name: "b"}, delay: 50 * time.Millisecond}
+
+		response, err := newClient(a, b).Call("foo", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "b", response.Result())
+	})
+
+	t.Run("BothFail", func(t *testing.T) {
+		a := &slowTransport{namedTransport: namedTransport{name: "a", err: errors.New("a is down")}}
+		b := &slowTransport{namedTransport: namedTransport{name: "b", err: errors.New("b is down")}}
+
+		_, err := newClient(a, b).Call("foo", nil)
+		assert.EqualError(t, err, "b is down")
+	})
+}
@@ -0,0 +1,122 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"time"
+)
+
+// BatchBudget limits the resources that can be used by all of the requests of
+// a single batch. See SetBatchBudget.
+//
+// A zero value means there is no limit.
+type BatchBudget struct {
+	// MaxDuration is the total time the handlers can spend on the requests of
+	// the batch. When the requests are handled concurrently (see
+	// SetBatchWorkers) this is the sum of the time spent by each request, not
+	// the time taken by the batch.
+	MaxDuration time.Duration
+
+	// MaxResultSize is the total size of the JSON encoded responses of the
+	// batch, in bytes.
+	MaxResultSize int
+}
+
+// SetBatchBudget protects the server from batches that would take too long or
+// produce too much data. Once the budget has been used, the remaining requests
+// of the batch are not handled. Instead they receive a ServerError response
+// with the message "Batch budget exceeded.". Remaining notifications are
+// dropped.
+//
+// The request that goes over the budget is always completed, so the budget
+// may be exceeded by one request. Use SetLimits to limit individual requests.
+func (server *SimpleServer) SetBatchBudget(budget BatchBudget) {
+	server.batchBudget = budget
+}
+
+// SetMaxBatchSize limits the number of requests in a batch. A batch with more
+// requests is not handled at all. Instead it receives a single InvalidRequest
+// error with the message "Batch is too large." and the limit as the error data:
+//
+//     {"jsonrpc":"2.0","id":null,"error":{"code":-32600,
+//      "message":"Batch is too large.","data":{"maxBatchSize":100}}}
+//
+// Without a limit a single payload can make the server do an unbounded amount
+// of work. A max of zero (or less) allows batches of any size, which is the
+// default.
+func (server *SimpleServer) SetMaxBatchSize(max int) {
+	server.maxBatchSize = max
+}
+
+func (server *SimpleServer) batchTooLarge(size int) bool {
+	return server.maxBatchSize > 0 && size > server.maxBatchSize
+}
+
+// batchSpend is the budget used so far by a batch.
+type batchSpend struct {
+	budget   BatchBudget
+	duration int64
+	size     int64
+}
+
+// newBatchSpend returns nil if the batch does not have a budget.
+func (server *SimpleServer) newBatchSpend() *batchSpend {
+	if server.batchBudget == (BatchBudget{}) {
+		return nil
+	}
+
+	return &batchSpend{budget: server.batchBudget}
+}
+
+func (spent *batchSpend) exceeded() bool {
+	budget := spent.budget
+
+	return (budget.MaxDuration > 0 && time.Duration(atomic.LoadInt64(&spent.duration)) >= budget.MaxDuration) ||
+		(budget.MaxResultSize > 0 && atomic.LoadInt64(&spent.size) >= int64(budget.MaxResultSize))
+}
+
+// handleBudgetedRequest handles a request of a batch if there is budget
+// remaining.
+func (server *SimpleServer) handleBudgetedRequest(spent *batchSpend, rawRequest json.RawMessage, position int, state State) Responses {
+	if spent == nil {
+		return server.handleSingle(rawRequest, position, state)
+	}
+
+	if spent.exceeded() {
+		if responses, ok := batchBudgetExceeded(rawRequest); ok {
+			return responses
+		}
+	}
+
+	start := server.now()
+	responses := server.handleSingle(rawRequest, position, state)
+	atomic.AddInt64(&spent.duration, int64(server.now().Sub(start)))
+
+	for _, response := range responses {
+		atomic.AddInt64(&spent.size, int64(len(response.Bytes())))
+	}
+
+	return responses
+}
+
+// batchBudgetExceeded returns the responses for a request that cannot be
+// handled because the budget has been used. ok will be false if the request is
+// not valid, so that it receives the usual error.
+func batchBudgetExceeded(rawRequest json.RawMessage) (responses Responses, ok bool) {
+	var object map[string]json.RawMessage
+	if json.Unmarshal(rawRequest, &object) != nil || object == nil {
+		return nil, false
+	}
+
+	// A missing or null id is a notification, which does not receive a
+	// response in the same way as when it is handled.
+	var id interface{}
+	if rawId, ok := object["id"]; ok {
+		json.Unmarshal(rawId, &id)
+	}
+
+	responses = Responses{}
+	appendResponses(&responses, NewErrorResponse(id, ServerError, "Batch budget exceeded."))
+
+	return responses, true
+}
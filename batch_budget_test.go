@@ -0,0 +1,130 @@
+package jsonrpc_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/elliotchance/jsonrpc/jsonrpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBatchBudgetServer() (*jsonrpc.SimpleServer, *int) {
+	calls := 0
+	clock := jsonrpctest.NewClock(time.Unix(1000, 0))
+
+	server := jsonrpc.NewSimpleServer()
+	server.SetClock(clock)
+	server.SetHandler("work", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		calls++
+		clock.Advance(time.Second)
+
+		return request.NewSuccessResponse(strings.Repeat("x", 100))
+	})
+
+	return server, &calls
+}
+
+func TestSimpleServer_SetBatchBudget(t *testing.T) {
+	batch := []byte(`[
+		{"jsonrpc":"2.0","method":"work","id":1},
+		{"jsonrpc":"2.0","method":"work","id":2},
+		{"jsonrpc":"2.0","method":"work"},
+		{"jsonrpc":"2.0","method":"work","id":3},
+		1
+	]`)
+
+	t.Run("NoBudget", func(t *testing.T) {
+		server, calls := newBatchBudgetServer()
+
+		responses := server.Handle(batch)
+
+		assert.Len(t, responses, 4)
+		assert.Equal(t, 4, *calls)
+	})
+
+	t.Run("MaxDuration", func(t *testing.T) {
+		server, calls := newBatchBudgetServer()
+		server.SetBatchBudget(jsonrpc.BatchBudget{MaxDuration: 2 * time.Second})
+
+		responses := server.Handle(batch)
+
+		require.Len(t, responses, 4)
+		assert.Equal(t, 2, *calls)
+		assert.Equal(t, jsonrpc.Success, responses[0].ErrorCode())
+		assert.Equal(t, jsonrpc.Success, responses[1].ErrorCode())
+		assert.Equal(t, jsonrpc.NewErrorResponse(3.0, jsonrpc.ServerError,
+			"Batch budget exceeded."), responses[2])
+		assert.Equal(t, jsonrpc.InvalidRequest, responses[3].ErrorCode())
+	})
+
+	t.Run("MaxResultSize", func(t *testing.T) {
+		server, calls := newBatchBudgetServer()
+		server.SetBatchBudget(jsonrpc.BatchBudget{MaxResultSize: 50})
+
+		responses := server.Handle(batch)
+
+		require.Len(t, responses, 4)
+		assert.Equal(t, 1, *calls)
+		assert.Equal(t, jsonrpc.Success, responses[0].ErrorCode())
+		assert.Equal(t, "Batch budget exceeded.", responses[1].ErrorMessage())
+		assert.Equal(t, "Batch budget exceeded.", responses[2].ErrorMessage())
+	})
+
+	t.Run("NullId", func(t *testing.T) {
+		server, calls := newBatchBudgetServer()
+		server.SetBatchBudget(jsonrpc.BatchBudget{MaxDuration: time.Second})
+
+		responses := server.Handle([]byte(`[
+			{"jsonrpc":"2.0","method":"work","id":1},
+			{"jsonrpc":"2.0","method":"work","id":null},
+			{"jsonrpc":"2.0","method":"work","id":2}
+		]`))
+
+		require.Len(t, responses, 2)
+		assert.Equal(t, 1, *calls)
+		assert.Equal(t, jsonrpc.Success, responses[0].ErrorCode())
+		assert.Equal(t, jsonrpc.NewErrorResponse(2.0, jsonrpc.ServerError,
+			"Batch budget exceeded."), responses[1])
+	})
+
+	t.Run("SingleRequest", func(t *testing.T) {
+		server, calls := newBatchBudgetServer()
+		server.SetBatchBudget(jsonrpc.BatchBudget{MaxResultSize: 1})
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"work","id":1}`))
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"work","id":2}`))
+
+		assert.Equal(t, 2, *calls)
+		assert.Equal(t, jsonrpc.Success, responses[0].ErrorCode())
+	})
+}
+
+func TestSimpleServer_SetMaxBatchSize(t *testing.T) {
+	server, calls := newBatchBudgetServer()
+	server.SetMaxBatchSize(2)
+
+	responses := server.Handle([]byte(`[
+		{"jsonrpc":"2.0","method":"work","id":1},
+		{"jsonrpc":"2.0","method":"work","id":2}
+	]`))
+	assert.Len(t, responses, 2)
+
+	responses = server.Handle([]byte(`[
+		{"jsonrpc":"2.0","method":"work","id":1},
+		{"jsonrpc":"2.0","method":"work","id":2},
+		{"jsonrpc":"2.0","method":"work"}
+	]`))
+	assert.Equal(t,
+		`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Batch is too large.","data":{"maxBatchSize":2}}}`,
+		responses[0].String())
+	assert.Len(t, responses, 1)
+	assert.Equal(t, 2, *calls)
+
+	// A single request is not a batch.
+	server.SetMaxBatchSize(1)
+	responses = server.Handle([]byte(`{"jsonrpc":"2.0","method":"work","id":1}`))
+	assert.Equal(t, jsonrpc.Success, responses[0].ErrorCode())
+}
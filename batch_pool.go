@@ -0,0 +1,114 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// SetBatchWorkers handles the requests of a batch concurrently, with at most
+// workers requests of the same batch being handled at the same time. This
+// improves the throughput of large batches with slow (such as I/O bound)
+// handlers. A value of 0 or 1 handles the requests one at a time, which is the
+// default.
+//
+// The responses are still returned in the same order as the requests. The time
+// a request waits for a worker is included in the queue wait of
+// RequestTimings.
+//
+// Handlers (and middleware) must be safe to call concurrently when this is
+// enabled.
+func (server *SimpleServer) SetBatchWorkers(workers int) {
+	server.batchWorkers = workers
+}
+
+// The State key that is set for payloads from a connection that must be
+// handled in order. See SetOrderedConnections.
+const orderedKey = "jsonrpc.ordered"
+
+// SetOrderedConnections guarantees that the requests from a single stream
+// connection (see ServeConn and ServeStream) are handled strictly in the order
+// they arrive, for clients whose calls depend on each other. Each connection
+// is still handled concurrently with the other connections.
+//
+// Payloads from a connection are always handled one at a time. This option
+// also handles the requests inside a batch one at a time, even if
+// SetBatchWorkers is used. Batches from other transports (such as HTTP) still
+// use the batch workers.
+func (server *SimpleServer) SetOrderedConnections(ordered bool) {
+	server.orderedConnections = ordered
+}
+
+// connState returns the State shared by all of the payloads of a connection.
+func (server *SimpleServer) connState(transport string) State {
+	state := State{transportKey: transport}
+	if server.orderedConnections {
+		state[orderedKey] = true
+	}
+
+	return state
+}
+
+// handleBatch handles each of the requests of a batch. state must not be
+// shared with the caller, since it may be changed.
+func (server *SimpleServer) handleBatch(batch []json.RawMessage, state State) Responses {
+	results := make([]Responses, len(batch))
+	server.handleBatchFunc(batch, state, func(position int, responses Responses) {
+		results[position] = responses
+	})
+
+	responses := make(Responses, 0, len(batch))
+	for _, result := range results {
+		responses = append(responses, result...)
+	}
+
+	return responses
+}
+
+// handleBatchFunc calls done with the responses of each request of a batch as
+// soon as they are ready. done may be called concurrently (for different
+// positions) if there are batch workers.
+func (server *SimpleServer) handleBatchFunc(batch []json.RawMessage, state State, done func(position int, responses Responses)) {
+	spent := server.newBatchSpend()
+
+	workers := server.batchWorkers
+	if workers > len(batch) {
+		workers = len(batch)
+	}
+
+	if ordered, _ := state[orderedKey].(bool); ordered {
+		workers = 1
+	}
+
+	if workers <= 1 {
+		for position, request := range batch {
+			done(position, server.handleBudgetedRequest(spent, request, position, state))
+		}
+
+		return
+	}
+
+	// The request has been waiting for a worker since the batch was received,
+	// unless it was already waiting in a queue before that.
+	if _, ok := state[queuedAtKey]; !ok {
+		state[queuedAtKey] = server.now()
+	}
+
+	positions := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for position := range positions {
+				done(position, server.handleBudgetedRequest(spent, batch[position], position, state))
+			}
+		}()
+	}
+
+	for position := range batch {
+		positions <- position
+	}
+	close(positions)
+	wg.Wait()
+}
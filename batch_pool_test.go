@@ -0,0 +1,133 @@
+package jsonrpc_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// concurrencyServer has a "slow" method that records the most requests that
+// were handled at the same time.
+func concurrencyServer() (*jsonrpc.SimpleServer, func() int) {
+	var mutex sync.Mutex
+	active, max := 0, 0
+
+	server := jsonrpc.NewSimpleServer()
+	server.SetHandler("slow", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		mutex.Lock()
+		active++
+		if active > max {
+			max = active
+		}
+		mutex.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mutex.Lock()
+		active--
+		mutex.Unlock()
+
+		return request.NewSuccessResponse(request.Params())
+	})
+
+	return server, func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		return max
+	}
+}
+
+func batchOf(n int) []byte {
+	requests := make([]string, n)
+	for i := range requests {
+		requests[i] = fmt.Sprintf(`{"jsonrpc":"2.0","method":"slow","params":[%d],"id":%d}`, i, i)
+	}
+
+	return []byte("[" + strings.Join(requests, ",") + "]")
+}
+
+func TestSimpleServer_SetBatchWorkers(t *testing.T) {
+	t.Run("Sequential", func(t *testing.T) {
+		server, maxActive := concurrencyServer()
+
+		responses := server.Handle(batchOf(3))
+		assert.Len(t, responses, 3)
+		assert.Equal(t, 1, maxActive())
+		assert.Equal(t, uint64(0), server.RequestTimings().Queued)
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		server, maxActive := concurrencyServer()
+		server.SetBatchWorkers(2)
+
+		responses := server.Handle(batchOf(6))
+		assert.Equal(t, 2, maxActive())
+
+		// The responses are in the same order as the requests.
+		for i, response := range responses {
+			assert.Equal(t, float64(i), response.Id())
+			assert.Equal(t, []interface{}{float64(i)}, response.Result())
+		}
+
+		// The requests that waited for a worker are counted as queued.
+		timings := server.RequestTimings()
+		assert.Equal(t, uint64(6), timings.Queued)
+		assert.True(t, timings.MaxQueueWait >= 20*time.Millisecond, "max queue wait %v", timings.MaxQueueWait)
+	})
+
+	t.Run("InvalidRequests", func(t *testing.T) {
+		server, _ := concurrencyServer()
+		server.SetBatchWorkers(4)
+
+		responses := server.Handle([]byte(`[1,{"jsonrpc":"2.0","method":"slow","params":[1],"id":1},{"jsonrpc":"2.0","method":"slow","params":[2]}]`))
+		assert.Equal(t, `[{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Invalid request"}},{"jsonrpc":"2.0","id":1,"result":[1]}]`,
+			responses.String())
+	})
+}
+
+func TestSimpleServer_SetOrderedConnections(t *testing.T) {
+	serveBatch := func(server *jsonrpc.SimpleServer) string {
+		var output bytes.Buffer
+		err := server.ServeConn(struct {
+			io.Reader
+			io.Writer
+		}{bytes.NewReader(append(batchOf(4), '\n')), &output})
+		require.NoError(t, err)
+
+		return output.String()
+	}
+
+	t.Run("Ordered", func(t *testing.T) {
+		server, maxActive := concurrencyServer()
+		server.SetBatchWorkers(2)
+		server.SetOrderedConnections(true)
+
+		output := serveBatch(server)
+		assert.Equal(t, 1, maxActive())
+		assert.Equal(t, `[{"jsonrpc":"2.0","id":0,"result":[0]},`+
+			`{"jsonrpc":"2.0","id":1,"result":[1]},`+
+			`{"jsonrpc":"2.0","id":2,"result":[2]},`+
+			`{"jsonrpc":"2.0","id":3,"result":[3]}]`+"\n", output)
+
+		// Other transports still use the batch workers.
+		server.Handle(batchOf(4))
+		assert.Equal(t, 2, maxActive())
+	})
+
+	t.Run("NotOrdered", func(t *testing.T) {
+		server, maxActive := concurrencyServer()
+		server.SetBatchWorkers(2)
+
+		serveBatch(server)
+		assert.Equal(t, 2, maxActive())
+	})
+}
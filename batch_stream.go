@@ -0,0 +1,221 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// SetHTTPBatchStreaming sends each response of a batch over HTTP as soon as it
+// is ready, instead of waiting for the whole batch. The requests of the batch
+// are handled in the same way as other batches (see SetBatchWorkers and
+// SetBatchBudget) and the responses are written (with chunked transfer
+// encoding) to a JSON array in the order that they finish:
+//
+//     [{"jsonrpc":"2.0","id":2,"result":"fast"}
+//     ,{"jsonrpc":"2.0","id":1,"result":"slow"}
+//     ]
+//
+// Each response ends with a new line, so the responses can also be read one
+// line at a time.
+//
+// This allows clients that decode the array as a stream to start processing
+// the responses before the slowest request has finished. It makes no
+// difference to clients that read the whole response.
+//
+// Streamed responses are not compressed. Single requests (and batches that
+// are only notifications) are not affected.
+func (server *SimpleServer) SetHTTPBatchStreaming(enabled bool) {
+	server.httpBatchStreaming = enabled
+}
+
+// streamHTTPBatch writes the responses of a batch as they are ready. false is
+// returned if the payload cannot be streamed and must be handled normally.
+func (server *SimpleServer) streamHTTPBatch(w http.ResponseWriter, r *http.Request, payload []byte) bool {
+	flusher, ok := w.(http.Flusher)
+	if !server.httpBatchStreaming || !ok {
+		return false
+	}
+
+	var batch []json.RawMessage
+	if json.Unmarshal(payload, &batch) != nil || len(batch) == 0 ||
+		server.batchTooLarge(len(batch)) {
+		return false
+	}
+
+	server.start()
+	atomic.AddUint64(&server.totalPayloads, 1)
+	received := server.now()
+	state := State{
+		contextKey:   r.Context(),
+		transportKey: TransportHTTP,
+	}
+	server.observePayload(state)
+
+	writer := &arrayWriter{
+		w: w,
+		start: func() {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+		},
+		flush: flusher.Flush,
+	}
+
+	// The whole response is only kept if it needs to be captured.
+	capture := server.wireCapture.enabled()
+	if capture {
+		writer.capture = new(bytes.Buffer)
+	}
+
+	var mutex sync.Mutex
+	server.handleBatchFunc(batch, state, func(position int, responses Responses) {
+		server.observeTransportResponses(state, responses)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		for _, response := range responses {
+			writer.write(response)
+		}
+	})
+
+	if !writer.close() {
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	if capture {
+		server.wireCapture.record(received, payload, writer.captured())
+	}
+
+	return true
+}
+
+// HandleTo is like HandleMessage except that the responses are written to w.
+// The responses of a batch are written as soon as each one is ready, so that
+// the client of a large batch can start receiving results before the slowest
+// request has finished. The output is a valid JSON array once it is complete:
+//
+//     [{"jsonrpc":"2.0","id":1,"result":3}
+//     ,{"jsonrpc":"2.0","id":2,"result":7}
+//     ]
+//
+// Each response ends with a new line, so the responses can also be read one
+// line at a time. The responses are written in the order they finish, which is
+// the order of the requests unless SetBatchWorkers is used.
+//
+// Nothing is written if there is nothing to send back. The error is from the
+// first write to w that failed. All of the requests are still handled after a
+// write fails.
+func (server *SimpleServer) HandleTo(w io.Writer, payload []byte) error {
+	var batch []json.RawMessage
+	if json.Unmarshal(payload, &batch) != nil || len(batch) == 0 ||
+		server.batchTooLarge(len(batch)) {
+		data := server.HandleMessage(payload, nil)
+		if data == nil {
+			return nil
+		}
+
+		_, err := w.Write(data)
+
+		return err
+	}
+
+	server.start()
+	atomic.AddUint64(&server.totalPayloads, 1)
+	received := server.now()
+	state := State{}
+	server.observePayload(state)
+
+	writer := &arrayWriter{w: w}
+	capture := server.wireCapture.enabled()
+	if capture {
+		writer.capture = new(bytes.Buffer)
+	}
+
+	var mutex sync.Mutex
+	server.handleBatchFunc(batch, state, func(position int, responses Responses) {
+		server.observeTransportResponses(state, responses)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		for _, response := range responses {
+			writer.write(response)
+		}
+	})
+	writer.close()
+
+	if capture {
+		server.wireCapture.record(received, payload, writer.captured())
+	}
+
+	return writer.err
+}
+
+// arrayWriter writes the responses of a batch as a JSON array, one response
+// at a time. It is not safe to use concurrently.
+type arrayWriter struct {
+	w io.Writer
+
+	// start is called before the first response is written, and flush after
+	// each write. Both are optional.
+	start func()
+	flush func()
+
+	// capture receives a copy of everything written, if it is not nil.
+	capture *bytes.Buffer
+
+	started bool
+	err     error
+}
+
+func (writer *arrayWriter) write(response Response) {
+	separator := ","
+	if !writer.started {
+		if writer.start != nil {
+			writer.start()
+		}
+
+		separator = "["
+		writer.started = true
+	}
+
+	chunk := append([]byte(separator), response.Bytes()...)
+	writer.writeChunk(append(chunk, '\n'))
+}
+
+// writeChunk writes data unless a previous write has failed.
+func (writer *arrayWriter) writeChunk(data []byte) {
+	if writer.err == nil {
+		_, writer.err = writer.w.Write(data)
+	}
+
+	if writer.flush != nil {
+		writer.flush()
+	}
+
+	if writer.capture != nil {
+		writer.capture.Write(data)
+	}
+}
+
+// close ends the array. It returns false if nothing was written.
+func (writer *arrayWriter) close() bool {
+	if writer.started {
+		writer.writeChunk([]byte("]"))
+	}
+
+	return writer.started
+}
+
+// captured returns everything that was written, or nil if nothing was.
+func (writer *arrayWriter) captured() []byte {
+	if !writer.started {
+		return nil
+	}
+
+	return writer.capture.Bytes()
+}
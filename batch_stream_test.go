@@ -0,0 +1,217 @@
+package jsonrpc_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postBatch(t *testing.T, url, payload string) *http.Response {
+	response, err := http.Post(url, "application/json", strings.NewReader(payload))
+	assert.NoError(t, err)
+
+	return response
+}
+
+func TestSimpleServer_SetHTTPBatchStreaming(t *testing.T) {
+	release := make(chan bool)
+	server := newBlockingServer(release)
+	server.SetHTTPBatchStreaming(true)
+	server.SetBatchWorkers(2)
+
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	t.Run("Streamed", func(t *testing.T) {
+		response := postBatch(t, httpServer.URL, `[
+			{"jsonrpc":"2.0","method":"block","id":1},
+			{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":2},
+			{"jsonrpc":"2.0","method":"notify_hello","params":[7]}
+		]`)
+		defer response.Body.Close()
+
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+
+		// The fast response arrives while the slow request is still blocked.
+		reader := bufio.NewReader(response.Body)
+		line, err := reader.ReadString('\n')
+		assert.NoError(t, err)
+		assert.Equal(t, `[{"jsonrpc":"2.0","id":2,"result":3}`+"\n", line)
+
+		release <- true
+
+		rest, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		assert.Equal(t, `,{"jsonrpc":"2.0","id":1,"result":true}`+"\n]", string(rest))
+
+		var responses []map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line+string(rest)), &responses))
+		assert.Len(t, responses, 2)
+	})
+
+	t.Run("OnlyNotifications", func(t *testing.T) {
+		response := postBatch(t, httpServer.URL, `[{"jsonrpc":"2.0","method":"notify_hello","params":[7]}]`)
+		response.Body.Close()
+
+		assert.Equal(t, http.StatusNoContent, response.StatusCode)
+	})
+
+	t.Run("InvalidRequests", func(t *testing.T) {
+		response := postBatch(t, httpServer.URL, `[1]`)
+		defer response.Body.Close()
+
+		body, _ := io.ReadAll(response.Body)
+		assert.Equal(t, `[{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Invalid request"}}`+"\n]", string(body))
+	})
+
+	t.Run("SingleRequest", func(t *testing.T) {
+		response := postBatch(t, httpServer.URL, `{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`)
+		defer response.Body.Close()
+
+		body, _ := io.ReadAll(response.Body)
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":3}`, string(body))
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		server := newTestServer()
+		server.SetHTTPBatchStreaming(true)
+		httpServer := httptest.NewServer(server)
+		defer httpServer.Close()
+
+		response := postBatch(t, httpServer.URL, `[{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1},{"jsonrpc":"2.0","method":"foo","id":2}]`)
+		response.Body.Close()
+
+		assert.Equal(t, uint64(1), server.TotalPayloads())
+		assert.Equal(t, uint64(1), server.TotalRequests())
+		assert.Equal(t, uint64(1), server.TotalSuccessResponses())
+		assert.Equal(t, uint64(1), server.TotalErrorResponses())
+	})
+}
+
+func TestSimpleServer_SetHTTPBatchStreamingBudget(t *testing.T) {
+	server, calls := newBatchBudgetServer()
+	server.SetHTTPBatchStreaming(true)
+	server.SetBatchBudget(jsonrpc.BatchBudget{MaxResultSize: 50})
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	response := postBatch(t, httpServer.URL, `[
+		{"jsonrpc":"2.0","method":"work","id":1},
+		{"jsonrpc":"2.0","method":"work","id":2},
+		{"jsonrpc":"2.0","method":"work","id":3}
+	]`)
+	defer response.Body.Close()
+
+	var responses []map[string]interface{}
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&responses))
+	require.Len(t, responses, 3)
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, 1.0, responses[0]["id"])
+	for _, response := range responses[1:] {
+		assert.Equal(t, "Batch budget exceeded.", response["error"].(map[string]interface{})["message"])
+	}
+}
+
+func TestSimpleServer_SetHTTPBatchStreamingDisabled(t *testing.T) {
+	httpServer := httptest.NewServer(newTestServer())
+	defer httpServer.Close()
+
+	response := postBatch(t, httpServer.URL, `[{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}]`)
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(response.Body)
+	assert.Equal(t, `[{"jsonrpc":"2.0","id":1,"result":3}]`, string(body))
+}
+
+func TestSimpleServer_SetHTTPBatchStreamingMaxBatchSize(t *testing.T) {
+	server := newTestServer()
+	server.SetHTTPBatchStreaming(true)
+	server.SetMaxBatchSize(1)
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	response := postBatch(t, httpServer.URL, `[{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1},{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":2}]`)
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(response.Body)
+	assert.Equal(t, `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Batch is too large.","data":{"maxBatchSize":1}}}`, string(body))
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(data []byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestSimpleServer_HandleTo(t *testing.T) {
+	t.Run("Streamed", func(t *testing.T) {
+		release := make(chan bool)
+		server := newBlockingServer(release)
+		reader, writer := io.Pipe()
+
+		done := make(chan error, 1)
+		go func() {
+			done <- server.HandleTo(writer, []byte(`[
+				{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1},
+				{"jsonrpc":"2.0","method":"notify_hello","params":[7]},
+				{"jsonrpc":"2.0","method":"block","id":2}
+			]`))
+			writer.Close()
+		}()
+
+		// The first response arrives while the second request is still
+		// being handled.
+		lines := bufio.NewReader(reader)
+		line, err := lines.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, `[{"jsonrpc":"2.0","id":1,"result":3}`+"\n", line)
+
+		close(release)
+		rest, err := io.ReadAll(lines)
+		require.NoError(t, err)
+		assert.Equal(t, `,{"jsonrpc":"2.0","id":2,"result":true}`+"\n]", string(rest))
+		assert.NoError(t, <-done)
+
+		var responses []interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line+string(rest)), &responses))
+		assert.Equal(t, uint64(1), server.TotalPayloads())
+	})
+
+	t.Run("SingleRequest", func(t *testing.T) {
+		var output strings.Builder
+		err := newTestServer().HandleTo(&output,
+			[]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+
+		assert.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":3}`, output.String())
+	})
+
+	t.Run("OnlyNotifications", func(t *testing.T) {
+		var output strings.Builder
+		err := newTestServer().HandleTo(&output,
+			[]byte(`[{"jsonrpc":"2.0","method":"notify_hello","params":[7]}]`))
+
+		assert.NoError(t, err)
+		assert.Empty(t, output.String())
+	})
+
+	t.Run("WriteError", func(t *testing.T) {
+		server := newTestServer()
+		err := server.HandleTo(failingWriter{}, []byte(`[
+			{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1},
+			{"jsonrpc":"2.0","method":"sum","params":[3,4],"id":2}
+		]`))
+
+		assert.ErrorIs(t, err, io.ErrClosedPipe)
+		assert.Equal(t, uint64(2), server.TotalRequests())
+	})
+}
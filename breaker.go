@@ -0,0 +1,209 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by a CircuitBreaker that is not sending requests
+// because the endpoint has been failing.
+var ErrCircuitOpen = errors.New("Circuit breaker is open")
+
+// CircuitBreaker is a ClientTransport that stops sending requests to an
+// endpoint after it fails too many times in a row. Requests fail immediately
+// with ErrCircuitOpen until the cool down has passed. Then a single request is
+// allowed through to test the endpoint. If it succeeds the endpoint is used
+// normally again, otherwise the cool down starts again.
+//
+// A failure is a transport error or a response with an Internal error or
+// Server error. Other errors (such as Invalid params) are caused by the
+// request, not the endpoint.
+//
+// With HealthCheck, the endpoint is tested with a "rpc.ping" request instead
+// of a real one. Requests keep failing with ErrCircuitOpen until the ping
+// succeeds, so no real request is sent to an endpoint that is still down.
+//
+// A breaker should be used for each endpoint of a LoadBalancer, so that
+// failing endpoints are skipped quickly:
+//
+//     client := jsonrpc.NewClient(&jsonrpc.LoadBalancer{
+//         Endpoints: []jsonrpc.ClientTransport{
+//             &jsonrpc.CircuitBreaker{Transport: primary},
+//             &jsonrpc.CircuitBreaker{Transport: secondary},
+//         },
+//         Strategy: jsonrpc.Failover,
+//     })
+type CircuitBreaker struct {
+	Transport ClientTransport
+
+	// Threshold is the number of consecutive failures that will open the
+	// circuit. The default is 5.
+	Threshold int
+
+	// CoolDown is how long the circuit stays open. The default is 30 seconds.
+	CoolDown time.Duration
+
+	// HealthCheck sends a "rpc.ping" request to test the endpoint once the
+	// cool down has passed. Any response that is not an Internal error or
+	// Server error closes the circuit, so a server that does not implement
+	// "rpc.ping" (and responds with Method not found) is still healthy.
+	HealthCheck bool
+
+	// Clock is used to measure the cool down. If it is nil the system clock
+	// is used.
+	Clock Clock
+
+	mutex     sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func (breaker *CircuitBreaker) RoundTrip(ctx context.Context, payload []byte, expectResponse bool) ([]byte, error) {
+	probe, err := breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	if probe && breaker.HealthCheck {
+		if err := breaker.ping(ctx); err != nil {
+			return nil, err
+		}
+
+		probe = false
+	}
+
+	data, err := breaker.Transport.RoundTrip(ctx, payload, expectResponse)
+
+	// A cancelled request does not say anything about the endpoint.
+	if ctx.Err() != nil {
+		breaker.mutex.Lock()
+		if probe {
+			breaker.probing = false
+		}
+		breaker.mutex.Unlock()
+
+		return data, err
+	}
+
+	breaker.record(probe, err == nil && !isServerFailure(data))
+
+	return data, err
+}
+
+// pingPayload is the health check sent by a CircuitBreaker.
+var pingPayload = []byte(`{"jsonrpc":"2.0","method":"rpc.ping","id":"rpc.ping"}`)
+
+// ping tests the endpoint while the circuit is open. ErrCircuitOpen is returned
+// if the endpoint is still failing.
+func (breaker *CircuitBreaker) ping(ctx context.Context) error {
+	data, err := breaker.Transport.RoundTrip(ctx, pingPayload, true)
+
+	if ctx.Err() != nil {
+		breaker.mutex.Lock()
+		breaker.probing = false
+		breaker.mutex.Unlock()
+
+		return ctx.Err()
+	}
+
+	healthy := err == nil && !isServerFailure(data)
+	breaker.record(true, healthy)
+
+	if !healthy {
+		return ErrCircuitOpen
+	}
+
+	return nil
+}
+
+// Open reports whether requests are currently being rejected.
+func (breaker *CircuitBreaker) Open() bool {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	return breaker.now().Before(breaker.openUntil)
+}
+
+// allow returns ErrCircuitOpen if the request cannot be sent. probe is true if
+// this request will decide whether the circuit closes again.
+func (breaker *CircuitBreaker) allow() (probe bool, err error) {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	if breaker.failures < breaker.threshold() {
+		return false, nil
+	}
+
+	if breaker.probing || breaker.now().Before(breaker.openUntil) {
+		return false, ErrCircuitOpen
+	}
+
+	breaker.probing = true
+
+	return true, nil
+}
+
+func (breaker *CircuitBreaker) record(probe, success bool) {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	if probe {
+		breaker.probing = false
+	}
+
+	if success {
+		breaker.failures = 0
+		return
+	}
+
+	breaker.failures++
+	if breaker.failures >= breaker.threshold() {
+		coolDown := breaker.CoolDown
+		if coolDown <= 0 {
+			coolDown = 30 * time.Second
+		}
+
+		breaker.openUntil = breaker.now().Add(coolDown)
+	}
+}
+
+func (breaker *CircuitBreaker) threshold() int {
+	if breaker.Threshold <= 0 {
+		return 5
+	}
+
+	return breaker.Threshold
+}
+
+func (breaker *CircuitBreaker) now() time.Time {
+	if breaker.Clock == nil {
+		return time.Now()
+	}
+
+	return breaker.Clock.Now()
+}
+
+// isServerFailure reports whether the response payload contains an error that
+// was caused by the server, rather than the request.
+func isServerFailure(data []byte) bool {
+	if data == nil {
+		return false
+	}
+
+	responses, err := NewResponsesFromJSON(data)
+	if err != nil {
+		return true
+	}
+
+	for _, response := range responses {
+		code := response.ErrorCode()
+		if code == InternalError || (code >= ServerErrorMin && code <= ServerError) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,199 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/elliotchance/jsonrpc/jsonrpctest"
+	"github.com/stretchr/testify/assert"
+)
+
+// transportFunc is a ClientTransport that responds to each request with code.
+type transportFunc func(request jsonrpc.Request) (int, error)
+
+func (fn transportFunc) RoundTrip(ctx context.Context, payload []byte, expectResponse bool) ([]byte, error) {
+	request, _ := jsonrpc.NewRequestFromJSON(payload)
+	code, err := fn(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if code == jsonrpc.Success {
+		return jsonrpc.NewSuccessResponse(request.Id(), true).Bytes(), nil
+	}
+
+	return jsonrpc.NewErrorResponse(request.Id(), code, "").Bytes(), nil
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	clock := jsonrpctest.NewClock(time.Now())
+	calls := 0
+	var code int
+	var failure error
+	breaker := &jsonrpc.CircuitBreaker{
+		Transport: transportFunc(func(request jsonrpc.Request) (int, error) {
+			calls++
+
+			return code, failure
+		}),
+		Threshold: 2,
+		CoolDown:  time.Minute,
+		Clock:     clock,
+	}
+	client := jsonrpc.NewClient(breaker)
+
+	call := func() error {
+		_, err := client.Call("foo", nil)
+		return err
+	}
+
+	// Errors caused by the request do not count.
+	code = jsonrpc.InvalidParams
+	assert.NoError(t, call())
+	assert.NoError(t, call())
+	assert.False(t, breaker.Open())
+
+	// A success resets the consecutive failures.
+	code = jsonrpc.ServerError
+	assert.NoError(t, call())
+	code = jsonrpc.Success
+	assert.NoError(t, call())
+	code = jsonrpc.InternalError
+	assert.NoError(t, call())
+	assert.False(t, breaker.Open())
+
+	failure = errors.New("connection refused")
+	assert.ErrorIs(t, call(), failure)
+	assert.True(t, breaker.Open())
+
+	calls = 0
+	assert.ErrorIs(t, call(), jsonrpc.ErrCircuitOpen)
+	assert.Equal(t, 0, calls)
+
+	// The probe after the cool down fails, so the circuit opens again.
+	clock.Advance(time.Minute)
+	assert.False(t, breaker.Open())
+	assert.ErrorIs(t, call(), failure)
+	assert.ErrorIs(t, call(), jsonrpc.ErrCircuitOpen)
+	assert.Equal(t, 1, calls)
+
+	// The probe succeeds.
+	clock.Advance(time.Minute)
+	failure, code = nil, jsonrpc.Success
+	assert.NoError(t, call())
+	assert.NoError(t, call())
+	assert.Equal(t, 3, calls)
+}
+
+func TestCircuitBreaker_Failover(t *testing.T) {
+	down := &jsonrpc.CircuitBreaker{
+		Transport: transportFunc(func(request jsonrpc.Request) (int, error) {
+			return 0, errors.New("down")
+		}),
+		Threshold: 1,
+	}
+	up := &jsonrpc.CircuitBreaker{
+		Transport: transportFunc(func(request jsonrpc.Request) (int, error) {
+			return jsonrpc.Success, nil
+		}),
+	}
+	client := jsonrpc.NewClient(&jsonrpc.LoadBalancer{
+		Endpoints: []jsonrpc.ClientTransport{down, up},
+		Strategy:  jsonrpc.Failover,
+	})
+
+	for i := 0; i < 3; i++ {
+		response, err := client.Call("foo", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, true, response.Result())
+	}
+
+	assert.True(t, down.Open())
+}
+
+func TestCircuitBreaker_HealthCheck(t *testing.T) {
+	clock := jsonrpctest.NewClock(time.Now())
+	var methods []string
+	var failure error
+	breaker := &jsonrpc.CircuitBreaker{
+		Transport: transportFunc(func(request jsonrpc.Request) (int, error) {
+			methods = append(methods, request.Method())
+
+			return jsonrpc.Success, failure
+		}),
+		Threshold:   1,
+		CoolDown:    time.Minute,
+		HealthCheck: true,
+		Clock:       clock,
+	}
+	client := jsonrpc.NewClient(breaker)
+
+	failure = errors.New("connection refused")
+	_, err := client.Call("foo", nil)
+	assert.ErrorIs(t, err, failure)
+	assert.True(t, breaker.Open())
+
+	// The ping fails, so the request is not sent.
+	clock.Advance(time.Minute)
+	_, err = client.Call("foo", nil)
+	assert.ErrorIs(t, err, jsonrpc.ErrCircuitOpen)
+	assert.Equal(t, []string{"foo", "rpc.ping"}, methods)
+	assert.True(t, breaker.Open())
+
+	// The ping succeeds, so the circuit closes and the request is sent.
+	clock.Advance(time.Minute)
+	failure = nil
+	_, err = client.Call("foo", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foo", "rpc.ping", "rpc.ping", "foo"}, methods)
+
+	_, err = client.Call("foo", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foo", "rpc.ping", "rpc.ping", "foo", "foo"}, methods)
+}
+
+func TestCircuitBreaker_HealthCheckServer(t *testing.T) {
+	jsonrpcServer := jsonrpc.NewSimpleServer()
+	jsonrpcServer.SetPingMethod(true)
+	server := httptest.NewServer(jsonrpcServer)
+	defer server.Close()
+
+	breaker := &jsonrpc.CircuitBreaker{
+		Transport:   &jsonrpc.HTTPTransport{URL: server.URL},
+		HealthCheck: true,
+	}
+
+	response, err := jsonrpc.NewClient(breaker).Call("rpc.ping", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "pong", response.Result())
+}
+
+func TestCircuitBreaker_RoundRobin(t *testing.T) {
+	down := &jsonrpc.CircuitBreaker{
+		Transport: transportFunc(func(request jsonrpc.Request) (int, error) {
+			return 0, errors.New("down")
+		}),
+		Threshold: 1,
+	}
+	up := &jsonrpc.CircuitBreaker{
+		Transport: &namedTransport{name: "up"},
+	}
+	client := jsonrpc.NewClient(&jsonrpc.LoadBalancer{
+		Endpoints: []jsonrpc.ClientTransport{down, up},
+	})
+
+	// The first request fails because it was sent to the endpoint that is
+	// down. After that the endpoint is skipped.
+	_, err := client.Call("foo", nil)
+	assert.Error(t, err)
+
+	for i := 0; i < 4; i++ {
+		response, err := client.Call("foo", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "up", response.Result())
+	}
+}
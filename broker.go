@@ -0,0 +1,104 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Broker sends the notifications from Broadcast between servers, so that a
+// broadcast on one server also reaches the subscribers that are connected to
+// other servers. It can be adapted from any pub/sub system, such as Redis or
+// NATS. See SetBroker.
+type Broker interface {
+	// Publish sends a message to all of the servers using the broker,
+	// including this one.
+	Publish(message []byte) error
+
+	// Subscribe starts delivering the messages published by any server to
+	// receive. It is called once by SetBroker and must not block.
+	Subscribe(receive func(message []byte)) error
+}
+
+// brokerMessage is the message sent through the Broker for each broadcast.
+type brokerMessage struct {
+	Origin       string          `json:"origin"`
+	Topic        string          `json:"topic"`
+	Notification json.RawMessage `json:"notification"`
+}
+
+// SetBroker connects the server to other servers so that Broadcast reaches
+// all subscribers, regardless of which server they are connected to. The
+// servers should also share a SubscriptionStore.
+func (server *SimpleServer) SetBroker(broker Broker) error {
+	origin := GenerateRequestId()
+
+	err := broker.Subscribe(func(data []byte) {
+		var message brokerMessage
+		if json.Unmarshal(data, &message) != nil || message.Origin == origin {
+			return
+		}
+
+		server.broadcast(message.Topic, message.Notification)
+	})
+	if err != nil {
+		return err
+	}
+
+	server.subscriptionMutex.Lock()
+	defer server.subscriptionMutex.Unlock()
+
+	server.broker = broker
+	server.brokerOrigin = origin
+
+	return nil
+}
+
+// publish sends a notification to the other servers, if there is a broker.
+func (server *SimpleServer) publish(topic string, notification []byte) error {
+	server.subscriptionMutex.Lock()
+	broker, origin := server.broker, server.brokerOrigin
+	server.subscriptionMutex.Unlock()
+
+	if broker == nil {
+		return nil
+	}
+
+	message, err := json.Marshal(brokerMessage{
+		Origin:       origin,
+		Topic:        topic,
+		Notification: notification,
+	})
+	if err != nil {
+		return err
+	}
+
+	return broker.Publish(message)
+}
+
+// MemoryBroker is a Broker for servers running in the same process, such as
+// in tests.
+type MemoryBroker struct {
+	mutex     sync.Mutex
+	receivers []func(message []byte)
+}
+
+func (broker *MemoryBroker) Publish(message []byte) error {
+	broker.mutex.Lock()
+	receivers := broker.receivers
+	broker.mutex.Unlock()
+
+	for _, receive := range receivers {
+		receive(message)
+	}
+
+	return nil
+}
+
+func (broker *MemoryBroker) Subscribe(receive func(message []byte)) error {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	broker.receivers = append(broker.receivers, receive)
+
+	return nil
+}
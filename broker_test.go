@@ -0,0 +1,100 @@
+package jsonrpc_test
+
+import (
+	"bufio"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingBroker struct {
+	err error
+}
+
+func (broker failingBroker) Publish(message []byte) error {
+	return broker.err
+}
+
+func (broker failingBroker) Subscribe(receive func(message []byte)) error {
+	return broker.err
+}
+
+func TestSimpleServer_SetBroker(t *testing.T) {
+	store := &jsonrpc.MemorySubscriptionStore{}
+	broker := &jsonrpc.MemoryBroker{}
+
+	server1 := newTestServer()
+	server1.SetSubscriptionStore(store)
+	assert.NoError(t, server1.SetBroker(broker))
+
+	server2 := newTestServer()
+	server2.SetSubscriptionStore(store)
+	assert.NoError(t, server2.SetBroker(broker))
+
+	assert.NoError(t, server1.Subscribe("abc", "prices"))
+
+	// The client is connected to the second server.
+	httpServer := httptest.NewServer(server2.EventsHandler(identifyClient))
+	defer httpServer.Close()
+
+	response, err := openEventStream(httpServer.URL, "abc")
+	assert.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Eventually(t, func() bool {
+		return server2.NotifyClient("abc", "ready", nil) == nil
+	}, time.Second, time.Millisecond)
+
+	sent, err := server1.Broadcast("prices", "priceChanged", []float64{1.5})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	reader := bufio.NewReader(response.Body)
+
+	line, err := reader.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, `data: {"jsonrpc":"2.0","method":"ready","id":null}`+"\n", line)
+
+	_, err = reader.ReadString('\n')
+	assert.NoError(t, err)
+
+	line, err = reader.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, `data: {"jsonrpc":"2.0","method":"priceChanged","params":[1.5],"id":null}`+"\n", line)
+
+	// The server that broadcasts does not receive its own message again.
+	sent, err = server2.Broadcast("prices", "priceChanged", []float64{2.5})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	_, err = reader.ReadString('\n')
+	assert.NoError(t, err)
+
+	line, err = reader.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, `data: {"jsonrpc":"2.0","method":"priceChanged","params":[2.5],"id":null}`+"\n", line)
+
+	assert.NoError(t, server2.NotifyClient("abc", "done", nil))
+
+	_, err = reader.ReadString('\n')
+	assert.NoError(t, err)
+
+	line, err = reader.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, `data: {"jsonrpc":"2.0","method":"done","id":null}`+"\n", line)
+}
+
+func TestSimpleServer_SetBroker_Error(t *testing.T) {
+	brokerErr := errors.New("connection refused")
+	server := newTestServer()
+
+	assert.Equal(t, brokerErr, server.SetBroker(failingBroker{brokerErr}))
+
+	// The broker was not set, so broadcasts are only local.
+	_, err := server.Broadcast("prices", "priceChanged", nil)
+	assert.NoError(t, err)
+}
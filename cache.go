@@ -0,0 +1,220 @@
+package jsonrpc
+
+import (
+	"sync"
+	"time"
+)
+
+type cachePolicy struct {
+	ttl                  time.Duration
+	staleWhileRevalidate time.Duration
+}
+
+type cacheEntry struct {
+	method     string
+	response   Response
+	storedAt   time.Time
+	maxAge     time.Duration // overrides the ttl of the policy if not zero
+	refreshing bool
+}
+
+// resultCache holds successful responses for methods that have caching
+// enabled. Entries are keyed by the method and params of the request.
+type resultCache struct {
+	mutex     sync.Mutex
+	policies  map[string]cachePolicy
+	entries   map[string]*cacheEntry
+	nextSweep int
+}
+
+// The number of entries before expired entries are removed for the first
+// time. After that, expired entries are removed each time the number of
+// entries doubles.
+const cacheSweepSize = 64
+
+// SetCache enables caching of successful results for a method. A cached result
+// is returned to any request with the same method and params until it is older
+// than ttl. Error responses are never cached.
+//
+// If staleWhileRevalidate is not zero, a result that has expired will still be
+// returned for up to that additional duration. Returning a stale result will
+// trigger the handler to be called in the background to refresh the cached
+// result. The context of the refresh is not cancelled when the request that
+// triggered it is finished. This is useful for methods that need low latency
+// but can tolerate slightly out of date results.
+//
+// A ttl of zero will disable caching for the method and remove any results
+// that are already cached for it.
+//
+// A handler can override the ttl for an individual result by returning it with
+// NewSuccessResponseWithMaxAge.
+//
+// Like SetCollapseDuplicates, the State of requests is not considered when
+// looking up a cached result.
+func (server *SimpleServer) SetCache(methodName string, ttl, staleWhileRevalidate time.Duration) {
+	cache := &server.cache
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.policies == nil {
+		cache.policies = make(map[string]cachePolicy)
+		cache.entries = make(map[string]*cacheEntry)
+	}
+
+	if ttl <= 0 {
+		delete(cache.policies, methodName)
+		for key, entry := range cache.entries {
+			if entry.method == methodName {
+				delete(cache.entries, key)
+			}
+		}
+
+		return
+	}
+
+	cache.policies[methodName] = cachePolicy{
+		ttl:                  ttl,
+		staleWhileRevalidate: staleWhileRevalidate,
+	}
+}
+
+// callHandler runs the handler for a request, or uses a cached result if
+// caching has been enabled for the method.
+func (server *SimpleServer) callHandler(handler RequestHandler, request RequestResponder) Response {
+	cache := &server.cache
+	cache.mutex.Lock()
+	policy, ok := cache.policies[request.Method()]
+	if !ok {
+		cache.mutex.Unlock()
+
+		return server.callCollapsed(handler, request)
+	}
+
+	key, ok := requestKey(request)
+	if !ok {
+		cache.mutex.Unlock()
+
+		return server.callCollapsed(handler, request)
+	}
+
+	if entry, ok := cache.entries[key]; ok {
+		age := server.now().Sub(entry.storedAt)
+		ttl := policy.ttl
+		if entry.maxAge > 0 {
+			ttl = entry.maxAge
+		}
+
+		if age < ttl {
+			cache.mutex.Unlock()
+
+			return copyCachedResponse(request, entry.response, age)
+		}
+
+		if age < ttl+policy.staleWhileRevalidate {
+			if !entry.refreshing {
+				entry.refreshing = true
+				go server.refreshCache(handler, detachRequest(request), key, entry)
+			}
+			cache.mutex.Unlock()
+
+			return copyCachedResponse(request, entry.response, age)
+		}
+
+		delete(cache.entries, key)
+	}
+	cache.mutex.Unlock()
+
+	response := server.callCollapsed(handler, request)
+	if response != nil && response.ErrorCode() == Success {
+		cache.store(request.Method(), key, response, server.now())
+	}
+
+	return response
+}
+
+// refreshCache calls the handler in the background to replace a stale entry.
+func (server *SimpleServer) refreshCache(handler RequestHandler, request RequestResponder, key string, entry *cacheEntry) {
+	cache := &server.cache
+
+	defer func() {
+		// There is nobody to send a panic back to. The stale entry will be
+		// refreshed again by the next request.
+		if r := recover(); r != nil {
+			server.reportPanic(request, r)
+		}
+
+		cache.mutex.Lock()
+		entry.refreshing = false
+		cache.mutex.Unlock()
+	}()
+
+	response := server.callCollapsed(handler, request)
+	if response != nil && response.ErrorCode() == Success {
+		cache.store(request.Method(), key, response, server.now())
+	}
+}
+
+func (cache *resultCache) store(methodName, key string, response Response, storedAt time.Time) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	// Caching may have been disabled while the handler was running.
+	if _, ok := cache.policies[methodName]; !ok {
+		return
+	}
+
+	// A max-age of zero means the handler does not want the result reused.
+	maxAge, hasMaxAge := ResponseMaxAge(response)
+	if hasMaxAge && maxAge <= 0 {
+		return
+	}
+
+	cache.entries[key] = &cacheEntry{
+		method:   methodName,
+		response: response,
+		storedAt: storedAt,
+		maxAge:   maxAge,
+	}
+
+	// Expired entries are only removed when the same request is made again,
+	// so they are removed here to stop the cache growing with params that are
+	// never used again.
+	if len(cache.entries) >= cache.nextSweep {
+		for entryKey, entry := range cache.entries {
+			if cache.expired(entry, storedAt) {
+				delete(cache.entries, entryKey)
+			}
+		}
+
+		cache.nextSweep = 2 * len(cache.entries)
+		if cache.nextSweep < cacheSweepSize {
+			cache.nextSweep = cacheSweepSize
+		}
+	}
+}
+
+// expired returns true if the entry can no longer be returned, even as a
+// stale result. mutex must be locked.
+func (cache *resultCache) expired(entry *cacheEntry, now time.Time) bool {
+	policy, ok := cache.policies[entry.method]
+	if !ok {
+		return true
+	}
+
+	ttl := policy.ttl
+	if entry.maxAge > 0 {
+		ttl = entry.maxAge
+	}
+
+	return now.Sub(entry.storedAt) >= ttl+policy.staleWhileRevalidate
+}
+
+// CacheSize returns the number of results that are cached (see SetCache). It
+// includes expired results that have not been removed yet.
+func (server *SimpleServer) CacheSize() int {
+	cache := &server.cache
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return len(cache.entries)
+}
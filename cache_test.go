@@ -0,0 +1,163 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/elliotchance/jsonrpc/jsonrpctest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCachingTestServer(calls *uint64) *jsonrpc.SimpleServer {
+	server := jsonrpc.NewSimpleServer()
+	server.SetHandler("counter", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponse(float64(atomic.AddUint64(calls, 1)))
+	})
+	server.SetHandler("fail", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		atomic.AddUint64(calls, 1)
+
+		return request.NewErrorResponse(jsonrpc.ServerError, "")
+	})
+
+	return server
+}
+
+func TestSimpleServer_SetCache(t *testing.T) {
+	t.Run("ResultIsCached", func(t *testing.T) {
+		calls := uint64(0)
+		server := newCachingTestServer(&calls)
+		server.SetCache("counter", time.Minute, 0)
+
+		r1 := server.Handle([]byte(`{"jsonrpc": "2.0", "method": "counter", "params": [1], "id": 1}`))
+		r2 := server.Handle([]byte(`{"jsonrpc": "2.0", "method": "counter", "params": [1], "id": 2}`))
+
+		assert.Equal(t, jsonrpc.Responses{jsonrpc.NewSuccessResponse(1.0, 1.0)}, r1)
+		assert.Equal(t, jsonrpc.Responses{jsonrpc.NewSuccessResponse(2.0, 1.0)}, r2)
+		assert.Equal(t, uint64(1), atomic.LoadUint64(&calls))
+	})
+
+	t.Run("DifferentParams", func(t *testing.T) {
+		calls := uint64(0)
+		server := newCachingTestServer(&calls)
+		server.SetCache("counter", time.Minute, 0)
+
+		server.Handle([]byte(`{"jsonrpc": "2.0", "method": "counter", "params": [1], "id": 1}`))
+		r := server.Handle([]byte(`{"jsonrpc": "2.0", "method": "counter", "params": [2], "id": 2}`))
+
+		assert.Equal(t, jsonrpc.Responses{jsonrpc.NewSuccessResponse(2.0, 2.0)}, r)
+	})
+
+	t.Run("ErrorsAreNotCached", func(t *testing.T) {
+		calls := uint64(0)
+		server := newCachingTestServer(&calls)
+		server.SetCache("fail", time.Minute, 0)
+
+		server.Handle([]byte(`{"jsonrpc": "2.0", "method": "fail", "id": 1}`))
+		server.Handle([]byte(`{"jsonrpc": "2.0", "method": "fail", "id": 2}`))
+
+		assert.Equal(t, uint64(2), atomic.LoadUint64(&calls))
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		calls := uint64(0)
+		server := newCachingTestServer(&calls)
+		server.SetCache("counter", time.Millisecond, 0)
+
+		server.Handle([]byte(`{"jsonrpc": "2.0", "method": "counter", "id": 1}`))
+		time.Sleep(5 * time.Millisecond)
+		r := server.Handle([]byte(`{"jsonrpc": "2.0", "method": "counter", "id": 2}`))
+
+		assert.Equal(t, jsonrpc.Responses{jsonrpc.NewSuccessResponse(2.0, 2.0)}, r)
+	})
+
+	t.Run("StaleWhileRevalidate", func(t *testing.T) {
+		calls := uint64(0)
+		server := newCachingTestServer(&calls)
+		server.SetCache("counter", time.Millisecond, time.Minute)
+
+		server.Handle([]byte(`{"jsonrpc": "2.0", "method": "counter", "id": 1}`))
+		time.Sleep(5 * time.Millisecond)
+
+		// The stale value is returned immediately.
+		r := server.Handle([]byte(`{"jsonrpc": "2.0", "method": "counter", "id": 2}`))
+		assert.Equal(t, jsonrpc.Responses{jsonrpc.NewSuccessResponse(2.0, 1.0)}, r)
+
+		// Eventually the background refresh will replace the stale value.
+		assert.Eventually(t, func() bool {
+			r := server.Handle([]byte(`{"jsonrpc": "2.0", "method": "counter", "id": 3}`))
+			return r[0].Result() != 1.0
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("RefreshOutlivesRequest", func(t *testing.T) {
+		calls := uint64(0)
+		server := jsonrpc.NewSimpleServer()
+		server.SetContextHandler("counter", func(ctx context.Context, request jsonrpc.RequestResponder) jsonrpc.Response {
+			time.Sleep(5 * time.Millisecond)
+			if ctx.Err() != nil {
+				return request.NewErrorResponse(jsonrpc.ServerError, ctx.Err().Error())
+			}
+
+			return request.NewSuccessResponse(float64(atomic.AddUint64(&calls, 1)))
+		})
+		server.SetCache("counter", time.Millisecond, time.Minute)
+
+		server.Handle([]byte(`{"jsonrpc": "2.0", "method": "counter", "id": 1}`))
+		time.Sleep(5 * time.Millisecond)
+
+		// The refresh must not be cancelled when the request that triggered
+		// it is finished.
+		ctx, cancel := context.WithCancel(context.Background())
+		server.HandleContext(ctx, []byte(`{"jsonrpc": "2.0", "method": "counter", "id": 2}`))
+		cancel()
+
+		assert.Eventually(t, func() bool {
+			return atomic.LoadUint64(&calls) == 2
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("Disable", func(t *testing.T) {
+		calls := uint64(0)
+		server := newCachingTestServer(&calls)
+		server.SetCache("counter", time.Minute, 0)
+
+		server.Handle([]byte(`{"jsonrpc": "2.0", "method": "counter", "id": 1}`))
+		server.SetCache("counter", 0, 0)
+		r := server.Handle([]byte(`{"jsonrpc": "2.0", "method": "counter", "id": 2}`))
+
+		assert.Equal(t, jsonrpc.Responses{jsonrpc.NewSuccessResponse(2.0, 2.0)}, r)
+
+		// Enabling the cache again must not return a result that was cached
+		// before it was disabled.
+		server.SetCache("counter", time.Minute, 0)
+		r = server.Handle([]byte(`{"jsonrpc": "2.0", "method": "counter", "id": 3}`))
+
+		assert.Equal(t, jsonrpc.Responses{jsonrpc.NewSuccessResponse(3.0, 3.0)}, r)
+	})
+
+	t.Run("ExpiredEntriesAreRemoved", func(t *testing.T) {
+		calls := uint64(0)
+		clock := jsonrpctest.NewClock(time.Unix(1000, 0))
+		server := newCachingTestServer(&calls)
+		server.SetClock(clock)
+		server.SetCache("counter", time.Minute, 0)
+
+		handle := func(from, to int) {
+			for i := from; i < to; i++ {
+				server.Handle([]byte(fmt.Sprintf(`{"jsonrpc": "2.0", "method": "counter", "params": [%d], "id": 1}`, i)))
+			}
+		}
+
+		handle(0, 64)
+		assert.Equal(t, 64, server.CacheSize())
+
+		// None of the expired params are requested again.
+		clock.Advance(2 * time.Minute)
+		handle(64, 128)
+		assert.Equal(t, 64, server.CacheSize())
+	})
+}
@@ -0,0 +1,108 @@
+package jsonrpc
+
+import (
+	"sync"
+	"time"
+)
+
+// WireCapture is the exact bytes received and sent for a single payload. See
+// SetWireCapture.
+type WireCapture struct {
+	// Time is when the payload was received.
+	Time time.Time
+
+	// Request is the payload exactly as it was received.
+	Request []byte
+
+	// Response is exactly what was sent back. It will be nil if nothing was
+	// sent back, such as for notifications.
+	Response []byte
+}
+
+// wireCaptures is a fixed size ring buffer of the most recent captures.
+type wireCaptures struct {
+	mutex    sync.Mutex
+	captures []WireCapture
+	next     int
+	full     bool
+	hook     func(WireCapture)
+}
+
+// SetWireCapture enables capturing of the exact bytes of each payload and its
+// responses. This is intended for debugging, such as comparing the output
+// against other implementations or attaching to bug reports.
+//
+// Only the most recent size captures are retained. A size of zero disables
+// capturing and discards any existing captures.
+//
+// Captures are only recorded for payloads that pass through HandleMessage,
+// which includes all of the transports provided by this package.
+func (server *SimpleServer) SetWireCapture(size int) {
+	capture := &server.wireCapture
+	capture.mutex.Lock()
+	defer capture.mutex.Unlock()
+
+	capture.captures = make([]WireCapture, size)
+	capture.next = 0
+	capture.full = false
+}
+
+// OnWireCapture sets a function that is called with every new capture. It is
+// only called when wire capture is enabled with SetWireCapture. The function
+// is called synchronously so it should not block.
+func (server *SimpleServer) OnWireCapture(hook func(WireCapture)) {
+	capture := &server.wireCapture
+	capture.mutex.Lock()
+	defer capture.mutex.Unlock()
+
+	capture.hook = hook
+}
+
+// WireCaptures returns the retained captures, oldest first.
+func (server *SimpleServer) WireCaptures() []WireCapture {
+	capture := &server.wireCapture
+	capture.mutex.Lock()
+	defer capture.mutex.Unlock()
+
+	if !capture.full {
+		return append([]WireCapture{}, capture.captures[:capture.next]...)
+	}
+
+	return append(append([]WireCapture{}, capture.captures[capture.next:]...),
+		capture.captures[:capture.next]...)
+}
+
+func (capture *wireCaptures) enabled() bool {
+	capture.mutex.Lock()
+	defer capture.mutex.Unlock()
+
+	return len(capture.captures) > 0
+}
+
+func (capture *wireCaptures) record(received time.Time, request, response []byte) {
+	capture.mutex.Lock()
+	if len(capture.captures) == 0 {
+		capture.mutex.Unlock()
+		return
+	}
+
+	c := WireCapture{
+		Time:     received,
+		Request:  append([]byte(nil), request...),
+		Response: append([]byte(nil), response...),
+	}
+
+	capture.captures[capture.next] = c
+	capture.next += 1
+	if capture.next == len(capture.captures) {
+		capture.next = 0
+		capture.full = true
+	}
+
+	hook := capture.hook
+	capture.mutex.Unlock()
+
+	if hook != nil {
+		hook(c)
+	}
+}
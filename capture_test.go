@@ -0,0 +1,71 @@
+package jsonrpc_test
+
+import (
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimpleServer_SetWireCapture(t *testing.T) {
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		server := newTestServer()
+		server.HandleMessage([]byte(`{"jsonrpc": "2.0", "method": "sum", "params": [1], "id": 1}`), nil)
+
+		assert.Empty(t, server.WireCaptures())
+	})
+
+	t.Run("ExactBytes", func(t *testing.T) {
+		server := newTestServer()
+		server.SetWireCapture(10)
+		server.HandleMessage([]byte(`{"jsonrpc": "2.0", "method": "sum", "params": [1], "id": 1}`), nil)
+		server.HandleMessage([]byte(`{"jsonrpc": "2.0", "method": "sum", "params": [1]}`), nil)
+
+		captures := server.WireCaptures()
+		assert.Len(t, captures, 2)
+
+		assert.Equal(t, `{"jsonrpc": "2.0", "method": "sum", "params": [1], "id": 1}`, string(captures[0].Request))
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":1}`, string(captures[0].Response))
+		assert.False(t, captures[0].Time.IsZero())
+
+		assert.Equal(t, `{"jsonrpc": "2.0", "method": "sum", "params": [1]}`, string(captures[1].Request))
+		assert.Nil(t, captures[1].Response)
+	})
+
+	t.Run("Bounded", func(t *testing.T) {
+		server := newTestServer()
+		server.SetWireCapture(2)
+		server.HandleMessage([]byte(`{"jsonrpc": "2.0", "method": "sum", "params": [1], "id": 1}`), nil)
+		server.HandleMessage([]byte(`{"jsonrpc": "2.0", "method": "sum", "params": [2], "id": 2}`), nil)
+		server.HandleMessage([]byte(`{"jsonrpc": "2.0", "method": "sum", "params": [3], "id": 3}`), nil)
+
+		captures := server.WireCaptures()
+		assert.Len(t, captures, 2)
+		assert.Equal(t, `{"jsonrpc":"2.0","id":2,"result":2}`, string(captures[0].Response))
+		assert.Equal(t, `{"jsonrpc":"2.0","id":3,"result":3}`, string(captures[1].Response))
+	})
+
+	t.Run("Hook", func(t *testing.T) {
+		server := newTestServer()
+		server.SetWireCapture(1)
+
+		var captured []jsonrpc.WireCapture
+		server.OnWireCapture(func(capture jsonrpc.WireCapture) {
+			captured = append(captured, capture)
+		})
+
+		server.HandleMessage([]byte(`{"jsonrpc": "2.0", "method": "sum", "params": [1], "id": 1}`), nil)
+
+		assert.Len(t, captured, 1)
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":1}`, string(captured[0].Response))
+	})
+
+	t.Run("Disable", func(t *testing.T) {
+		server := newTestServer()
+		server.SetWireCapture(1)
+		server.HandleMessage([]byte(`{"jsonrpc": "2.0", "method": "sum", "params": [1], "id": 1}`), nil)
+		server.SetWireCapture(0)
+
+		assert.Empty(t, server.WireCaptures())
+	})
+}
@@ -0,0 +1,128 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MethodInfo describes a method for documentation. See SetMethodInfo.
+type MethodInfo struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Params      []ParamInfo `json:"params,omitempty"`
+	Result      string      `json:"result,omitempty"`
+	Deprecated  bool        `json:"deprecated,omitempty"`
+}
+
+// ParamInfo describes a single parameter of a method.
+type ParamInfo struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// CatalogFormat is the output format for ExportCatalog.
+type CatalogFormat int
+
+const (
+	CatalogJSON CatalogFormat = iota
+	CatalogMarkdown
+)
+
+// SetMethodInfo attaches documentation to a method. The Name of info is
+// ignored and will always be methodName.
+func (server *SimpleServer) SetMethodInfo(methodName string, info MethodInfo) {
+	info.Name = methodName
+
+	if server.methodInfo == nil {
+		server.methodInfo = make(map[string]MethodInfo)
+	}
+
+	server.methodInfo[methodName] = info
+}
+
+// MethodInfo returns the documentation for a method. A method that does not
+// have any documentation will only contain the Name.
+func (server *SimpleServer) MethodInfo(methodName string) MethodInfo {
+	info, ok := server.methodInfo[methodName]
+	if !ok {
+		return MethodInfo{Name: methodName}
+	}
+
+	return info
+}
+
+// ExportCatalog writes the documentation for all of the methods that have a
+// handler, sorted by name. This is suitable for publishing API reference
+// material generated from the live server.
+func (server *SimpleServer) ExportCatalog(w io.Writer, format CatalogFormat) error {
+	catalog := server.catalog()
+
+	switch format {
+	case CatalogJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(catalog)
+
+	case CatalogMarkdown:
+		_, err := io.WriteString(w, catalogMarkdown(catalog))
+
+		return err
+	}
+
+	return errors.New("Unknown catalog format")
+}
+
+// catalog returns the MethodInfo of all of the methods, sorted by name.
+func (server *SimpleServer) catalog() []MethodInfo {
+	methods := server.Methods()
+	catalog := make([]MethodInfo, len(methods))
+	for i, methodName := range methods {
+		catalog[i] = server.MethodInfo(methodName)
+	}
+
+	return catalog
+}
+
+func catalogMarkdown(catalog []MethodInfo) string {
+	var s strings.Builder
+	s.WriteString("# Methods\n")
+
+	for _, info := range catalog {
+		fmt.Fprintf(&s, "\n## %s\n", info.Name)
+
+		if info.Deprecated {
+			s.WriteString("\n**Deprecated**\n")
+		}
+
+		if info.Description != "" {
+			fmt.Fprintf(&s, "\n%s\n", info.Description)
+		}
+
+		if len(info.Params) > 0 {
+			s.WriteString("\n| Param | Type | Required | Description |\n")
+			s.WriteString("| --- | --- | --- | --- |\n")
+
+			for _, param := range info.Params {
+				required := "No"
+				if param.Required {
+					required = "Yes"
+				}
+
+				fmt.Fprintf(&s, "| %s | %s | %s | %s |\n", param.Name,
+					param.Type, required, param.Description)
+			}
+		}
+
+		if info.Result != "" {
+			fmt.Fprintf(&s, "\nResult: %s\n", info.Result)
+		}
+	}
+
+	return s.String()
+}
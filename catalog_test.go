@@ -0,0 +1,71 @@
+package jsonrpc_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCatalogTestServer() *jsonrpc.SimpleServer {
+	server := jsonrpc.NewSimpleServer()
+	server.SetHandler("sum", sum)
+	server.SetHandler("get_data", getData)
+	server.SetMethodInfo("sum", jsonrpc.MethodInfo{
+		Description: "Adds numbers together.",
+		Params: []jsonrpc.ParamInfo{
+			{Name: "numbers", Type: "number[]", Required: true},
+		},
+		Result: "The total.",
+	})
+
+	return server
+}
+
+func TestSimpleServer_MethodInfo(t *testing.T) {
+	server := newCatalogTestServer()
+
+	assert.Equal(t, "sum", server.MethodInfo("sum").Name)
+	assert.Equal(t, "Adds numbers together.", server.MethodInfo("sum").Description)
+	assert.Equal(t, jsonrpc.MethodInfo{Name: "get_data"}, server.MethodInfo("get_data"))
+}
+
+func TestSimpleServer_ExportCatalog(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		err := newCatalogTestServer().ExportCatalog(buf, jsonrpc.CatalogJSON)
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `[
+			{"name": "get_data"},
+			{
+				"name": "sum",
+				"description": "Adds numbers together.",
+				"params": [{"name": "numbers", "type": "number[]", "required": true}],
+				"result": "The total."
+			}
+		]`, buf.String())
+	})
+
+	t.Run("Markdown", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		err := newCatalogTestServer().ExportCatalog(buf, jsonrpc.CatalogMarkdown)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "# Methods\n"+
+			"\n## get_data\n"+
+			"\n## sum\n"+
+			"\nAdds numbers together.\n"+
+			"\n| Param | Type | Required | Description |\n"+
+			"| --- | --- | --- | --- |\n"+
+			"| numbers | number[] | Yes |  |\n"+
+			"\nResult: The total.\n", buf.String())
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		err := newCatalogTestServer().ExportCatalog(new(bytes.Buffer), 99)
+
+		assert.EqualError(t, err, "Unknown catalog format")
+	})
+}
@@ -0,0 +1,173 @@
+package jsonrpc
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Chaos describes faults that the server will inject into requests. This is
+// for testing how clients cope with a misbehaving server, and should never be
+// enabled in production. See SetChaos.
+//
+// Each rate is a probability between 0 (never) and 1 (always).
+type Chaos struct {
+	// MaxDelay adds a random delay, up to MaxDelay, before each request is
+	// handled.
+	MaxDelay time.Duration
+
+	// ServerErrorRate is how often a request receives a ServerError response
+	// instead of calling the handler.
+	ServerErrorRate float64
+
+	// DropNotificationRate is how often a notification is silently dropped
+	// without calling the handler.
+	DropNotificationRate float64
+
+	// Random returns a number in the range [0, 1). If it is nil then
+	// rand.Float64 is used. It must be safe to call concurrently.
+	Random func() float64
+}
+
+// SetChaos enables fault injection for all requests. Passing nil disables
+// fault injection, which is the default:
+//
+//     server.SetChaos(&jsonrpc.Chaos{
+//         MaxDelay:        100 * time.Millisecond,
+//         ServerErrorRate: 0.05,
+//     })
+//
+// The chaos is copied so it cannot be changed after it is set.
+func (server *SimpleServer) SetChaos(chaos *Chaos) {
+	if chaos == nil {
+		server.chaos = nil
+		return
+	}
+
+	c := *chaos
+	if c.Random == nil {
+		c.Random = rand.Float64
+	}
+
+	server.chaos = &c
+}
+
+// inject applies the faults to a request. If a response is returned then it
+// must be used instead of calling the handler.
+func (chaos *Chaos) inject(request RequestResponder) Response {
+	if chaos.MaxDelay > 0 {
+		time.Sleep(time.Duration(chaos.Random() * float64(chaos.MaxDelay)))
+	}
+
+	if request.Id() == nil {
+		if chaos.Random() < chaos.DropNotificationRate {
+			return request.NewSuccessResponse(nil)
+		}
+
+		return nil
+	}
+
+	if chaos.Random() < chaos.ServerErrorRate {
+		return request.NewErrorResponse(ServerError, "Injected fault.")
+	}
+
+	return nil
+}
+
+// Fault is injected into a percentage of the requests for a single method. It
+// allows operators to rehearse how clients handle failures of a specific
+// method, such as in a staging environment. See SetFault.
+type Fault struct {
+	// Rate is the probability between 0 (never) and 1 (always) that a request
+	// receives the fault.
+	Rate float64
+
+	// Delay is added before the request is handled.
+	Delay time.Duration
+
+	// ErrorCode is the code of the error response sent instead of calling the
+	// handler. If it is zero the handler is still called (after the Delay).
+	ErrorCode int
+
+	// ErrorMessage is the message of the error response. If it is empty then
+	// "Injected fault." is used.
+	ErrorMessage string
+}
+
+type methodFaults struct {
+	mutex  sync.RWMutex
+	faults map[string]Fault
+}
+
+// SetFault injects a fault into a percentage of the requests for a method. A
+// Rate of zero (or less) removes the fault for the method:
+//
+//     server.SetFault("getUser", jsonrpc.Fault{
+//         Rate:      0.25,
+//         Delay:     2 * time.Second,
+//         ErrorCode: jsonrpc.ServerError,
+//     })
+//
+// Unlike SetChaos, faults can be changed while requests are being handled, and
+// can also be changed remotely with the admin.faults method (see EnableAdmin).
+// Faults must never be enabled in production.
+//
+// Faults are never injected into the methods added by EnableAdmin, so that
+// they can always be removed.
+func (server *SimpleServer) SetFault(methodName string, fault Fault) {
+	faults := &server.faults
+	faults.mutex.Lock()
+	defer faults.mutex.Unlock()
+
+	if fault.Rate <= 0 {
+		delete(faults.faults, methodName)
+		return
+	}
+
+	if faults.faults == nil {
+		faults.faults = make(map[string]Fault)
+	}
+
+	faults.faults[methodName] = fault
+}
+
+// Faults returns the faults for each method that has one.
+func (server *SimpleServer) Faults() map[string]Fault {
+	faults := &server.faults
+	faults.mutex.RLock()
+	defer faults.mutex.RUnlock()
+
+	copied := make(map[string]Fault, len(faults.faults))
+	for methodName, fault := range faults.faults {
+		copied[methodName] = fault
+	}
+
+	return copied
+}
+
+// inject applies the fault for the method of the request, if there is one. If
+// a response is returned then it must be used instead of calling the handler.
+func (faults *methodFaults) inject(request RequestResponder) Response {
+	faults.mutex.RLock()
+	fault, ok := faults.faults[request.Method()]
+	faults.mutex.RUnlock()
+
+	if !ok || rand.Float64() >= fault.Rate {
+		return nil
+	}
+
+	if fault.Delay > 0 {
+		time.Sleep(fault.Delay)
+	}
+
+	if fault.ErrorCode == 0 {
+		return nil
+	}
+
+	message := fault.ErrorMessage
+	if message == "" {
+		message = "Injected fault."
+	}
+
+	return request.NewErrorResponse(fault.ErrorCode, message)
+}
@@ -0,0 +1,119 @@
+package jsonrpc_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimpleServer_SetChaos(t *testing.T) {
+	called := 0
+	server := jsonrpc.NewSimpleServer()
+	server.SetHandler("count", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		called++
+
+		return request.NewSuccessResponse(called)
+	})
+
+	request := []byte(`{"jsonrpc": "2.0", "method": "count", "id": 1}`)
+	notification := []byte(`{"jsonrpc": "2.0", "method": "count"}`)
+
+	t.Run("ServerError", func(t *testing.T) {
+		called = 0
+		server.SetChaos(&jsonrpc.Chaos{
+			ServerErrorRate: 0.5,
+			Random:          sequence(0.2, 0.7),
+		})
+
+		assert.Equal(t, jsonrpc.ServerError, server.Handle(request)[0].ErrorCode())
+		assert.Equal(t, 1, server.Handle(request)[0].Result())
+		assert.Equal(t, 1, called)
+	})
+
+	t.Run("DropNotification", func(t *testing.T) {
+		called = 0
+		server.SetChaos(&jsonrpc.Chaos{
+			DropNotificationRate: 0.5,
+			Random:               sequence(0.2, 0.7),
+		})
+
+		assert.Empty(t, server.Handle(notification))
+		assert.Empty(t, server.Handle(notification))
+		assert.Equal(t, 1, called)
+	})
+
+	t.Run("MaxDelay", func(t *testing.T) {
+		server.SetChaos(&jsonrpc.Chaos{
+			MaxDelay: 100 * time.Millisecond,
+			Random:   sequence(0.5, 0.9),
+		})
+
+		start := time.Now()
+		server.Handle(request)
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		called = 0
+		server.SetChaos(nil)
+
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, jsonrpc.Success, server.Handle(request)[0].ErrorCode())
+		}
+		assert.Equal(t, 10, called)
+	})
+}
+
+// sequence returns a random function that cycles through values.
+func sequence(values ...float64) func() float64 {
+	i := -1
+
+	return func() float64 {
+		i++
+
+		return values[i%len(values)]
+	}
+}
+
+func TestSimpleServer_SetFault(t *testing.T) {
+	t.Run("Error", func(t *testing.T) {
+		server := newTestServer()
+		server.SetFault("sum", jsonrpc.Fault{Rate: 1, ErrorCode: jsonrpc.ServerError})
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+		assert.Equal(t, jsonrpc.NewErrorResponse(1.0, jsonrpc.ServerError, "Injected fault."), responses[0])
+
+		responses = server.Handle([]byte(`{"jsonrpc":"2.0","method":"subtract","params":[3,2],"id":1}`))
+		assert.Equal(t, jsonrpc.Success, responses[0].ErrorCode())
+	})
+
+	t.Run("Message", func(t *testing.T) {
+		server := newTestServer()
+		server.SetFault("sum", jsonrpc.Fault{Rate: 1, ErrorCode: 123, ErrorMessage: "Nope."})
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+		assert.Equal(t, jsonrpc.NewErrorResponse(1.0, 123, "Nope."), responses[0])
+	})
+
+	t.Run("Delay", func(t *testing.T) {
+		server := newTestServer()
+		server.SetFault("sum", jsonrpc.Fault{Rate: 1, Delay: 20 * time.Millisecond})
+
+		start := time.Now()
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+		assert.True(t, time.Since(start) >= 20*time.Millisecond)
+		assert.Equal(t, 3.0, responses[0].Result())
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		server := newTestServer()
+		server.SetFault("sum", jsonrpc.Fault{Rate: 1, ErrorCode: jsonrpc.ServerError})
+		server.SetFault("sum", jsonrpc.Fault{})
+
+		assert.Empty(t, server.Faults())
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+		assert.Equal(t, 3.0, responses[0].Result())
+	})
+}
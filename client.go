@@ -0,0 +1,806 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ClientTransport sends payloads to a server for a Client.
+type ClientTransport interface {
+	// RoundTrip sends the payload and returns the raw response. If
+	// expectResponse is false (the payload only contains notifications) the
+	// transport must not wait for a response and should return nil.
+	//
+	// The transport must abandon the operation and return an error when ctx is
+	// cancelled or reaches its deadline.
+	RoundTrip(ctx context.Context, payload []byte, expectResponse bool) ([]byte, error)
+}
+
+// ServerRequestReceiver is implemented by transports that can receive requests
+// and notifications sent by the server over a persistent connection, such as
+// ConnTransport and WebSocketTransport. See Client.OnNotification.
+type ServerRequestReceiver interface {
+	// OnServerRequest sets the function that is called for each request
+	// received from the server.
+	OnServerRequest(handler func(request Request))
+}
+
+// DisconnectNotifier is implemented by transports that can tell when their
+// connection is lost, so that subscriptions can be closed. See Subscribe.
+type DisconnectNotifier interface {
+	// OnDisconnect sets the function that is called when the connection fails
+	// or is closed. err is the reason, if it is known.
+	OnDisconnect(handler func(err error))
+}
+
+// IdPolicy decides what Client.Send does with requests that do not have an
+// id. A request without an id is a notification, so the server will not send
+// back a response. This is a common mistake when requests are built by hand.
+type IdPolicy int
+
+const (
+	// PreserveIds sends requests without an id as notifications. This is the
+	// default.
+	PreserveIds IdPolicy = iota
+
+	// BackfillIds gives requests without an id a generated id, so that they
+	// receive a response. Use Notify to send notifications.
+	BackfillIds
+
+	// RejectNotifications returns ErrUnexpectedNotification for requests
+	// without an id. Use Notify to send notifications.
+	RejectNotifications
+)
+
+// ErrUnexpectedNotification is returned by Client.Send for a request without
+// an id when the IdPolicy is RejectNotifications.
+var ErrUnexpectedNotification = errors.New("Request does not have an id, use Notify to send notifications")
+
+// ClientInvoker sends a request and receives the response. The response will
+// be nil for notifications.
+type ClientInvoker func(ctx context.Context, request Request) (Response, error)
+
+// ClientInterceptor wraps the sending of every request made by a Client. It can
+// observe or replace the outgoing request and the incoming response, or stop the
+// request from being sent at all by not calling next:
+//
+//     func logging(next jsonrpc.ClientInvoker) jsonrpc.ClientInvoker {
+//         return func(ctx context.Context, request jsonrpc.Request) (jsonrpc.Response, error) {
+//             response, err := next(ctx, request)
+//             log.Println(request, response, err)
+//
+//             return response, err
+//         }
+//     }
+type ClientInterceptor func(next ClientInvoker) ClientInvoker
+
+// Client calls methods on a JSON-RPC server.
+type Client struct {
+	transport    ClientTransport
+	interceptors []ClientInterceptor
+
+	notificationMutex    sync.Mutex
+	notificationHandlers map[string]func(params interface{})
+
+	metrics          ClientMetricsCollector
+	idPolicy         IdPolicy
+	tokens           TokenProvider
+	batchConcurrency int
+
+	// See SetMethodInfo
+	methodInfo map[string]MethodInfo
+
+	// See Subscribe
+	subscriptions clientSubscriptions
+
+	// See SetCache
+	cache clientCache
+	clock Clock
+}
+
+// NewClient creates a client that uses any transport. See NewHTTPClient for
+// the most common case.
+func NewClient(transport ClientTransport) *Client {
+	client := &Client{
+		transport:            transport,
+		notificationHandlers: make(map[string]func(params interface{})),
+	}
+
+	if receiver, ok := transport.(ServerRequestReceiver); ok {
+		receiver.OnServerRequest(client.handleServerRequest)
+	}
+
+	if notifier, ok := transport.(DisconnectNotifier); ok {
+		notifier.OnDisconnect(client.subscriptions.closeAll)
+	}
+
+	return client
+}
+
+// NewHTTPClient creates a client that sends requests to a JSON-RPC over HTTP
+// server, such as a SimpleServer mounted with ServeHTTP.
+func NewHTTPClient(url string) *Client {
+	return NewClient(&HTTPTransport{URL: url})
+}
+
+// Use adds interceptors that will be run for every call and notification. The
+// interceptors run in the order they were added, so the first interceptor sees
+// the request first and the response last.
+//
+// Use is not safe to call concurrently with calls on the client.
+func (client *Client) Use(interceptors ...ClientInterceptor) {
+	client.interceptors = append(client.interceptors, interceptors...)
+}
+
+// OnNotification registers (or replaces) a function that is called when the
+// server sends a notification for a method. This is needed for subscription
+// style APIs where the server pushes updates:
+//
+//     client.OnNotification("eth_subscription", func(params interface{}) {
+//         // ...
+//     })
+//
+// Requests from the server that have an id are also delivered, but no
+// response is sent back. A nil handler removes the handler for the method.
+//
+// Notifications can only be received by transports that implement
+// ServerRequestReceiver. Handlers are called one at a time, in the order the
+// notifications are received, so they must not block or make calls with the
+// same client.
+func (client *Client) OnNotification(method string, handler func(params interface{})) {
+	client.notificationMutex.Lock()
+	defer client.notificationMutex.Unlock()
+
+	if handler == nil {
+		delete(client.notificationHandlers, method)
+	} else {
+		client.notificationHandlers[method] = handler
+	}
+}
+
+func (client *Client) handleServerRequest(request Request) {
+	if client.subscriptions.dispatch(request) {
+		return
+	}
+
+	client.notificationMutex.Lock()
+	handler := client.notificationHandlers[request.Method()]
+	client.notificationMutex.Unlock()
+
+	if handler != nil {
+		handler(request.Params())
+	}
+}
+
+// SetIdPolicy decides what Send does with requests that do not have an id.
+// See IdPolicy.
+func (client *Client) SetIdPolicy(policy IdPolicy) {
+	client.idPolicy = policy
+}
+
+// Call is the same as CallContext with context.Background().
+func (client *Client) Call(method string, params interface{}) (Response, error) {
+	return client.CallContext(context.Background(), method, params)
+}
+
+// CallContext calls a method and waits for the response. The ctx can be used
+// to set a deadline or cancel the call, which will also cancel the underlying
+// HTTP or socket operation.
+//
+// An error (a *TransportError) is only returned if the response could not be
+// received. A JSON-RPC error is returned as a Response with a non-zero
+// ErrorCode(). Use CallIntoContext to receive it as an *RPCError instead.
+func (client *Client) CallContext(ctx context.Context, method string, params interface{}) (Response, error) {
+	request := NewRequestResponder("2.0", GenerateRequestId(), method, params)
+
+	return client.invoke(ctx, request)
+}
+
+// CallInto is the same as CallIntoContext with context.Background().
+func (client *Client) CallInto(method string, params interface{}, result interface{}) error {
+	return client.CallIntoContext(context.Background(), method, params, result)
+}
+
+// CallIntoContext calls a method and decodes the result into result, which
+// must be a pointer:
+//
+//     var user User
+//     err := client.CallIntoContext(ctx, "getUser", []int{123}, &user)
+//
+// If the server responds with an error then an *RPCError is returned
+// containing the error. An *RPCError with the InvalidParams code is also
+// returned if the result cannot be decoded into result.
+func (client *Client) CallIntoContext(ctx context.Context, method string, params interface{}, result interface{}) error {
+	response, err := client.CallContext(ctx, method, params)
+	if err != nil {
+		return err
+	}
+
+	if response.ErrorCode() != Success {
+		return newRPCError(response)
+	}
+
+	data, err := json.Marshal(response.Result())
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, result); err != nil {
+		return &RPCError{
+			Code:    InvalidParams,
+			Message: "Cannot decode result: " + err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// Send sends a request that has already been created, such as one with a
+// specific id. If the request does not have an id then it is a notification
+// and the response will be nil, unless the IdPolicy of the client says
+// otherwise.
+func (client *Client) Send(ctx context.Context, request Request) (Response, error) {
+	if request.Id() == nil {
+		switch client.idPolicy {
+		case BackfillIds:
+			request = NewRequestResponderWithState(request.Version(),
+				GenerateRequestId(), request.Method(), request.Params(), State{})
+
+		case RejectNotifications:
+			return nil, ErrUnexpectedNotification
+		}
+	}
+
+	return client.invoke(ctx, request)
+}
+
+// Notify is the same as NotifyContext with context.Background().
+func (client *Client) Notify(method string, params interface{}) error {
+	return client.NotifyContext(context.Background(), method, params)
+}
+
+// NotifyContext sends a notification. The server does not send back a response
+// for notifications so there is no way to know if it was successful.
+func (client *Client) NotifyContext(ctx context.Context, method string, params interface{}) error {
+	request := NewRequestResponder("2.0", nil, method, params)
+	_, err := client.invoke(ctx, request)
+
+	return err
+}
+
+// invoke sends the request through all of the interceptors.
+func (client *Client) invoke(ctx context.Context, request Request) (Response, error) {
+	if rpcErr := client.validateParams(request); rpcErr != nil {
+		if request.Id() == nil {
+			return nil, rpcErr
+		}
+
+		return NewErrorResponse(request.Id(), rpcErr.Code, rpcErr.Message), nil
+	}
+
+	invoker := client.cached(client.authenticate)
+	for i := len(client.interceptors) - 1; i >= 0; i-- {
+		invoker = client.interceptors[i](invoker)
+	}
+
+	return invoker(ctx, request)
+}
+
+// send is the last ClientInvoker that actually sends the request with the
+// transport.
+func (client *Client) send(ctx context.Context, request Request) (Response, error) {
+	payload := request.Bytes()
+	isNotification := request.Id() == nil
+
+	hint := &maxAgeHint{}
+	ctx = context.WithValue(ctx, maxAgeKey{}, hint)
+
+	start := time.Now()
+	data, err := client.transport.RoundTrip(ctx, payload, !isNotification)
+
+	var response Response
+	if err == nil && !isNotification {
+		response, err = decodeResponse(request, data)
+	}
+
+	if err == nil && hint.ok && response != nil && response.ErrorCode() == Success {
+		response = NewSuccessResponseWithMaxAge(response.Id(), response.Result(),
+			hint.maxAge)
+	}
+	err = newTransportError(err)
+
+	if client.metrics != nil {
+		metrics := CallMetrics{
+			Method:        request.Method(),
+			Notification:  isNotification,
+			Duration:      time.Since(start),
+			BytesSent:     len(payload),
+			BytesReceived: len(data),
+			Err:           err,
+		}
+		if response != nil {
+			metrics.ErrorCode = response.ErrorCode()
+		}
+
+		client.metrics.ObserveCall(metrics)
+	}
+
+	return response, err
+}
+
+// decodeResponse returns the response for the request.
+func decodeResponse(request Request, data []byte) (Response, error) {
+	responses, err := NewResponsesFromJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(responses) != 1 {
+		return nil, fmt.Errorf("Expected 1 response but received %d", len(responses))
+	}
+
+	// A Parse error or Invalid request may not contain the ID. The IDs are
+	// compared as JSON because a request may use any numeric type.
+	if responses[0].Id() != nil && !sameId(responses[0].Id(), request.Id()) {
+		return nil, fmt.Errorf("Expected response for %v but received %v",
+			request.Id(), responses[0].Id())
+	}
+
+	return responses[0], nil
+}
+
+func sameId(a, b interface{}) bool {
+	aJSON, _ := json.Marshal(a)
+	bJSON, _ := json.Marshal(b)
+
+	return string(aJSON) == string(bJSON)
+}
+
+// TransportError is returned by a Client when a request could not be sent, or
+// its response could not be received or understood. Use errors.As to tell it
+// apart from an *RPCError, which is an error response from the server:
+//
+//     var transportErr *jsonrpc.TransportError
+//     var rpcErr *jsonrpc.RPCError
+//     switch err := client.CallInto("getUser", []int{123}, &user); {
+//     case errors.As(err, &transportErr):
+//         // The server may not have received the request. Retry later.
+//
+//     case errors.As(err, &rpcErr):
+//         log.Println(rpcErr.Code, rpcErr.Message, rpcErr.Data)
+//     }
+//
+// The original error can be found with errors.Is or errors.As, such as
+// context.DeadlineExceeded or an *HTTPStatusError.
+type TransportError struct {
+	Err error
+}
+
+// newTransportError wraps err in a *TransportError, unless it is nil or
+// already wrapped.
+func newTransportError(err error) error {
+	var transportErr *TransportError
+	if err == nil || errors.As(err, &transportErr) {
+		return err
+	}
+
+	return &TransportError{Err: err}
+}
+
+func (err *TransportError) Error() string {
+	return err.Err.Error()
+}
+
+func (err *TransportError) Unwrap() error {
+	return err.Err
+}
+
+// RPCError is an error response from the server.
+type RPCError struct {
+	Code    int
+	Message string
+	Data    interface{}
+}
+
+func newRPCError(response Response) *RPCError {
+	return &RPCError{
+		Code:    response.ErrorCode(),
+		Message: response.ErrorMessage(),
+		Data:    response.ErrorData(),
+	}
+}
+
+func (err *RPCError) Error() string {
+	return fmt.Sprintf("%s (%d)", err.Message, err.Code)
+}
+
+// HTTPTransport sends each payload as a HTTP POST.
+type HTTPTransport struct {
+	URL string
+
+	// Client is used to send the requests. If it is nil then
+	// http.DefaultClient is used.
+	Client *http.Client
+
+	// Header is sent with every request. Headers added to the context of a
+	// call with WithHeader replace headers with the same name.
+	Header http.Header
+
+	// MaxResponseSize is the largest response body (in bytes) that will be
+	// read. A larger response returns ErrPayloadTooLarge. If it is zero then
+	// DefaultMaxHTTPBodySize is used.
+	MaxResponseSize int64
+}
+
+// HTTPStatusError is returned by HTTPTransport when the server responds with
+// an unexpected HTTP status.
+type HTTPStatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (err *HTTPStatusError) Error() string {
+	return "Unexpected HTTP status: " + err.Status
+}
+
+type headerKey struct{}
+
+// WithHeader returns a context that sends an extra HTTP header with any calls
+// made with it, such as authorization or tracing headers:
+//
+//     ctx = jsonrpc.WithHeader(ctx, "Authorization", "Bearer "+token)
+//     response, err := client.CallContext(ctx, "getUser", []int{123})
+//
+// Headers are added to any that are already in the context. Transports that do
+// not use HTTP ignore the headers.
+func WithHeader(ctx context.Context, key, value string) context.Context {
+	header := HeaderFromContext(ctx).Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Add(key, value)
+
+	return context.WithValue(ctx, headerKey{}, header)
+}
+
+// HeaderFromContext returns the headers added with WithHeader. It is useful
+// for custom transports. The returned header must not be modified.
+func HeaderFromContext(ctx context.Context) http.Header {
+	header, _ := ctx.Value(headerKey{}).(http.Header)
+
+	return header
+}
+
+func (transport *HTTPTransport) RoundTrip(ctx context.Context, payload []byte, expectResponse bool) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		transport.URL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	for _, header := range []http.Header{transport.Header, HeaderFromContext(ctx)} {
+		for key, values := range header {
+			request.Header.Del(key)
+			for _, value := range values {
+				request.Header.Add(key, value)
+			}
+		}
+	}
+
+	client := transport.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	maxResponseSize := transport.MaxResponseSize
+	if maxResponseSize == 0 {
+		maxResponseSize = DefaultMaxHTTPBodySize
+	}
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > maxResponseSize {
+		return nil, fmt.Errorf("%w: response is more than %d bytes",
+			ErrPayloadTooLarge, maxResponseSize)
+	}
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNoContent {
+		return nil, &HTTPStatusError{
+			StatusCode: response.StatusCode,
+			Status:     response.Status,
+		}
+	}
+
+	if !expectResponse {
+		return nil, nil
+	}
+
+	if len(data) == 0 {
+		return nil, errors.New("Empty response")
+	}
+
+	if maxAge, ok := parseCacheControl(response.Header.Get("Cache-Control")); ok {
+		SetResponseMaxAge(ctx, maxAge)
+	}
+
+	return data, nil
+}
+
+// ConnTransport sends payloads over a stream connection, such as one served by
+// ListenAndServeTCP or ListenAndServeUnix. Payloads are separated by new lines.
+//
+// Many calls can be in flight at the same time. The responses are matched to
+// their requests by id, so they can be received in any order.
+type ConnTransport struct {
+	conn        net.Conn
+	reader      *bufio.Reader
+	writer      io.Writer
+	compression string
+	writeMutex  sync.Mutex
+
+	mutex         sync.Mutex
+	pending       map[string]chan []byte
+	err           error
+	serverRequest func(request Request)
+	disconnected  func(err error)
+}
+
+// NewConnTransport creates a transport for an open connection. A goroutine
+// reads responses from the connection until it is closed.
+func NewConnTransport(conn net.Conn) *ConnTransport {
+	transport := newConnTransport(conn)
+
+	go transport.readResponses()
+
+	return transport
+}
+
+func newConnTransport(conn net.Conn) *ConnTransport {
+	return &ConnTransport{
+		conn:    conn,
+		reader:  bufio.NewReader(conn),
+		writer:  conn,
+		pending: make(map[string]chan []byte),
+	}
+}
+
+func (transport *ConnTransport) RoundTrip(ctx context.Context, payload []byte, expectResponse bool) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var key string
+	var response chan []byte
+	if expectResponse {
+		var ok bool
+		key, ok = payloadKey(payload)
+		if !ok {
+			return nil, errors.New("Cannot find the id of the request")
+		}
+
+		response = make(chan []byte, 1)
+
+		transport.mutex.Lock()
+		if transport.err != nil {
+			transport.mutex.Unlock()
+
+			return nil, transport.err
+		}
+		if _, ok := transport.pending[key]; ok {
+			transport.mutex.Unlock()
+
+			return nil, fmt.Errorf("Request %s is already in flight", key)
+		}
+		transport.pending[key] = response
+		transport.mutex.Unlock()
+
+		// The request is no longer waiting when this returns (including when
+		// ctx is done), so a late response will be discarded.
+		defer func() {
+			transport.mutex.Lock()
+			delete(transport.pending, key)
+			transport.mutex.Unlock()
+		}()
+	}
+
+	if err := transport.write(ctx, payload); err != nil {
+		return nil, err
+	}
+
+	if !expectResponse {
+		return nil, nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+
+	case data, ok := <-response:
+		if !ok {
+			transport.mutex.Lock()
+			defer transport.mutex.Unlock()
+
+			return nil, transport.err
+		}
+
+		return data, nil
+	}
+}
+
+func (transport *ConnTransport) write(ctx context.Context, payload []byte) error {
+	transport.writeMutex.Lock()
+	defer transport.writeMutex.Unlock()
+
+	// Unblock the write if the context is cancelled.
+	stop := context.AfterFunc(ctx, func() {
+		transport.conn.SetWriteDeadline(time.Now())
+	})
+	defer func() {
+		if !stop() {
+			transport.conn.SetWriteDeadline(time.Time{})
+		}
+	}()
+
+	err := writePayload(NewlineFraming, transport.writer, payload)
+	if err != nil && ctx.Err() != nil {
+		// Part of the payload may have been written, so nothing else can be
+		// sent on the connection.
+		transport.conn.Close()
+
+		return ctx.Err()
+	}
+
+	return err
+}
+
+// readResponses delivers each response to the request waiting for it. When
+// the connection fails all of the waiting requests receive the error.
+func (transport *ConnTransport) readResponses() {
+	for {
+		data, err := NewlineFraming.ReadPayload(transport.reader)
+		if err != nil {
+			transport.mutex.Lock()
+			transport.err = err
+			for key, response := range transport.pending {
+				close(response)
+				delete(transport.pending, key)
+			}
+			disconnected := transport.disconnected
+			transport.mutex.Unlock()
+
+			if disconnected != nil {
+				disconnected(err)
+			}
+
+			return
+		}
+
+		if request, ok := serverRequest(data); ok {
+			transport.mutex.Lock()
+			handler := transport.serverRequest
+			transport.mutex.Unlock()
+
+			if handler != nil {
+				handler(request)
+			}
+
+			continue
+		}
+
+		key, ok := payloadKey(data)
+
+		transport.mutex.Lock()
+		response, found := transport.pending[key]
+
+		// An error for a request that could not be parsed will not have an
+		// id. It can only be delivered if there is one request waiting.
+		if !ok && len(transport.pending) == 1 {
+			for key, response = range transport.pending {
+				found = true
+			}
+		}
+
+		if found {
+			delete(transport.pending, key)
+			response <- data
+		}
+		transport.mutex.Unlock()
+	}
+}
+
+func (transport *ConnTransport) OnServerRequest(handler func(request Request)) {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	transport.serverRequest = handler
+}
+
+// OnDisconnect sets the function that is called once the connection fails or
+// is closed.
+func (transport *ConnTransport) OnDisconnect(handler func(err error)) {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	transport.disconnected = handler
+}
+
+// Close closes the underlying connection. Any calls that are waiting for a
+// response will return an error.
+func (transport *ConnTransport) Close() error {
+	return transport.conn.Close()
+}
+
+// payloadKey returns a key made from the ids in a request or response payload.
+// The key for a request will be the same as the key for its response. false is
+// returned if the payload does not have any ids.
+func payloadKey(payload []byte) (string, bool) {
+	type message struct {
+		Id interface{} `json:"id"`
+	}
+
+	var messages []message
+	if json.Unmarshal(payload, &messages) != nil {
+		var single message
+		if json.Unmarshal(payload, &single) != nil {
+			return "", false
+		}
+
+		messages = []message{single}
+	}
+
+	var ids []string
+	for _, message := range messages {
+		if message.Id != nil {
+			id, _ := json.Marshal(message.Id)
+			ids = append(ids, string(id))
+		}
+	}
+
+	if len(ids) == 0 {
+		return "", false
+	}
+
+	sort.Strings(ids)
+
+	return strings.Join(ids, ","), true
+}
+
+// serverRequest returns the request if the payload is a request (rather than a
+// response) sent by the server.
+func serverRequest(payload []byte) (Request, bool) {
+	var message struct {
+		Method *string `json:"method"`
+	}
+
+	if json.Unmarshal(payload, &message) != nil || message.Method == nil {
+		return nil, false
+	}
+
+	request, err := NewRequestFromJSON(payload)
+	if err != nil {
+		return nil, false
+	}
+
+	return request, true
+}
@@ -0,0 +1,147 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// SetBatchSplitting sends the requests of a Batch as individual requests, for
+// servers that do not support batches. The caller receives the same Responses
+// either way, so it does not need two code paths.
+//
+// concurrency is the number of requests that can be in flight at the same
+// time. 1 sends the requests one at a time, in order. 0 (the default) sends
+// each batch in a single payload.
+func (client *Client) SetBatchSplitting(concurrency int) {
+	client.batchConcurrency = concurrency
+}
+
+// Batch sends many requests at the same time. Requests without an id are
+// notifications:
+//
+//     responses, err := client.Batch(ctx,
+//         jsonrpc.NewRequestResponder("2.0", 1, "sum", []int{1, 2}),
+//         jsonrpc.NewRequestResponder("2.0", 2, "subtract", []int{5, 3}),
+//         jsonrpc.NewRequestResponder("2.0", nil, "log", []string{"hi"}),
+//     )
+//
+// There is one response for each request that has an id, in the same order as
+// the requests. As with CallContext, an error (a *TransportError) is only
+// returned if the responses could not be received. If the server rejects the
+// whole batch then an *RPCError is returned.
+//
+// Interceptors and metrics only apply to batches that are split (see
+// SetBatchSplitting), since they work with one request at a time.
+func (client *Client) Batch(ctx context.Context, requests ...Request) (Responses, error) {
+	if len(requests) == 0 {
+		return Responses{}, nil
+	}
+
+	if client.batchConcurrency > 0 {
+		return client.splitBatch(ctx, requests)
+	}
+
+	var payload bytes.Buffer
+	expectResponse := false
+	payload.WriteByte('[')
+	for i, request := range requests {
+		if i > 0 {
+			payload.WriteByte(',')
+		}
+		payload.Write(request.Bytes())
+
+		if request.Id() != nil {
+			expectResponse = true
+		}
+	}
+	payload.WriteByte(']')
+
+	var data []byte
+	err := client.authorize(ctx, func(ctx context.Context) (err error) {
+		data, err = client.transport.RoundTrip(ctx, payload.Bytes(), expectResponse)
+
+		return newTransportError(err)
+	})
+	if err != nil || !expectResponse {
+		return Responses{}, err
+	}
+
+	received, err := NewResponsesFromJSON(data)
+	if err != nil {
+		return nil, newTransportError(err)
+	}
+
+	// A server that cannot handle the batch responds with a single error.
+	if len(received) == 1 && received[0].Id() == nil && received[0].ErrorCode() != Success {
+		return nil, newRPCError(received[0])
+	}
+
+	return orderResponses(requests, received)
+}
+
+// splitBatch sends each request of the batch individually.
+func (client *Client) splitBatch(ctx context.Context, requests []Request) (Responses, error) {
+	results := make(Responses, len(requests))
+	errs := make([]error, len(requests))
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, client.batchConcurrency)
+	for i, request := range requests {
+		slots <- struct{}{}
+		wg.Add(1)
+
+		go func(i int, request Request) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			results[i], errs[i] = client.invoke(ctx, request)
+		}(i, request)
+	}
+
+	wg.Wait()
+
+	responses := Responses{}
+	for i, request := range requests {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+
+		if request.Id() != nil {
+			responses = append(responses, results[i])
+		}
+	}
+
+	return responses, nil
+}
+
+// orderResponses matches the responses to the requests, because the server
+// may send them back in any order.
+func orderResponses(requests []Request, received Responses) (Responses, error) {
+	responses := Responses{}
+	for _, request := range requests {
+		if request.Id() == nil {
+			continue
+		}
+
+		var found Response
+		for _, response := range received {
+			if sameId(response.Id(), request.Id()) {
+				found = response
+				break
+			}
+		}
+
+		if found == nil {
+			return nil, newTransportError(
+				fmt.Errorf("Did not receive a response for %v", request.Id()))
+		}
+
+		responses = append(responses, found)
+	}
+
+	return responses, nil
+}
@@ -0,0 +1,162 @@
+package jsonrpc_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// newNoBatchServer returns an HTTP server that rejects batches.
+func newNoBatchServer(server *jsonrpc.SimpleServer) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if bytes.HasPrefix(body, []byte("[")) {
+			w.Write([]byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Batches are not supported"}}`))
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		server.ServeHTTP(w, r)
+	}))
+}
+
+func batchRequests() []jsonrpc.Request {
+	return []jsonrpc.Request{
+		jsonrpc.NewRequestResponder("2.0", 1, "sum", []int{1, 2}),
+		jsonrpc.NewRequestResponder("2.0", nil, "notify_hello", []int{7}),
+		jsonrpc.NewRequestResponder("2.0", "b", "subtract", []int{5, 3}),
+		jsonrpc.NewRequestResponder("2.0", 3, "foo", nil),
+	}
+}
+
+func assertBatchResponses(t *testing.T, responses jsonrpc.Responses) {
+	if assert.Len(t, responses, 3) {
+		assert.Equal(t, 3.0, responses[0].Result())
+		assert.Equal(t, "b", responses[1].Id())
+		assert.Equal(t, 2.0, responses[1].Result())
+		assert.Equal(t, jsonrpc.MethodNotFound, responses[2].ErrorCode())
+	}
+}
+
+func TestClient_Batch(t *testing.T) {
+	t.Run("Native", func(t *testing.T) {
+		httpServer := httptest.NewServer(newTestServer())
+		defer httpServer.Close()
+
+		responses, err := jsonrpc.NewHTTPClient(httpServer.URL).Batch(context.Background(), batchRequests()...)
+		assert.NoError(t, err)
+		assertBatchResponses(t, responses)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		responses, err := jsonrpc.NewHTTPClient("http://localhost:0").Batch(context.Background())
+		assert.NoError(t, err)
+		assert.Empty(t, responses)
+	})
+
+	t.Run("OnlyNotifications", func(t *testing.T) {
+		httpServer := httptest.NewServer(newTestServer())
+		defer httpServer.Close()
+
+		responses, err := jsonrpc.NewHTTPClient(httpServer.URL).Batch(context.Background(),
+			jsonrpc.NewRequestResponder("2.0", nil, "notify_hello", []int{7}))
+		assert.NoError(t, err)
+		assert.Empty(t, responses)
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		httpServer := newNoBatchServer(newTestServer())
+		defer httpServer.Close()
+
+		_, err := jsonrpc.NewHTTPClient(httpServer.URL).Batch(context.Background(), batchRequests()...)
+		assert.EqualError(t, err, "Batches are not supported (-32600)")
+	})
+
+	t.Run("MissingResponse", func(t *testing.T) {
+		httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`[{"jsonrpc":"2.0","id":1,"result":3}]`))
+		}))
+		defer httpServer.Close()
+
+		_, err := jsonrpc.NewHTTPClient(httpServer.URL).Batch(context.Background(), batchRequests()...)
+		assert.EqualError(t, err, "Did not receive a response for b")
+	})
+}
+
+func TestClient_SetBatchSplitting(t *testing.T) {
+	t.Run("Sequential", func(t *testing.T) {
+		httpServer := newNoBatchServer(newTestServer())
+		defer httpServer.Close()
+
+		var methods []string
+		client := jsonrpc.NewHTTPClient(httpServer.URL)
+		client.SetBatchSplitting(1)
+		client.Use(func(next jsonrpc.ClientInvoker) jsonrpc.ClientInvoker {
+			return func(ctx context.Context, request jsonrpc.Request) (jsonrpc.Response, error) {
+				methods = append(methods, request.Method())
+
+				return next(ctx, request)
+			}
+		})
+
+		responses, err := client.Batch(context.Background(), batchRequests()...)
+		assert.NoError(t, err)
+		assertBatchResponses(t, responses)
+		assert.Equal(t, []string{"sum", "notify_hello", "subtract", "foo"}, methods)
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		var active, maxActive int32
+		var mutex sync.Mutex
+		server := newTestServer()
+		server.SetHandler("slow", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+			n := atomic.AddInt32(&active, 1)
+			defer atomic.AddInt32(&active, -1)
+
+			mutex.Lock()
+			if n > maxActive {
+				maxActive = n
+			}
+			mutex.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			return request.NewSuccessResponse(request.Id())
+		})
+
+		httpServer := newNoBatchServer(server)
+		defer httpServer.Close()
+
+		client := jsonrpc.NewHTTPClient(httpServer.URL)
+		client.SetBatchSplitting(2)
+
+		var requests []jsonrpc.Request
+		for i := 0; i < 6; i++ {
+			requests = append(requests, jsonrpc.NewRequestResponder("2.0", i, "slow", nil))
+		}
+
+		responses, err := client.Batch(context.Background(), requests...)
+		assert.NoError(t, err)
+		for i, response := range responses {
+			assert.Equal(t, float64(i), response.Result())
+		}
+		assert.Equal(t, int32(2), maxActive)
+	})
+
+	t.Run("TransportError", func(t *testing.T) {
+		client := jsonrpc.NewHTTPClient("http://127.0.0.1:0")
+		client.SetBatchSplitting(1)
+
+		_, err := client.Batch(context.Background(), batchRequests()...)
+		assert.Error(t, err)
+	})
+}
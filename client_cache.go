@@ -0,0 +1,156 @@
+package jsonrpc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type clientCacheEntry struct {
+	method    string
+	result    interface{}
+	expiresAt time.Time
+}
+
+// clientCache holds successful results for methods that have caching enabled
+// with Client.SetCache. Entries are keyed by RequestHash.
+type clientCache struct {
+	mutex     sync.Mutex
+	ttls      map[string]time.Duration
+	entries   map[string]clientCacheEntry
+	nextSweep int
+}
+
+// The number of entries before expired entries are removed for the first
+// time. After that, expired entries are removed each time the number of
+// entries doubles.
+const clientCacheSweepSize = 64
+
+// SetCache enables caching of successful results for a read-only method, in
+// the same way as SimpleServer.SetCache. A call with the same method and
+// params receives the cached result without sending anything to the server
+// until it is older than ttl. This is useful when many parts of a program ask
+// the server the same questions.
+//
+// Params are compared by their JSON encoding (see RequestHash), so a struct and
+// the equivalent map use the same entry. Error responses and notifications are
+// never cached. A max-age hint received with a result (see
+// NewSuccessResponseWithMaxAge) is used instead of ttl for that result.
+// Interceptors still see every call, including those that are answered from
+// the cache.
+//
+// A ttl of zero will disable caching for the method and remove any results
+// that are already cached for it. SetCache is safe to call concurrently with
+// calls on the client.
+func (client *Client) SetCache(method string, ttl time.Duration) {
+	cache := &client.cache
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.ttls == nil {
+		cache.ttls = make(map[string]time.Duration)
+		cache.entries = make(map[string]clientCacheEntry)
+	}
+
+	if ttl > 0 {
+		cache.ttls[method] = ttl
+
+		return
+	}
+
+	delete(cache.ttls, method)
+	for key, entry := range cache.entries {
+		if entry.method == method {
+			delete(cache.entries, key)
+		}
+	}
+}
+
+// SetClock replaces the clock used to expire cached results (see SetCache). A
+// nil clock will use the system clock.
+func (client *Client) SetClock(clock Clock) {
+	client.clock = clock
+}
+
+func (client *Client) now() time.Time {
+	if client.clock == nil {
+		return time.Now()
+	}
+
+	return client.clock.Now()
+}
+
+// cached returns a ClientInvoker that uses a cached result (if there is one)
+// instead of calling next.
+func (client *Client) cached(next ClientInvoker) ClientInvoker {
+	return func(ctx context.Context, request Request) (Response, error) {
+		cache := &client.cache
+		cache.mutex.Lock()
+		ttl, ok := cache.ttls[request.Method()]
+		cache.mutex.Unlock()
+
+		if !ok || request.Id() == nil {
+			return next(ctx, request)
+		}
+
+		key, ok := requestKey(request)
+		if !ok {
+			return next(ctx, request)
+		}
+
+		cache.mutex.Lock()
+		entry, ok := cache.entries[key]
+		cache.mutex.Unlock()
+
+		if ok && client.now().Before(entry.expiresAt) {
+			return NewSuccessResponse(request.Id(), entry.result), nil
+		}
+
+		response, err := next(ctx, request)
+		if err == nil && response != nil && response.ErrorCode() == Success {
+			// A max-age from the server replaces the ttl. Zero means that the
+			// result must not be reused.
+			if maxAge, ok := ResponseMaxAge(response); ok {
+				ttl = maxAge
+			}
+
+			if ttl > 0 {
+				cache.store(request.Method(), key, response.Result(), client.now(), ttl)
+			}
+		}
+
+		return response, err
+	}
+}
+
+func (cache *clientCache) store(method, key string, result interface{}, now time.Time, ttl time.Duration) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	// Caching may have been disabled while the request was being sent.
+	if _, ok := cache.ttls[method]; !ok {
+		return
+	}
+
+	cache.entries[key] = clientCacheEntry{
+		method:    method,
+		result:    result,
+		expiresAt: now.Add(ttl),
+	}
+
+	// Expired entries are only replaced when the same call is made again, so
+	// they are removed here to stop the cache growing with params that are
+	// never used again.
+	if len(cache.entries) >= cache.nextSweep {
+		for entryKey, entry := range cache.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(cache.entries, entryKey)
+			}
+		}
+
+		cache.nextSweep = 2 * len(cache.entries)
+		if cache.nextSweep < clientCacheSweepSize {
+			cache.nextSweep = clientCacheSweepSize
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/elliotchance/jsonrpc/jsonrpctest"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingTransport responds with the number of requests it has received, or
+// an error when code is not Success. The response has a max-age hint if
+// sendMaxAge is true.
+type countingTransport struct {
+	calls      int
+	code       int
+	maxAge     time.Duration
+	sendMaxAge bool
+}
+
+func (transport *countingTransport) RoundTrip(ctx context.Context, payload []byte, expectResponse bool) ([]byte, error) {
+	transport.calls++
+	request, _ := jsonrpc.NewRequestFromJSON(payload)
+
+	if !expectResponse {
+		return nil, nil
+	}
+
+	if transport.code != jsonrpc.Success {
+		return jsonrpc.NewErrorResponse(request.Id(), transport.code, "failed").Bytes(), nil
+	}
+
+	if transport.sendMaxAge {
+		jsonrpc.SetResponseMaxAge(ctx, transport.maxAge)
+	}
+
+	return jsonrpc.NewSuccessResponse(request.Id(), transport.calls).Bytes(), nil
+}
+
+func TestClient_SetCache(t *testing.T) {
+	newClient := func() (*jsonrpc.Client, *countingTransport, *jsonrpctest.Clock) {
+		transport := &countingTransport{}
+		clock := jsonrpctest.NewClock(time.Now())
+		client := jsonrpc.NewClient(transport)
+		client.SetClock(clock)
+		client.SetCache("get", time.Minute)
+
+		return client, transport, clock
+	}
+
+	t.Run("CachesUntilTTL", func(t *testing.T) {
+		client, transport, clock := newClient()
+
+		request := jsonrpc.NewRequestResponder("2.0", 1, "get", map[string]interface{}{"a": 1, "b": 2})
+		response, err := client.Send(context.Background(), request)
+		assert.NoError(t, err)
+		assert.Equal(t, 1.0, response.Result())
+
+		// The params are compared by their JSON encoding, and the response has
+		// the id of the new request.
+		request = jsonrpc.NewRequestResponder("2.0", 2, "get", struct {
+			B int `json:"b"`
+			A int `json:"a"`
+		}{2, 1})
+		clock.Advance(59 * time.Second)
+		response, err = client.Send(context.Background(), request)
+		assert.NoError(t, err)
+		assert.Equal(t, 1.0, response.Result())
+		assert.Equal(t, 2, response.Id())
+		assert.Equal(t, 1, transport.calls)
+
+		clock.Advance(time.Second)
+		response, err = client.Call("get", map[string]interface{}{"a": 1, "b": 2})
+		assert.NoError(t, err)
+		assert.Equal(t, 2.0, response.Result())
+	})
+
+	t.Run("DifferentParams", func(t *testing.T) {
+		client, transport, _ := newClient()
+
+		client.Call("get", []int{1})
+		client.Call("get", []int{2})
+		client.Call("get", []int{1})
+		assert.Equal(t, 2, transport.calls)
+	})
+
+	t.Run("OtherMethodsAreNotCached", func(t *testing.T) {
+		client, transport, _ := newClient()
+
+		client.Call("set", nil)
+		client.Call("set", nil)
+		assert.Equal(t, 2, transport.calls)
+	})
+
+	t.Run("ErrorsAreNotCached", func(t *testing.T) {
+		client, transport, _ := newClient()
+		transport.code = jsonrpc.ServerError
+
+		client.Call("get", nil)
+		response, _ := client.Call("get", nil)
+		assert.Equal(t, jsonrpc.ServerError, response.ErrorCode())
+		assert.Equal(t, 2, transport.calls)
+	})
+
+	t.Run("NotificationsAreNotCached", func(t *testing.T) {
+		client, transport, _ := newClient()
+
+		client.Notify("get", nil)
+		client.Notify("get", nil)
+		assert.Equal(t, 2, transport.calls)
+	})
+
+	t.Run("Disable", func(t *testing.T) {
+		client, transport, _ := newClient()
+
+		client.Call("get", nil)
+		client.SetCache("get", 0)
+		client.Call("get", nil)
+		assert.Equal(t, 2, transport.calls)
+
+		// The old result was removed.
+		client.SetCache("get", time.Minute)
+		response, _ := client.Call("get", nil)
+		assert.Equal(t, 3.0, response.Result())
+	})
+
+	t.Run("InterceptorsSeeCachedCalls", func(t *testing.T) {
+		client, _, _ := newClient()
+		var results []interface{}
+		client.Use(func(next jsonrpc.ClientInvoker) jsonrpc.ClientInvoker {
+			return func(ctx context.Context, request jsonrpc.Request) (jsonrpc.Response, error) {
+				response, err := next(ctx, request)
+				results = append(results, response.Result())
+
+				return response, err
+			}
+		})
+
+		client.Call("get", nil)
+		client.Call("get", nil)
+		assert.Equal(t, []interface{}{1.0, 1.0}, results)
+	})
+
+	t.Run("ExpiredEntriesAreRequestedAgain", func(t *testing.T) {
+		client, transport, clock := newClient()
+
+		for i := 0; i < 100; i++ {
+			client.Call("get", []int{i})
+		}
+
+		clock.Advance(time.Minute)
+		for i := 100; i < 200; i++ {
+			client.Call("get", []int{i})
+		}
+
+		// The first results expired, so they are requested again.
+		transport.calls = 0
+		for i := 0; i < 200; i += 10 {
+			client.Call("get", []int{i})
+		}
+		assert.Equal(t, 10, transport.calls)
+	})
+
+	t.Run("MaxAgeReplacesTTL", func(t *testing.T) {
+		client, transport, clock := newClient()
+		transport.maxAge, transport.sendMaxAge = 10*time.Second, true
+
+		response, err := client.Call("get", nil)
+		assert.NoError(t, err)
+		maxAge, ok := jsonrpc.ResponseMaxAge(response)
+		assert.True(t, ok)
+		assert.Equal(t, 10*time.Second, maxAge)
+
+		clock.Advance(9 * time.Second)
+		client.Call("get", nil)
+		assert.Equal(t, 1, transport.calls)
+
+		clock.Advance(time.Second)
+		client.Call("get", nil)
+		assert.Equal(t, 2, transport.calls)
+	})
+
+	t.Run("ZeroMaxAgeIsNotCached", func(t *testing.T) {
+		client, transport, _ := newClient()
+		transport.sendMaxAge = true
+
+		client.Call("get", nil)
+		client.Call("get", nil)
+		assert.Equal(t, 2, transport.calls)
+	})
+}
+
+func TestClient_SetCacheHTTPMaxAge(t *testing.T) {
+	calls := 0
+	server := jsonrpc.NewSimpleServer()
+	server.SetHandler("rates", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		calls++
+
+		return request.NewSuccessResponseWithMaxAge(calls, 0)
+	})
+
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	client := jsonrpc.NewClient(&jsonrpc.HTTPTransport{URL: httpServer.URL})
+	client.SetCache("rates", time.Minute)
+
+	client.Call("rates", nil)
+	client.Call("rates", nil)
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,57 @@
+package jsonrpc
+
+import (
+	"time"
+)
+
+// CallMetrics describes a single call (or notification) made by a Client.
+type CallMetrics struct {
+	Method       string
+	Notification bool
+
+	// Duration is the time spent sending the request and receiving the
+	// response.
+	Duration time.Duration
+
+	// ErrorCode is the code of the error response, or Success. It is also
+	// Success for notifications and calls that did not receive a response.
+	ErrorCode int
+
+	// Err is the error returned if there was no response, such as a network
+	// failure or timeout.
+	Err error
+
+	// BytesSent and BytesReceived are the sizes of the encoded request and
+	// response.
+	BytesSent     int
+	BytesReceived int
+}
+
+// ClientMetricsCollector receives the metrics for every call made by a
+// Client. This is where metrics can be forwarded to Prometheus, statsd, etc.
+//
+// ObserveCall is called after each call completes. It must be safe to call
+// concurrently and should not block.
+type ClientMetricsCollector interface {
+	ObserveCall(metrics CallMetrics)
+}
+
+// ClientMetricsFunc allows a function to be used as a ClientMetricsCollector.
+type ClientMetricsFunc func(metrics CallMetrics)
+
+func (fn ClientMetricsFunc) ObserveCall(metrics CallMetrics) {
+	fn(metrics)
+}
+
+// SetMetricsCollector sets the collector that receives the metrics for every
+// call. A nil collector disables metrics, which is the default:
+//
+//     client.SetMetricsCollector(jsonrpc.ClientMetricsFunc(func(metrics jsonrpc.CallMetrics) {
+//         callDuration.WithLabelValues(metrics.Method).Observe(metrics.Duration.Seconds())
+//     }))
+//
+// SetMetricsCollector is not safe to call concurrently with calls on the
+// client.
+func (client *Client) SetMetricsCollector(collector ClientMetricsCollector) {
+	client.metrics = collector
+}
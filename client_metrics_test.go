@@ -0,0 +1,55 @@
+package jsonrpc_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_SetMetricsCollector(t *testing.T) {
+	httpServer := httptest.NewServer(newTestServer())
+	defer httpServer.Close()
+
+	client := jsonrpc.NewHTTPClient(httpServer.URL)
+
+	var observed []jsonrpc.CallMetrics
+	client.SetMetricsCollector(jsonrpc.ClientMetricsFunc(func(metrics jsonrpc.CallMetrics) {
+		observed = append(observed, metrics)
+	}))
+
+	client.Call("sum", []int{1, 2})
+	client.Call("foo", nil)
+	client.Notify("notify_hello", nil)
+
+	assert.Len(t, observed, 3)
+
+	assert.Equal(t, "sum", observed[0].Method)
+	assert.Equal(t, jsonrpc.Success, observed[0].ErrorCode)
+	assert.NoError(t, observed[0].Err)
+	assert.Greater(t, observed[0].Duration.Nanoseconds(), int64(0))
+	assert.Greater(t, observed[0].BytesSent, 50)
+	assert.Equal(t, len(`{"jsonrpc":"2.0","id":"00000000000000000000000000000000","result":3}`),
+		observed[0].BytesReceived)
+
+	assert.Equal(t, jsonrpc.MethodNotFound, observed[1].ErrorCode)
+
+	assert.True(t, observed[2].Notification)
+	assert.Equal(t, 0, observed[2].BytesReceived)
+
+	t.Run("TransportError", func(t *testing.T) {
+		observed = nil
+		client := jsonrpc.NewClient(transportFunc(func(request jsonrpc.Request) (int, error) {
+			return 0, errors.New("down")
+		}))
+		client.SetMetricsCollector(jsonrpc.ClientMetricsFunc(func(metrics jsonrpc.CallMetrics) {
+			observed = append(observed, metrics)
+		}))
+
+		client.Call("sum", nil)
+		assert.EqualError(t, observed[0].Err, "down")
+		assert.Equal(t, jsonrpc.Success, observed[0].ErrorCode)
+	})
+}
@@ -0,0 +1,71 @@
+package jsonrpc
+
+import (
+	"io"
+	"strings"
+)
+
+// SetMethodInfo describes the params of methods so that calls can be checked
+// before they are sent. A call with params that are missing a required param,
+// or have a param of the wrong type, receives an InvalidParams error without
+// contacting the server. Params that are not described are allowed, since the
+// server may accept them.
+//
+// Calls (including CallInto and Send) receive the error as a Response, in the
+// same way as an error from the server. Notifications receive an *RPCError.
+// Batch does not check params.
+//
+// Types are checked in the same way as OnSchemaDrift. SetMethodInfo is not
+// safe to call concurrently with calls on the client.
+func (client *Client) SetMethodInfo(methods ...MethodInfo) {
+	if client.methodInfo == nil {
+		client.methodInfo = make(map[string]MethodInfo)
+	}
+
+	for _, info := range methods {
+		client.methodInfo[info.Name] = info
+	}
+}
+
+// LoadOpenRPC reads the methods from an OpenRPC document (such as the result
+// of the rpc.discover method) and uses them to check calls. See SetMethodInfo
+// and ParseOpenRPC.
+func (client *Client) LoadOpenRPC(r io.Reader) error {
+	methods, err := ParseOpenRPC(r)
+	if err != nil {
+		return err
+	}
+
+	client.SetMethodInfo(methods...)
+
+	return nil
+}
+
+// validateParams returns an *RPCError if the params of the request do not
+// match the MethodInfo of the method.
+func (client *Client) validateParams(request Request) *RPCError {
+	info, ok := client.methodInfo[request.Method()]
+	if !ok {
+		return nil
+	}
+
+	var problems []string
+	for _, drift := range paramsDrift(info, decodedResult(request.Params())) {
+		// Undeclared params are allowed.
+		if drift.Expected == "" {
+			continue
+		}
+
+		drift.Method = request.Method()
+		problems = append(problems, drift.String())
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return &RPCError{
+		Code:    InvalidParams,
+		Message: "Invalid params: " + strings.Join(problems, "; "),
+	}
+}
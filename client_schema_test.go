@@ -0,0 +1,82 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_SetMethodInfo(t *testing.T) {
+	server := newTestServer()
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	client := jsonrpc.NewHTTPClient(httpServer.URL)
+	client.SetMethodInfo(jsonrpc.MethodInfo{
+		Name: "subtract",
+		Params: []jsonrpc.ParamInfo{
+			{Name: "minuend", Type: "number", Required: true},
+			{Name: "subtrahend", Type: "number", Required: true},
+		},
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		response, err := client.Call("subtract", []int{5, 3})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2.0, response.Result())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		before := server.TotalPayloads()
+		response, err := client.Call("subtract", []interface{}{5, "3"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, jsonrpc.InvalidParams, response.ErrorCode())
+		assert.Equal(t, "Invalid params: subtract: subtrahend expected number but was string", response.ErrorMessage())
+		assert.Equal(t, before, server.TotalPayloads())
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		var result float64
+		err := client.CallInto("subtract", nil, &result)
+
+		var rpcErr *jsonrpc.RPCError
+		assert.True(t, errors.As(err, &rpcErr))
+		assert.Equal(t, jsonrpc.InvalidParams, rpcErr.Code)
+		assert.Equal(t, "Invalid params: subtract: missing required minuend; subtract: missing required subtrahend", rpcErr.Message)
+	})
+
+	t.Run("Undeclared", func(t *testing.T) {
+		response, err := client.Call("subtract", []int{5, 3, 1})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2.0, response.Result())
+	})
+
+	t.Run("Notification", func(t *testing.T) {
+		err := client.NotifyContext(context.Background(), "subtract", []string{"a", "b"})
+
+		var rpcErr *jsonrpc.RPCError
+		assert.True(t, errors.As(err, &rpcErr))
+		assert.Equal(t, jsonrpc.InvalidParams, rpcErr.Code)
+	})
+}
+
+func TestClient_LoadOpenRPC(t *testing.T) {
+	client := jsonrpc.NewHTTPClient("http://127.0.0.1:1")
+
+	assert.NoError(t, client.LoadOpenRPC(strings.NewReader(testOpenRPC)))
+
+	// The call is rejected without connecting to the server.
+	response, err := client.Call("getUser", []interface{}{"abc"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Invalid params: getUser: id expected integer but was string", response.ErrorMessage())
+
+	assert.Error(t, client.LoadOpenRPC(strings.NewReader(`[`)))
+}
@@ -0,0 +1,434 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// The number of notifications kept for a subscription that has not received
+// its id yet. See Subscribe.
+const earlyNotificationLimit = 64
+
+// DefaultSubscriptionQueueLimit is the number of notifications that can be
+// waiting to be received from a subscription. See WithQueueLimit.
+const DefaultSubscriptionQueueLimit = 1024
+
+var (
+	// ErrSubscriptionQueueFull is returned by the unsubscribe function of a
+	// subscription that was closed because too many notifications were
+	// waiting to be received.
+	ErrSubscriptionQueueFull = errors.New("Subscription queue is full")
+
+	// ErrSubscriptionDisconnected is returned by the unsubscribe function of a
+	// subscription that was closed because the connection was lost.
+	ErrSubscriptionDisconnected = errors.New("Subscription connection was lost")
+)
+
+// SubscribeOption changes the methods used by Subscribe.
+type SubscribeOption func(options *subscribeOptions)
+
+type subscribeOptions struct {
+	notification string
+	unsubscribe  string
+	queueLimit   int
+}
+
+// WithNotificationMethod sets the method of the notifications sent by the
+// server for the subscription.
+func WithNotificationMethod(method string) SubscribeOption {
+	return func(options *subscribeOptions) {
+		options.notification = method
+	}
+}
+
+// WithUnsubscribeMethod sets the method called to cancel the subscription. An
+// empty method means that nothing is sent to the server.
+func WithUnsubscribeMethod(method string) SubscribeOption {
+	return func(options *subscribeOptions) {
+		options.unsubscribe = method
+	}
+}
+
+// WithQueueLimit sets the number of notifications that can be waiting to be
+// received from the channel. The default is DefaultSubscriptionQueueLimit.
+func WithQueueLimit(limit int) SubscribeOption {
+	return func(options *subscribeOptions) {
+		options.queueLimit = limit
+	}
+}
+
+// newSubscribeOptions returns the methods for a subscribe method by
+// convention. For example "eth_subscribe" receives "eth_subscription"
+// notifications and is cancelled with "eth_unsubscribe".
+func newSubscribeOptions(method string, options []SubscribeOption) subscribeOptions {
+	opts := subscribeOptions{
+		notification: method,
+		queueLimit:   DefaultSubscriptionQueueLimit,
+	}
+	if i := strings.LastIndex(method, "subscribe"); i >= 0 {
+		rest := method[i+len("subscribe"):]
+		opts.notification = method[:i] + "subscription" + rest
+		opts.unsubscribe = method[:i] + "unsubscribe" + rest
+	}
+
+	for _, option := range options {
+		option(&opts)
+	}
+
+	return opts
+}
+
+// Subscribe calls a method that starts a subscription and returns a channel
+// that receives each notification for the subscription, decoded into T:
+//
+//     prices, unsubscribe, err := jsonrpc.Subscribe[Price](ctx, client, "prices.subscribe", []string{"BTC"})
+//     if err != nil {
+//         return err
+//     }
+//     defer unsubscribe()
+//
+//     for price := range prices {
+//         // ...
+//     }
+//
+// The result of method must be the id of the subscription. Notifications
+// must have params of {"subscription": id, "result": value}, where value is
+// decoded into T. Notifications that cannot be decoded into T are skipped.
+//
+// By convention, the notifications use the method name with "subscribe"
+// replaced by "subscription", and the subscription is cancelled by calling the
+// method with "subscribe" replaced by "unsubscribe" with the id as the only
+// param. This is the same as "eth_subscribe". The methods can be changed with
+// WithNotificationMethod and WithUnsubscribeMethod.
+//
+// The subscription is cancelled when unsubscribe is called or ctx is done.
+// The channel is then closed. unsubscribe can be called more than once, but
+// only the first call is sent to the server. Every call returns its error.
+//
+// The client must use a transport that implements ServerRequestReceiver.
+// Notifications are queued until they are received from the channel, so they
+// never hold up the connection. If the queue reaches its limit (see
+// WithQueueLimit) the subscription is cancelled and unsubscribe returns
+// ErrSubscriptionQueueFull. If the transport implements DisconnectNotifier and
+// the connection is lost, the channel is closed and unsubscribe returns
+// ErrSubscriptionDisconnected. In both cases the notifications that were
+// already queued are received before the channel is closed.
+func Subscribe[T any](ctx context.Context, client *Client, method string, params interface{}, options ...SubscribeOption) (<-chan T, func() error, error) {
+	opts := newSubscribeOptions(method, options)
+	subscriptions := &client.subscriptions
+
+	disconnects := subscriptions.begin(opts.notification)
+
+	var id interface{}
+	if err := client.CallIntoContext(ctx, method, params, &id); err != nil {
+		subscriptions.end(opts.notification, "", nil, disconnects)
+
+		return nil, nil, err
+	}
+
+	key := fmt.Sprint(id)
+	queue := newNotificationQueue(opts.queueLimit)
+	subscriptions.end(opts.notification, key, queue, disconnects)
+
+	out := make(chan T)
+	done := make(chan struct{})
+
+	var once sync.Once
+	var unsubscribeErr error
+	unsubscribe := func() error {
+		once.Do(func() {
+			subscriptions.remove(opts.notification, key)
+			close(done)
+
+			// There is nothing to cancel on the server after the connection
+			// was lost.
+			closedErr := queue.err()
+			if opts.unsubscribe != "" && !errors.Is(closedErr, ErrSubscriptionDisconnected) {
+				var ok interface{}
+				unsubscribeErr = client.CallIntoContext(context.WithoutCancel(ctx),
+					opts.unsubscribe, []interface{}{id}, &ok)
+			}
+
+			if unsubscribeErr == nil {
+				unsubscribeErr = closedErr
+			}
+		})
+
+		return unsubscribeErr
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			unsubscribe()
+		case <-done:
+		}
+	}()
+
+	go func() {
+		// A queue that was closed by the client must still be cancelled, but
+		// only after the channel is closed.
+		defer func() {
+			if queue.err() != nil {
+				unsubscribe()
+			}
+		}()
+		defer close(out)
+
+		for {
+			result, ok := queue.next(done)
+			if !ok {
+				return
+			}
+
+			var value T
+			if decodeInto(result, &value) != nil {
+				continue
+			}
+
+			select {
+			case out <- value:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return out, unsubscribe, nil
+}
+
+// decodeInto converts a decoded JSON value into v.
+func decodeInto(value interface{}, v interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// clientSubscriptions routes notifications to the subscriptions of a client.
+type clientSubscriptions struct {
+	mutex  sync.Mutex
+	active map[string]map[string]*notificationQueue
+
+	// Notifications can arrive before the response with the id of the
+	// subscription. While there are Subscribe calls waiting for their id, the
+	// notifications for unknown subscriptions are kept in early.
+	pending map[string]int
+	early   map[string][]map[string]interface{}
+
+	// disconnects is the number of times the connection was lost.
+	disconnects int
+}
+
+// begin starts a Subscribe call. It returns the number of disconnects so far,
+// which must be passed to end.
+func (subscriptions *clientSubscriptions) begin(notification string) int {
+	subscriptions.mutex.Lock()
+	defer subscriptions.mutex.Unlock()
+
+	if subscriptions.pending == nil {
+		subscriptions.pending = make(map[string]int)
+	}
+
+	subscriptions.pending[notification]++
+
+	return subscriptions.disconnects
+}
+
+// end finishes a Subscribe call. If queue is not nil it receives the
+// notifications for key, including those that arrived early.
+//
+// The connection may have been lost after the response was received, but
+// before the queue was added. The queue is closed straight away in that case,
+// since it would not be closed by closeAll.
+func (subscriptions *clientSubscriptions) end(notification, key string, queue *notificationQueue, disconnects int) {
+	subscriptions.mutex.Lock()
+	defer subscriptions.mutex.Unlock()
+
+	if queue != nil && disconnects != subscriptions.disconnects {
+		queue.close(ErrSubscriptionDisconnected)
+	}
+
+	if queue != nil {
+		if subscriptions.active == nil {
+			subscriptions.active = make(map[string]map[string]*notificationQueue)
+		}
+
+		if subscriptions.active[notification] == nil {
+			subscriptions.active[notification] = make(map[string]*notificationQueue)
+		}
+
+		subscriptions.active[notification][key] = queue
+
+		for _, params := range subscriptions.early[notification] {
+			if fmt.Sprint(params["subscription"]) == key {
+				queue.push(params["result"])
+			}
+		}
+	}
+
+	subscriptions.pending[notification]--
+	if subscriptions.pending[notification] == 0 {
+		delete(subscriptions.pending, notification)
+		delete(subscriptions.early, notification)
+	}
+}
+
+// closeAll closes every subscription because the connection was lost.
+func (subscriptions *clientSubscriptions) closeAll(err error) {
+	subscriptions.mutex.Lock()
+	defer subscriptions.mutex.Unlock()
+
+	closedErr := ErrSubscriptionDisconnected
+	if err != nil {
+		closedErr = fmt.Errorf("%w: %s", ErrSubscriptionDisconnected, err)
+	}
+
+	for _, queues := range subscriptions.active {
+		for _, queue := range queues {
+			queue.close(closedErr)
+		}
+	}
+
+	subscriptions.active = nil
+	subscriptions.disconnects++
+}
+
+func (subscriptions *clientSubscriptions) remove(notification, key string) {
+	subscriptions.mutex.Lock()
+	defer subscriptions.mutex.Unlock()
+
+	delete(subscriptions.active[notification], key)
+	if len(subscriptions.active[notification]) == 0 {
+		delete(subscriptions.active, notification)
+	}
+}
+
+// dispatch delivers a notification to its subscription. It returns false if
+// the notification is not for a subscription.
+func (subscriptions *clientSubscriptions) dispatch(request Request) bool {
+	subscriptions.mutex.Lock()
+	defer subscriptions.mutex.Unlock()
+
+	method := request.Method()
+	if subscriptions.active[method] == nil && subscriptions.pending[method] == 0 {
+		return false
+	}
+
+	params, ok := request.Params().(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	if queue, ok := subscriptions.active[method][fmt.Sprint(params["subscription"])]; ok {
+		queue.push(params["result"])
+
+		return true
+	}
+
+	if subscriptions.pending[method] == 0 {
+		return false
+	}
+
+	if subscriptions.early == nil {
+		subscriptions.early = make(map[string][]map[string]interface{})
+	}
+
+	if len(subscriptions.early[method]) < earlyNotificationLimit {
+		subscriptions.early[method] = append(subscriptions.early[method], params)
+	}
+
+	return true
+}
+
+// notificationQueue holds the notifications for a subscription until they
+// are received. It is closed with an error when it is full, or when the
+// connection is lost.
+type notificationQueue struct {
+	mutex     sync.Mutex
+	items     []interface{}
+	limit     int
+	closedErr error
+	signal    chan struct{}
+}
+
+func newNotificationQueue(limit int) *notificationQueue {
+	return &notificationQueue{
+		limit:  limit,
+		signal: make(chan struct{}, 1),
+	}
+}
+
+func (queue *notificationQueue) push(item interface{}) {
+	queue.mutex.Lock()
+	switch {
+	case queue.closedErr != nil:
+	case queue.limit > 0 && len(queue.items) >= queue.limit:
+		queue.closedErr = ErrSubscriptionQueueFull
+	default:
+		queue.items = append(queue.items, item)
+	}
+	queue.mutex.Unlock()
+
+	queue.wake()
+}
+
+// close stops the queue from receiving more notifications. The notifications
+// that are already queued can still be received.
+func (queue *notificationQueue) close(err error) {
+	queue.mutex.Lock()
+	if queue.closedErr == nil {
+		queue.closedErr = err
+	}
+	queue.mutex.Unlock()
+
+	queue.wake()
+}
+
+func (queue *notificationQueue) wake() {
+	select {
+	case queue.signal <- struct{}{}:
+	default:
+	}
+}
+
+// err returns the reason the queue was closed, or nil.
+func (queue *notificationQueue) err() error {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	return queue.closedErr
+}
+
+// next waits for the next notification. It returns false if done is closed
+// first, or the queue is closed and empty.
+func (queue *notificationQueue) next(done chan struct{}) (interface{}, bool) {
+	for {
+		queue.mutex.Lock()
+		if len(queue.items) > 0 {
+			item := queue.items[0]
+			queue.items = queue.items[1:]
+			queue.mutex.Unlock()
+
+			return item, true
+		}
+		closed := queue.closedErr != nil
+		queue.mutex.Unlock()
+
+		if closed {
+			return nil, false
+		}
+
+		select {
+		case <-queue.signal:
+		case <-done:
+			return nil, false
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package jsonrpc_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+type price struct {
+	Symbol string  `json:"symbol"`
+	Amount float64 `json:"amount"`
+}
+
+// newSubscriptionServer returns a client connected to a fake server. The
+// server responds to each request with handle, and requests are sent to
+// received.
+func newSubscriptionServer(t *testing.T, handle func(conn net.Conn, request jsonrpc.Request)) (*jsonrpc.Client, chan jsonrpc.Request) {
+	clientConn, serverConn := net.Pipe()
+	transport := jsonrpc.NewConnTransport(clientConn)
+	t.Cleanup(func() {
+		transport.Close()
+	})
+
+	received := make(chan jsonrpc.Request, 10)
+	go func() {
+		reader := bufio.NewReader(serverConn)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if err != nil {
+				return
+			}
+
+			request, _ := jsonrpc.NewRequestFromJSON(line)
+			received <- request
+			handle(serverConn, request)
+		}
+	}()
+
+	return jsonrpc.NewClient(transport), received
+}
+
+func TestSubscribe(t *testing.T) {
+	client, received := newSubscriptionServer(t, func(conn net.Conn, request jsonrpc.Request) {
+		switch request.Method() {
+		case "prices.subscribe":
+			// A notification can arrive before the id.
+			conn.Write([]byte(`{"jsonrpc":"2.0","method":"prices.subscription","params":{"subscription":"s1","result":{"symbol":"BTC","amount":1.5}}}` + "\n"))
+			conn.Write(append(jsonrpc.NewSuccessResponse(request.Id(), "s1").Bytes(), '\n'))
+			conn.Write([]byte(`{"jsonrpc":"2.0","method":"prices.subscription","params":{"subscription":"other","result":{"symbol":"ETH"}}}` + "\n"))
+			conn.Write([]byte(`{"jsonrpc":"2.0","method":"prices.subscription","params":{"subscription":"s1","result":"invalid"}}` + "\n"))
+			conn.Write([]byte(`{"jsonrpc":"2.0","method":"prices.subscription","params":{"subscription":"s1","result":{"symbol":"BTC","amount":2.5}}}` + "\n"))
+
+		default:
+			conn.Write(append(jsonrpc.NewSuccessResponse(request.Id(), true).Bytes(), '\n'))
+		}
+	})
+
+	prices, unsubscribe, err := jsonrpc.Subscribe[price](context.Background(), client, "prices.subscribe", []string{"BTC"})
+	assert.NoError(t, err)
+	assert.Equal(t, "prices.subscribe", (<-received).Method())
+
+	assert.Equal(t, price{"BTC", 1.5}, <-prices)
+	assert.Equal(t, price{"BTC", 2.5}, <-prices)
+
+	assert.NoError(t, unsubscribe())
+	assert.NoError(t, unsubscribe())
+
+	request := <-received
+	assert.Equal(t, "prices.unsubscribe", request.Method())
+	assert.Equal(t, []interface{}{"s1"}, request.Params())
+
+	_, ok := <-prices
+	assert.False(t, ok)
+}
+
+func TestSubscribe_Context(t *testing.T) {
+	client, received := newSubscriptionServer(t, func(conn net.Conn, request jsonrpc.Request) {
+		result := interface{}(true)
+		if request.Method() == "watch" {
+			result = 7
+		}
+
+		conn.Write(append(jsonrpc.NewSuccessResponse(request.Id(), result).Bytes(), '\n'))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	values, _, err := jsonrpc.Subscribe[int](ctx, client, "watch", nil,
+		jsonrpc.WithNotificationMethod("changed"),
+		jsonrpc.WithUnsubscribeMethod("unwatch"))
+	assert.NoError(t, err)
+	assert.Equal(t, "watch", (<-received).Method())
+
+	cancel()
+
+	select {
+	case request := <-received:
+		assert.Equal(t, "unwatch", request.Method())
+		assert.Equal(t, []interface{}{7.0}, request.Params())
+	case <-time.After(time.Second):
+		t.Fatal("unsubscribe was not called")
+	}
+
+	_, ok := <-values
+	assert.False(t, ok)
+}
+
+func TestSubscribe_Error(t *testing.T) {
+	client, _ := newSubscriptionServer(t, func(conn net.Conn, request jsonrpc.Request) {
+		conn.Write(append(jsonrpc.NewErrorResponse(request.Id(), jsonrpc.InvalidParams, "").Bytes(), '\n'))
+	})
+
+	_, _, err := jsonrpc.Subscribe[int](context.Background(), client, "prices.subscribe", nil)
+
+	var rpcErr *jsonrpc.RPCError
+	assert.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, jsonrpc.InvalidParams, rpcErr.Code)
+}
+
+func TestSubscribe_QueueFull(t *testing.T) {
+	client, received := newSubscriptionServer(t, func(conn net.Conn, request jsonrpc.Request) {
+		conn.Write(append(jsonrpc.NewSuccessResponse(request.Id(), "s1").Bytes(), '\n'))
+
+		if request.Method() == "prices.subscribe" {
+			for i := 0; i < 5; i++ {
+				conn.Write([]byte(`{"jsonrpc":"2.0","method":"prices.subscription","params":{"subscription":"s1","result":{"symbol":"BTC"}}}` + "\n"))
+			}
+			conn.Write([]byte(`{"jsonrpc":"2.0","method":"sent"}` + "\n"))
+		}
+	})
+
+	sent := make(chan struct{})
+	client.OnNotification("sent", func(params interface{}) {
+		close(sent)
+	})
+
+	prices, unsubscribe, err := jsonrpc.Subscribe[price](context.Background(), client,
+		"prices.subscribe", nil, jsonrpc.WithQueueLimit(1))
+	assert.NoError(t, err)
+	assert.Equal(t, "prices.subscribe", (<-received).Method())
+	<-sent
+
+	// At most one notification is being sent to the channel and one is
+	// queued. The rest are dropped and the subscription is cancelled.
+	count := 0
+	for range prices {
+		count++
+	}
+	assert.True(t, count >= 1 && count <= 2, count)
+
+	select {
+	case request := <-received:
+		assert.Equal(t, "prices.unsubscribe", request.Method())
+	case <-time.After(time.Second):
+		t.Fatal("unsubscribe was not called")
+	}
+
+	assert.Equal(t, jsonrpc.ErrSubscriptionQueueFull, unsubscribe())
+}
+
+func TestSubscribe_Disconnected(t *testing.T) {
+	client, received := newSubscriptionServer(t, func(conn net.Conn, request jsonrpc.Request) {
+		if request.Method() == "crash" {
+			conn.Close()
+			return
+		}
+
+		conn.Write(append(jsonrpc.NewSuccessResponse(request.Id(), "s1").Bytes(), '\n'))
+		conn.Write([]byte(`{"jsonrpc":"2.0","method":"prices.subscription","params":{"subscription":"s1","result":{"symbol":"BTC","amount":1.5}}}` + "\n"))
+	})
+
+	prices, unsubscribe, err := jsonrpc.Subscribe[price](context.Background(), client, "prices.subscribe", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "prices.subscribe", (<-received).Method())
+
+	_, err = client.Call("crash", nil)
+	assert.Error(t, err)
+
+	// The notifications that were already received are not lost.
+	assert.Equal(t, price{"BTC", 1.5}, <-prices)
+
+	select {
+	case _, ok := <-prices:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("the channel was not closed")
+	}
+
+	assert.ErrorIs(t, unsubscribe(), jsonrpc.ErrSubscriptionDisconnected)
+	assert.Equal(t, "crash", (<-received).Method())
+	assert.Len(t, received, 0)
+}
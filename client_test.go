@@ -0,0 +1,455 @@
+package jsonrpc_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// newBlockingServer returns a server with a "block" method that does not
+// respond until release is closed.
+func newBlockingServer(release chan bool) *jsonrpc.SimpleServer {
+	server := newTestServer()
+	server.SetHandler("block", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		<-release
+
+		return request.NewSuccessResponse(true)
+	})
+
+	return server
+}
+
+func TestClient_Call(t *testing.T) {
+	httpServer := httptest.NewServer(newTestServer())
+	defer httpServer.Close()
+
+	client := jsonrpc.NewHTTPClient(httpServer.URL)
+
+	t.Run("Success", func(t *testing.T) {
+		response, err := client.Call("sum", []int{1, 2, 4})
+		assert.NoError(t, err)
+		assert.Equal(t, 7.0, response.Result())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		response, err := client.Call("foo", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, jsonrpc.MethodNotFound, response.ErrorCode())
+	})
+
+	t.Run("Notify", func(t *testing.T) {
+		assert.NoError(t, client.Notify("notify_hello", []int{7}))
+	})
+}
+
+func TestClient_CallContext(t *testing.T) {
+	t.Run("HTTPTimeout", func(t *testing.T) {
+		release := make(chan bool)
+		httpServer := httptest.NewServer(newBlockingServer(release))
+		defer httpServer.Close()
+		defer close(release)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := jsonrpc.NewHTTPClient(httpServer.URL).CallContext(ctx, "block", nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("ConnCancel", func(t *testing.T) {
+		release := make(chan bool)
+		server := newBlockingServer(release)
+		defer close(release)
+
+		clientConn, serverConn := net.Pipe()
+		go server.ServeConn(serverConn)
+
+		transport := jsonrpc.NewConnTransport(clientConn)
+		defer transport.Close()
+		client := jsonrpc.NewClient(transport)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			cancel()
+		}()
+
+		_, err := client.CallContext(ctx, "block", nil)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ConnSuccess", func(t *testing.T) {
+		clientConn, serverConn := net.Pipe()
+		go newTestServer().ServeConn(serverConn)
+
+		transport := jsonrpc.NewConnTransport(clientConn)
+		defer transport.Close()
+		client := jsonrpc.NewClient(transport)
+
+		response, err := client.CallContext(context.Background(), "sum", []int{1, 2})
+		assert.NoError(t, err)
+		assert.Equal(t, 3.0, response.Result())
+
+		assert.NoError(t, client.Notify("notify_hello", nil))
+
+		response, err = client.CallContext(context.Background(), "sum", []int{3, 4})
+		assert.NoError(t, err)
+		assert.Equal(t, 7.0, response.Result())
+	})
+}
+
+func TestClient_Use(t *testing.T) {
+	httpServer := httptest.NewServer(newTestServer())
+	defer httpServer.Close()
+
+	client := jsonrpc.NewHTTPClient(httpServer.URL)
+
+	var calls []string
+	record := func(name string) jsonrpc.ClientInterceptor {
+		return func(next jsonrpc.ClientInvoker) jsonrpc.ClientInvoker {
+			return func(ctx context.Context, request jsonrpc.Request) (jsonrpc.Response, error) {
+				calls = append(calls, name+" "+request.Method())
+				response, err := next(ctx, request)
+				calls = append(calls, name+" done")
+
+				return response, err
+			}
+		}
+	}
+
+	// Replaces the params of every request.
+	rewrite := func(next jsonrpc.ClientInvoker) jsonrpc.ClientInvoker {
+		return func(ctx context.Context, request jsonrpc.Request) (jsonrpc.Response, error) {
+			return next(ctx, jsonrpc.NewRequestResponder(request.Version(),
+				request.Id(), request.Method(), []int{10, 20}))
+		}
+	}
+
+	client.Use(record("a"), record("b"), rewrite)
+
+	t.Run("Call", func(t *testing.T) {
+		calls = nil
+		response, err := client.Call("sum", []int{1, 2})
+		assert.NoError(t, err)
+		assert.Equal(t, 30.0, response.Result())
+		assert.Equal(t, []string{"a sum", "b sum", "b done", "a done"}, calls)
+	})
+
+	t.Run("Notify", func(t *testing.T) {
+		calls = nil
+		assert.NoError(t, client.Notify("notify_hello", nil))
+		assert.Equal(t, []string{"a notify_hello", "b notify_hello", "b done", "a done"}, calls)
+	})
+}
+
+func TestClient_CallInto(t *testing.T) {
+	server := newTestServer()
+	server.SetHandler("echo", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponse(request.Params())
+	})
+
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	client := jsonrpc.NewHTTPClient(httpServer.URL)
+
+	t.Run("Success", func(t *testing.T) {
+		var result int
+		assert.NoError(t, client.CallInto("sum", []int{1, 2, 4}, &result))
+		assert.Equal(t, 7, result)
+	})
+
+	t.Run("Struct", func(t *testing.T) {
+		var result struct {
+			Minuend    int `json:"minuend"`
+			Subtrahend int `json:"subtrahend"`
+		}
+		assert.NoError(t, client.CallInto("echo",
+			map[string]int{"minuend": 5, "subtrahend": 3}, &result))
+		assert.Equal(t, 5, result.Minuend)
+		assert.Equal(t, 3, result.Subtrahend)
+	})
+
+	t.Run("Mismatch", func(t *testing.T) {
+		var result string
+		err := client.CallInto("sum", []int{1, 2, 4}, &result)
+
+		var rpcErr *jsonrpc.RPCError
+		assert.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, jsonrpc.InvalidParams, rpcErr.Code)
+	})
+
+	t.Run("ErrorResponse", func(t *testing.T) {
+		var result string
+		err := client.CallInto("foo", nil, &result)
+
+		var rpcErr *jsonrpc.RPCError
+		assert.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, jsonrpc.MethodNotFound, rpcErr.Code)
+		assert.Equal(t, "Method not found (-32601)", err.Error())
+	})
+}
+
+func TestConnTransport_Multiplexed(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	transport := jsonrpc.NewConnTransport(clientConn)
+	defer transport.Close()
+	client := jsonrpc.NewClient(transport)
+
+	// Responds to the requests in the opposite order they were received.
+	go func() {
+		reader := bufio.NewReader(serverConn)
+		var requests []jsonrpc.Request
+		for len(requests) < 2 {
+			line, err := reader.ReadBytes('\n')
+			if err != nil {
+				return
+			}
+
+			request, _ := jsonrpc.NewRequestFromJSON(line)
+			requests = append(requests, request)
+		}
+
+		for i := len(requests) - 1; i >= 0; i-- {
+			response := jsonrpc.NewSuccessResponse(requests[i].Id(), requests[i].Method())
+			serverConn.Write(append(response.Bytes(), '\n'))
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for _, method := range []string{"a", "b"} {
+		wg.Add(1)
+		go func(method string) {
+			defer wg.Done()
+
+			response, err := client.Call(method, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, method, response.Result())
+		}(method)
+	}
+	wg.Wait()
+}
+
+func TestConnTransport_Closed(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	transport := jsonrpc.NewConnTransport(clientConn)
+	client := jsonrpc.NewClient(transport)
+
+	go func() {
+		bufio.NewReader(serverConn).ReadBytes('\n')
+		serverConn.Close()
+	}()
+
+	_, err := client.Call("sum", []int{1, 2})
+	assert.ErrorIs(t, err, io.EOF)
+
+	_, err = client.Call("sum", []int{1, 2})
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestClient_OnNotification(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	transport := jsonrpc.NewConnTransport(clientConn)
+	defer transport.Close()
+	client := jsonrpc.NewClient(transport)
+
+	notifications := make(chan interface{}, 10)
+	client.OnNotification("update", func(params interface{}) {
+		notifications <- params
+	})
+
+	// Sends notifications before responding to the request.
+	go func() {
+		line, err := bufio.NewReader(serverConn).ReadBytes('\n')
+		if err != nil {
+			return
+		}
+
+		request, _ := jsonrpc.NewRequestFromJSON(line)
+		serverConn.Write([]byte(`{"jsonrpc":"2.0","method":"update","params":[1]}` + "\n"))
+		serverConn.Write([]byte(`{"jsonrpc":"2.0","method":"other","params":[2]}` + "\n"))
+		serverConn.Write([]byte(`{"jsonrpc":"2.0","method":"update","params":[3]}` + "\n"))
+		serverConn.Write(append(jsonrpc.NewSuccessResponse(request.Id(), "ok").Bytes(), '\n'))
+	}()
+
+	response, err := client.Call("subscribe", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", response.Result())
+
+	assert.Equal(t, []interface{}{1.0}, <-notifications)
+	assert.Equal(t, []interface{}{3.0}, <-notifications)
+	assert.Len(t, notifications, 0)
+}
+
+func TestClient_SetIdPolicy(t *testing.T) {
+	httpServer := httptest.NewServer(newTestServer())
+	defer httpServer.Close()
+
+	client := jsonrpc.NewHTTPClient(httpServer.URL)
+	ctx := context.Background()
+	notification := jsonrpc.NewRequestResponder("2.0", nil, "sum", []int{1, 2})
+
+	t.Run("WithId", func(t *testing.T) {
+		response, err := client.Send(ctx, jsonrpc.NewRequestResponder("2.0", 7, "sum", []int{1, 2}))
+		assert.NoError(t, err)
+		assert.Equal(t, 7.0, response.Id())
+		assert.Equal(t, 3.0, response.Result())
+	})
+
+	t.Run("PreserveIds", func(t *testing.T) {
+		response, err := client.Send(ctx, notification)
+		assert.NoError(t, err)
+		assert.Nil(t, response)
+	})
+
+	t.Run("BackfillIds", func(t *testing.T) {
+		client.SetIdPolicy(jsonrpc.BackfillIds)
+
+		response, err := client.Send(ctx, notification)
+		assert.NoError(t, err)
+		assert.Len(t, response.Id(), 32)
+		assert.Equal(t, 3.0, response.Result())
+
+		assert.NoError(t, client.Notify("notify_hello", nil))
+	})
+
+	t.Run("RejectNotifications", func(t *testing.T) {
+		client.SetIdPolicy(jsonrpc.RejectNotifications)
+
+		_, err := client.Send(ctx, notification)
+		assert.Equal(t, jsonrpc.ErrUnexpectedNotification, err)
+
+		assert.NoError(t, client.Notify("notify_hello", nil))
+	})
+}
+
+func TestHTTPTransport_MaxResponseSize(t *testing.T) {
+	httpServer := httptest.NewServer(newTestServer())
+	defer httpServer.Close()
+
+	t.Run("TooLarge", func(t *testing.T) {
+		client := jsonrpc.NewClient(&jsonrpc.HTTPTransport{
+			URL:             httpServer.URL,
+			MaxResponseSize: 10,
+		})
+
+		_, err := client.Call("sum", []int{1, 2, 4})
+		assert.ErrorIs(t, err, jsonrpc.ErrPayloadTooLarge)
+	})
+
+	t.Run("WithinLimit", func(t *testing.T) {
+		client := jsonrpc.NewClient(&jsonrpc.HTTPTransport{
+			URL:             httpServer.URL,
+			MaxResponseSize: 1024,
+		})
+
+		result, err := client.Call("sum", []int{1, 2, 4})
+		if assert.NoError(t, err) {
+			assert.Equal(t, 7.0, result.Result())
+		}
+	})
+}
+
+func TestHTTPTransport_Header(t *testing.T) {
+	var received http.Header
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+		newTestServer().ServeHTTP(w, r)
+	}))
+	defer httpServer.Close()
+
+	client := jsonrpc.NewClient(&jsonrpc.HTTPTransport{
+		URL: httpServer.URL,
+		Header: http.Header{
+			"Authorization": {"Bearer client"},
+			"X-Client":      {"abc"},
+		},
+	})
+
+	t.Run("Client", func(t *testing.T) {
+		_, err := client.Call("sum", []int{1})
+		assert.NoError(t, err)
+		assert.Equal(t, "Bearer client", received.Get("Authorization"))
+		assert.Equal(t, "abc", received.Get("X-Client"))
+	})
+
+	t.Run("Call", func(t *testing.T) {
+		ctx := jsonrpc.WithHeader(context.Background(), "Authorization", "Bearer call")
+		ctx = jsonrpc.WithHeader(ctx, "X-Trace", "1")
+		ctx = jsonrpc.WithHeader(ctx, "X-Trace", "2")
+
+		_, err := client.CallContext(ctx, "sum", []int{1})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Bearer call"}, received.Values("Authorization"))
+		assert.Equal(t, "abc", received.Get("X-Client"))
+		assert.Equal(t, []string{"1", "2"}, received.Values("X-Trace"))
+		assert.Equal(t, "application/json", received.Get("Content-Type"))
+	})
+}
+
+func TestClient_TransportError(t *testing.T) {
+	t.Run("Unreachable", func(t *testing.T) {
+		_, err := jsonrpc.NewHTTPClient("http://127.0.0.1:0").Call("sum", []int{1, 2})
+
+		var transportErr *jsonrpc.TransportError
+		assert.True(t, errors.As(err, &transportErr))
+
+		var rpcErr *jsonrpc.RPCError
+		assert.False(t, errors.As(err, &rpcErr))
+	})
+
+	t.Run("HTTPStatus", func(t *testing.T) {
+		httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer httpServer.Close()
+
+		err := jsonrpc.NewHTTPClient(httpServer.URL).CallInto("sum", []int{1, 2}, new(float64))
+
+		var transportErr *jsonrpc.TransportError
+		assert.True(t, errors.As(err, &transportErr))
+
+		var statusErr *jsonrpc.HTTPStatusError
+		if assert.True(t, errors.As(err, &statusErr)) {
+			assert.Equal(t, http.StatusBadGateway, statusErr.StatusCode)
+		}
+	})
+
+	t.Run("InvalidResponse", func(t *testing.T) {
+		httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"jsonrpc":"2.0","id":"other","result":1}`))
+		}))
+		defer httpServer.Close()
+
+		_, err := jsonrpc.NewHTTPClient(httpServer.URL).Call("sum", []int{1, 2})
+
+		var transportErr *jsonrpc.TransportError
+		assert.True(t, errors.As(err, &transportErr))
+	})
+
+	t.Run("RPCError", func(t *testing.T) {
+		httpServer := httptest.NewServer(newTestServer())
+		defer httpServer.Close()
+
+		err := jsonrpc.NewHTTPClient(httpServer.URL).CallInto("foo", nil, new(float64))
+
+		var transportErr *jsonrpc.TransportError
+		assert.False(t, errors.As(err, &transportErr))
+
+		var rpcErr *jsonrpc.RPCError
+		if assert.True(t, errors.As(err, &rpcErr)) {
+			assert.Equal(t, jsonrpc.MethodNotFound, rpcErr.Code)
+			assert.Equal(t, "Method not found", rpcErr.Message)
+		}
+	})
+}
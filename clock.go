@@ -0,0 +1,105 @@
+package jsonrpc
+
+import (
+	"time"
+)
+
+// Clock provides the current time to the server. It is used for Uptime, the
+// age of cached results and the time of wire captures. Its timers are used for
+// the MaxDuration of Limits and the timeout of SetMaxConcurrentRequests. A
+// Client also uses it to expire cached results, see Client.SetClock.
+//
+// The default clock is the system clock. Tests can use jsonrpctest.Clock to
+// control time instead of sleeping.
+type Clock interface {
+	Now() time.Time
+
+	// NewTimer creates a Timer that sends the time on its channel once d
+	// has passed, in the same way as time.NewTimer.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single event created by Clock.NewTimer. It is the same as a
+// time.Timer.
+type Timer interface {
+	// C returns the channel that the time is sent on when the timer fires.
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing. It returns false if the timer has
+	// already fired or been stopped.
+	Stop() bool
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	timer *time.Timer
+}
+
+func (timer systemTimer) C() <-chan time.Time {
+	return timer.timer.C
+}
+
+func (timer systemTimer) Stop() bool {
+	return timer.timer.Stop()
+}
+
+// SetClock replaces the clock used by the server. A nil clock will use the
+// system clock.
+//
+// Uptime is measured from when the clock was set.
+func (server *SimpleServer) SetClock(clock Clock) {
+	if clock == nil {
+		clock = systemClock{}
+	}
+
+	server.clock = clock
+	server.startTime = clock.Now()
+}
+
+// start sets the start time of a zero value server the first time it is used.
+// See Uptime.
+func (server *SimpleServer) start() {
+	server.startOnce.Do(func() {
+		if server.startTime.IsZero() {
+			server.startTime = server.now()
+		}
+	})
+}
+
+func (server *SimpleServer) now() time.Time {
+	if server.clock == nil {
+		return time.Now()
+	}
+
+	return server.clock.Now()
+}
+
+func (server *SimpleServer) newTimer(d time.Duration) Timer {
+	if server.clock == nil {
+		return systemClock{}.NewTimer(d)
+	}
+
+	return server.clock.NewTimer(d)
+}
+
+// serverClock follows the clock of the server, even if it is changed later.
+type serverClock struct {
+	server *SimpleServer
+}
+
+func (clock serverClock) Now() time.Time {
+	return clock.server.now()
+}
+
+func (clock serverClock) NewTimer(d time.Duration) Timer {
+	return clock.server.newTimer(d)
+}
@@ -0,0 +1,52 @@
+// jsonrpc-conformance checks a JSON-RPC 2.0 server against the examples in the
+// specification and the edge cases from this package.
+//
+// The server can be a URL that accepts JSON-RPC over HTTP:
+//
+//     jsonrpc-conformance -url http://localhost:8080/rpc
+//
+// Or a command that reads a single payload from stdin and writes the response
+// to stdout:
+//
+//     jsonrpc-conformance -- ./my-server --stdio
+//
+// The exit status is 1 if there are any divergences.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/elliotchance/jsonrpc/conformance"
+)
+
+func main() {
+	url := flag.String("url", "", "URL of a JSON-RPC over HTTP server")
+	flag.Parse()
+
+	var target conformance.Target
+	switch {
+	case *url != "":
+		target = conformance.HTTPTarget(*url)
+
+	case flag.NArg() > 0:
+		target = conformance.CommandTarget(flag.Arg(0), flag.Args()[1:]...)
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	divergences := conformance.Run(target, conformance.AllCases)
+	for _, divergence := range divergences {
+		fmt.Println(divergence)
+	}
+
+	fmt.Printf("%d cases, %d divergences\n",
+		len(conformance.AllCases), len(divergences))
+
+	if len(divergences) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,112 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+)
+
+// ResultMarshaler can be implemented by types that are returned as results (or
+// inside a result) to control how they are encoded in a response. This is
+// useful for domain types (such as decimals or custom IDs) that should have a
+// different JSON form in responses without implementing json.Marshaler, which
+// would affect how they are encoded everywhere else.
+//
+// MarshalResult must return valid JSON.
+//
+// A ResultMarshaler is honored when it is the result itself or an element of a
+// []interface{} or map[string]interface{} result (at any depth).
+type ResultMarshaler interface {
+	MarshalResult() ([]byte, error)
+}
+
+// ParamsUnmarshaler can be implemented by types that receive the params of a
+// request through UnmarshalParams. It is the counterpart to ResultMarshaler.
+//
+// UnmarshalParams receives the params encoded as JSON.
+type ParamsUnmarshaler interface {
+	UnmarshalParams(data []byte) error
+}
+
+// UnmarshalParams decodes the params of a request into v. If v implements
+// ParamsUnmarshaler then it will be used, otherwise the params are decoded with
+// json.Unmarshal.
+func UnmarshalParams(request Request, v interface{}) error {
+	data, err := json.Marshal(request.Params())
+	if err != nil {
+		return err
+	}
+
+	if unmarshaler, ok := v.(ParamsUnmarshaler); ok {
+		return unmarshaler.UnmarshalParams(data)
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// marshalResult replaces any ResultMarshaler values with their encoded form so
+// that the value can be passed to json.Marshal.
+func marshalResult(v interface{}) (interface{}, error) {
+	// Most results do not contain a ResultMarshaler, so they do not need to be
+	// copied.
+	if !containsResultMarshaler(v) {
+		return v, nil
+	}
+
+	switch value := v.(type) {
+	case ResultMarshaler:
+		data, err := value.MarshalResult()
+		if err != nil {
+			return nil, err
+		}
+
+		return json.RawMessage(data), nil
+
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, element := range value {
+			var err error
+			result[i], err = marshalResult(element)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return result, nil
+
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for key, element := range value {
+			var err error
+			result[key], err = marshalResult(element)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return result, nil
+	}
+
+	return v, nil
+}
+
+func containsResultMarshaler(v interface{}) bool {
+	switch value := v.(type) {
+	case ResultMarshaler:
+		return true
+
+	case []interface{}:
+		for _, element := range value {
+			if containsResultMarshaler(element) {
+				return true
+			}
+		}
+
+	case map[string]interface{}:
+		for _, element := range value {
+			if containsResultMarshaler(element) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,84 @@
+package jsonrpc_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// cents is encoded as a decimal string in results.
+type cents int
+
+func (c cents) MarshalResult() ([]byte, error) {
+	if c < 0 {
+		return nil, errors.New("negative")
+	}
+
+	return []byte(`"` + strconv.Itoa(int(c)/100) + "." + strconv.Itoa(int(c)%100) + `"`), nil
+}
+
+// upperParams receives the first positional param in upper case.
+type upperParams struct {
+	value string
+}
+
+func (p *upperParams) UnmarshalParams(data []byte) error {
+	p.value = strings.ToUpper(string(data))
+
+	return nil
+}
+
+func TestResultMarshaler(t *testing.T) {
+	t.Run("Result", func(t *testing.T) {
+		response := jsonrpc.NewSuccessResponse(1, cents(1234))
+
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":"12.34"}`, response.String())
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		response := jsonrpc.NewSuccessResponse(1, map[string]interface{}{
+			"prices": []interface{}{cents(150), "free"},
+		})
+
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":{"prices":["1.50","free"]}}`, response.String())
+	})
+
+	t.Run("Responses", func(t *testing.T) {
+		responses := jsonrpc.Responses{jsonrpc.NewSuccessResponse(1, cents(1234))}
+
+		assert.Equal(t, `[{"jsonrpc":"2.0","id":1,"result":"12.34"}]`, responses.String())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		response := jsonrpc.NewSuccessResponse(1, cents(-1))
+
+		assert.Nil(t, response.Bytes())
+	})
+}
+
+func TestUnmarshalParams(t *testing.T) {
+	t.Run("ParamsUnmarshaler", func(t *testing.T) {
+		request := jsonrpc.NewRequestResponder("2.0", 1, "foo", []interface{}{"bar"})
+		params := new(upperParams)
+		err := jsonrpc.UnmarshalParams(request, params)
+
+		assert.NoError(t, err)
+		assert.Equal(t, `["BAR"]`, params.value)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		request := jsonrpc.NewRequestResponder("2.0", 1, "foo",
+			map[string]interface{}{"name": "Bob"})
+		var params struct {
+			Name string `json:"name"`
+		}
+		err := jsonrpc.UnmarshalParams(request, &params)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Bob", params.Name)
+	})
+}
@@ -0,0 +1,257 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"compress/flate"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"time"
+)
+
+// The method that a client sends as the first request on a connection to
+// negotiate compression. See SetCompressionCodecs.
+const compressionMethod = "rpc.compression"
+
+// CompressionCodec compresses a whole stream connection, such as a TCP or unix
+// socket. Unlike SetCompressionThreshold (which compresses individual HTTP
+// responses) the compression state is shared by all the payloads on the
+// connection, so even small payloads benefit from it.
+//
+// DeflateCodec is provided. Other codecs, such as zstd or snappy, can be added
+// with a small adapter (see the README) so that this package does not need to
+// depend on them.
+type CompressionCodec interface {
+	// Name is used to negotiate the codec with the other side, such as "zstd".
+	Name() string
+
+	// NewReader decompresses everything read from r.
+	NewReader(r io.Reader) (io.Reader, error)
+
+	// NewWriter compresses everything written to w.
+	NewWriter(w io.Writer) (CompressionWriter, error)
+}
+
+// CompressionWriter is a compressing writer. Flush is called after each payload
+// so that it can be read by the other side straight away.
+type CompressionWriter interface {
+	io.Writer
+	Flush() error
+}
+
+// DeflateCodec compresses connections with deflate (RFC 1951).
+var DeflateCodec CompressionCodec = deflateCodec{}
+
+type deflateCodec struct{}
+
+func (deflateCodec) Name() string {
+	return "deflate"
+}
+
+func (deflateCodec) NewReader(r io.Reader) (io.Reader, error) {
+	return flate.NewReader(r), nil
+}
+
+func (deflateCodec) NewWriter(w io.Writer) (CompressionWriter, error) {
+	return flate.NewWriter(w, flate.DefaultCompression)
+}
+
+// SetCompressionCodecs allows clients to compress their connection to
+// ServeConn (and all of the transports that use it) with one of the codecs.
+// This is most useful for high volume links between internal services.
+//
+// The codec is negotiated by the client sending a "rpc.compression" request
+// as the first payload on the connection. The params are the names of the
+// codecs the client supports, in order of preference:
+//
+//     {"jsonrpc":"2.0","method":"rpc.compression","params":["zstd","deflate"],"id":1}
+//
+// The result is the name of the first codec that the server also supports, or
+// null if there are none. Once the response has been sent everything after it
+// (in both directions) is compressed with that codec. NewCompressedConnTransport
+// does this for you.
+//
+// Connections that do not send "rpc.compression" first are not compressed.
+// Calling SetCompressionCodecs with no codecs disables negotiation, which is
+// the default. Clients will then receive a MethodNotFound error and can
+// continue without compression.
+func (server *SimpleServer) SetCompressionCodecs(codecs ...CompressionCodec) {
+	server.compressionCodecs = codecs
+}
+
+// negotiateCompression handles a "rpc.compression" request. ok will be false if
+// the payload is any other request. codec will be nil if there is no codec
+// that both sides support. data is the response to send before compression
+// starts.
+func (server *SimpleServer) negotiateCompression(payload []byte) (codec CompressionCodec, data []byte, ok bool) {
+	if len(server.compressionCodecs) == 0 {
+		return nil, nil, false
+	}
+
+	var request struct {
+		Method string          `json:"method"`
+		Params []string        `json:"params"`
+		Id     json.RawMessage `json:"id"`
+	}
+	if json.Unmarshal(payload, &request) != nil ||
+		request.Method != compressionMethod || len(request.Id) == 0 {
+		return nil, nil, false
+	}
+
+	var id interface{}
+	json.Unmarshal(request.Id, &id)
+
+	for _, name := range request.Params {
+		for _, c := range server.compressionCodecs {
+			if c.Name() == name {
+				return c, NewSuccessResponse(id, name).Bytes(), true
+			}
+		}
+	}
+
+	return nil, NewSuccessResponse(id, nil).Bytes(), true
+}
+
+// compressStream wraps both sides of a connection with the codec. Any data
+// that has already been buffered by reader is decompressed as well.
+func compressStream(codec CompressionCodec, reader *bufio.Reader, w io.Writer) (*bufio.Reader, io.Writer, error) {
+	r, err := codec.NewReader(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	writer, err := codec.NewWriter(w)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return bufio.NewReader(closedStreamReader{r}), writer, nil
+}
+
+// closedStreamReader treats a compressed stream that ends without being
+// finished as a normal EOF. Neither side finishes the stream before closing
+// the connection, so this is how every compressed connection ends.
+type closedStreamReader struct {
+	io.Reader
+}
+
+func (reader closedStreamReader) Read(p []byte) (int, error) {
+	n, err := reader.Reader.Read(p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+
+	return n, err
+}
+
+// writePayload writes the payload with the framing, flushing it if the writer
+// is compressed.
+func writePayload(framing Framing, writer io.Writer, payload []byte) error {
+	if err := framing.WritePayload(writer, payload); err != nil {
+		return err
+	}
+
+	if compressed, ok := writer.(CompressionWriter); ok {
+		return compressed.Flush()
+	}
+
+	return nil
+}
+
+// NewCompressedConnTransport is like NewConnTransport except that it first
+// negotiates compression with the server using the codecs, in order of
+// preference. See SetCompressionCodecs.
+//
+// If the server does not support any of the codecs (or compression at all) the
+// connection is used without compression. Compression returns the name of the
+// codec that was chosen.
+//
+// ctx only applies to the negotiation. If it fails the connection is closed.
+func NewCompressedConnTransport(ctx context.Context, conn net.Conn, codecs ...CompressionCodec) (*ConnTransport, error) {
+	transport, err := negotiateConnCompression(ctx, conn, codecs)
+	if err != nil {
+		conn.Close()
+
+		return nil, err
+	}
+
+	go transport.readResponses()
+
+	return transport, nil
+}
+
+func negotiateConnCompression(ctx context.Context, conn net.Conn, codecs []CompressionCodec) (*ConnTransport, error) {
+	transport := newConnTransport(conn)
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	defer conn.SetDeadline(time.Time{})
+
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	names := make([]string, len(codecs))
+	for i, codec := range codecs {
+		names[i] = codec.Name()
+	}
+
+	request := NewRequestResponder("2.0", compressionMethod, compressionMethod, names)
+	if err := NewlineFraming.WritePayload(conn, []byte(request.String())); err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	data, err := NewlineFraming.ReadPayload(transport.reader)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	var response struct {
+		Result *string `json:"result"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	// An error (most likely MethodNotFound) means the server does not support
+	// compression.
+	if response.Result == nil {
+		return transport, nil
+	}
+
+	for _, codec := range codecs {
+		if codec.Name() == *response.Result {
+			reader, writer, err := compressStream(codec, transport.reader, conn)
+			if err != nil {
+				return nil, err
+			}
+
+			transport.reader = reader
+			transport.writer = writer
+			transport.compression = codec.Name()
+
+			return transport, nil
+		}
+	}
+
+	return transport, nil
+}
+
+// contextError prefers the error of ctx, since a failed read or write is
+// usually caused by the deadline being reached.
+func contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	return err
+}
+
+// Compression returns the name of the codec used to compress the connection,
+// or an empty string if it is not compressed.
+func (transport *ConnTransport) Compression() string {
+	return transport.compression
+}
@@ -0,0 +1,160 @@
+package jsonrpc_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingConn keeps a copy of everything written to the connection.
+type recordingConn struct {
+	net.Conn
+	mutex   sync.Mutex
+	written bytes.Buffer
+}
+
+func (conn *recordingConn) Write(data []byte) (int, error) {
+	conn.mutex.Lock()
+	conn.written.Write(data)
+	conn.mutex.Unlock()
+
+	return conn.Conn.Write(data)
+}
+
+func (conn *recordingConn) String() string {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	return conn.written.String()
+}
+
+type unsupportedCodec struct {
+	jsonrpc.CompressionCodec
+}
+
+func (unsupportedCodec) Name() string {
+	return "zstd"
+}
+
+func serveCompressed(server *jsonrpc.SimpleServer) (client net.Conn, done chan error) {
+	clientConn, serverConn := net.Pipe()
+	done = make(chan error, 1)
+	go func() {
+		done <- server.ServeConn(serverConn)
+	}()
+
+	return clientConn, done
+}
+
+func TestNewCompressedConnTransport(t *testing.T) {
+	t.Run("Negotiated", func(t *testing.T) {
+		server := newTestServer()
+		server.SetCompressionCodecs(jsonrpc.DeflateCodec)
+		clientConn, done := serveCompressed(server)
+		conn := &recordingConn{Conn: clientConn}
+
+		transport, err := jsonrpc.NewCompressedConnTransport(context.Background(),
+			conn, unsupportedCodec{}, jsonrpc.DeflateCodec)
+		require.NoError(t, err)
+		assert.Equal(t, "deflate", transport.Compression())
+
+		client := jsonrpc.NewClient(transport)
+		response, err := client.Call("sum", []int{1, 2})
+		require.NoError(t, err)
+		assert.Equal(t, 3.0, response.Result())
+
+		padding := strings.Repeat("a", 1000)
+		_, err = client.Call("sum", []string{padding})
+		require.NoError(t, err)
+
+		written := conn.String()
+		assert.Contains(t, written, `"rpc.compression"`)
+		assert.NotContains(t, written, padding)
+		assert.Less(t, len(written), len(padding))
+
+		transport.Close()
+		assert.NoError(t, <-done)
+	})
+
+	t.Run("NoCommonCodec", func(t *testing.T) {
+		server := newTestServer()
+		server.SetCompressionCodecs(jsonrpc.DeflateCodec)
+		clientConn, _ := serveCompressed(server)
+
+		transport, err := jsonrpc.NewCompressedConnTransport(context.Background(),
+			clientConn, unsupportedCodec{})
+		require.NoError(t, err)
+		defer transport.Close()
+		assert.Equal(t, "", transport.Compression())
+
+		response, err := jsonrpc.NewClient(transport).Call("sum", []int{1, 2})
+		require.NoError(t, err)
+		assert.Equal(t, 3.0, response.Result())
+	})
+
+	t.Run("NotSupportedByServer", func(t *testing.T) {
+		clientConn, _ := serveCompressed(newTestServer())
+
+		transport, err := jsonrpc.NewCompressedConnTransport(context.Background(),
+			clientConn, jsonrpc.DeflateCodec)
+		require.NoError(t, err)
+		defer transport.Close()
+		assert.Equal(t, "", transport.Compression())
+
+		response, err := jsonrpc.NewClient(transport).Call("sum", []int{1, 2})
+		require.NoError(t, err)
+		assert.Equal(t, 3.0, response.Result())
+	})
+
+	t.Run("ContextCancelled", func(t *testing.T) {
+		clientConn, serverConn := net.Pipe()
+		defer serverConn.Close()
+		go io.Copy(io.Discard, serverConn)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := jsonrpc.NewCompressedConnTransport(ctx, clientConn, jsonrpc.DeflateCodec)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestSimpleServer_SetCompressionCodecs(t *testing.T) {
+	t.Run("UncompressedClient", func(t *testing.T) {
+		server := newTestServer()
+		server.SetCompressionCodecs(jsonrpc.DeflateCodec)
+		clientConn, _ := serveCompressed(server)
+
+		transport := jsonrpc.NewConnTransport(clientConn)
+		defer transport.Close()
+
+		response, err := jsonrpc.NewClient(transport).Call("sum", []int{1, 2})
+		require.NoError(t, err)
+		assert.Equal(t, 3.0, response.Result())
+	})
+
+	t.Run("OnlyFirstPayload", func(t *testing.T) {
+		server := newTestServer()
+		server.SetCompressionCodecs(jsonrpc.DeflateCodec)
+		clientConn, _ := serveCompressed(server)
+
+		transport := jsonrpc.NewConnTransport(clientConn)
+		defer transport.Close()
+		client := jsonrpc.NewClient(transport)
+
+		_, err := client.Call("sum", []int{1, 2})
+		require.NoError(t, err)
+
+		response, err := client.Call("rpc.compression", []string{"deflate"})
+		require.NoError(t, err)
+		assert.Equal(t, jsonrpc.MethodNotFound, response.ErrorCode())
+	})
+}
@@ -0,0 +1,194 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"regexp"
+	"sort"
+)
+
+// Compliance controls behaviors where some clients expect something other than
+// what the JSON-RPC 2.0 specification requires. The zero value is fully
+// compliant with the specification.
+type Compliance struct {
+	// EmptyBatchReturnsEmptyArray will respond to an empty batch ("[]") with an
+	// empty array instead of an Invalid request error.
+	EmptyBatchReturnsEmptyArray bool
+
+	// ParseErrorEchoesId will try to find the id of a single request that
+	// could not be parsed, so that the Parse error can be correlated by the
+	// client. The specification requires the id to be null. Finding the id is
+	// best effort, a null id is still used if it cannot be found.
+	ParseErrorEchoesId bool
+
+	// CaseInsensitiveMethods matches method names without considering case,
+	// so "getuser" will call the handler for "getUser". This only applies to
+	// handlers set with SetHandler. If more than one handler matches, the
+	// first in alphabetical order is used.
+	CaseInsensitiveMethods bool
+
+	// TrimMethodWhitespace ignores whitespace at the start and end of method
+	// names. This only applies to handlers set with SetHandler.
+	TrimMethodWhitespace bool
+
+	// RejectInvalidIds responds with an InvalidRequest error to requests that
+	// have an id that is not a string, number or null, such as an object or an
+	// array. Numbers with a fractional part (like 1.5) are also rejected
+	// because the specification says they should not be used. Without this
+	// the id is accepted and echoed back in the response.
+	//
+	// Unlike the options above this is stricter than the specification
+	// requires, so it is not part of StrictCompliance.
+	RejectInvalidIds bool
+
+	// RejectUnknownMembers responds with an InvalidRequest error to requests
+	// that have members other than "jsonrpc", "method", "params" and "id". The
+	// message names the unknown member, such as "Unknown member: extra". This
+	// is useful for testing that clients only send what the specification
+	// allows. Like RejectInvalidIds, it is not part of StrictCompliance.
+	RejectUnknownMembers bool
+}
+
+var (
+	// StrictCompliance follows the JSON-RPC 2.0 specification. This is the
+	// default.
+	StrictCompliance = Compliance{}
+
+	// LenientCompliance is compatible with clients that do not strictly follow
+	// the JSON-RPC 2.0 specification.
+	LenientCompliance = Compliance{
+		EmptyBatchReturnsEmptyArray: true,
+		ParseErrorEchoesId:          true,
+		CaseInsensitiveMethods:      true,
+		TrimMethodWhitespace:        true,
+	}
+)
+
+// SetCompliance changes how strictly the server follows the JSON-RPC 2.0
+// specification. See Compliance.
+func (server *SimpleServer) SetCompliance(compliance Compliance) {
+	server.compliance = compliance
+}
+
+// checkCompliance returns an InvalidRequest error for a request that breaks
+// one of the optional rules of the Compliance. The request is only decoded if
+// one of the rules is enabled. Requests that are not JSON objects are left for
+// the usual errors.
+func (server *SimpleServer) checkCompliance(jsonRequest []byte) (errCode int, errMessage string) {
+	compliance := server.compliance
+	if !compliance.RejectInvalidIds && !compliance.RejectUnknownMembers {
+		return Success, ""
+	}
+
+	var object map[string]json.RawMessage
+	if json.Unmarshal(jsonRequest, &object) != nil || object == nil {
+		return Success, ""
+	}
+
+	if compliance.RejectUnknownMembers {
+		if member := unknownMember(object); member != "" {
+			return InvalidRequest, "Unknown member: " + member
+		}
+	}
+
+	if rawId, ok := object["id"]; ok && compliance.RejectInvalidIds && !wellFormedId(rawId) {
+		return InvalidRequest, "Id must be a string, integer or null."
+	}
+
+	return Success, ""
+}
+
+// unknownMember returns the first member (in alphabetical order) of a request
+// that is not defined by the specification, or an empty string.
+func unknownMember(object map[string]json.RawMessage) string {
+	var unknown []string
+	for member := range object {
+		switch member {
+		case "jsonrpc", "method", "params", "id":
+		default:
+			unknown = append(unknown, member)
+		}
+	}
+
+	if len(unknown) == 0 {
+		return ""
+	}
+
+	sort.Strings(unknown)
+
+	return unknown[0]
+}
+
+// wellFormedId returns true if the id is a string, null or a number without a
+// fractional part.
+func wellFormedId(rawId json.RawMessage) bool {
+	var id interface{}
+	decoder := json.NewDecoder(bytes.NewReader(rawId))
+	decoder.UseNumber()
+	if decoder.Decode(&id) != nil {
+		return false
+	}
+
+	switch id := id.(type) {
+	case nil, string:
+		return true
+
+	case json.Number:
+		f, err := id.Float64()
+		return err == nil && f == math.Trunc(f)
+	}
+
+	return false
+}
+
+var idPattern = regexp.MustCompile(`"id"\s*:\s*("(?:[^"\\]|\\.)*"|-?\d+(?:\.\d+)?(?:[eE][+-]?\d+)?)`)
+
+// recoverId tries to find the id of a request that is not valid JSON. The
+// members before the error are read in order, which is reliable if the id
+// comes before the error. Otherwise, the first "id" member that looks valid is
+// used. nil is returned if an id cannot be found.
+func recoverId(data []byte) interface{} {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	// Only a single request (not a batch) can have its id recovered.
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil
+	}
+
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			break
+		}
+
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			break
+		}
+
+		if key == "id" {
+			return validId(value)
+		}
+	}
+
+	if match := idPattern.FindSubmatch(data); match != nil {
+		var value interface{}
+		if json.NewDecoder(bytes.NewReader(match[1])).Decode(&value) == nil {
+			return validId(value)
+		}
+	}
+
+	return nil
+}
+
+// validId returns the id if it is a string or number, which are the only valid
+// types for an id.
+func validId(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string, float64:
+		return v
+	}
+
+	return nil
+}
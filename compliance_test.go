@@ -0,0 +1,198 @@
+package jsonrpc_test
+
+import (
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimpleServer_SetCompliance(t *testing.T) {
+	t.Run("EmptyBatchIsInvalidByDefault", func(t *testing.T) {
+		server := newTestServer()
+		data := server.HandleMessage([]byte(`[]`), nil)
+
+		assert.Equal(t, `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Batch is empty."}}`, string(data))
+		assert.Equal(t, uint64(1), server.TotalErrorResponses())
+	})
+
+	t.Run("EmptyBatchReturnsEmptyArray", func(t *testing.T) {
+		server := newTestServer()
+		server.SetCompliance(jsonrpc.Compliance{EmptyBatchReturnsEmptyArray: true})
+
+		assert.Equal(t, jsonrpc.Responses{}, server.Handle([]byte(`[]`)))
+		assert.Equal(t, `[]`, string(server.HandleMessage([]byte(` [ ] `), nil)))
+		assert.Equal(t, uint64(0), server.TotalErrorResponses())
+	})
+
+	t.Run("ParseErrorIdIsNullByDefault", func(t *testing.T) {
+		server := newTestServer()
+		data := server.HandleMessage([]byte(`{"jsonrpc":"2.0","id":5,"method":"sum",`), nil)
+
+		assert.Equal(t, `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"Parse error"}}`, string(data))
+	})
+
+	t.Run("ParseErrorEchoesId", func(t *testing.T) {
+		server := newTestServer()
+		server.SetCompliance(jsonrpc.Compliance{ParseErrorEchoesId: true})
+
+		for payload, id := range map[string]interface{}{
+			`{"jsonrpc":"2.0","id":5,"method":"sum",`:            5.0,
+			`{"jsonrpc":"2.0","id":"abc","params":[1,}`:          "abc",
+			`{"jsonrpc" "2.0", "id": "a\"b", "method": "sum"}`: `a"b`,
+			`{"jsonrpc":"2.0","params":{"id":true},`:            nil,
+			`{"jsonrpc":"2.0","id":{"a":1},`:                    nil,
+			`not json`:                                          nil,
+		} {
+			responses := server.Handle([]byte(payload))
+			assert.Equal(t, jsonrpc.ParseError, responses[0].ErrorCode(), payload)
+			assert.Equal(t, id, responses[0].Id(), payload)
+		}
+	})
+
+	t.Run("ParseErrorInBatchIsNotEchoed", func(t *testing.T) {
+		server := newTestServer()
+		server.SetCompliance(jsonrpc.Compliance{ParseErrorEchoesId: true})
+
+		responses := server.Handle([]byte(`[{"jsonrpc":"2.0","id":5,]`))
+		assert.Nil(t, responses[0].Id())
+	})
+
+	t.Run("LenientCompliance", func(t *testing.T) {
+		server := newTestServer()
+		server.SetCompliance(jsonrpc.LenientCompliance)
+
+		assert.Equal(t, `[]`, string(server.HandleMessage([]byte(`[]`), nil)))
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":" SUM ","params":[1,2],"id":1}`))
+		assert.Equal(t, 3.0, responses[0].Result())
+	})
+
+	t.Run("CaseInsensitiveMethods", func(t *testing.T) {
+		server := newTestServer()
+		server.SetHandler("getMethod", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+			return request.NewSuccessResponse(request.Method())
+		})
+		server.SetHandler("device.*.status", wildcardsHandler("status"))
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"getmethod","id":1}`))
+		assert.Equal(t, jsonrpc.MethodNotFound, responses[0].ErrorCode())
+
+		server.SetCompliance(jsonrpc.Compliance{CaseInsensitiveMethods: true})
+
+		// The handler receives the name it was registered with.
+		responses = server.Handle([]byte(`{"jsonrpc":"2.0","method":"GETMETHOD","id":1}`))
+		assert.Equal(t, "getMethod", responses[0].Result())
+
+		responses = server.Handle([]byte(`{"jsonrpc":"2.0","method":"Device.ABC.Status","id":1}`))
+		assert.Equal(t, []string{"ABC"}, responses[0].Result().(map[string]interface{})["wildcards"])
+
+		responses = server.Handle([]byte(`{"jsonrpc":"2.0","method":" getMethod","id":1}`))
+		assert.Equal(t, jsonrpc.MethodNotFound, responses[0].ErrorCode())
+	})
+
+	t.Run("CaseInsensitiveMethodsChanged", func(t *testing.T) {
+		server := jsonrpc.NewSimpleServer()
+		server.SetCompliance(jsonrpc.Compliance{CaseInsensitiveMethods: true})
+		method := func(request jsonrpc.RequestResponder) jsonrpc.Response {
+			return request.NewSuccessResponse(request.Method())
+		}
+		server.SetHandler("getA", method)
+		server.SetHandler("GetA", method)
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"geta","id":1}`))
+		assert.Equal(t, "GetA", responses[0].Result())
+
+		server.RemoveHandler("GetA")
+		responses = server.Handle([]byte(`{"jsonrpc":"2.0","method":"geta","id":1}`))
+		assert.Equal(t, "getA", responses[0].Result())
+
+		server.RemoveHandler("getA")
+		responses = server.Handle([]byte(`{"jsonrpc":"2.0","method":"geta","id":1}`))
+		assert.Equal(t, jsonrpc.MethodNotFound, responses[0].ErrorCode())
+
+		registry := &jsonrpc.MemoryHandlerRegistry{}
+		registry.Set("Hello", method)
+		server.SetHandlerRegistry(registry)
+
+		responses = server.Handle([]byte(`{"jsonrpc":"2.0","method":"HELLO","id":1}`))
+		assert.Equal(t, "Hello", responses[0].Result())
+	})
+
+	t.Run("TrimMethodWhitespace", func(t *testing.T) {
+		server := newTestServer()
+		server.SetCompliance(jsonrpc.Compliance{TrimMethodWhitespace: true})
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"\tsum\n","params":[1,2],"id":1}`))
+		assert.Equal(t, 3.0, responses[0].Result())
+
+		responses = server.Handle([]byte(`{"jsonrpc":"2.0","method":"Sum","params":[1,2],"id":1}`))
+		assert.Equal(t, jsonrpc.MethodNotFound, responses[0].ErrorCode())
+	})
+
+	t.Run("InvalidIdsAreEchoedByDefault", func(t *testing.T) {
+		server := newTestServer()
+		data := server.HandleMessage([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":{"a":1}}`), nil)
+
+		assert.Equal(t, `{"jsonrpc":"2.0","id":{"a":1},"result":3}`, string(data))
+	})
+
+	t.Run("RejectInvalidIds", func(t *testing.T) {
+		server := newTestServer()
+		server.SetCompliance(jsonrpc.Compliance{RejectInvalidIds: true})
+
+		for _, id := range []string{`{"a":1}`, `[1]`, `true`, `1.5`, `-0.25`, `1e-3`} {
+			data := server.HandleMessage([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":`+id+`}`), nil)
+
+			assert.Equal(t, `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Id must be a string, integer or null."}}`, string(data), id)
+		}
+
+		for _, id := range []string{`"abc"`, `5`, `-12`, `1.0`, `1e3`} {
+			responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":` + id + `}`))
+
+			assert.Equal(t, 3.0, responses[0].Result(), id)
+		}
+
+		// A null id is allowed, it is handled like a notification.
+		assert.Equal(t, jsonrpc.Responses{}, server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":null}`)))
+		assert.Equal(t, jsonrpc.Responses{}, server.Handle([]byte(`{"jsonrpc":"2.0","method":"notify_hello","params":[7]}`)))
+	})
+
+	t.Run("RejectInvalidIdsInBatch", func(t *testing.T) {
+		server := newTestServer()
+		server.SetCompliance(jsonrpc.Compliance{RejectInvalidIds: true})
+
+		responses := server.Handle([]byte(`[
+			{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":[1]},
+			{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":2}
+		]`))
+
+		assert.Len(t, responses, 2)
+		assert.Equal(t, jsonrpc.InvalidRequest, responses[0].ErrorCode())
+		assert.Nil(t, responses[0].Id())
+		assert.Equal(t, 3.0, responses[1].Result())
+	})
+
+	t.Run("UnknownMembersAreIgnoredByDefault", func(t *testing.T) {
+		server := newTestServer()
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1,"extra":true}`))
+
+		assert.Equal(t, 3.0, responses[0].Result())
+	})
+
+	t.Run("RejectUnknownMembers", func(t *testing.T) {
+		server := newTestServer()
+		server.SetCompliance(jsonrpc.Compliance{RejectUnknownMembers: true})
+
+		data := server.HandleMessage([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1,"zzz":1,"Id":2}`), nil)
+		assert.Equal(t, `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Unknown member: Id"}}`, string(data))
+
+		// Notifications are rejected too, since the request is not valid.
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"notify_hello","params":[7],"extra":1}`))
+		assert.Equal(t, jsonrpc.InvalidRequest, responses[0].ErrorCode())
+
+		responses = server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+		assert.Equal(t, 3.0, responses[0].Result())
+		assert.Equal(t, jsonrpc.Responses{}, server.Handle([]byte(`{"jsonrpc":"2.0","method":"notify_hello"}`)))
+	})
+}
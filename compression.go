@@ -0,0 +1,167 @@
+package jsonrpc
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// responseSizes tracks the typical size of the responses for each method, as
+// an exponential moving average.
+type responseSizes struct {
+	mutex     sync.Mutex
+	threshold int
+	averages  map[string]float64
+}
+
+// The weight of each new response size in the moving average.
+const responseSizeWeight = 0.2
+
+// SetCompressionThreshold enables gzip compression of HTTP responses, but only
+// for methods whose responses are typically at least threshold bytes.
+// Compressing small responses wastes CPU and can make them larger.
+//
+// The typical size of each method is tracked automatically from the responses
+// sent (see ResponseSize), so the first response for a method is never
+// compressed. A batch is compressed if the average of its methods is over the
+// threshold. Responses are only compressed for clients that send
+// "Accept-Encoding: gzip".
+//
+// Only methods that have a handler are tracked, by the name the handler was
+// registered with (so "user.*" rather than each method it matches). Every
+// response includes "Vary: Accept-Encoding" while compression is enabled.
+//
+// A threshold of zero disables compression, which is the default.
+func (server *SimpleServer) SetCompressionThreshold(threshold int) {
+	sizes := &server.responseSizes
+	sizes.mutex.Lock()
+	defer sizes.mutex.Unlock()
+
+	sizes.threshold = threshold
+	if sizes.averages == nil {
+		sizes.averages = make(map[string]float64)
+	}
+}
+
+// ResponseSize returns the typical size of the encoded responses for a method,
+// in bytes. It is zero if the size is not known yet, or compression is not
+// enabled.
+func (server *SimpleServer) ResponseSize(methodName string) int {
+	sizes := &server.responseSizes
+	sizes.mutex.Lock()
+	defer sizes.mutex.Unlock()
+
+	return int(sizes.averages[methodName])
+}
+
+func (sizes *responseSizes) enabled() bool {
+	sizes.mutex.Lock()
+	defer sizes.mutex.Unlock()
+
+	return sizes.threshold > 0
+}
+
+// shouldCompress reports whether the response to the methods is typically
+// large enough to be compressed.
+func (sizes *responseSizes) shouldCompress(methods []string) bool {
+	sizes.mutex.Lock()
+	defer sizes.mutex.Unlock()
+
+	if sizes.threshold <= 0 || len(methods) == 0 {
+		return false
+	}
+
+	total := 0.0
+	for _, method := range methods {
+		average, ok := sizes.averages[method]
+		if !ok {
+			return false
+		}
+
+		total += average
+	}
+
+	return total/float64(len(methods)) >= float64(sizes.threshold)
+}
+
+// record shares the size of a response between its methods.
+func (sizes *responseSizes) record(methods []string, size int) {
+	sizes.mutex.Lock()
+	defer sizes.mutex.Unlock()
+
+	if sizes.threshold <= 0 || len(methods) == 0 {
+		return
+	}
+
+	share := float64(size) / float64(len(methods))
+	for _, method := range methods {
+		if average, ok := sizes.averages[method]; ok {
+			sizes.averages[method] = average + responseSizeWeight*(share-average)
+		} else {
+			sizes.averages[method] = share
+		}
+	}
+}
+
+// The State key that holds the *payloadMethods of a payload. It is only set
+// while compression is enabled.
+const payloadMethodsKey = "jsonrpc.payloadMethods"
+
+// payloadMethods collects the methods of the requests in a payload that will
+// receive a response, as each request is routed. Each method is the name its
+// handler was registered with, and methods without a handler are left out.
+// This limits the sizes that are tracked to the methods of the server, no
+// matter what clients send.
+type payloadMethods struct {
+	mutex   sync.Mutex
+	methods []string
+}
+
+// recordPayloadMethod adds the handler of a request to the methods of its
+// payload. The requests of a batch may be routed concurrently.
+func recordPayloadMethod(request RequestResponder, handlerName string) {
+	methods, ok := request.State(payloadMethodsKey).(*payloadMethods)
+	if !ok || request.Id() == nil {
+		return
+	}
+
+	methods.mutex.Lock()
+	defer methods.mutex.Unlock()
+
+	methods.methods = append(methods.methods, handlerName)
+}
+
+// writeCompressedHTTPResponse writes the data, compressing it if the methods
+// of the payload typically have large responses and the client accepts gzip.
+func (server *SimpleServer) writeCompressedHTTPResponse(w http.ResponseWriter, r *http.Request, methods []string, data []byte) {
+	if !server.responseSizes.enabled() {
+		writeHTTPResponse(w, data)
+		return
+	}
+
+	// Any response may be compressed for clients that accept it, so caches
+	// must not share an uncompressed response with them (or the reverse).
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	if data == nil {
+		writeHTTPResponse(w, data)
+		return
+	}
+
+	compress := server.responseSizes.shouldCompress(methods) &&
+		strings.Contains(r.Header.Get("Accept-Encoding"), "gzip")
+	server.responseSizes.record(methods, len(data))
+
+	if !compress {
+		writeHTTPResponse(w, data)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Encoding", "gzip")
+
+	writer := gzip.NewWriter(w)
+	writer.Write(data)
+	writer.Close()
+}
@@ -0,0 +1,111 @@
+package jsonrpc_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimpleServer_SetCompressionThreshold(t *testing.T) {
+	server := newTestServer()
+	server.SetHandler("large", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponse(strings.Repeat("a", 1000))
+	})
+	server.SetCompressionThreshold(500)
+
+	post := func(body string) *http.Response {
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		request.Header.Set("Accept-Encoding", "gzip")
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, request)
+
+		return recorder.Result()
+	}
+
+	large := `{"jsonrpc":"2.0","method":"large","id":1}`
+	small := `{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`
+
+	t.Run("FirstResponseIsNotCompressed", func(t *testing.T) {
+		response := post(large)
+		assert.Equal(t, "", response.Header.Get("Content-Encoding"))
+		assert.Greater(t, server.ResponseSize("large"), 1000)
+	})
+
+	t.Run("LargeMethod", func(t *testing.T) {
+		response := post(large)
+		assert.Equal(t, "gzip", response.Header.Get("Content-Encoding"))
+		assert.Equal(t, []string{"Accept-Encoding"}, response.Header.Values("Vary"))
+
+		reader, err := gzip.NewReader(response.Body)
+		assert.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(data, []byte(`{"jsonrpc":"2.0","id":1,"result":"aaa`)))
+	})
+
+	t.Run("SmallMethod", func(t *testing.T) {
+		post(small)
+		response := post(small)
+		assert.Equal(t, "", response.Header.Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", response.Header.Get("Vary"))
+
+		data, _ := io.ReadAll(response.Body)
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":3}`, string(data))
+	})
+
+	t.Run("UnknownMethodsAreNotTracked", func(t *testing.T) {
+		post(`{"jsonrpc":"2.0","method":"random123","id":1}`)
+		post(`[{"jsonrpc":"2.0","method":"random456","id":1},{"jsonrpc":"2.0","method":"sum","params":[1],"id":2}]`)
+
+		assert.Equal(t, 0, server.ResponseSize("random123"))
+		assert.Equal(t, 0, server.ResponseSize("random456"))
+		assert.NotEqual(t, 0, server.ResponseSize("sum"))
+	})
+
+	t.Run("PatternsAreTrackedByTheirName", func(t *testing.T) {
+		server.SetHandler("user.*", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+			return request.NewSuccessResponse(nil)
+		})
+		post(`{"jsonrpc":"2.0","method":"user.a","id":1}`)
+		post(`{"jsonrpc":"2.0","method":"user.b","id":1}`)
+
+		assert.Equal(t, 0, server.ResponseSize("user.a"))
+		assert.NotEqual(t, 0, server.ResponseSize("user.*"))
+	})
+
+	t.Run("ClientDoesNotAcceptGzip", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(large))
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, request)
+
+		assert.Equal(t, "", recorder.Header().Get("Content-Encoding"))
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		server.SetCompressionThreshold(0)
+
+		response := post(large)
+		assert.Equal(t, "", response.Header.Get("Content-Encoding"))
+	})
+}
+
+func TestClient_CompressedResponse(t *testing.T) {
+	server := newTestServer()
+	server.SetCompressionThreshold(1)
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	client := jsonrpc.NewHTTPClient(httpServer.URL)
+	for i := 0; i < 2; i++ {
+		response, err := client.Call("sum", []int{1, 2})
+		assert.NoError(t, err)
+		assert.Equal(t, 3.0, response.Result())
+	}
+}
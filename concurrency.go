@@ -0,0 +1,77 @@
+package jsonrpc
+
+import (
+	"time"
+)
+
+// SetMaxConcurrentRequests limits the number of handlers that can run at the
+// same time. This protects the server (and the resources that handlers use,
+// such as a database) from being overloaded by a burst of requests.
+//
+// When the limit has been reached a request waits for up to timeout for
+// another request to finish. If it is still not able to run (or its context is
+// cancelled) it receives a ServerError with the message "Server is
+// overloaded." A timeout of 0 rejects requests straight away, and a negative
+// timeout waits for as long as it takes. The time spent waiting is included in
+// the queue wait of RequestTimings.
+//
+// A max of 0 (the default) removes the limit. The limit includes middleware,
+// but not raw or notification handlers. It should be set before requests are
+// handled.
+func (server *SimpleServer) SetMaxConcurrentRequests(max int, timeout time.Duration) {
+	if max <= 0 {
+		server.concurrencySlots = nil
+		return
+	}
+
+	server.concurrencySlots = make(chan struct{}, max)
+	server.concurrencyTimeout = timeout
+}
+
+// acquireSlot waits for the request to be allowed to run. If it can run, the
+// request is returned (with the time it started waiting, if it had to wait)
+// along with a function to call when it has finished. Otherwise, the release
+// function is nil.
+func (server *SimpleServer) acquireSlot(request RequestResponder) (RequestResponder, func()) {
+	slots := server.concurrencySlots
+	if slots == nil {
+		return request, func() {}
+	}
+
+	release := func() {
+		<-slots
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return request, release
+	default:
+	}
+
+	if server.concurrencyTimeout == 0 {
+		return request, nil
+	}
+
+	waitingSince := server.now()
+
+	var timeout <-chan time.Time
+	if server.concurrencyTimeout > 0 {
+		timer := server.newTimer(server.concurrencyTimeout)
+		defer timer.Stop()
+		timeout = timer.C()
+	}
+
+	select {
+	case slots <- struct{}{}:
+	case <-timeout:
+		return request, nil
+	case <-RequestContext(request).Done():
+		return request, nil
+	}
+
+	if request.State(queuedAtKey) == nil {
+		request = WithState(request, State{queuedAtKey: waitingSince})
+	}
+
+	return request, release
+}
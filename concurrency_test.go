@@ -0,0 +1,108 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/elliotchance/jsonrpc/jsonrpctest"
+	"github.com/stretchr/testify/assert"
+)
+
+const overloadedResponse = `{"jsonrpc":"2.0","id":2,"error":{"code":-32000,"message":"Server is overloaded."}}`
+
+// blockingServer has a "block" method that does not respond until unblock is
+// called. It returns once the first request is running. The system clock is
+// used if clock is nil.
+func blockingServer(t *testing.T, clock jsonrpc.Clock, max int, timeout time.Duration) (server *jsonrpc.SimpleServer, unblock func(), first chan jsonrpc.Responses) {
+	release := make(chan struct{})
+
+	server = jsonrpc.NewSimpleServer()
+	server.SetClock(clock)
+	server.SetMaxConcurrentRequests(max, timeout)
+	server.SetHandler("block", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		<-release
+
+		return request.NewSuccessResponse(true)
+	})
+
+	first = make(chan jsonrpc.Responses, 1)
+	go func() {
+		first <- server.Handle([]byte(`{"jsonrpc":"2.0","method":"block","id":1}`))
+	}()
+
+	assert.Eventually(t, func() bool {
+		return server.CurrentActiveRequests() == 1
+	}, time.Second, time.Millisecond)
+
+	return server, func() { close(release) }, first
+}
+
+func TestSimpleServer_SetMaxConcurrentRequests(t *testing.T) {
+	second := []byte(`{"jsonrpc":"2.0","method":"block","id":2}`)
+
+	t.Run("Reject", func(t *testing.T) {
+		server, unblock, first := blockingServer(t, nil, 1, 0)
+
+		assert.Equal(t, overloadedResponse, server.Handle(second)[0].String())
+
+		unblock()
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":true}`, (<-first)[0].String())
+
+		// There is a free slot again.
+		assert.Equal(t, `{"jsonrpc":"2.0","id":2,"result":true}`, server.Handle(second)[0].String())
+	})
+
+	t.Run("Wait", func(t *testing.T) {
+		server, unblock, first := blockingServer(t, nil, 1, time.Minute)
+
+		time.AfterFunc(20*time.Millisecond, unblock)
+		assert.Equal(t, `{"jsonrpc":"2.0","id":2,"result":true}`, server.Handle(second)[0].String())
+		<-first
+
+		timings := server.RequestTimings()
+		assert.Equal(t, uint64(1), timings.Queued)
+		assert.True(t, timings.MaxQueueWait > 0)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		clock := jsonrpctest.NewClock(time.Unix(1000, 0))
+		server, unblock, _ := blockingServer(t, clock, 1, 10*time.Millisecond)
+		defer unblock()
+
+		responses := make(chan jsonrpc.Responses, 1)
+		go func() {
+			responses <- server.Handle(second)
+		}()
+
+		assert.Eventually(t, func() bool {
+			return clock.Timers() == 1
+		}, time.Second, time.Millisecond)
+		clock.Advance(10 * time.Millisecond)
+
+		assert.Equal(t, overloadedResponse, (<-responses)[0].String())
+	})
+
+	t.Run("ContextCancelled", func(t *testing.T) {
+		server, unblock, _ := blockingServer(t, nil, 1, -1)
+		defer unblock()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		assert.Equal(t, overloadedResponse, server.HandleContext(ctx, second)[0].String())
+	})
+
+	t.Run("Unlimited", func(t *testing.T) {
+		server, unblock, _ := blockingServer(t, nil, 1, 0)
+		defer unblock()
+
+		server.SetMaxConcurrentRequests(0, 0)
+		go server.Handle(second)
+
+		assert.Eventually(t, func() bool {
+			return server.CurrentActiveRequests() == 2
+		}, time.Second, time.Millisecond)
+	})
+}
@@ -0,0 +1,138 @@
+package conformance
+
+// SpecCases are the examples from the JSON-RPC 2.0 specification:
+// http://www.jsonrpc.org/specification#examples
+var SpecCases = []Case{
+	{
+		Name:     "rpc call with positional parameters 1",
+		Payload:  `{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}`,
+		Expected: `{"jsonrpc": "2.0", "result": 19, "id": 1}`,
+	},
+	{
+		Name:     "rpc call with positional parameters 2",
+		Payload:  `{"jsonrpc": "2.0", "method": "subtract", "params": [23, 42], "id": 2}`,
+		Expected: `{"jsonrpc": "2.0", "result": -19, "id": 2}`,
+	},
+	{
+		Name:     "rpc call with named parameters 1",
+		Payload:  `{"jsonrpc": "2.0", "method": "subtract", "params": {"subtrahend": 23, "minuend": 42}, "id": 3}`,
+		Expected: `{"jsonrpc": "2.0", "result": 19, "id": 3}`,
+	},
+	{
+		Name:     "rpc call with named parameters 2",
+		Payload:  `{"jsonrpc": "2.0", "method": "subtract", "params": {"minuend": 42, "subtrahend": 23}, "id": 4}`,
+		Expected: `{"jsonrpc": "2.0", "result": 19, "id": 4}`,
+	},
+	{
+		Name:    "a notification 1",
+		Payload: `{"jsonrpc": "2.0", "method": "update", "params": [1,2,3,4,5]}`,
+	},
+	{
+		Name:    "a notification 2",
+		Payload: `{"jsonrpc": "2.0", "method": "foobar"}`,
+	},
+	{
+		Name:     "rpc call of non-existent method",
+		Payload:  `{"jsonrpc": "2.0", "method": "foobar", "id": "1"}`,
+		Expected: `{"jsonrpc": "2.0", "error": {"code": -32601, "message": "Method not found"}, "id": "1"}`,
+	},
+	{
+		Name:     "rpc call with invalid JSON",
+		Payload:  `{"jsonrpc": "2.0", "method": "foobar, "params": "bar", "baz]`,
+		Expected: `{"jsonrpc": "2.0", "error": {"code": -32700, "message": "Parse error"}, "id": null}`,
+	},
+	{
+		Name:     "rpc call with invalid Request object",
+		Payload:  `{"jsonrpc": "2.0", "method": 1, "params": "bar"}`,
+		Expected: `{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null}`,
+	},
+	{
+		Name: "rpc call Batch, invalid JSON",
+		Payload: `[
+			{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": "1"},
+			{"jsonrpc": "2.0", "method"
+		]`,
+		Expected: `{"jsonrpc": "2.0", "error": {"code": -32700, "message": "Parse error"}, "id": null}`,
+	},
+	{
+		Name:     "rpc call with an empty Array",
+		Payload:  `[]`,
+		Expected: `{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null}`,
+	},
+	{
+		Name:     "rpc call with an invalid Batch (but not empty)",
+		Payload:  `[1]`,
+		Expected: `[{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null}]`,
+	},
+	{
+		Name:    "rpc call with invalid Batch",
+		Payload: `[1,2,3]`,
+		Expected: `[
+			{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null},
+			{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null},
+			{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null}
+		]`,
+	},
+	{
+		Name: "rpc call Batch",
+		Payload: `[
+			{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": "1"},
+			{"jsonrpc": "2.0", "method": "notify_hello", "params": [7]},
+			{"jsonrpc": "2.0", "method": "subtract", "params": [42,23], "id": "2"},
+			{"foo": "boo"},
+			{"jsonrpc": "2.0", "method": "foo.get", "params": {"name": "myself"}, "id": "5"},
+			{"jsonrpc": "2.0", "method": "get_data", "id": "9"}
+		]`,
+		Expected: `[
+			{"jsonrpc": "2.0", "result": 7, "id": "1"},
+			{"jsonrpc": "2.0", "result": 19, "id": "2"},
+			{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null},
+			{"jsonrpc": "2.0", "error": {"code": -32601, "message": "Method not found"}, "id": "5"},
+			{"jsonrpc": "2.0", "result": ["hello", 5], "id": "9"}
+		]`,
+	},
+	{
+		Name: "rpc call Batch (all notifications)",
+		Payload: `[
+			{"jsonrpc": "2.0", "method": "notify_sum", "params": [1,2,4]},
+			{"jsonrpc": "2.0", "method": "notify_hello", "params": [7]}
+		]`,
+	},
+}
+
+// EdgeCases are extra cases that are not covered by the examples in the
+// specification.
+var EdgeCases = []Case{
+	{
+		Name:     "wrong version",
+		Payload:  `{"jsonrpc": "2", "method": "subtract", "params": [42, 23], "id": 2}`,
+		Expected: `{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": 2}`,
+	},
+	{
+		Name:     "bad version type",
+		Payload:  `{"jsonrpc": true, "method": "subtract", "params": [42, 23], "id": 2}`,
+		Expected: `{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": 2}`,
+	},
+	{
+		Name:     "missing version",
+		Payload:  `{"method": "subtract", "params": [42, 23], "id": 2}`,
+		Expected: `{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": 2}`,
+	},
+	{
+		Name:    "non-existent method as notification",
+		Payload: `{"jsonrpc": "2.0", "method": "foobar"}`,
+	},
+	{
+		Name:     "batch with a single request",
+		Payload:  `[{"jsonrpc": "2.0", "method": "sum", "params": [1,2], "id": 1}]`,
+		Expected: `[{"jsonrpc": "2.0", "result": 3, "id": 1}]`,
+	},
+	{
+		Name:     "string id",
+		Payload:  `{"jsonrpc": "2.0", "method": "sum", "params": [1,2], "id": "abc"}`,
+		Expected: `{"jsonrpc": "2.0", "result": 3, "id": "abc"}`,
+	},
+}
+
+// AllCases contains SpecCases and EdgeCases.
+var AllCases = append(append([]Case{}, SpecCases...), EdgeCases...)
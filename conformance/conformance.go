@@ -0,0 +1,195 @@
+// Package conformance checks that a JSON-RPC 2.0 server behaves the same as
+// this package. It can be used to find divergences in other implementations
+// that a client written against this package may need to talk to.
+//
+// The target server must provide the methods used in the examples of the
+// JSON-RPC 2.0 specification:
+//
+//     subtract     - [minuend, subtrahend] or {"minuend", "subtrahend"}
+//     sum          - sum of all the positional params
+//     notify_hello - any result
+//     update       - any result
+//     get_data     - ["hello", 5]
+//
+// See Run and cmd/jsonrpc-conformance.
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"reflect"
+)
+
+// Target sends a raw payload to the server under test and returns the raw
+// response. An empty response (or nil) means nothing was sent back.
+type Target func(payload []byte) ([]byte, error)
+
+// Case is a single payload and the expected response.
+type Case struct {
+	Name    string
+	Payload string
+
+	// Expected is the response that should be sent back. An empty string means
+	// that nothing should be sent back.
+	//
+	// Error messages are not compared since they are implementation specific.
+	// The order of batch responses is also not compared.
+	Expected string
+}
+
+// Divergence describes a Case where the target did not respond as expected.
+type Divergence struct {
+	Case   Case
+	Actual string
+	Reason string
+}
+
+func (divergence Divergence) String() string {
+	return fmt.Sprintf("%s: %s\n  payload:  %s\n  expected: %s\n  actual:   %s",
+		divergence.Case.Name, divergence.Reason, divergence.Case.Payload,
+		divergence.Case.Expected, divergence.Actual)
+}
+
+// Run sends every case to the target and returns all of the divergences. An
+// error from the target is reported as a divergence for that case.
+func Run(target Target, cases []Case) []Divergence {
+	var divergences []Divergence
+
+	for _, c := range cases {
+		actual, err := target([]byte(c.Payload))
+		if err != nil {
+			divergences = append(divergences, Divergence{c, "", err.Error()})
+			continue
+		}
+
+		if reason := compare(c.Expected, string(bytes.TrimSpace(actual))); reason != "" {
+			divergences = append(divergences, Divergence{c, string(actual), reason})
+		}
+	}
+
+	return divergences
+}
+
+// HTTPTarget sends each payload as the body of a POST request to url.
+func HTTPTarget(url string) Target {
+	return func(payload []byte) ([]byte, error) {
+		response, err := http.Post(url, "application/json", bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		defer response.Body.Close()
+
+		return io.ReadAll(response.Body)
+	}
+}
+
+// CommandTarget starts the command for each payload. The payload is written
+// to stdin (followed by a new line), then stdin is closed and the response is
+// read from stdout until the command exits.
+func CommandTarget(name string, args ...string) Target {
+	return func(payload []byte) ([]byte, error) {
+		cmd := exec.Command(name, args...)
+		cmd.Stdin = bytes.NewReader(append(payload, '\n'))
+
+		return cmd.Output()
+	}
+}
+
+func compare(expected, actual string) string {
+	if expected == "" {
+		if actual != "" {
+			return "expected no response"
+		}
+
+		return ""
+	}
+
+	if actual == "" {
+		return "expected a response"
+	}
+
+	var e, a interface{}
+	if err := json.Unmarshal([]byte(expected), &e); err != nil {
+		return "invalid expected JSON: " + err.Error()
+	}
+
+	if err := json.Unmarshal([]byte(actual), &a); err != nil {
+		return "response is not valid JSON: " + err.Error()
+	}
+
+	switch e := e.(type) {
+	case []interface{}:
+		a, ok := a.([]interface{})
+		if !ok {
+			return "expected a batch response"
+		}
+
+		if len(a) != len(e) {
+			return fmt.Sprintf("expected %d responses but got %d", len(e), len(a))
+		}
+
+		// Each expected response must match a different actual response.
+		used := make([]bool, len(a))
+	expectedLoop:
+		for _, expectedResponse := range e {
+			for i, actualResponse := range a {
+				if !used[i] && compareResponse(expectedResponse, actualResponse) == "" {
+					used[i] = true
+					continue expectedLoop
+				}
+			}
+
+			return fmt.Sprintf("no response matches %v", expectedResponse)
+		}
+
+		return ""
+
+	default:
+		if _, ok := a.([]interface{}); ok {
+			return "expected a single response"
+		}
+
+		return compareResponse(e, a)
+	}
+}
+
+func compareResponse(expected, actual interface{}) string {
+	e, _ := expected.(map[string]interface{})
+	a, ok := actual.(map[string]interface{})
+	if !ok {
+		return "response is not an object"
+	}
+
+	if a["jsonrpc"] != "2.0" {
+		return `"jsonrpc" must be "2.0"`
+	}
+
+	if !reflect.DeepEqual(e["id"], a["id"]) {
+		return fmt.Sprintf("expected id %v but got %v", e["id"], a["id"])
+	}
+
+	if result, ok := e["result"]; ok {
+		if !reflect.DeepEqual(result, a["result"]) {
+			return fmt.Sprintf("expected result %v but got %v", result, a["result"])
+		}
+
+		return ""
+	}
+
+	expectedError, _ := e["error"].(map[string]interface{})
+	actualError, ok := a["error"].(map[string]interface{})
+	if !ok {
+		return "expected an error"
+	}
+
+	if expectedError["code"] != actualError["code"] {
+		return fmt.Sprintf("expected error code %v but got %v",
+			expectedError["code"], actualError["code"])
+	}
+
+	return ""
+}
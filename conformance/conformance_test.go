@@ -0,0 +1,95 @@
+package conformance_test
+
+import (
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/elliotchance/jsonrpc/conformance"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSpecServer() *jsonrpc.SimpleServer {
+	server := jsonrpc.NewSimpleServer()
+
+	server.SetHandler("subtract", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		switch p := request.Params().(type) {
+		case []interface{}:
+			return request.NewSuccessResponse(p[0].(float64) - p[1].(float64))
+		case map[string]interface{}:
+			return request.NewSuccessResponse(p["minuend"].(float64) - p["subtrahend"].(float64))
+		}
+
+		return request.NewErrorResponse(jsonrpc.InvalidParams, "")
+	})
+	server.SetHandler("sum", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		total := 0.0
+		for _, x := range request.Params().([]interface{}) {
+			total += x.(float64)
+		}
+
+		return request.NewSuccessResponse(total)
+	})
+	server.SetHandler("get_data", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponse([]interface{}{"hello", 5})
+	})
+
+	return server
+}
+
+func serverTarget(server *jsonrpc.SimpleServer) conformance.Target {
+	return func(payload []byte) ([]byte, error) {
+		return server.HandleMessage(payload, nil), nil
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Run("SimpleServerConforms", func(t *testing.T) {
+		divergences := conformance.Run(serverTarget(newSpecServer()), conformance.AllCases)
+
+		assert.Empty(t, divergences)
+	})
+
+	t.Run("Divergences", func(t *testing.T) {
+		target := func(payload []byte) ([]byte, error) {
+			return []byte(`{"jsonrpc": "2.0", "result": 20, "id": 1}`), nil
+		}
+		divergences := conformance.Run(target, []conformance.Case{
+			{
+				Name:     "wrong result",
+				Payload:  `{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}`,
+				Expected: `{"jsonrpc": "2.0", "result": 19, "id": 1}`,
+			},
+			{
+				Name:     "wrong id",
+				Payload:  `{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 2}`,
+				Expected: `{"jsonrpc": "2.0", "result": 20, "id": 2}`,
+			},
+			{
+				Name:     "not a batch",
+				Payload:  `[{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}]`,
+				Expected: `[{"jsonrpc": "2.0", "result": 20, "id": 1}]`,
+			},
+			{
+				Name:    "notification",
+				Payload: `{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23]}`,
+			},
+			{
+				Name:     "ok",
+				Payload:  `{"jsonrpc": "2.0", "method": "subtract", "params": [42, 22], "id": 1}`,
+				Expected: `{"jsonrpc": "2.0", "result": 20, "id": 1}`,
+			},
+		})
+
+		reasons := map[string]string{}
+		for _, divergence := range divergences {
+			reasons[divergence.Case.Name] = divergence.Reason
+		}
+
+		assert.Equal(t, map[string]string{
+			"wrong result": "expected result 19 but got 20",
+			"wrong id":     "expected id 2 but got 1",
+			"not a batch":  "expected a batch response",
+			"notification": "expected no response",
+		}, reasons)
+	})
+}
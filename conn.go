@@ -0,0 +1,177 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"crypto/tls"
+	"io"
+	"net"
+	"os"
+)
+
+// ServeConn handles JSON-RPC payloads from a connection until it is closed or
+// reaches EOF.
+//
+// By default each payload (a single request or a batch) must be on its own
+// line, and the responses for each payload are written back as a single line.
+// See SetFraming for other options. Payloads that only contain notifications do
+// not write anything back.
+//
+// The connection can be compressed if codecs have been set with
+// SetCompressionCodecs.
+//
+// Payloads are processed in the order they are received on the connection. See
+// SetOrderedConnections to also process the requests of a batch in order.
+func (server *SimpleServer) ServeConn(conn io.ReadWriter) error {
+	return server.serveConn(conn, connTransport(conn))
+}
+
+func (server *SimpleServer) serveConn(conn io.ReadWriter, transport string) error {
+	framing := server.getFraming()
+	reader := bufio.NewReader(conn)
+	var writer io.Writer = conn
+	state := server.connState(transport)
+	first := true
+
+	for {
+		payload, err := framing.ReadPayload(reader)
+
+		if len(payload) > 0 && first {
+			first = false
+
+			if codec, data, ok := server.negotiateCompression(payload); ok {
+				if writeErr := framing.WritePayload(conn, data); writeErr != nil {
+					return writeErr
+				}
+
+				if codec != nil {
+					var codecErr error
+					reader, writer, codecErr = compressStream(codec, reader, conn)
+					if codecErr != nil {
+						return codecErr
+					}
+				}
+
+				payload = nil
+			}
+		}
+
+		if len(payload) > 0 {
+			if data := server.HandleMessage(payload, state); data != nil {
+				if writeErr := writePayload(framing, writer, data); writeErr != nil {
+					return writeErr
+				}
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ServeStdio serves JSON-RPC over a pair of streams, usually os.Stdin and
+// os.Stdout. This allows the server to run as a child process of another
+// program, such as an editor plugin.
+//
+// ServeStdio returns nil once it reaches EOF.
+func (server *SimpleServer) ServeStdio(in io.Reader, out io.Writer) error {
+	return server.serveConn(struct {
+		io.Reader
+		io.Writer
+	}{in, out}, TransportStdio)
+}
+
+// SetFraming changes how payloads are separated on streams for ServeConn and
+// all of the transports that use it. The default is NewlineFraming.
+func (server *SimpleServer) SetFraming(framing Framing) {
+	server.framing = framing
+}
+
+func (server *SimpleServer) getFraming() Framing {
+	if server.framing == nil {
+		return NewlineFraming
+	}
+
+	return server.framing
+}
+
+// Serve accepts connections on the listener and serves each one with
+// ServeConn in its own goroutine. It will only return when the listener fails
+// to accept a connection, such as when it is closed.
+func (server *SimpleServer) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+
+		go func(conn net.Conn) {
+			defer conn.Close()
+			server.ServeConn(conn)
+		}(conn)
+	}
+}
+
+// ListenAndServeTCP listens on the TCP address (like "localhost:8080") and
+// serves JSON-RPC on each connection. See ServeConn for details on framing.
+func (server *SimpleServer) ListenAndServeTCP(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	return server.Serve(listener)
+}
+
+// ListenAndServeTCPTLS is the same as ListenAndServeTCP except that each
+// connection is secured with TLS. The config must contain at least one
+// certificate.
+//
+// Clients can be required to provide a certificate by setting ClientAuth (and
+// ClientCAs) on the config:
+//
+//     config.ClientAuth = tls.RequireAndVerifyClientCert
+//
+func (server *SimpleServer) ListenAndServeTCPTLS(addr string, config *tls.Config) error {
+	listener, err := tls.Listen("tcp", addr, config)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	return server.Serve(listener)
+}
+
+// ListenAndServeUnix listens on a unix domain socket at path and serves
+// JSON-RPC on each connection. This is the preferred way to expose a local
+// daemon without opening a TCP port.
+//
+// perm controls which local users are able to connect. On unix systems the
+// socket is created with a umask that does not allow more than perm, so it is
+// never connectable with wider permissions. perm is then applied to the socket
+// file exactly. Any existing socket file at path is removed first so that a
+// server can be restarted after a crash.
+func (server *SimpleServer) ListenAndServeUnix(path string, perm os.FileMode) error {
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	listener, err := listenUnix(path, perm)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	if err := os.Chmod(path, perm); err != nil {
+		return err
+	}
+
+	return server.Serve(listener)
+}
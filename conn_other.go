@@ -0,0 +1,14 @@
+//go:build !unix
+
+package jsonrpc
+
+import (
+	"net"
+	"os"
+)
+
+// listenUnix creates the socket. The umask cannot be changed on this platform,
+// so the permissions are only applied once it has been created.
+func listenUnix(path string, perm os.FileMode) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,215 @@
+package jsonrpc_test
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type bufferConn struct {
+	*strings.Reader
+	*bytes.Buffer
+}
+
+func (conn bufferConn) Read(p []byte) (int, error) {
+	return conn.Reader.Read(p)
+}
+
+func (conn bufferConn) Write(p []byte) (int, error) {
+	return conn.Buffer.Write(p)
+}
+
+func newBufferConn(input string) bufferConn {
+	return bufferConn{strings.NewReader(input), new(bytes.Buffer)}
+}
+
+func TestSimpleServer_ServeConn(t *testing.T) {
+	t.Run("Single", func(t *testing.T) {
+		conn := newBufferConn(`{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}` + "\n")
+		err := newTestServer().ServeConn(conn)
+
+		assert.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":7}`+"\n", conn.Buffer.String())
+	})
+
+	t.Run("MissingTrailingNewline", func(t *testing.T) {
+		conn := newBufferConn(`{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}`)
+		err := newTestServer().ServeConn(conn)
+
+		assert.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":7}`+"\n", conn.Buffer.String())
+	})
+
+	t.Run("Multiple", func(t *testing.T) {
+		conn := newBufferConn(
+			`{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}` + "\n\n" +
+				`{"jsonrpc": "2.0", "method": "sum", "params": [3]}` + "\n" +
+				`{"jsonrpc": "2.0", "method": "sum", "params": [1,2], "id": 2}` + "\n")
+		err := newTestServer().ServeConn(conn)
+
+		assert.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":7}`+"\n"+
+			`{"jsonrpc":"2.0","id":2,"result":3}`+"\n", conn.Buffer.String())
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		conn := newBufferConn(`[{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}]` + "\n")
+		err := newTestServer().ServeConn(conn)
+
+		assert.NoError(t, err)
+		assert.Equal(t, `[{"jsonrpc":"2.0","id":1,"result":7}]`+"\n", conn.Buffer.String())
+	})
+
+	t.Run("EmptyBatch", func(t *testing.T) {
+		conn := newBufferConn("[]\n")
+		err := newTestServer().ServeConn(conn)
+
+		assert.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Batch is empty."}}`+"\n", conn.Buffer.String())
+	})
+}
+
+func TestSimpleServer_ListenAndServeUnix(t *testing.T) {
+	dir, err := os.MkdirTemp("", "jsonrpc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "rpc.sock")
+	go newTestServer().ListenAndServeUnix(path, 0600)
+
+	var conn net.Conn
+	for i := 0; i < 100; i += 1 {
+		conn, err = net.Dial("unix", path)
+		if err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	_, err = conn.Write([]byte(`{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}` + "\n"))
+	assert.NoError(t, err)
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":7}`+"\n", line)
+}
+
+func TestSimpleServer_ServeStdio(t *testing.T) {
+	in := strings.NewReader(
+		`{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}` + "\n" +
+			`{"jsonrpc": "2.0", "method": "sum", "params": [1,2], "id": 2}`)
+	out := new(bytes.Buffer)
+	err := newTestServer().ServeStdio(in, out)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":7}`+"\n"+
+		`{"jsonrpc":"2.0","id":2,"result":3}`+"\n", out.String())
+}
+
+// newTestCertificate creates a self-signed certificate for 127.0.0.1 that can
+// be used by servers and clients.
+func newTestCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	certificate, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(certificate)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
+
+// freeAddr returns a local TCP address that is not in use.
+func freeAddr(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	return listener.Addr().String()
+}
+
+func dialTLS(addr string, config *tls.Config) (conn *tls.Conn, err error) {
+	for i := 0; i < 100; i += 1 {
+		conn, err = tls.Dial("tcp", addr, config)
+		if err == nil {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return
+}
+
+func TestSimpleServer_ListenAndServeTCPTLS(t *testing.T) {
+	certificate, pool := newTestCertificate(t)
+	addr := freeAddr(t)
+	go newTestServer().ListenAndServeTCPTLS(addr, &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	})
+
+	t.Run("WithClientCertificate", func(t *testing.T) {
+		conn, err := dialTLS(addr, &tls.Config{
+			Certificates: []tls.Certificate{certificate},
+			RootCAs:      pool,
+		})
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		_, err = conn.Write([]byte(`{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}` + "\n"))
+		assert.NoError(t, err)
+
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		assert.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":7}`+"\n", line)
+	})
+
+	t.Run("WithoutClientCertificate", func(t *testing.T) {
+		conn, err := dialTLS(addr, &tls.Config{RootCAs: pool})
+		if err == nil {
+			defer conn.Close()
+			conn.Write([]byte(`{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}` + "\n"))
+			_, err = bufio.NewReader(conn).ReadString('\n')
+		}
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,30 @@
+//go:build unix
+
+package jsonrpc
+
+import (
+	"net"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// umaskMutex stops sockets being created at the same time, because the umask
+// is shared by the whole process.
+var umaskMutex sync.Mutex
+
+// listenUnix creates the socket with a umask that removes any permissions that
+// are not in perm, so that it is never connectable by other users before the
+// permissions are set. The umask is only ever made more restrictive, so other
+// files created at the same time are not exposed either.
+func listenUnix(path string, perm os.FileMode) (net.Listener, error) {
+	umaskMutex.Lock()
+	defer umaskMutex.Unlock()
+
+	umask := syscall.Umask(0777)
+	defer syscall.Umask(umask)
+
+	syscall.Umask(umask | int(^perm.Perm()&os.ModePerm))
+
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,72 @@
+package jsonrpc
+
+import (
+	"context"
+)
+
+// The State key that holds the context.Context of a request.
+const contextKey = "jsonrpc.context"
+
+type requestContextKey struct{}
+
+// ContextHandler is a handler that also receives the context of the request.
+// See SetContextHandler.
+type ContextHandler func(ctx context.Context, request RequestResponder) Response
+
+// SetContextHandler will register (or replace) a handler that receives the
+// context of the request. The context carries the deadline and cancellation of
+// the caller (such as the HTTP request), so it should be passed to databases,
+// HTTP clients and tracing:
+//
+//     server.SetContextHandler("getUser", func(ctx context.Context, request jsonrpc.RequestResponder) jsonrpc.Response {
+//         row := db.QueryRowContext(ctx, "SELECT name FROM users WHERE id = ?", request.Params())
+//         // ...
+//     })
+//
+// The context is context.Background() if the request was not handled with a
+// context. See HandleContext. The options are the same as for SetHandler.
+func (server *SimpleServer) SetContextHandler(methodName string, handler ContextHandler, options ...HandlerOption) {
+	server.SetHandler(methodName, func(request RequestResponder) Response {
+		return handler(RequestContext(request), request)
+	}, options...)
+}
+
+// HandleContext is the same as Handle, except that handlers receive ctx. See
+// SetContextHandler.
+func (server *SimpleServer) HandleContext(ctx context.Context, jsonRequest []byte) Responses {
+	return server.HandleWithState(jsonRequest, State{contextKey: ctx})
+}
+
+// RequestContext returns the context of a request. The State of the request
+// can be read from the context with ContextState.
+func RequestContext(request Request) context.Context {
+	ctx, ok := request.State(contextKey).(context.Context)
+	if !ok {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, requestContextKey{}, request)
+}
+
+// ContextState returns the State value for key of the request that the context
+// belongs to. It is nil if the context was not created by RequestContext.
+func ContextState(ctx context.Context, key string) interface{} {
+	request, ok := ctx.Value(requestContextKey{}).(Request)
+	if !ok {
+		return nil
+	}
+
+	return request.State(key)
+}
+
+// detachRequest returns the request with a context that keeps the values of
+// its context, but is not cancelled when the request is finished. It must be
+// used for work that continues in the background after the response is sent.
+func detachRequest(request RequestResponder) RequestResponder {
+	ctx, ok := request.State(contextKey).(context.Context)
+	if !ok {
+		return request
+	}
+
+	return WithState(request, State{contextKey: context.WithoutCancel(ctx)})
+}
@@ -0,0 +1,114 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+type ctxKey struct{}
+
+func TestSimpleServer_SetContextHandler(t *testing.T) {
+	server := jsonrpc.NewSimpleServer()
+	server.SetContextHandler("value", func(ctx context.Context, request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponse(ctx.Value(ctxKey{}))
+	})
+	server.SetContextHandler("state", func(ctx context.Context, request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponse(jsonrpc.ContextState(ctx, "user"))
+	})
+	server.SetContextHandler("wait", func(ctx context.Context, request jsonrpc.RequestResponder) jsonrpc.Response {
+		<-ctx.Done()
+
+		return request.NewServerErrorResponse(ctx.Err())
+	})
+
+	t.Run("HandleContext", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), ctxKey{}, "foo")
+		responses := server.HandleContext(ctx, []byte(`{"jsonrpc":"2.0","method":"value","id":1}`))
+
+		assert.Equal(t, "foo", responses[0].Result())
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), ctxKey{}, "foo")
+		responses := server.HandleContext(ctx, []byte(`[{"jsonrpc":"2.0","method":"value","id":1},{"jsonrpc":"2.0","method":"value","id":2}]`))
+
+		assert.Equal(t, "foo", responses[0].Result())
+		assert.Equal(t, "foo", responses[1].Result())
+	})
+
+	t.Run("WithoutContext", func(t *testing.T) {
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"value","id":1}`))
+
+		assert.Equal(t, jsonrpc.Success, responses[0].ErrorCode())
+		assert.Nil(t, responses[0].Result())
+	})
+
+	t.Run("Deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		responses := server.HandleContext(ctx, []byte(`{"jsonrpc":"2.0","method":"wait","id":1}`))
+		assert.Equal(t, "context deadline exceeded", responses[0].ErrorMessage())
+	})
+
+	t.Run("State", func(t *testing.T) {
+		responses := server.HandleWithState([]byte(`{"jsonrpc":"2.0","method":"state","id":1}`),
+			jsonrpc.State{"user": "bob"})
+
+		assert.Equal(t, "bob", responses[0].Result())
+	})
+
+	t.Run("MaxDuration", func(t *testing.T) {
+		server.SetLimits("wait", jsonrpc.Limits{MaxDuration: 10 * time.Millisecond})
+		defer server.SetLimits("wait", jsonrpc.Limits{})
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"wait","id":1}`))
+		assert.Equal(t, "Timeout exceeded.", responses[0].ErrorMessage())
+	})
+
+	t.Run("HTTP", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		request := httptest.NewRequest("POST", "/", strings.NewReader(`{"jsonrpc":"2.0","method":"wait","id":1}`))
+		request = request.WithContext(ctx)
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, request)
+
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, "context deadline exceeded",
+			response["error"].(map[string]interface{})["message"])
+	})
+}
+
+func TestContextState(t *testing.T) {
+	assert.Nil(t, jsonrpc.ContextState(context.Background(), "user"))
+}
+
+func TestRequestContext(t *testing.T) {
+	request := jsonrpc.NewRequestResponder("2.0", 1, "foo", nil)
+
+	assert.NotNil(t, jsonrpc.RequestContext(request))
+}
+
+func TestRawHandlerContext(t *testing.T) {
+	server := jsonrpc.NewSimpleServer()
+	server.SetRawHandler("value", func(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, *jsonrpc.RPCError) {
+		value, _ := json.Marshal(ctx.Value(ctxKey{}))
+
+		return value, nil
+	})
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "foo")
+	responses := server.HandleContext(ctx, []byte(`{"jsonrpc":"2.0","method":"value","id":1}`))
+
+	assert.Equal(t, `[{"jsonrpc":"2.0","id":1,"result":"foo"}]`, responses.String())
+}
@@ -0,0 +1,99 @@
+package jsonrpc
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS configures Cross-Origin Resource Sharing for the HTTP transport so that
+// browsers can call the server directly. See SetCORS.
+type CORS struct {
+	// AllowedOrigins is the list of origins that may make requests, such as
+	// "https://example.com". "*" allows any origin.
+	AllowedOrigins []string
+
+	// AllowedMethods defaults to POST (and GET if SetHTTPGet is enabled).
+	AllowedMethods []string
+
+	// AllowedHeaders defaults to Content-Type.
+	AllowedHeaders []string
+
+	// AllowCredentials allows cookies and HTTP authentication to be sent with
+	// requests. The response will contain the actual origin rather than "*".
+	AllowCredentials bool
+
+	// MaxAge is how long the browser may cache the result of a preflight
+	// request. Zero will not send a max age.
+	MaxAge time.Duration
+}
+
+// SetCORS enables CORS headers (and preflight requests) for ServeHTTP. A nil
+// value disables CORS, which is the default.
+func (server *SimpleServer) SetCORS(cors *CORS) {
+	server.cors = cors
+}
+
+func (cors *CORS) allowsOrigin(origin string) bool {
+	for _, allowed := range cors.AllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+
+	return false
+}
+
+// applyCORS adds the CORS headers to the response. true is returned if this
+// was a preflight request that has been responded to.
+func (server *SimpleServer) applyCORS(w http.ResponseWriter, r *http.Request) bool {
+	cors := server.cors
+	origin := r.Header.Get("Origin")
+	if cors == nil || origin == "" || !cors.allowsOrigin(origin) {
+		return false
+	}
+
+	header := w.Header()
+	header.Add("Vary", "Origin")
+
+	if cors.AllowCredentials {
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Set("Access-Control-Allow-Credentials", "true")
+	} else if cors.allowsOrigin("*") {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+	}
+
+	isPreflight := r.Method == http.MethodOptions &&
+		r.Header.Get("Access-Control-Request-Method") != ""
+	if !isPreflight {
+		return false
+	}
+
+	methods := cors.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{http.MethodPost}
+		if server.httpGet {
+			methods = append(methods, http.MethodGet)
+		}
+	}
+
+	headers := cors.AllowedHeaders
+	if len(headers) == 0 {
+		headers = []string{"Content-Type"}
+	}
+
+	header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	header.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+
+	if cors.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age",
+			strconv.Itoa(int(cors.MaxAge/time.Second)))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return true
+}
@@ -0,0 +1,95 @@
+package jsonrpc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimpleServer_SetCORS(t *testing.T) {
+	preflight := func(server *jsonrpc.SimpleServer, origin string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodOptions, "/", nil)
+		r.Header.Set("Origin", origin)
+		r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, r)
+
+		return w
+	}
+
+	post := func(server *jsonrpc.SimpleServer, origin string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/",
+			strings.NewReader(`{"jsonrpc": "2.0", "method": "sum", "params": [1,2], "id": 1}`))
+		r.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, r)
+
+		return w
+	}
+
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		w := preflight(newTestServer(), "https://example.com")
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("Preflight", func(t *testing.T) {
+		server := newTestServer()
+		server.SetCORS(&jsonrpc.CORS{
+			AllowedOrigins: []string{"https://example.com"},
+			MaxAge:         time.Hour,
+		})
+		w := preflight(server, "https://example.com")
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "https://example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "POST", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "3600", w.Header().Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("DisallowedOrigin", func(t *testing.T) {
+		server := newTestServer()
+		server.SetCORS(&jsonrpc.CORS{
+			AllowedOrigins: []string{"https://example.com"},
+		})
+		w := post(server, "https://evil.com")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("Wildcard", func(t *testing.T) {
+		server := newTestServer()
+		server.SetCORS(&jsonrpc.CORS{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"POST", "GET"},
+			AllowedHeaders: []string{"Content-Type", "Authorization"},
+		})
+		w := preflight(server, "https://example.com")
+
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "POST, GET", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Content-Type, Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+		assert.Empty(t, w.Header().Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("Credentials", func(t *testing.T) {
+		server := newTestServer()
+		server.SetCORS(&jsonrpc.CORS{
+			AllowedOrigins:   []string{"*"},
+			AllowCredentials: true,
+		})
+		w := post(server, "https://example.com")
+
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":3}`, w.Body.String())
+		assert.Equal(t, "https://example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	})
+}
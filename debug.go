@@ -0,0 +1,45 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// SetDebug enables extra diagnostics in the data of error responses. This is
+// intended for development and for operators tracking down malformed payloads.
+// It should not be enabled for untrusted clients.
+//
+// When debug is enabled a Parse error will contain the location of the syntax
+// error in the payload:
+//
+//     {"code":-32700,"message":"Parse error","data":{"offset":42,"line":3,"column":7}}
+//
+// offset is the number of bytes read before the error. line and column both
+// start at 1.
+func (server *SimpleServer) SetDebug(debug bool) {
+	server.debug = debug
+}
+
+// parseErrorPosition returns the location of the JSON syntax error in data, or
+// nil if there is no syntax error.
+func parseErrorPosition(data []byte) map[string]interface{} {
+	var v interface{}
+	syntaxErr, ok := json.Unmarshal(data, &v).(*json.SyntaxError)
+	if !ok {
+		return nil
+	}
+
+	offset := int(syntaxErr.Offset)
+	if offset > len(data) {
+		offset = len(data)
+	}
+
+	consumed := data[:offset]
+	lineStart := bytes.LastIndexByte(consumed, '\n') + 1
+
+	return map[string]interface{}{
+		"offset": offset,
+		"line":   bytes.Count(consumed, []byte{'\n'}) + 1,
+		"column": offset - lineStart,
+	}
+}
@@ -0,0 +1,51 @@
+package jsonrpc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimpleServer_SetDebug(t *testing.T) {
+	payload := "[\n  {\"jsonrpc\": \"2.0\", \"method\": \"sum\", \"id\": 1},\n  {\"jsonrpc\": \"2.0\", \"method\"\n]"
+
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		responses := newTestServer().Handle([]byte(payload))
+
+		assert.Nil(t, responses[0].ErrorData())
+	})
+
+	t.Run("ParseErrorPosition", func(t *testing.T) {
+		server := newTestServer()
+		server.SetDebug(true)
+		responses := server.Handle([]byte(payload))
+
+		assert.Equal(t, map[string]interface{}{
+			"offset": 81,
+			"line":   4,
+			"column": 1,
+		}, responses[0].ErrorData())
+	})
+
+	t.Run("SingleLine", func(t *testing.T) {
+		server := newTestServer()
+		server.SetDebug(true)
+		responses := server.Handle([]byte(`{"jsonrpc": "2.0", "method": }`))
+
+		assert.Equal(t, map[string]interface{}{
+			"offset": 30,
+			"line":   1,
+			"column": 30,
+		}, responses[0].ErrorData())
+	})
+
+	t.Run("CombinedWithBatchPosition", func(t *testing.T) {
+		server := newTestServer()
+		server.SetDebug(true)
+		server.SetBatchErrorPositions(true)
+		responses := server.Handle([]byte(`[1]`))
+
+		// 1 is valid JSON so there is no parse position.
+		assert.Equal(t, map[string]interface{}{"position": 0}, responses[0].ErrorData())
+	})
+}
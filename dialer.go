@@ -0,0 +1,43 @@
+package jsonrpc
+
+import (
+	"context"
+	"net"
+)
+
+// Dialer opens connections for a client. *net.Dialer, *tls.Dialer and the
+// dialers from golang.org/x/net/proxy (such as SOCKS5) all satisfy this
+// interface, and DialerFunc can be used for anything else, like in-memory
+// pipes.
+type Dialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// DialerFunc adapts a function to a Dialer.
+type DialerFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+func (dial DialerFunc) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return dial(ctx, network, address)
+}
+
+// DialConnTransport opens a connection with the dialer and creates a
+// ConnTransport for it. A nil dialer uses a *net.Dialer:
+//
+//     transport, err := jsonrpc.DialConnTransport(ctx, nil, "unix", "/tmp/rpc.sock")
+//     if err != nil {
+//         return err
+//     }
+//
+//     client := jsonrpc.NewClient(transport)
+func DialConnTransport(ctx context.Context, dialer Dialer, network, address string) (*ConnTransport, error) {
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewConnTransport(conn), nil
+}
@@ -0,0 +1,66 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialConnTransport(t *testing.T) {
+	t.Run("CustomDialer", func(t *testing.T) {
+		var dialed string
+		dialer := jsonrpc.DialerFunc(func(ctx context.Context, network, address string) (net.Conn, error) {
+			dialed = network + " " + address
+
+			clientConn, serverConn := net.Pipe()
+			go newTestServer().ServeConn(serverConn)
+
+			return clientConn, nil
+		})
+
+		transport, err := jsonrpc.DialConnTransport(context.Background(), dialer, "memory", "server")
+		assert.NoError(t, err)
+		defer transport.Close()
+
+		response, err := jsonrpc.NewClient(transport).Call("sum", []int{1, 2})
+		assert.NoError(t, err)
+		assert.Equal(t, 3.0, response.Result())
+		assert.Equal(t, "memory server", dialed)
+	})
+
+	t.Run("DefaultDialer", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		defer listener.Close()
+
+		go func() {
+			conn, err := listener.Accept()
+			if err == nil {
+				newTestServer().ServeConn(conn)
+			}
+		}()
+
+		transport, err := jsonrpc.DialConnTransport(context.Background(), nil, "tcp", listener.Addr().String())
+		assert.NoError(t, err)
+		defer transport.Close()
+
+		response, err := jsonrpc.NewClient(transport).Call("sum", []int{3, 4})
+		assert.NoError(t, err)
+		assert.Equal(t, 7.0, response.Result())
+	})
+
+	t.Run("DialError", func(t *testing.T) {
+		dialErr := errors.New("unreachable")
+		dialer := jsonrpc.DialerFunc(func(ctx context.Context, network, address string) (net.Conn, error) {
+			return nil, dialErr
+		})
+
+		transport, err := jsonrpc.DialConnTransport(context.Background(), dialer, "tcp", "x")
+		assert.Nil(t, transport)
+		assert.ErrorIs(t, err, dialErr)
+	})
+}
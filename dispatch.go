@@ -0,0 +1,56 @@
+package jsonrpc
+
+import (
+	"context"
+)
+
+// DispatchOptions describe where a request passed to Dispatch came from.
+type DispatchOptions struct {
+	// Transport is returned by RequestTransport and is used for the
+	// TransportStats. It is optional.
+	Transport string
+
+	// State is added to the State of the request.
+	State State
+}
+
+// Dispatch handles exactly one request with all of the features of the server,
+// such as middleware, validation, rate limits, caching and statistics. It is
+// for frameworks that embed this package and need their own batching or
+// transport, without having to reimplement how Handle treats each request:
+//
+//     request := jsonrpc.NewRequestResponder("2.0", id, method, params)
+//     response := server.Dispatch(ctx, request, jsonrpc.DispatchOptions{
+//         Transport: "quic",
+//     })
+//
+// Handlers receive ctx (see SetContextHandler). The response is nil if the
+// request is a notification.
+//
+// Each call counts as one payload. Handlers set with SetRawHandler are not
+// used, since the request has already been decoded.
+func (server *SimpleServer) Dispatch(ctx context.Context, request RequestResponder, opts DispatchOptions) Response {
+	state := opts.State.copy()
+	if ctx != nil {
+		state[contextKey] = ctx
+	}
+	if opts.Transport != "" {
+		state[transportKey] = opts.Transport
+	}
+
+	if len(state) > 0 {
+		request = WithState(request, state)
+	}
+
+	server.start()
+	server.observePayload(state)
+
+	responses := server.HandleRequest(request)
+	server.observeTransportResponses(state, responses)
+
+	if len(responses) == 0 {
+		return nil
+	}
+
+	return responses[0]
+}
@@ -0,0 +1,75 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type dispatchKey struct{}
+
+func TestSimpleServer_Dispatch(t *testing.T) {
+	t.Run("Pipeline", func(t *testing.T) {
+		server := newTestServer()
+		middlewareCalls := 0
+		server.Use(func(next jsonrpc.RequestHandler) jsonrpc.RequestHandler {
+			return func(request jsonrpc.RequestResponder) jsonrpc.Response {
+				middlewareCalls++
+
+				return next(request)
+			}
+		})
+
+		response := server.Dispatch(context.Background(),
+			jsonrpc.NewRequestResponder("2.0", 1, "sum", []interface{}{1.0, 2.0}),
+			jsonrpc.DispatchOptions{})
+
+		require.NotNil(t, response)
+		assert.Equal(t, 3.0, response.Result())
+		assert.Equal(t, 1, middlewareCalls)
+		assert.Equal(t, uint64(1), server.TotalRequests())
+		assert.Equal(t, uint64(1), server.TotalSuccessResponses())
+	})
+
+	t.Run("MethodNotFound", func(t *testing.T) {
+		response := newTestServer().Dispatch(context.Background(),
+			jsonrpc.NewRequestResponder("2.0", 1, "missing", nil),
+			jsonrpc.DispatchOptions{})
+
+		assert.Equal(t, jsonrpc.MethodNotFound, response.ErrorCode())
+	})
+
+	t.Run("Notification", func(t *testing.T) {
+		response := newTestServer().Dispatch(context.Background(),
+			jsonrpc.NewRequestResponder("2.0", nil, "sum", []interface{}{1.0, 2.0}),
+			jsonrpc.DispatchOptions{})
+
+		assert.Nil(t, response)
+	})
+
+	t.Run("ContextAndState", func(t *testing.T) {
+		server := jsonrpc.NewSimpleServer()
+		server.SetContextHandler("whoami", func(ctx context.Context, request jsonrpc.RequestResponder) jsonrpc.Response {
+			return request.NewSuccessResponse([]interface{}{
+				ctx.Value(dispatchKey{}),
+				request.State("user"),
+				jsonrpc.RequestTransport(request),
+			})
+		})
+
+		ctx := context.WithValue(context.Background(), dispatchKey{}, "traced")
+		response := server.Dispatch(ctx,
+			jsonrpc.NewRequestResponder("2.0", 1, "whoami", nil),
+			jsonrpc.DispatchOptions{
+				Transport: "quic",
+				State:     jsonrpc.State{"user": "bob"},
+			})
+
+		assert.Equal(t, []interface{}{"traced", "bob", "quic"}, response.Result())
+		assert.Equal(t, jsonrpc.TransportStats{Payloads: 1, SuccessResponses: 1},
+			server.TransportStats()["quic"])
+	})
+}
@@ -0,0 +1,152 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Framing describes how payloads are separated from each other on a stream,
+// such as a socket or stdin/stdout.
+type Framing interface {
+	// ReadPayload returns the next payload from the stream. io.EOF is returned
+	// when there are no more payloads. A payload that is cut short by the end
+	// of the stream may be returned along with io.EOF.
+	ReadPayload(reader *bufio.Reader) ([]byte, error)
+
+	// WritePayload writes a single payload to the stream.
+	WritePayload(writer io.Writer, payload []byte) error
+}
+
+// NewlineFraming expects each payload to be on its own line. This is the
+// default framing. Blank lines are ignored.
+var NewlineFraming Framing = newlineFraming{}
+
+type newlineFraming struct{}
+
+func (newlineFraming) ReadPayload(reader *bufio.Reader) ([]byte, error) {
+	for {
+		line, err := reader.ReadBytes('\n')
+
+		payload := bytes.TrimSpace(line)
+		if len(payload) > 0 || err != nil {
+			return payload, err
+		}
+	}
+}
+
+func (newlineFraming) WritePayload(writer io.Writer, payload []byte) error {
+	_, err := writer.Write(append(payload, '\n'))
+
+	return err
+}
+
+// DefaultMaxContentLength is the largest payload that ContentLengthFraming
+// will read, 32 MB.
+const DefaultMaxContentLength = 32 << 20
+
+// ContentLengthFraming precedes each payload with a Content-Length header, in
+// the same way as the Language Server Protocol:
+//
+//     Content-Length: 52\r\n
+//     \r\n
+//     {"jsonrpc":"2.0","method":"initialize","id":1}
+//
+// Other headers (such as Content-Type) are ignored when reading and are not
+// written. Payloads larger than DefaultMaxContentLength are rejected with
+// ErrPayloadTooLarge, use NewContentLengthFraming for a different limit.
+var ContentLengthFraming = NewContentLengthFraming(DefaultMaxContentLength)
+
+// NewContentLengthFraming is the same as ContentLengthFraming except that it
+// reads payloads of up to maxContentLength bytes. The Content-Length is sent by
+// the other side, so without a limit a single header could make the server
+// allocate any amount of memory.
+func NewContentLengthFraming(maxContentLength int) Framing {
+	return contentLengthFraming{maxContentLength: maxContentLength}
+}
+
+var (
+	// ErrMissingContentLength is returned by ContentLengthFraming when the
+	// headers of a payload do not include a valid Content-Length.
+	ErrMissingContentLength = errors.New("missing or invalid Content-Length header")
+
+	// ErrPayloadTooLarge is returned by ContentLengthFraming when the
+	// Content-Length is larger than the maximum. See NewContentLengthFraming.
+	ErrPayloadTooLarge = errors.New("payload is too large")
+)
+
+type contentLengthFraming struct {
+	maxContentLength int
+}
+
+func (framing contentLengthFraming) ReadPayload(reader *bufio.Reader) ([]byte, error) {
+	length, headers := -1, 0
+	for {
+		// ReadSlice limits a header line to the size of the buffer.
+		slice, err := reader.ReadSlice('\n')
+		line := strings.TrimSpace(string(slice))
+
+		if err != nil {
+			if err == io.EOF && (headers > 0 || line != "") {
+				err = io.ErrUnexpectedEOF
+			}
+
+			return nil, err
+		}
+
+		// A blank line ends the headers. Blank lines between payloads are
+		// ignored.
+		if line == "" {
+			if headers == 0 {
+				continue
+			}
+
+			break
+		}
+
+		headers++
+		name, value, _ := strings.Cut(line, ":")
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("%w: %s", ErrMissingContentLength, line)
+			}
+
+			if n > framing.maxContentLength {
+				return nil, fmt.Errorf("%w: %d bytes is more than %d", ErrPayloadTooLarge, n, framing.maxContentLength)
+			}
+
+			length = n
+		}
+	}
+
+	if length < 0 {
+		return nil, ErrMissingContentLength
+	}
+
+	// The payload grows as it is read, so a Content-Length that is never sent
+	// is not allocated up front.
+	payload, err := io.ReadAll(io.LimitReader(reader, int64(length)))
+	if err != nil {
+		return nil, err
+	}
+
+	// A payload that is cut short is not returned, since it would only
+	// produce a Parse error.
+	if len(payload) < length {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	return payload, nil
+}
+
+func (framing contentLengthFraming) WritePayload(writer io.Writer, payload []byte) error {
+	header := "Content-Length: " + strconv.Itoa(len(payload)) + "\r\n\r\n"
+	_, err := writer.Write(append([]byte(header), payload...))
+
+	return err
+}
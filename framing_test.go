@@ -0,0 +1,128 @@
+package jsonrpc_test
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewlineFraming_ReadPayload(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("foo\n\n  \r\nbar\nbaz"))
+
+	payload, err := jsonrpc.NewlineFraming.ReadPayload(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", string(payload))
+
+	payload, err = jsonrpc.NewlineFraming.ReadPayload(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", string(payload))
+
+	payload, err = jsonrpc.NewlineFraming.ReadPayload(reader)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, "baz", string(payload))
+}
+
+func TestNewlineFraming_WritePayload(t *testing.T) {
+	buf := new(bytes.Buffer)
+	err := jsonrpc.NewlineFraming.WritePayload(buf, []byte("foo"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "foo\n", buf.String())
+}
+
+func TestContentLengthFraming_ReadPayload(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader(
+		"Content-Length: 3\r\n\r\nfoo" +
+			"content-length:3\r\nContent-Type: application/vscode-jsonrpc; charset=utf-8\r\n\r\nbar" +
+			"\r\nContent-Length: 5\n\nbaz"))
+
+	payload, err := jsonrpc.ContentLengthFraming.ReadPayload(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", string(payload))
+
+	payload, err = jsonrpc.ContentLengthFraming.ReadPayload(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", string(payload))
+
+	// A payload that is cut short is not returned.
+	payload, err = jsonrpc.ContentLengthFraming.ReadPayload(reader)
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	assert.Nil(t, payload)
+
+	_, err = jsonrpc.ContentLengthFraming.ReadPayload(reader)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestContentLengthFraming_ReadPayloadErrors(t *testing.T) {
+	for header, expected := range map[string]error{
+		"Content-Type: text/plain\r\n\r\nfoo": jsonrpc.ErrMissingContentLength,
+		"Content-Length: abc\r\n\r\nfoo":      jsonrpc.ErrMissingContentLength,
+		"Content-Length: -1\r\n\r\nfoo":       jsonrpc.ErrMissingContentLength,
+		"Content-Length: 3\r\n":               io.ErrUnexpectedEOF,
+		"Content-Length: 3\r\n\r\nfo":         io.ErrUnexpectedEOF,
+		"Content-Length: 33554433\r\n\r\n{}":  jsonrpc.ErrPayloadTooLarge,
+
+		// Too large to be an int.
+		"Content-Length: 46116860184273879040000\r\n\r\n{}": jsonrpc.ErrMissingContentLength,
+
+		// Header lines cannot be longer than the buffer.
+		"X-Padding: " + strings.Repeat("a", 8192) + "\r\n": bufio.ErrBufferFull,
+	} {
+		payload, err := jsonrpc.ContentLengthFraming.ReadPayload(bufio.NewReader(strings.NewReader(header)))
+		assert.ErrorIs(t, err, expected, header)
+		assert.Nil(t, payload)
+	}
+}
+
+func TestNewContentLengthFraming(t *testing.T) {
+	framing := jsonrpc.NewContentLengthFraming(5)
+
+	payload, err := framing.ReadPayload(bufio.NewReader(strings.NewReader("Content-Length: 5\r\n\r\n12345")))
+	assert.NoError(t, err)
+	assert.Equal(t, "12345", string(payload))
+
+	_, err = framing.ReadPayload(bufio.NewReader(strings.NewReader("Content-Length: 6\r\n\r\n123456")))
+	assert.ErrorIs(t, err, jsonrpc.ErrPayloadTooLarge)
+	assert.EqualError(t, err, "payload is too large: 6 bytes is more than 5")
+}
+
+func TestContentLengthFraming_WritePayload(t *testing.T) {
+	buf := new(bytes.Buffer)
+	err := jsonrpc.ContentLengthFraming.WritePayload(buf, []byte("foo"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Content-Length: 3\r\n\r\nfoo", buf.String())
+}
+
+func TestSimpleServer_ServeStdioContentLength(t *testing.T) {
+	in := new(bytes.Buffer)
+	jsonrpc.ContentLengthFraming.WritePayload(in, []byte(`{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}`))
+	jsonrpc.ContentLengthFraming.WritePayload(in, []byte(`{"jsonrpc": "2.0", "method": "notify_hello", "params": [7]}`))
+	jsonrpc.ContentLengthFraming.WritePayload(in, []byte(`{"jsonrpc": "2.0", "method": "sum", "params": [1,2], "id": 2}`))
+
+	server := newTestServer()
+	server.SetFraming(jsonrpc.ContentLengthFraming)
+	out := new(bytes.Buffer)
+	err := server.ServeStdio(in, out)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Content-Length: 35\r\n\r\n"+`{"jsonrpc":"2.0","id":1,"result":7}`+
+		"Content-Length: 35\r\n\r\n"+`{"jsonrpc":"2.0","id":2,"result":3}`, out.String())
+}
+
+func TestSimpleServer_ServeStdioContentLengthTooLarge(t *testing.T) {
+	in := strings.NewReader("Content-Length: 4611686018427387904\r\n\r\n{}")
+
+	server := newTestServer()
+	server.SetFraming(jsonrpc.ContentLengthFraming)
+	out := new(bytes.Buffer)
+	err := server.ServeStdio(in, out)
+
+	assert.ErrorIs(t, err, jsonrpc.ErrPayloadTooLarge)
+	assert.Empty(t, out.String())
+}
@@ -0,0 +1,118 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrRawHandlerOptions is returned by Group.SetRawHandler when the group has
+// options, since they cannot be applied to a RawHandler.
+var ErrRawHandlerOptions = errors.New("Raw handlers do not support handler options")
+
+// Group registers methods that share a prefix and options. See
+// SimpleServer.Group.
+type Group struct {
+	server  *SimpleServer
+	prefix  string
+	options []HandlerOption
+}
+
+// Group returns a Group that registers methods with a prefix:
+//
+//     billing := server.Group("billing.", jsonrpc.WithMiddleware(requireAccount))
+//     billing.SetHandler("charge", charge) // billing.charge
+//     billing.SetHandler("refund", refund) // billing.refund
+//
+// The options are applied to every method registered with the group, before
+// the options given for each method.
+func (server *SimpleServer) Group(prefix string, options ...HandlerOption) *Group {
+	return &Group{
+		server:  server,
+		prefix:  prefix,
+		options: options,
+	}
+}
+
+// Group returns a nested group. The prefix is added to the prefix of this
+// group and the options run after the options of this group.
+func (group *Group) Group(prefix string, options ...HandlerOption) *Group {
+	return &Group{
+		server:  group.server,
+		prefix:  group.prefix + prefix,
+		options: group.withOptions(options),
+	}
+}
+
+// Prefix is the prefix added to each method name.
+func (group *Group) Prefix() string {
+	return group.prefix
+}
+
+func (group *Group) withOptions(options []HandlerOption) []HandlerOption {
+	all := make([]HandlerOption, 0, len(group.options)+len(options))
+	all = append(all, group.options...)
+
+	return append(all, options...)
+}
+
+// SetHandler is the same as SimpleServer.SetHandler with the group prefix and
+// options.
+func (group *Group) SetHandler(methodName string, handler RequestHandler, options ...HandlerOption) {
+	group.server.SetHandler(group.prefix+methodName, handler, group.withOptions(options)...)
+}
+
+// SetContextHandler is the same as SimpleServer.SetContextHandler with the
+// group prefix and options.
+func (group *Group) SetContextHandler(methodName string, handler ContextHandler, options ...HandlerOption) {
+	group.server.SetContextHandler(group.prefix+methodName, handler, group.withOptions(options)...)
+}
+
+// SetInjectedHandler is the same as SimpleServer.SetInjectedHandler with the
+// group prefix and options.
+func (group *Group) SetInjectedHandler(methodName string, handler interface{}, options ...HandlerOption) error {
+	return group.server.SetInjectedHandler(group.prefix+methodName, handler, group.withOptions(options)...)
+}
+
+// SetNotificationHandler is the same as SimpleServer.SetNotificationHandler
+// with the group prefix. The middleware of the group runs before the handler,
+// which is not called if the middleware does not call next. The response of
+// the middleware is discarded.
+func (group *Group) SetNotificationHandler(methodName string, handler NotificationHandler) {
+	if handler != nil && len(group.options) > 0 {
+		handler = notificationWithOptions(handler, group.options)
+	}
+
+	group.server.SetNotificationHandler(group.prefix+methodName, handler)
+}
+
+// notificationWithOptions wraps the handler with the middleware of the
+// options.
+func notificationWithOptions(handler NotificationHandler, options []HandlerOption) NotificationHandler {
+	var opts handlerOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	next := chainMiddleware(func(request RequestResponder) Response {
+		handler(context.WithoutCancel(RequestContext(request)), request)
+
+		return request.NewSuccessResponse(nil)
+	}, opts.middleware)
+
+	return func(ctx context.Context, request Request) {
+		next(request.(RequestResponder))
+	}
+}
+
+// SetRawHandler is the same as SimpleServer.SetRawHandler with the group
+// prefix. Raw handlers cannot have handler options, so ErrRawHandlerOptions is
+// returned if the group has options.
+func (group *Group) SetRawHandler(methodName string, handler RawHandler) error {
+	if len(group.options) > 0 {
+		return ErrRawHandlerOptions
+	}
+
+	group.server.SetRawHandler(group.prefix+methodName, handler)
+
+	return nil
+}
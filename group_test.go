@@ -0,0 +1,125 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimpleServer_Group(t *testing.T) {
+	t.Run("Prefix", func(t *testing.T) {
+		server := jsonrpc.NewSimpleServer()
+		billing := server.Group("billing.")
+		billing.SetHandler("charge", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+			return request.NewSuccessResponse(request.Method())
+		})
+		billing.SetContextHandler("refund", func(ctx context.Context, request jsonrpc.RequestResponder) jsonrpc.Response {
+			return request.NewSuccessResponse(request.Method())
+		})
+		assert.NoError(t, billing.SetInjectedHandler("total", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+			return request.NewSuccessResponse(42)
+		}))
+		assert.NoError(t, billing.SetRawHandler("raw", func(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, *jsonrpc.RPCError) {
+			return json.RawMessage(`"raw"`), nil
+		}))
+		billing.SetNotificationHandler("event", func(ctx context.Context, request jsonrpc.Request) {})
+
+		assert.Equal(t, "billing.", billing.Prefix())
+		assert.Equal(t, []string{
+			"billing.charge",
+			"billing.event",
+			"billing.raw",
+			"billing.refund",
+			"billing.total",
+		}, server.Methods())
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"billing.charge","id":1}`))
+		assert.Equal(t, "billing.charge", responses[0].Result())
+
+		responses = server.Handle([]byte(`{"jsonrpc":"2.0","method":"billing.refund","id":1}`))
+		assert.Equal(t, "billing.refund", responses[0].Result())
+
+		responses = server.Handle([]byte(`{"jsonrpc":"2.0","method":"charge","id":1}`))
+		assert.Equal(t, jsonrpc.MethodNotFound, responses[0].ErrorCode())
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		var calls []string
+		server := jsonrpc.NewSimpleServer()
+		billing := server.Group("billing.", jsonrpc.WithMiddleware(recordMiddleware("group", &calls)))
+		invoices := billing.Group("invoices.", jsonrpc.WithMiddleware(recordMiddleware("nested", &calls)))
+		invoices.SetHandler("list", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+			calls = append(calls, "handler")
+
+			return request.NewSuccessResponse(true)
+		}, jsonrpc.WithMiddleware(recordMiddleware("method", &calls)))
+
+		assert.Equal(t, "billing.invoices.", invoices.Prefix())
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"billing.invoices.list","id":1}`))
+		assert.Equal(t, true, responses[0].Result())
+		assert.Equal(t, []string{
+			"group before",
+			"nested before",
+			"method before",
+			"handler",
+			"method after",
+			"nested after",
+			"group after",
+		}, calls)
+	})
+	t.Run("NotificationMiddleware", func(t *testing.T) {
+		var calls []string
+		done := make(chan struct{})
+		server := jsonrpc.NewSimpleServer()
+		events := server.Group("events.",
+			jsonrpc.WithMiddleware(recordMiddleware("group", &calls)),
+			jsonrpc.WithDefaultState(jsonrpc.State{"source": "group"}))
+		events.SetNotificationHandler("created", func(ctx context.Context, request jsonrpc.Request) {
+			calls = append(calls, "handler", request.State("source").(string),
+				jsonrpc.ContextState(ctx, "source").(string))
+			close(done)
+		})
+
+		assert.Empty(t, server.Handle([]byte(`{"jsonrpc":"2.0","method":"events.created"}`)))
+		<-done
+		assert.Eventually(t, func() bool {
+			return server.CurrentActiveRequests() == 0
+		}, time.Second, time.Millisecond)
+		assert.Equal(t, []string{"group before", "handler", "group", "group", "group after"}, calls)
+	})
+
+	t.Run("NotificationMiddlewareRejects", func(t *testing.T) {
+		called := make(chan struct{}, 1)
+		server := jsonrpc.NewSimpleServer()
+		events := server.Group("events.", jsonrpc.WithMiddleware(func(next jsonrpc.RequestHandler) jsonrpc.RequestHandler {
+			return func(request jsonrpc.RequestResponder) jsonrpc.Response {
+				return request.NewErrorResponse(jsonrpc.ServerError, "Unauthorized.")
+			}
+		}))
+		events.SetNotificationHandler("created", func(ctx context.Context, request jsonrpc.Request) {
+			called <- struct{}{}
+		})
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"events.created"}`))
+		assert.Eventually(t, func() bool {
+			return server.CurrentActiveRequests() == 0
+		}, time.Second, time.Millisecond)
+		assert.Len(t, called, 0)
+	})
+
+	t.Run("RawHandlerWithOptions", func(t *testing.T) {
+		server := jsonrpc.NewSimpleServer()
+		billing := server.Group("billing.", jsonrpc.WithMiddleware(recordMiddleware("group", new([]string))))
+
+		err := billing.SetRawHandler("raw", func(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, *jsonrpc.RPCError) {
+			return json.RawMessage(`"raw"`), nil
+		})
+		assert.Equal(t, jsonrpc.ErrRawHandlerOptions, err)
+		assert.Empty(t, server.Methods())
+	})
+}
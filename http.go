@@ -0,0 +1,222 @@
+package jsonrpc
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultMaxHTTPBodySize is the largest HTTP body that is read by ServeHTTP and
+// HTTPTransport, 32 MB.
+const DefaultMaxHTTPBodySize = 32 << 20
+
+// The time allowed to read the headers of a request by ListenAndServeHTTP and
+// ListenAndServeHTTPTLS, so that slow clients cannot hold connections open.
+const httpReadHeaderTimeout = 10 * time.Second
+
+// ServeHTTP allows the server to be used as a http.Handler. Each HTTP request
+// must be a POST with the JSON-RPC payload (a single request or a batch) as the
+// body:
+//
+//     http.Handle("/rpc", server)
+//
+// The responses are sent back with a 200 status, even if they contain errors.
+// If there is nothing to send back (all the requests were notifications) a 204
+// status is returned with no body.
+//
+// Handlers receive the context of the HTTP request. See SetContextHandler.
+//
+// GET requests can also be enabled with SetHTTPGet, and browsers can be
+// allowed to call the server directly with SetCORS. Metrics can be served
+// from the same handler with SetMetricsPath.
+//
+// A "Cache-Control" header is sent when handlers return their results with
+// NewSuccessResponseWithMaxAge.
+//
+// A body larger than DefaultMaxHTTPBodySize (see SetMaxHTTPBodySize) receives
+// a 413 status.
+func (server *SimpleServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if server.applyCORS(w, r) {
+		return
+	}
+
+	if server.serveMetrics(w, r) {
+		return
+	}
+
+	var payload []byte
+	switch {
+	case r.Method == http.MethodPost:
+		var err error
+		payload, err = io.ReadAll(server.limitHTTPBody(w, r))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				http.Error(w, "Request body is too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+	case r.Method == http.MethodGet && server.httpGet:
+		var errResponse Response
+		payload, errResponse = payloadFromQuery(r.URL.Query())
+		if errResponse != nil {
+			writeHTTPResponse(w, errResponse.Bytes())
+			return
+		}
+
+	default:
+		allow := http.MethodPost
+		if server.httpGet {
+			allow = http.MethodGet + ", " + http.MethodPost
+		}
+
+		w.Header().Set("Allow", allow)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if server.streamHTTPBatch(w, r, payload) {
+		return
+	}
+
+	data, responses, info := server.handleMessage(payload, State{
+		contextKey:   r.Context(),
+		transportKey: TransportHTTP,
+	})
+	if data != nil {
+		setCacheControl(w, responses)
+	}
+
+	server.writeCompressedHTTPResponse(w, r, info.methods, data)
+}
+
+// SetMaxHTTPBodySize sets the largest body (in bytes) that ServeHTTP will read.
+// Larger requests receive a 413 status without being handled. Zero uses
+// DefaultMaxHTTPBodySize and a negative max removes the limit.
+func (server *SimpleServer) SetMaxHTTPBodySize(max int64) {
+	server.maxHTTPBodySize = max
+}
+
+func (server *SimpleServer) limitHTTPBody(w http.ResponseWriter, r *http.Request) io.Reader {
+	switch {
+	case server.maxHTTPBodySize < 0:
+		return r.Body
+	case server.maxHTTPBodySize == 0:
+		return http.MaxBytesReader(w, r.Body, DefaultMaxHTTPBodySize)
+	default:
+		return http.MaxBytesReader(w, r.Body, server.maxHTTPBodySize)
+	}
+}
+
+func writeHTTPResponse(w http.ResponseWriter, data []byte) {
+	if data == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// SetHTTPGet allows requests to be sent with a HTTP GET as described in the
+// JSON-RPC over HTTP draft. The request is made up of the "method", "params"
+// and "id" query parameters:
+//
+//     GET /rpc?method=sum&params=[1,2,3]&id=1
+//
+// params must be JSON, which may also be base64 encoded. If id is omitted the
+// request is a notification. An id that is not valid JSON will be treated as a
+// string.
+//
+// This is useful for debugging and simple read-only integrations. GET requests
+// are disabled by default.
+func (server *SimpleServer) SetHTTPGet(enabled bool) {
+	server.httpGet = enabled
+}
+
+// payloadFromQuery builds a JSON-RPC request from the query parameters of a
+// GET request. See SetHTTPGet. If the params are not valid then an error
+// response is returned instead.
+func payloadFromQuery(query url.Values) ([]byte, Response) {
+	var id interface{}
+	if rawId, ok := query["id"]; ok {
+		if json.Unmarshal([]byte(rawId[0]), &id) != nil {
+			id = rawId[0]
+		}
+	}
+
+	request := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  query.Get("method"),
+		"id":      id,
+	}
+
+	if params := query.Get("params"); params != "" {
+		if !json.Valid([]byte(params)) {
+			for _, encoding := range []*base64.Encoding{
+				base64.StdEncoding, base64.URLEncoding,
+				base64.RawStdEncoding, base64.RawURLEncoding,
+			} {
+				if decoded, err := encoding.DecodeString(params); err == nil {
+					params = string(decoded)
+					break
+				}
+			}
+		}
+
+		if !json.Valid([]byte(params)) {
+			return nil, NewErrorResponse(id, InvalidParams,
+				"Params must be JSON or base64 encoded JSON.")
+		}
+
+		request["params"] = json.RawMessage(params)
+	}
+
+	payload, _ := json.Marshal(request)
+
+	return payload, nil
+}
+
+// ListenAndServeHTTP listens on the TCP address and serves JSON-RPC over HTTP
+// on every path. Use ServeHTTP if you need to mount the server on a specific
+// path or alongside other handlers.
+//
+// The headers of each request must be read within 10 seconds. Use ServeHTTP
+// with your own http.Server for other timeouts.
+func (server *SimpleServer) ListenAndServeHTTP(addr string) error {
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           server,
+		ReadHeaderTimeout: httpReadHeaderTimeout,
+	}
+
+	return httpServer.ListenAndServe()
+}
+
+// ListenAndServeHTTPTLS is the same as ListenAndServeHTTP except that it
+// serves HTTPS. The config must contain at least one certificate.
+//
+// Clients can be required to provide a certificate by setting ClientAuth (and
+// ClientCAs) on the config:
+//
+//     config.ClientAuth = tls.RequireAndVerifyClientCert
+//
+func (server *SimpleServer) ListenAndServeHTTPTLS(addr string, config *tls.Config) error {
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           server,
+		TLSConfig:         config,
+		ReadHeaderTimeout: httpReadHeaderTimeout,
+	}
+
+	return httpServer.ListenAndServeTLS("", "")
+}
@@ -0,0 +1,250 @@
+package jsonrpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// The content type of the request and response of an HTTP/2 session.
+const sessionContentType = "application/jsonrpc-session"
+
+// HTTP2SessionHandler returns a http.Handler that serves each HTTP request as
+// a session, in the same way as ServeConn. Payloads are read from the request
+// body as they arrive and the responses are written to the response body,
+// so both bodies stay open until the client closes the session:
+//
+//     http.Handle("/rpc/session", server.HTTP2SessionHandler())
+//
+// Each session is a single HTTP/2 stream, so many sessions share one
+// connection. See DialHTTP2Session. Sessions also work over HTTP/1.1, but
+// each one needs its own connection.
+func (server *SimpleServer) HTTP2SessionHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		controller := http.NewResponseController(w)
+
+		// HTTP/2 is always full duplex. This is only needed for HTTP/1.1.
+		controller.EnableFullDuplex()
+
+		w.Header().Set("Content-Type", sessionContentType)
+		w.WriteHeader(http.StatusOK)
+		if controller.Flush() != nil {
+			return
+		}
+
+		server.serveConn(struct {
+			io.Reader
+			io.Writer
+		}{r.Body, flushWriter{w, controller}}, TransportHTTP)
+	})
+}
+
+// flushWriter sends each write to the client immediately.
+type flushWriter struct {
+	w          io.Writer
+	controller *http.ResponseController
+}
+
+func (w flushWriter) Write(data []byte) (int, error) {
+	n, err := w.w.Write(data)
+	if err != nil {
+		return n, err
+	}
+
+	return n, w.controller.Flush()
+}
+
+// DialHTTP2Session opens a session with a server using HTTP2SessionHandler and
+// creates a ConnTransport for it:
+//
+//     transport, err := jsonrpc.DialHTTP2Session(ctx, httpClient, "https://example.com/rpc/session")
+//     if err != nil {
+//         return err
+//     }
+//     defer transport.Close()
+//
+//     client := jsonrpc.NewClient(transport)
+//
+// Sessions opened with the same http.Client are multiplexed over a single
+// HTTP/2 connection, one stream per session. A nil httpClient uses
+// http.DefaultClient, which only uses HTTP/2 for https URLs.
+//
+// ctx is only used while the session is opened. The session stays open until
+// the transport is closed.
+//
+// The connection of the transport does not support read deadlines, so
+// SetReadDeadline does nothing. A write deadline only affects a write that is
+// in progress when it is reached. Since part of the payload may have been
+// sent, that write ends the session.
+func DialHTTP2Session(ctx context.Context, httpClient *http.Client, url string) (*ConnTransport, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	bodyReader, bodyWriter := io.Pipe()
+	sessionCtx, cancelSession := context.WithCancel(context.WithoutCancel(ctx))
+
+	// The request body must also be closed, otherwise the HTTP client waits
+	// for it to finish.
+	cancel := func() {
+		bodyWriter.CloseWithError(context.Canceled)
+		cancelSession()
+	}
+
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	request, err := http.NewRequestWithContext(sessionCtx, http.MethodPost, url, bodyReader)
+	if err != nil {
+		cancel()
+
+		return nil, err
+	}
+
+	request.Header.Set("Content-Type", sessionContentType)
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		cancel()
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		cancel()
+
+		return nil, &HTTPStatusError{
+			StatusCode: response.StatusCode,
+			Status:     response.Status,
+		}
+	}
+
+	return NewConnTransport(&sessionConn{
+		body:   response.Body,
+		writer: bodyWriter,
+		cancel: cancel,
+		addr:   sessionAddr(url),
+	}), nil
+}
+
+// sessionAddr is the address of an HTTP/2 session.
+type sessionAddr string
+
+func (addr sessionAddr) Network() string {
+	return "http2"
+}
+
+func (addr sessionAddr) String() string {
+	return string(addr)
+}
+
+// sessionConn is a net.Conn made from the request and response bodies of an
+// HTTP/2 session.
+type sessionConn struct {
+	body   io.ReadCloser
+	writer *io.PipeWriter
+	cancel context.CancelFunc
+	addr   sessionAddr
+
+	mutex         sync.Mutex
+	writeDeadline *time.Timer
+	deadline      time.Time
+	writes        int
+}
+
+func (conn *sessionConn) Read(data []byte) (int, error) {
+	return conn.body.Read(data)
+}
+
+func (conn *sessionConn) Write(data []byte) (int, error) {
+	conn.mutex.Lock()
+	if !conn.deadline.IsZero() && !time.Now().Before(conn.deadline) {
+		conn.mutex.Unlock()
+
+		return 0, os.ErrDeadlineExceeded
+	}
+	conn.writes++
+	conn.mutex.Unlock()
+
+	n, err := conn.writer.Write(data)
+
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	conn.writes--
+	if err != nil && !conn.deadline.IsZero() && !time.Now().Before(conn.deadline) {
+		err = os.ErrDeadlineExceeded
+	}
+
+	return n, err
+}
+
+// Close ends the request body, so the server finishes the session, and
+// cancels the request.
+func (conn *sessionConn) Close() error {
+	conn.writer.Close()
+	err := conn.body.Close()
+	conn.cancel()
+
+	return err
+}
+
+func (conn *sessionConn) LocalAddr() net.Addr {
+	return conn.addr
+}
+
+func (conn *sessionConn) RemoteAddr() net.Addr {
+	return conn.addr
+}
+
+func (conn *sessionConn) SetDeadline(t time.Time) error {
+	return conn.SetWriteDeadline(t)
+}
+
+// SetReadDeadline is not supported. Reads end when the session is closed.
+func (conn *sessionConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+// SetWriteDeadline makes writes fail once the deadline is reached. A write
+// to a stream cannot be stopped part way through, so a write that is in
+// progress at the deadline ends the session. Otherwise, the session can still
+// be used after the deadline is cleared.
+func (conn *sessionConn) SetWriteDeadline(t time.Time) error {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	if conn.writeDeadline != nil {
+		conn.writeDeadline.Stop()
+		conn.writeDeadline = nil
+	}
+
+	conn.deadline = t
+	if !t.IsZero() {
+		conn.writeDeadline = time.AfterFunc(time.Until(t), func() {
+			conn.mutex.Lock()
+			defer conn.mutex.Unlock()
+
+			// The deadline may have been changed before the timer ran.
+			if conn.writes > 0 && conn.deadline.Equal(t) {
+				conn.writer.CloseWithError(os.ErrDeadlineExceeded)
+			}
+		})
+	}
+
+	return nil
+}
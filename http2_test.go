@@ -0,0 +1,143 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialHTTP2Session(t *testing.T) {
+	var connections int32
+	httpServer := httptest.NewUnstartedServer(newTestServer().HTTP2SessionHandler())
+	httpServer.EnableHTTP2 = true
+	httpServer.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	httpServer.StartTLS()
+	defer httpServer.Close()
+
+	ctx := context.Background()
+	var transports []*jsonrpc.ConnTransport
+	for i := 0; i < 3; i++ {
+		transport, err := jsonrpc.DialHTTP2Session(ctx, httpServer.Client(), httpServer.URL)
+		assert.NoError(t, err)
+		defer transport.Close()
+
+		transports = append(transports, transport)
+	}
+
+	for i, transport := range transports {
+		client := jsonrpc.NewClient(transport)
+
+		for j := 0; j < 2; j++ {
+			response, err := client.Call("sum", []int{i, j})
+			assert.NoError(t, err)
+			assert.Equal(t, float64(i+j), response.Result())
+		}
+	}
+
+	// All of the sessions share one connection.
+	assert.Equal(t, int32(1), atomic.LoadInt32(&connections))
+
+	// A closed session cannot be used.
+	assert.NoError(t, transports[0].Close())
+	_, err := jsonrpc.NewClient(transports[0]).Call("sum", []int{1, 2})
+	assert.Error(t, err)
+
+	// The other sessions still work.
+	response, err := jsonrpc.NewClient(transports[1]).Call("sum", []int{1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, response.Result())
+}
+
+func TestDialHTTP2Session_Cancel(t *testing.T) {
+	server := newTestServer()
+	server.SetHandler("slow", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		time.Sleep(50 * time.Millisecond)
+
+		return request.NewSuccessResponse(true)
+	})
+
+	httpServer := httptest.NewUnstartedServer(server.HTTP2SessionHandler())
+	httpServer.EnableHTTP2 = true
+	httpServer.StartTLS()
+	defer httpServer.Close()
+
+	transport, err := jsonrpc.DialHTTP2Session(context.Background(), httpServer.Client(), httpServer.URL)
+	assert.NoError(t, err)
+	defer transport.Close()
+
+	client := jsonrpc.NewClient(transport)
+
+	// Cancelling calls does not end the session.
+	for i := 0; i < 10; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		_, err = client.CallContext(ctx, "slow", nil)
+		cancel()
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+
+	response, err := client.Call("sum", []int{1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, response.Result())
+}
+
+func TestDialHTTP2Session_Errors(t *testing.T) {
+	t.Run("Status", func(t *testing.T) {
+		httpServer := httptest.NewUnstartedServer(http.NotFoundHandler())
+		httpServer.EnableHTTP2 = true
+		httpServer.StartTLS()
+		defer httpServer.Close()
+
+		_, err := jsonrpc.DialHTTP2Session(context.Background(), httpServer.Client(), httpServer.URL)
+
+		var statusErr *jsonrpc.HTTPStatusError
+		assert.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		// An HTTP/1.1 server that does not read the body will not respond.
+		httpServer := httptest.NewServer(http.NotFoundHandler())
+		defer httpServer.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := jsonrpc.DialHTTP2Session(ctx, nil, httpServer.URL)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("MethodNotAllowed", func(t *testing.T) {
+		httpServer := httptest.NewServer(newTestServer().HTTP2SessionHandler())
+		defer httpServer.Close()
+
+		response, err := http.Get(httpServer.URL)
+		assert.NoError(t, err)
+		response.Body.Close()
+
+		assert.Equal(t, http.StatusMethodNotAllowed, response.StatusCode)
+	})
+
+	t.Run("HTTP1", func(t *testing.T) {
+		httpServer := httptest.NewServer(newTestServer().HTTP2SessionHandler())
+		defer httpServer.Close()
+
+		transport, err := jsonrpc.DialHTTP2Session(context.Background(), nil, httpServer.URL)
+		assert.NoError(t, err)
+		defer transport.Close()
+
+		response, err := jsonrpc.NewClient(transport).Call("sum", []int{1, 2})
+		assert.NoError(t, err)
+		assert.Equal(t, 3.0, response.Result())
+	})
+}
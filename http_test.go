@@ -0,0 +1,196 @@
+package jsonrpc_test
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimpleServer_ServeHTTP(t *testing.T) {
+	t.Run("Single", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/",
+			strings.NewReader(`{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}`))
+		newTestServer().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":7}`, w.Body.String())
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/",
+			strings.NewReader(`[{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}]`))
+		newTestServer().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `[{"jsonrpc":"2.0","id":1,"result":7}]`, w.Body.String())
+	})
+
+	t.Run("Notification", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/",
+			strings.NewReader(`{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4]}`))
+		newTestServer().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "", w.Body.String())
+	})
+
+	t.Run("MethodNotAllowed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPut, "/", nil)
+		newTestServer().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, http.MethodPost, w.Header().Get("Allow"))
+	})
+}
+
+func TestSimpleServer_SetMaxHTTPBodySize(t *testing.T) {
+	payload := `{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}`
+
+	t.Run("TooLarge", func(t *testing.T) {
+		server := newTestServer()
+		server.SetMaxHTTPBodySize(int64(len(payload) - 1))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payload))
+		server.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("WithinLimit", func(t *testing.T) {
+		server := newTestServer()
+		server.SetMaxHTTPBodySize(int64(len(payload)))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payload))
+		server.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/",
+			strings.NewReader(strings.Repeat(" ", jsonrpc.DefaultMaxHTTPBodySize+1)))
+		newTestServer().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+}
+
+func TestSimpleServer_SetHTTPGet(t *testing.T) {
+	get := func(server *jsonrpc.SimpleServer, query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?"+query, nil))
+
+		return w
+	}
+
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		w := get(newTestServer(), "method=sum&params=[1,2]&id=1")
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, http.MethodPost, w.Header().Get("Allow"))
+	})
+
+	server := newTestServer()
+	server.SetHTTPGet(true)
+
+	t.Run("URLEncodedParams", func(t *testing.T) {
+		w := get(server, "method=sum&params="+url.QueryEscape("[1,2]")+"&id=1")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":3}`, w.Body.String())
+	})
+
+	t.Run("Base64Params", func(t *testing.T) {
+		w := get(server, "method=sum&params="+
+			url.QueryEscape(base64.StdEncoding.EncodeToString([]byte("[1,2,4]")))+"&id=1")
+
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":7}`, w.Body.String())
+	})
+
+	t.Run("StringId", func(t *testing.T) {
+		w := get(server, "method=get_data&id=abc")
+
+		assert.Equal(t, `{"jsonrpc":"2.0","id":"abc","result":["hello",5]}`, w.Body.String())
+	})
+
+	t.Run("Notification", func(t *testing.T) {
+		w := get(server, "method=sum&params=[1,2]")
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("InvalidParams", func(t *testing.T) {
+		w := get(server, "method=sum&params=%7Bfoo&id=1")
+
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"Params must be JSON or base64 encoded JSON."}}`, w.Body.String())
+	})
+
+	t.Run("Allow", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/", nil))
+
+		assert.Equal(t, "GET, POST", w.Header().Get("Allow"))
+	})
+}
+
+func TestSimpleServer_ListenAndServeHTTPTLS(t *testing.T) {
+	certificate, pool := newTestCertificate(t)
+	addr := freeAddr(t)
+	go newTestServer().ListenAndServeHTTPTLS(addr, &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	})
+
+	post := func(config *tls.Config) (response *http.Response, err error) {
+		client := &http.Client{
+			Transport: &http.Transport{TLSClientConfig: config},
+		}
+
+		for i := 0; i < 100; i += 1 {
+			response, err = client.Post("https://"+addr, "application/json",
+				strings.NewReader(`{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}`))
+			if err == nil || !strings.Contains(err.Error(), "connection refused") {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		return
+	}
+
+	t.Run("WithClientCertificate", func(t *testing.T) {
+		response, err := post(&tls.Config{
+			Certificates: []tls.Certificate{certificate},
+			RootCAs:      pool,
+		})
+		assert.NoError(t, err)
+		defer response.Body.Close()
+
+		body, err := io.ReadAll(response.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":7}`, string(body))
+	})
+
+	t.Run("WithoutClientCertificate", func(t *testing.T) {
+		_, err := post(&tls.Config{RootCAs: pool})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,115 @@
+package jsonrpc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	requestResponderType = reflect.TypeOf((*RequestResponder)(nil)).Elem()
+	responseType         = reflect.TypeOf((*Response)(nil)).Elem()
+	errorType            = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Provide registers a function that provides a dependency for handlers set
+// with SetInjectedHandler. The provider must be a function with no arguments
+// that returns a single value, or a value and an error:
+//
+//     server.Provide(func() *sql.DB {
+//         return db
+//     })
+//
+// The provider is called every time a handler needs the value. A provider for
+// the same type will replace the existing provider.
+func (server *SimpleServer) Provide(provider interface{}) error {
+	providerType := reflect.TypeOf(provider)
+	if providerType == nil || providerType.Kind() != reflect.Func ||
+		providerType.NumIn() != 0 || providerType.NumOut() < 1 ||
+		providerType.NumOut() > 2 ||
+		(providerType.NumOut() == 2 && providerType.Out(1) != errorType) {
+		return errors.New("Provider must be a func() T or func() (T, error)")
+	}
+
+	if server.providers == nil {
+		server.providers = make(map[reflect.Type]reflect.Value)
+	}
+
+	server.providers[providerType.Out(0)] = reflect.ValueOf(provider)
+
+	return nil
+}
+
+// SetInjectedHandler registers a handler that receives dependencies from the
+// providers registered with Provide as extra arguments, instead of reaching
+// for globals or State:
+//
+//     server.SetInjectedHandler("getUser",
+//         func(request jsonrpc.RequestResponder, db *sql.DB) jsonrpc.Response {
+//             // ...
+//         })
+//
+// The handler must accept a RequestResponder as the first argument and return
+// a Response. An error is returned if the handler does not have the right
+// signature.
+//
+// Dependencies are resolved for each request. If there is no provider for an
+// argument an Internal error is sent back. If a provider returns an error it is
+// sent back as a Server error.
+//
+// The options are the same as for SetHandler.
+func (server *SimpleServer) SetInjectedHandler(methodName string, handler interface{}, options ...HandlerOption) error {
+	handlerType := reflect.TypeOf(handler)
+	if handlerType == nil || handlerType.Kind() != reflect.Func ||
+		handlerType.NumIn() < 1 || handlerType.In(0) != requestResponderType ||
+		handlerType.NumOut() != 1 || handlerType.Out(0) != responseType {
+		return errors.New("Handler must be a func(RequestResponder, ...) Response")
+	}
+
+	if err := server.checkMethodName(methodName); err != nil {
+		return err
+	}
+
+	handlerValue := reflect.ValueOf(handler)
+
+	server.SetHandler(methodName, func(request RequestResponder) Response {
+		args := make([]reflect.Value, handlerType.NumIn())
+		args[0] = reflect.ValueOf(&request).Elem()
+
+		for i := 1; i < len(args); i += 1 {
+			value, errResponse := server.resolve(request, handlerType.In(i))
+			if errResponse != nil {
+				return errResponse
+			}
+
+			args[i] = value
+		}
+
+		response := handlerValue.Call(args)[0].Interface()
+		if response == nil {
+			return nil
+		}
+
+		return response.(Response)
+	}, options...)
+
+	return nil
+}
+
+// resolve calls the provider for a type. An error response for the request is
+// returned if the value cannot be provided.
+func (server *SimpleServer) resolve(request RequestResponder, t reflect.Type) (reflect.Value, Response) {
+	provider, ok := server.providers[t]
+	if !ok {
+		return reflect.Value{}, request.NewErrorResponse(InternalError,
+			fmt.Sprintf("No provider for %s.", t))
+	}
+
+	results := provider.Call(nil)
+	if len(results) == 2 && !results[1].IsNil() {
+		return reflect.Value{}, request.NewServerErrorResponse(
+			results[1].Interface().(error))
+	}
+
+	return results[0], nil
+}
@@ -0,0 +1,77 @@
+package jsonrpc_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+type testDB struct {
+	name string
+}
+
+type testLogger struct{}
+
+func TestSimpleServer_Provide(t *testing.T) {
+	server := jsonrpc.NewSimpleServer()
+
+	assert.NoError(t, server.Provide(func() *testDB { return nil }))
+	assert.NoError(t, server.Provide(func() (*testDB, error) { return nil, nil }))
+	assert.EqualError(t, server.Provide(nil), "Provider must be a func() T or func() (T, error)")
+	assert.EqualError(t, server.Provide(123), "Provider must be a func() T or func() (T, error)")
+	assert.EqualError(t, server.Provide(func(int) *testDB { return nil }), "Provider must be a func() T or func() (T, error)")
+	assert.EqualError(t, server.Provide(func() (*testDB, int) { return nil, 0 }), "Provider must be a func() T or func() (T, error)")
+}
+
+func TestSimpleServer_SetInjectedHandler(t *testing.T) {
+	handler := func(request jsonrpc.RequestResponder, db *testDB) jsonrpc.Response {
+		return request.NewSuccessResponse(db.name)
+	}
+
+	t.Run("InvalidHandler", func(t *testing.T) {
+		server := jsonrpc.NewSimpleServer()
+		message := "Handler must be a func(RequestResponder, ...) Response"
+
+		assert.EqualError(t, server.SetInjectedHandler("foo", nil), message)
+		assert.EqualError(t, server.SetInjectedHandler("foo", func() jsonrpc.Response { return nil }), message)
+		assert.EqualError(t, server.SetInjectedHandler("foo", func(*testDB) jsonrpc.Response { return nil }), message)
+		assert.EqualError(t, server.SetInjectedHandler("foo", func(jsonrpc.RequestResponder) {}), message)
+	})
+
+	t.Run("Injected", func(t *testing.T) {
+		server := jsonrpc.NewSimpleServer()
+		assert.NoError(t, server.SetInjectedHandler("foo", handler))
+		assert.NoError(t, server.Provide(func() *testDB { return &testDB{"main"} }))
+
+		responses := server.Handle([]byte(`{"jsonrpc": "2.0", "method": "foo", "id": 1}`))
+		assert.Equal(t, jsonrpc.Responses{jsonrpc.NewSuccessResponse(1.0, "main")}, responses)
+	})
+
+	t.Run("MissingProvider", func(t *testing.T) {
+		server := jsonrpc.NewSimpleServer()
+		assert.NoError(t, server.SetInjectedHandler("foo",
+			func(request jsonrpc.RequestResponder, logger *testLogger) jsonrpc.Response {
+				return request.NewSuccessResponse(nil)
+			}))
+
+		responses := server.Handle([]byte(`{"jsonrpc": "2.0", "method": "foo", "id": 1}`))
+		assert.Equal(t, jsonrpc.Responses{
+			jsonrpc.NewErrorResponse(1.0, jsonrpc.InternalError, "No provider for *jsonrpc_test.testLogger."),
+		}, responses)
+	})
+
+	t.Run("ProviderError", func(t *testing.T) {
+		server := jsonrpc.NewSimpleServer()
+		assert.NoError(t, server.SetInjectedHandler("foo", handler))
+		assert.NoError(t, server.Provide(func() (*testDB, error) {
+			return nil, errors.New("database is down")
+		}))
+
+		responses := server.Handle([]byte(`{"jsonrpc": "2.0", "method": "foo", "id": 1}`))
+		assert.Equal(t, jsonrpc.Responses{
+			jsonrpc.NewErrorResponse(1.0, jsonrpc.ServerError, "database is down"),
+		}, responses)
+	})
+}
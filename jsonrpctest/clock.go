@@ -0,0 +1,134 @@
+// Package jsonrpctest provides utilities for testing code that uses the
+// jsonrpc package.
+package jsonrpctest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+)
+
+// Clock is a jsonrpc.Clock that only moves when it is told to. This allows
+// tests for uptime, cache expiry and other time windows to run instantly:
+//
+//     clock := jsonrpctest.NewClock(time.Now())
+//     server.SetClock(clock)
+//
+//     clock.Advance(time.Minute)
+//     server.Uptime() // 1m0s
+//
+// Timers fire when Advance or Set moves the clock to (or past) their time. A
+// test can wait until Timers counts the timer it expects before moving the
+// clock, so that the clock does not move before the timer is started.
+//
+// It is safe to use concurrently.
+type Clock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*timer
+}
+
+// timer is a jsonrpc.Timer that fires when its clock reaches at.
+type timer struct {
+	clock *Clock
+	at    time.Time
+	c     chan time.Time
+}
+
+// NewClock creates a clock that is stopped at now.
+func NewClock(now time.Time) *Clock {
+	return &Clock{
+		now: now,
+	}
+}
+
+// Now returns the current time of the clock.
+func (clock *Clock) Now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	return clock.now
+}
+
+// Advance moves the clock forward by d and fires the timers that are due.
+func (clock *Clock) Advance(d time.Duration) {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	clock.now = clock.now.Add(d)
+	clock.fire()
+}
+
+// Set moves the clock to an exact time, which may be in the past. The timers
+// that are due are fired.
+func (clock *Clock) Set(now time.Time) {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	clock.now = now
+	clock.fire()
+}
+
+// NewTimer creates a timer that fires once the clock has moved forward by d.
+func (clock *Clock) NewTimer(d time.Duration) jsonrpc.Timer {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	timer := &timer{
+		clock: clock,
+		at:    clock.now.Add(d),
+		c:     make(chan time.Time, 1),
+	}
+
+	if d <= 0 {
+		timer.c <- clock.now
+	} else {
+		clock.timers = append(clock.timers, timer)
+	}
+
+	return timer
+}
+
+// Timers returns the number of timers that have not fired or been stopped.
+func (clock *Clock) Timers() int {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	return len(clock.timers)
+}
+
+// fire sends the time to the timers that are due. mutex must be locked.
+func (clock *Clock) fire() {
+	var waiting []*timer
+	for _, timer := range clock.timers {
+		if timer.at.After(clock.now) {
+			waiting = append(waiting, timer)
+			continue
+		}
+
+		timer.c <- clock.now
+	}
+
+	clock.timers = waiting
+}
+
+func (timer *timer) C() <-chan time.Time {
+	return timer.c
+}
+
+func (timer *timer) Stop() bool {
+	clock := timer.clock
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	for i, other := range clock.timers {
+		if other == timer {
+			clock.timers = append(clock.timers[:i], clock.timers[i+1:]...)
+
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,81 @@
+package jsonrpctest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/elliotchance/jsonrpc/jsonrpctest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := jsonrpctest.NewClock(start)
+	assert.Implements(t, (*jsonrpc.Clock)(nil), clock)
+
+	assert.Equal(t, start, clock.Now())
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Minute), clock.Now())
+
+	clock.Set(start)
+	assert.Equal(t, start, clock.Now())
+}
+
+func TestClock_NewTimer(t *testing.T) {
+	clock := jsonrpctest.NewClock(time.Unix(1000, 0))
+
+	timer := clock.NewTimer(time.Minute)
+	stopped := clock.NewTimer(time.Minute)
+	assert.Equal(t, 2, clock.Timers())
+
+	assert.True(t, stopped.Stop())
+	assert.False(t, stopped.Stop())
+
+	clock.Advance(59 * time.Second)
+	assert.Len(t, timer.C(), 0)
+
+	clock.Advance(time.Second)
+	assert.Equal(t, time.Unix(1060, 0), <-timer.C())
+	assert.Len(t, stopped.C(), 0)
+	assert.Equal(t, 0, clock.Timers())
+	assert.False(t, timer.Stop())
+
+	// A timer that is already due fires straight away.
+	assert.Equal(t, time.Unix(1060, 0), <-clock.NewTimer(0).C())
+}
+
+func TestClock_Uptime(t *testing.T) {
+	clock := jsonrpctest.NewClock(time.Now())
+	server := jsonrpc.NewSimpleServer()
+	server.SetClock(clock)
+
+	assert.Equal(t, time.Duration(0), server.Uptime())
+
+	clock.Advance(time.Hour)
+	assert.Equal(t, time.Hour, server.Uptime())
+}
+
+func TestClock_Cache(t *testing.T) {
+	calls := 0
+	server := jsonrpc.NewSimpleServer()
+	server.SetHandler("count", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		calls++
+
+		return request.NewSuccessResponse(calls)
+	})
+
+	clock := jsonrpctest.NewClock(time.Now())
+	server.SetClock(clock)
+	server.SetCache("count", time.Minute, 0)
+
+	request := []byte(`{"jsonrpc": "2.0", "method": "count", "id": 1}`)
+	assert.Equal(t, 1, server.Handle(request)[0].Result())
+
+	clock.Advance(59 * time.Second)
+	assert.Equal(t, 1, server.Handle(request)[0].Result())
+
+	clock.Advance(time.Second)
+	assert.Equal(t, 2, server.Handle(request)[0].Result())
+}
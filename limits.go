@@ -0,0 +1,191 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// ErrMemoryBudgetExceeded is returned by MemoryBudget.Allocate when there is
+// not enough budget remaining.
+var ErrMemoryBudgetExceeded = errors.New("Memory budget exceeded")
+
+// The State key that holds the *MemoryBudget of a request.
+const memoryBudgetKey = "jsonrpc.memoryBudget"
+
+// Limits guard against handlers that use too many resources, which is
+// important for servers shared by many tenants. See SetLimits.
+//
+// A handler that breaks a limit receives a ServerError response and is
+// counted by LimitViolations. A zero value means there is no limit.
+type Limits struct {
+	// MaxDuration is the longest time a handler can take to respond. The
+	// handler cannot be stopped, so it will continue to run in the
+	// background but its response is discarded. The context of the request
+	// (see SetContextHandler) is cancelled at the same time.
+	MaxDuration time.Duration
+
+	// MaxResultSize is the largest size of the JSON encoded result, in bytes.
+	MaxResultSize int
+
+	// MemoryBudget is the number of bytes the handler can allocate. The
+	// budget is cooperative, handlers must use RequestMemoryBudget to track
+	// their allocations.
+	MemoryBudget int64
+}
+
+// MemoryBudget tracks the memory allocated by a handler. See Limits.
+//
+// A nil *MemoryBudget is unlimited.
+type MemoryBudget struct {
+	remaining int64
+	exceeded  int32
+}
+
+// RequestMemoryBudget returns the memory budget for a request. It will be nil
+// (unlimited) if the method does not have a MemoryBudget limit:
+//
+//     budget := jsonrpc.RequestMemoryBudget(request)
+//     if err := budget.Allocate(int64(len(rows)) * rowSize); err != nil {
+//         return request.NewServerErrorResponse(err)
+//     }
+func RequestMemoryBudget(request Request) *MemoryBudget {
+	budget, _ := request.State(memoryBudgetKey).(*MemoryBudget)
+
+	return budget
+}
+
+// Allocate uses bytes from the budget. ErrMemoryBudgetExceeded is returned if
+// there is not enough remaining, in which case the handler should stop. The
+// response will be a ServerError even if the handler ignores the error.
+func (budget *MemoryBudget) Allocate(bytes int64) error {
+	if budget == nil {
+		return nil
+	}
+
+	if atomic.AddInt64(&budget.remaining, -bytes) < 0 {
+		atomic.StoreInt32(&budget.exceeded, 1)
+
+		return ErrMemoryBudgetExceeded
+	}
+
+	return nil
+}
+
+// Release returns bytes to the budget when they are no longer used.
+func (budget *MemoryBudget) Release(bytes int64) {
+	if budget != nil {
+		atomic.AddInt64(&budget.remaining, bytes)
+	}
+}
+
+// Remaining is the number of bytes that can still be allocated.
+func (budget *MemoryBudget) Remaining() int64 {
+	if budget == nil {
+		return math.MaxInt64
+	}
+
+	return atomic.LoadInt64(&budget.remaining)
+}
+
+// SetLimits sets the resource limits for every call to a method. Passing the
+// zero value of Limits removes the limits.
+func (server *SimpleServer) SetLimits(methodName string, limits Limits) {
+	if limits == (Limits{}) {
+		delete(server.limits, methodName)
+		return
+	}
+
+	if server.limits == nil {
+		server.limits = make(map[string]Limits)
+	}
+
+	server.limits[methodName] = limits
+}
+
+// LimitViolations is the number of calls that broke their Limits.
+func (server *SimpleServer) LimitViolations() uint64 {
+	return atomic.LoadUint64(&server.totalLimitViolations)
+}
+
+// limitHandler wraps the handler to enforce the limits of the method, if it
+// has any.
+func (server *SimpleServer) limitHandler(methodName string, handler RequestHandler) RequestHandler {
+	limits, ok := server.limits[methodName]
+	if !ok {
+		return handler
+	}
+
+	return func(request RequestResponder) Response {
+		var budget *MemoryBudget
+		if limits.MemoryBudget > 0 {
+			budget = &MemoryBudget{remaining: limits.MemoryBudget}
+			request = WithState(request, State{memoryBudgetKey: budget})
+		}
+
+		var response Response
+		ok := true
+		if limits.MaxDuration > 0 {
+			ctx, cancel := context.WithTimeout(RequestContext(request), limits.MaxDuration)
+			defer cancel()
+
+			request = WithState(request, State{contextKey: ctx})
+			response, ok = server.callWithTimeout(handler, request, limits.MaxDuration)
+		} else {
+			response = handler(request)
+		}
+
+		violation := ""
+		switch {
+		case !ok:
+			violation = "Timeout exceeded."
+
+		case budget != nil && atomic.LoadInt32(&budget.exceeded) != 0:
+			violation = "Memory budget exceeded."
+
+		case limits.MaxResultSize > 0 && response.ErrorCode() == Success:
+			if data, err := json.Marshal(response.Result()); err == nil && len(data) > limits.MaxResultSize {
+				violation = "Result is too large."
+			}
+		}
+
+		if violation != "" {
+			atomic.AddUint64(&server.totalLimitViolations, 1)
+
+			return request.NewErrorResponse(ServerError, violation)
+		}
+
+		return response
+	}
+}
+
+// callWithTimeout returns false if the handler does not respond in time.
+func (server *SimpleServer) callWithTimeout(handler RequestHandler, request RequestResponder, timeout time.Duration) (Response, bool) {
+	// The timer is started first so that a handler cannot move a test clock
+	// before it exists.
+	timer := server.newTimer(timeout)
+	defer timer.Stop()
+
+	done := make(chan Response, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				server.reportPanic(request, r)
+				done <- request.NewErrorResponse(ServerError, "")
+			}
+		}()
+
+		done <- handler(request)
+	}()
+
+	select {
+	case response := <-done:
+		return response, true
+
+	case <-timer.C():
+		return nil, false
+	}
+}
@@ -0,0 +1,76 @@
+package jsonrpc_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/elliotchance/jsonrpc/jsonrpctest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimpleServer_SetLimits(t *testing.T) {
+	clock := jsonrpctest.NewClock(time.Unix(1000, 0))
+	release := make(chan struct{})
+	defer close(release)
+
+	server := jsonrpc.NewSimpleServer()
+	server.SetClock(clock)
+
+	// sleep moves the clock forward. A handler that takes any time does not
+	// finish until the test is over, so it can only time out.
+	server.SetHandler("sleep", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		duration := time.Duration(request.Params().([]interface{})[0].(float64)) * time.Millisecond
+		clock.Advance(duration)
+		if duration > 0 {
+			<-release
+		}
+
+		return request.NewSuccessResponse(true)
+	})
+	server.SetHandler("repeat", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponse(strings.Repeat("a", int(request.Params().([]interface{})[0].(float64))))
+	})
+	server.SetHandler("allocate", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		budget := jsonrpc.RequestMemoryBudget(request)
+		budget.Allocate(int64(request.Params().([]interface{})[0].(float64)))
+
+		return request.NewSuccessResponse(budget.Remaining())
+	})
+	server.SetHandler("panic", forcePanic)
+
+	server.SetLimits("sleep", jsonrpc.Limits{MaxDuration: 20 * time.Millisecond})
+	server.SetLimits("repeat", jsonrpc.Limits{MaxResultSize: 10})
+	server.SetLimits("allocate", jsonrpc.Limits{MemoryBudget: 100})
+	server.SetLimits("panic", jsonrpc.Limits{MaxDuration: time.Second})
+
+	for testName, test := range map[string]struct {
+		request string
+		result  interface{}
+		err     string
+	}{
+		"InTime":           {`{"jsonrpc":"2.0","method":"sleep","params":[0],"id":1}`, true, ""},
+		"Timeout":          {`{"jsonrpc":"2.0","method":"sleep","params":[100],"id":1}`, nil, "Timeout exceeded."},
+		"SmallResult":      {`{"jsonrpc":"2.0","method":"repeat","params":[8],"id":1}`, "aaaaaaaa", ""},
+		"LargeResult":      {`{"jsonrpc":"2.0","method":"repeat","params":[9],"id":1}`, nil, "Result is too large."},
+		"WithinBudget":     {`{"jsonrpc":"2.0","method":"allocate","params":[60],"id":1}`, int64(40), ""},
+		"BudgetExceeded":   {`{"jsonrpc":"2.0","method":"allocate","params":[101],"id":1}`, nil, "Memory budget exceeded."},
+		"PanicWithTimeout": {`{"jsonrpc":"2.0","method":"panic","id":1}`, nil, "Server error"},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			response := server.Handle([]byte(test.request))[0]
+			assert.Equal(t, test.result, response.Result())
+			assert.Equal(t, test.err, response.ErrorMessage())
+		})
+	}
+
+	assert.Equal(t, uint64(3), server.LimitViolations())
+
+	t.Run("Unlimited", func(t *testing.T) {
+		server.SetLimits("allocate", jsonrpc.Limits{})
+
+		response := server.Handle([]byte(`{"jsonrpc":"2.0","method":"allocate","params":[1000],"id":1}`))[0]
+		assert.Equal(t, jsonrpc.Success, response.ErrorCode())
+	})
+}
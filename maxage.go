@@ -0,0 +1,127 @@
+package jsonrpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewSuccessResponseWithMaxAge creates a successful response, like
+// NewSuccessResponse, that also carries a hint for how long the result can be
+// reused:
+//
+//     return request.NewSuccessResponseWithMaxAge(rates, 30*time.Second)
+//
+// The hint is not sent as part of the JSON-RPC response. Instead it is used by
+// the server in two places:
+//
+// 1. If caching has been enabled for the method with SetCache, the result is
+// cached for maxAge instead of the ttl of the method. A maxAge of zero means
+// that the result will not be cached at all.
+//
+// 2. ServeHTTP sends a "Cache-Control: max-age=N" header so that browsers and
+// proxies can cache the response. A result served from the cache will have its
+// max-age reduced by the time it has already spent in the cache.
+//
+// HTTPTransport reads the header back, so the response received by a Client
+// has the max-age hint too (rounded down to whole seconds). It replaces the ttl
+// of Client.SetCache for that result.
+func NewSuccessResponseWithMaxAge(id interface{}, result interface{}, maxAge time.Duration) Response {
+	if maxAge < 0 {
+		maxAge = 0
+	}
+
+	return &response{
+		ResponseVersion: "2.0",
+		ResponseId:      id,
+		ResponseResult:  result,
+		maxAge:          maxAge,
+		hasMaxAge:       true,
+	}
+}
+
+// ResponseMaxAge returns the max-age hint that was attached to a response with
+// NewSuccessResponseWithMaxAge. ok will be false if the response does not have
+// a hint.
+func ResponseMaxAge(r Response) (maxAge time.Duration, ok bool) {
+	if response, isResponse := r.(*response); isResponse && response.hasMaxAge {
+		return response.maxAge, true
+	}
+
+	return 0, false
+}
+
+// copyCachedResponse copies a cached response for the request. Any max-age
+// hint is reduced by the time the response has spent in the cache.
+func copyCachedResponse(request RequestResponder, cached Response, age time.Duration) Response {
+	maxAge, ok := ResponseMaxAge(cached)
+	if !ok {
+		return copyResponse(request, cached)
+	}
+
+	return request.NewSuccessResponseWithMaxAge(cached.Result(), maxAge-age)
+}
+
+// setCacheControl sends the Cache-Control header for the responses of a HTTP
+// request. The header is only sent when every response has a max-age hint, and
+// the smallest hint is used for a batch.
+func setCacheControl(w http.ResponseWriter, responses Responses) {
+	var maxAge time.Duration
+	for i, response := range responses {
+		age, ok := ResponseMaxAge(response)
+		if !ok {
+			return
+		}
+
+		if i == 0 || age < maxAge {
+			maxAge = age
+		}
+	}
+
+	if len(responses) > 0 {
+		w.Header().Set("Cache-Control",
+			fmt.Sprintf("max-age=%d", int64(maxAge/time.Second)))
+	}
+}
+
+type maxAgeKey struct{}
+
+// maxAgeHint holds the max-age that a transport received for a call.
+type maxAgeHint struct {
+	maxAge time.Duration
+	ok     bool
+}
+
+// SetResponseMaxAge records the max-age hint that a transport received with
+// the response to a call, such as from a HTTP Cache-Control header. ctx is the
+// context passed to RoundTrip. It is useful for custom transports, and does
+// nothing if ctx is not from a Client. See NewSuccessResponseWithMaxAge.
+func SetResponseMaxAge(ctx context.Context, maxAge time.Duration) {
+	if hint, ok := ctx.Value(maxAgeKey{}).(*maxAgeHint); ok {
+		hint.maxAge, hint.ok = maxAge, true
+	}
+}
+
+// parseCacheControl returns the max-age of a Cache-Control header. no-store and
+// no-cache are treated as a max-age of zero.
+func parseCacheControl(header string) (maxAge time.Duration, ok bool) {
+	for _, directive := range strings.Split(header, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+
+		switch {
+		case directive == "no-store" || directive == "no-cache":
+			return 0, true
+
+		case strings.HasPrefix(directive, "max-age="):
+			seconds, err := strconv.ParseInt(strings.TrimPrefix(directive, "max-age="), 10, 64)
+			if err == nil && seconds >= 0 {
+				maxAge, ok = time.Duration(seconds)*time.Second, true
+			}
+		}
+	}
+
+	return maxAge, ok
+}
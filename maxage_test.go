@@ -0,0 +1,142 @@
+package jsonrpc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/elliotchance/jsonrpc/jsonrpctest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMaxAgeTestServer(calls *uint64) *jsonrpc.SimpleServer {
+	server := jsonrpc.NewSimpleServer()
+	server.SetHandler("rates", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponseWithMaxAge(
+			float64(atomic.AddUint64(calls, 1)), 30*time.Second)
+	})
+	server.SetHandler("live", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponseWithMaxAge(
+			float64(atomic.AddUint64(calls, 1)), 0)
+	})
+	server.SetHandler("plain", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponse(float64(atomic.AddUint64(calls, 1)))
+	})
+
+	return server
+}
+
+func TestResponseMaxAge(t *testing.T) {
+	t.Run("WithHint", func(t *testing.T) {
+		maxAge, ok := jsonrpc.ResponseMaxAge(
+			jsonrpc.NewSuccessResponseWithMaxAge(1, "foo", time.Minute))
+
+		assert.True(t, ok)
+		assert.Equal(t, time.Minute, maxAge)
+	})
+
+	t.Run("WithoutHint", func(t *testing.T) {
+		_, ok := jsonrpc.ResponseMaxAge(jsonrpc.NewSuccessResponse(1, "foo"))
+
+		assert.False(t, ok)
+	})
+
+	t.Run("NotEncoded", func(t *testing.T) {
+		response := jsonrpc.NewSuccessResponseWithMaxAge(1, "foo", time.Minute)
+
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":"foo"}`, response.String())
+	})
+}
+
+func TestSimpleServer_MaxAgeCache(t *testing.T) {
+	t.Run("OverridesTTL", func(t *testing.T) {
+		calls := uint64(0)
+		clock := jsonrpctest.NewClock(time.Unix(1000, 0))
+		server := newMaxAgeTestServer(&calls)
+		server.SetClock(clock)
+		server.SetCache("rates", time.Second, 0)
+
+		server.Handle([]byte(`{"jsonrpc": "2.0", "method": "rates", "id": 1}`))
+		clock.Advance(10 * time.Second)
+		r := server.Handle([]byte(`{"jsonrpc": "2.0", "method": "rates", "id": 2}`))
+
+		assert.Equal(t, uint64(1), atomic.LoadUint64(&calls))
+		maxAge, ok := jsonrpc.ResponseMaxAge(r[0])
+		assert.True(t, ok)
+		assert.Equal(t, 20*time.Second, maxAge)
+
+		clock.Advance(20 * time.Second)
+		server.Handle([]byte(`{"jsonrpc": "2.0", "method": "rates", "id": 3}`))
+
+		assert.Equal(t, uint64(2), atomic.LoadUint64(&calls))
+	})
+
+	t.Run("ZeroIsNotCached", func(t *testing.T) {
+		calls := uint64(0)
+		server := newMaxAgeTestServer(&calls)
+		server.SetCache("live", time.Minute, 0)
+
+		server.Handle([]byte(`{"jsonrpc": "2.0", "method": "live", "id": 1}`))
+		server.Handle([]byte(`{"jsonrpc": "2.0", "method": "live", "id": 2}`))
+
+		assert.Equal(t, uint64(2), atomic.LoadUint64(&calls))
+	})
+
+	t.Run("NotCachedWithoutSetCache", func(t *testing.T) {
+		calls := uint64(0)
+		server := newMaxAgeTestServer(&calls)
+
+		server.Handle([]byte(`{"jsonrpc": "2.0", "method": "rates", "id": 1}`))
+		server.Handle([]byte(`{"jsonrpc": "2.0", "method": "rates", "id": 2}`))
+
+		assert.Equal(t, uint64(2), atomic.LoadUint64(&calls))
+	})
+}
+
+func TestSimpleServer_ServeHTTPCacheControl(t *testing.T) {
+	for name, test := range map[string]struct {
+		body     string
+		expected string
+	}{
+		"Single": {
+			body:     `{"jsonrpc":"2.0","method":"rates","id":1}`,
+			expected: "max-age=30",
+		},
+		"Zero": {
+			body:     `{"jsonrpc":"2.0","method":"live","id":1}`,
+			expected: "max-age=0",
+		},
+		"NoHint": {
+			body:     `{"jsonrpc":"2.0","method":"plain","id":1}`,
+			expected: "",
+		},
+		"BatchUsesSmallest": {
+			body:     `[{"jsonrpc":"2.0","method":"rates","id":1},{"jsonrpc":"2.0","method":"live","id":2}]`,
+			expected: "max-age=0",
+		},
+		"BatchWithoutHint": {
+			body:     `[{"jsonrpc":"2.0","method":"rates","id":1},{"jsonrpc":"2.0","method":"plain","id":2}]`,
+			expected: "",
+		},
+		"Error": {
+			body:     `{"jsonrpc":"2.0","method":"missing","id":1}`,
+			expected: "",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			calls := uint64(0)
+			server := newMaxAgeTestServer(&calls)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.body))
+			server.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, test.expected, w.Header().Get("Cache-Control"))
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package jsonrpc
+
+// HandleMessage handles a raw JSON-RPC payload and returns the encoded
+// responses that should be sent back. This is the building block for serving
+// JSON-RPC over message buses, where each message is a payload and the reply
+// is published as another message.
+//
+// nil is returned if there is nothing to send back, such as when all the
+// requests are notifications. A nil state is treated as an empty State.
+//
+// ServeNATS uses this to serve the requests published to a NATS subject.
+func (server *SimpleServer) HandleMessage(payload []byte, state State) []byte {
+	data, _, _ := server.handleMessage(payload, state)
+
+	return data
+}
+
+// handleMessage is HandleMessage that also returns the responses before they
+// were encoded, and what was learned about the payload.
+func (server *SimpleServer) handleMessage(payload []byte, state State) ([]byte, Responses, payloadInfo) {
+	if state == nil {
+		state = State{}
+	}
+
+	if !server.wireCapture.enabled() {
+		responses, info := server.handlePayload(payload, state)
+
+		return server.encodeResponses(info, responses), responses, info
+	}
+
+	received := server.now()
+	responses, info := server.handlePayload(payload, state)
+	data := server.encodeResponses(info, responses)
+	server.wireCapture.record(received, payload, data)
+
+	return data, responses, info
+}
+
+// payloadInfo is what was learned about a payload while it was handled.
+type payloadInfo struct {
+	// isBatch is true if the payload is an array of batchSize requests.
+	isBatch   bool
+	batchSize int
+
+	// methods are collected only while compression is enabled. See
+	// payloadMethods.
+	methods []string
+}
+
+// encodeResponses renders the responses for a payload. The JSON-RPC spec
+// requires that a batch is answered with an array, even if it only contains
+// one response. However, a single request (or a batch that could not be
+// processed at all, because it is empty or too large) is answered with a single
+// response object.
+//
+// nil is returned if there is nothing to send back.
+func (server *SimpleServer) encodeResponses(info payloadInfo, responses Responses) []byte {
+	if len(responses) == 0 {
+		if info.isBatch && info.batchSize == 0 && server.compliance.EmptyBatchReturnsEmptyArray {
+			return []byte("[]")
+		}
+
+		return nil
+	}
+
+	if info.isBatch && info.batchSize > 0 && !server.batchTooLarge(info.batchSize) {
+		return responses.Bytes()
+	}
+
+	return responses[0].Bytes()
+}
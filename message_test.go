@@ -0,0 +1,39 @@
+package jsonrpc_test
+
+import (
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimpleServer_HandleMessage(t *testing.T) {
+	t.Run("Single", func(t *testing.T) {
+		data := newTestServer().HandleMessage(
+			[]byte(`{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}`), nil)
+
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":7}`, string(data))
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		data := newTestServer().HandleMessage(
+			[]byte(`[{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}]`), nil)
+
+		assert.Equal(t, `[{"jsonrpc":"2.0","id":1,"result":7}]`, string(data))
+	})
+
+	t.Run("Notification", func(t *testing.T) {
+		data := newTestServer().HandleMessage(
+			[]byte(`{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4]}`), nil)
+
+		assert.Nil(t, data)
+	})
+
+	t.Run("State", func(t *testing.T) {
+		data := newTestServer().HandleMessage(
+			[]byte(`{"jsonrpc": "2.0", "method": "handlerWithState", "id": 1}`),
+			jsonrpc.State{"foo": "bar"})
+
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":"bar"}`, string(data))
+	})
+}
@@ -0,0 +1,93 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// The content type of the Prometheus text exposition format.
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// SetMetricsPath serves the metrics of the server (see WriteMetrics) from
+// ServeHTTP for GET requests to path, so a small service does not need a
+// second HTTP server just for scraping:
+//
+//     server.SetMetricsPath("/metrics")
+//     http.ListenAndServe(":8080", server)
+//
+// Requests to any other path are handled as JSON-RPC. An empty path (the
+// default) disables the metrics.
+func (server *SimpleServer) SetMetricsPath(path string) {
+	server.metricsPath = path
+}
+
+// serveMetrics writes the metrics if the request is for the metrics path.
+func (server *SimpleServer) serveMetrics(w http.ResponseWriter, r *http.Request) bool {
+	if server.metricsPath == "" || r.URL.Path != server.metricsPath || r.Method != http.MethodGet {
+		return false
+	}
+
+	w.Header().Set("Content-Type", metricsContentType)
+	server.WriteMetrics(w)
+
+	return true
+}
+
+// WriteMetrics writes the statistics of the server in the Prometheus text
+// exposition format. All of the metrics start with "jsonrpc_".
+func (server *SimpleServer) WriteMetrics(w io.Writer) error {
+	timings := server.RequestTimings()
+	buffer := bufio.NewWriter(w)
+
+	metric := func(name, kind, help string, values ...string) {
+		fmt.Fprintf(buffer, "# HELP jsonrpc_%s %s\n", name, help)
+		fmt.Fprintf(buffer, "# TYPE jsonrpc_%s %s\n", name, kind)
+		for _, value := range values {
+			fmt.Fprintf(buffer, "jsonrpc_%s%s\n", name, value)
+		}
+	}
+
+	metric("payloads_total", "counter", "Payloads received. A batch is one payload.",
+		fmt.Sprintf(" %d", server.TotalPayloads()))
+	metric("requests_total", "counter", "Requests received, including notifications.",
+		fmt.Sprintf(" %d", server.TotalRequests()))
+	metric("responses_total", "counter", "Responses by result.",
+		fmt.Sprintf(`{result="success"} %d`, server.TotalSuccessResponses()),
+		fmt.Sprintf(`{result="error"} %d`, server.TotalErrorResponses()))
+	metric("notifications_total", "counter", "Notifications handled by result.",
+		fmt.Sprintf(`{result="success"} %d`, server.TotalNotificationSuccesses()),
+		fmt.Sprintf(`{result="error"} %d`, server.TotalNotificationErrors()))
+	metric("active_requests", "gauge", "Requests being handled.",
+		fmt.Sprintf(" %d", server.CurrentActiveRequests()))
+	metric("limit_violations_total", "counter", "Handlers that broke their limits.",
+		fmt.Sprintf(" %d", server.LimitViolations()))
+	metric("queued_requests_total", "counter", "Requests that waited in a queue.",
+		fmt.Sprintf(" %d", timings.Queued))
+	metric("queue_wait_seconds_total", "counter", "Time requests waited in a queue.",
+		fmt.Sprintf(" %g", timings.TotalQueueWait.Seconds()))
+	metric("handled_requests_total", "counter", "Requests that called a handler.",
+		fmt.Sprintf(" %d", timings.Handled))
+	metric("handler_seconds_total", "counter", "Time spent in handlers, including middleware.",
+		fmt.Sprintf(" %g", timings.TotalHandlerTime.Seconds()))
+	metric("uptime_seconds", "gauge", "Time since the server started.",
+		fmt.Sprintf(" %g", server.Uptime().Seconds()))
+
+	if transports := server.transports(); len(transports) > 0 {
+		stats := server.TransportStats()
+		var payloads, responses []string
+		for _, transport := range transports {
+			payloads = append(payloads, fmt.Sprintf(`{transport=%q} %d`,
+				transport, stats[transport].Payloads))
+			responses = append(responses,
+				fmt.Sprintf(`{transport=%q,result="success"} %d`, transport, stats[transport].SuccessResponses),
+				fmt.Sprintf(`{transport=%q,result="error"} %d`, transport, stats[transport].ErrorResponses))
+		}
+
+		metric("transport_payloads_total", "counter", "Payloads received by transport.", payloads...)
+		metric("transport_responses_total", "counter", "Responses by transport and result.", responses...)
+	}
+
+	return buffer.Flush()
+}
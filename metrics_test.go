@@ -0,0 +1,102 @@
+package jsonrpc_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc/jsonrpctest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimpleServer_WriteMetrics(t *testing.T) {
+	clock := jsonrpctest.NewClock(time.Unix(1000, 0))
+	server := newTestServer()
+	server.SetClock(clock)
+	server.Handle([]byte(`[{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1},{"jsonrpc":"2.0","method":"missing","id":2}]`))
+	clock.Advance(90 * time.Second)
+
+	var buffer bytes.Buffer
+	assert.NoError(t, server.WriteMetrics(&buffer))
+
+	assert.Equal(t, `# HELP jsonrpc_payloads_total Payloads received. A batch is one payload.
+# TYPE jsonrpc_payloads_total counter
+jsonrpc_payloads_total 1
+# HELP jsonrpc_requests_total Requests received, including notifications.
+# TYPE jsonrpc_requests_total counter
+jsonrpc_requests_total 1
+# HELP jsonrpc_responses_total Responses by result.
+# TYPE jsonrpc_responses_total counter
+jsonrpc_responses_total{result="success"} 1
+jsonrpc_responses_total{result="error"} 1
+# HELP jsonrpc_notifications_total Notifications handled by result.
+# TYPE jsonrpc_notifications_total counter
+jsonrpc_notifications_total{result="success"} 0
+jsonrpc_notifications_total{result="error"} 0
+# HELP jsonrpc_active_requests Requests being handled.
+# TYPE jsonrpc_active_requests gauge
+jsonrpc_active_requests 0
+# HELP jsonrpc_limit_violations_total Handlers that broke their limits.
+# TYPE jsonrpc_limit_violations_total counter
+jsonrpc_limit_violations_total 0
+# HELP jsonrpc_queued_requests_total Requests that waited in a queue.
+# TYPE jsonrpc_queued_requests_total counter
+jsonrpc_queued_requests_total 0
+# HELP jsonrpc_queue_wait_seconds_total Time requests waited in a queue.
+# TYPE jsonrpc_queue_wait_seconds_total counter
+jsonrpc_queue_wait_seconds_total 0
+# HELP jsonrpc_handled_requests_total Requests that called a handler.
+# TYPE jsonrpc_handled_requests_total counter
+jsonrpc_handled_requests_total 1
+# HELP jsonrpc_handler_seconds_total Time spent in handlers, including middleware.
+# TYPE jsonrpc_handler_seconds_total counter
+jsonrpc_handler_seconds_total 0
+# HELP jsonrpc_uptime_seconds Time since the server started.
+# TYPE jsonrpc_uptime_seconds gauge
+jsonrpc_uptime_seconds 90
+`, buffer.String())
+}
+
+func TestSimpleServer_SetMetricsPath(t *testing.T) {
+	server := newTestServer()
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	t.Run("Disabled", func(t *testing.T) {
+		response, err := http.Get(httpServer.URL + "/metrics")
+		assert.NoError(t, err)
+		response.Body.Close()
+
+		assert.Equal(t, http.StatusMethodNotAllowed, response.StatusCode)
+	})
+
+	server.SetMetricsPath("/metrics")
+
+	t.Run("Enabled", func(t *testing.T) {
+		response, err := http.Get(httpServer.URL + "/metrics")
+		assert.NoError(t, err)
+		defer response.Body.Close()
+
+		body, err := io.ReadAll(response.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", response.Header.Get("Content-Type"))
+		assert.Contains(t, string(body), "jsonrpc_payloads_total 0\n")
+	})
+
+	t.Run("JSONRPC", func(t *testing.T) {
+		response, err := http.Post(httpServer.URL+"/metrics", "application/json",
+			strings.NewReader(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+		assert.NoError(t, err)
+		defer response.Body.Close()
+
+		body, err := io.ReadAll(response.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":3}`, strings.TrimSpace(string(body)))
+	})
+}
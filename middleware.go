@@ -0,0 +1,131 @@
+package jsonrpc
+
+// Middleware wraps the handling of a request. It can observe or replace the
+// request and the response, or stop the handler from being called at all by
+// not calling next. See Use.
+type Middleware func(next RequestHandler) RequestHandler
+
+// Use adds middleware that runs for every request that calls a handler, such
+// as for authentication, logging or metrics:
+//
+//     server.Use(func(next jsonrpc.RequestHandler) jsonrpc.RequestHandler {
+//         return func(request jsonrpc.RequestResponder) jsonrpc.Response {
+//             start := time.Now()
+//             response := next(request)
+//             log.Println(request.Method(), time.Since(start))
+//
+//             return response
+//         }
+//     })
+//
+// The middleware runs in the order it was added, so the first middleware sees
+// the request first and the response last. It runs before rate limits and
+// caching, so values added to the request with WithState (such as the user)
+// can be used by them.
+//
+// Middleware also runs for NotificationHandler methods, where the response is
+// discarded. Returning a response without calling next stops the notification
+// handler from being called. It runs for RawHandler methods too, but the
+// params of the request are not decoded. Middleware does not run for requests
+// that are rejected before a handler is found (such as a Method not found
+// error). A panic in middleware is recovered in the same way as a panic in a
+// handler.
+//
+// Use is not safe to call concurrently with handling requests.
+func (server *SimpleServer) Use(middleware ...Middleware) {
+	server.middleware = append(server.middleware, middleware...)
+}
+
+// HandlerOption configures a single method. See SetHandler.
+type HandlerOption func(options *handlerOptions)
+
+type handlerOptions struct {
+	middleware []Middleware
+}
+
+// WithMiddleware adds middleware that only runs for one method, so expensive
+// checks only run where they are needed:
+//
+//     server.SetHandler("deleteUser", deleteUser, jsonrpc.WithMiddleware(requireAdmin))
+//
+// It runs after the middleware added with Use, in the order it was given.
+func WithMiddleware(middleware ...Middleware) HandlerOption {
+	return func(options *handlerOptions) {
+		options.middleware = append(options.middleware, middleware...)
+	}
+}
+
+// WithDefaultState provides State values to a method when the request does
+// not already have a value for the key. They are visible to the handler and
+// the middleware added with WithMiddleware:
+//
+//     admin := server.Group("admin.", jsonrpc.WithDefaultState(jsonrpc.State{
+//         "audit": true,
+//     }))
+func WithDefaultState(state State) HandlerOption {
+	state = state.copy()
+
+	return func(options *handlerOptions) {
+		options.middleware = append([]Middleware{func(next RequestHandler) RequestHandler {
+			return func(request RequestResponder) Response {
+				return next(&defaultStateRequest{
+					RequestResponder: request,
+					defaults:         state,
+				})
+			}
+		}}, options.middleware...)
+	}
+}
+
+// defaultStateRequest adds a State layer underneath the State of another
+// request.
+type defaultStateRequest struct {
+	RequestResponder
+	defaults State
+}
+
+func (request *defaultStateRequest) State(key string) interface{} {
+	if value := request.RequestResponder.State(key); value != nil {
+		return value
+	}
+
+	return request.defaults[key]
+}
+
+// setHandlerOptions replaces the options of a method. handlerMutex must be
+// locked.
+func (server *SimpleServer) setHandlerOptions(methodName string, options []HandlerOption) {
+	var opts handlerOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	if len(opts.middleware) == 0 {
+		delete(server.methodMiddleware, methodName)
+		return
+	}
+
+	if server.methodMiddleware == nil {
+		server.methodMiddleware = make(map[string][]Middleware)
+	}
+
+	server.methodMiddleware[methodName] = opts.middleware
+}
+
+// applyMiddleware wraps the handler with all of the middleware for the method.
+func (server *SimpleServer) applyMiddleware(methodName string, handler RequestHandler) RequestHandler {
+	server.handlerMutex.RLock()
+	methodMiddleware := server.methodMiddleware[methodName]
+	server.handlerMutex.RUnlock()
+
+	return chainMiddleware(chainMiddleware(handler, methodMiddleware), server.middleware)
+}
+
+// chainMiddleware wraps the handler so that the first middleware runs first.
+func chainMiddleware(handler RequestHandler, middleware []Middleware) RequestHandler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+
+	return handler
+}
@@ -0,0 +1,190 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func recordMiddleware(name string, calls *[]string) jsonrpc.Middleware {
+	return func(next jsonrpc.RequestHandler) jsonrpc.RequestHandler {
+		return func(request jsonrpc.RequestResponder) jsonrpc.Response {
+			*calls = append(*calls, name+" before")
+			response := next(request)
+			*calls = append(*calls, name+" after")
+
+			return response
+		}
+	}
+}
+
+func TestSimpleServer_Use(t *testing.T) {
+	t.Run("Order", func(t *testing.T) {
+		var calls []string
+		server := newTestServer()
+		server.Use(recordMiddleware("a", &calls), recordMiddleware("b", &calls))
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+		assert.Equal(t, 3.0, responses[0].Result())
+		assert.Equal(t, []string{"a before", "b before", "b after", "a after"}, calls)
+	})
+
+	t.Run("ShortCircuit", func(t *testing.T) {
+		server := newTestServer()
+		server.Use(func(next jsonrpc.RequestHandler) jsonrpc.RequestHandler {
+			return func(request jsonrpc.RequestResponder) jsonrpc.Response {
+				return request.NewErrorResponse(jsonrpc.ServerError, "Unauthorized.")
+			}
+		})
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+		assert.Equal(t, "Unauthorized.", responses[0].ErrorMessage())
+		assert.Equal(t, uint64(1), server.TotalErrorResponses())
+	})
+
+	t.Run("WithState", func(t *testing.T) {
+		server := newTestServer()
+		server.Use(func(next jsonrpc.RequestHandler) jsonrpc.RequestHandler {
+			return func(request jsonrpc.RequestResponder) jsonrpc.Response {
+				return next(jsonrpc.WithState(request, jsonrpc.State{"user": "bob"}))
+			}
+		})
+		server.SetHandler("user", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+			return request.NewSuccessResponse(request.State("user"))
+		})
+		server.SetRateLimit("user", jsonrpc.RateLimit{
+			Limit:  1,
+			Window: time.Hour,
+			Key: func(request jsonrpc.Request) string {
+				return request.State("user").(string)
+			},
+		})
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"user","id":1}`))
+		assert.Equal(t, "bob", responses[0].Result())
+
+		responses = server.Handle([]byte(`{"jsonrpc":"2.0","method":"user","id":1}`))
+		assert.Equal(t, "Rate limit exceeded.", responses[0].ErrorMessage())
+	})
+
+	t.Run("MethodNotFound", func(t *testing.T) {
+		var calls []string
+		server := newTestServer()
+		server.Use(recordMiddleware("a", &calls))
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"foo","id":1}`))
+		assert.Equal(t, jsonrpc.MethodNotFound, responses[0].ErrorCode())
+		assert.Empty(t, calls)
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		server := newTestServer()
+		server.Use(func(next jsonrpc.RequestHandler) jsonrpc.RequestHandler {
+			return func(request jsonrpc.RequestResponder) jsonrpc.Response {
+				panic("oops")
+			}
+		})
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+		assert.Equal(t, jsonrpc.ServerError, responses[0].ErrorCode())
+	})
+
+	t.Run("NilResponse", func(t *testing.T) {
+		server := newTestServer()
+		server.Use(func(next jsonrpc.RequestHandler) jsonrpc.RequestHandler {
+			return func(request jsonrpc.RequestResponder) jsonrpc.Response {
+				return nil
+			}
+		})
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+		assert.Equal(t, jsonrpc.ServerError, responses[0].ErrorCode())
+	})
+
+	t.Run("RecoverPanics", func(t *testing.T) {
+		server := newTestServer()
+		server.Use(func(next jsonrpc.RequestHandler) jsonrpc.RequestHandler {
+			return func(request jsonrpc.RequestResponder) (response jsonrpc.Response) {
+				defer func() {
+					if r := recover(); r != nil {
+						response = request.NewErrorResponse(jsonrpc.ServerError, "Recovered.")
+					}
+				}()
+
+				return next(request)
+			}
+		})
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"panic","id":1}`))
+		assert.Equal(t, "Recovered.", responses[0].ErrorMessage())
+	})
+}
+
+func TestWithMiddleware(t *testing.T) {
+	t.Run("OnlyForMethod", func(t *testing.T) {
+		var calls []string
+		server := newTestServer()
+		server.Use(recordMiddleware("global", &calls))
+		server.SetHandler("sum", sum, jsonrpc.WithMiddleware(
+			recordMiddleware("a", &calls), recordMiddleware("b", &calls)))
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+		assert.Equal(t, []string{
+			"global before", "a before", "b before",
+			"b after", "a after", "global after",
+		}, calls)
+
+		calls = nil
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"subtract","params":[3,2],"id":1}`))
+		assert.Equal(t, []string{"global before", "global after"}, calls)
+	})
+
+	t.Run("Replaced", func(t *testing.T) {
+		var calls []string
+		server := newTestServer()
+		server.SetHandler("sum", sum, jsonrpc.WithMiddleware(recordMiddleware("a", &calls)))
+		server.SetHandler("sum", sum)
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+		assert.Empty(t, calls)
+	})
+
+	t.Run("ContextHandler", func(t *testing.T) {
+		var calls []string
+		server := newTestServer()
+		server.SetContextHandler("ctx", func(ctx context.Context, request jsonrpc.RequestResponder) jsonrpc.Response {
+			return request.NewSuccessResponse(true)
+		}, jsonrpc.WithMiddleware(recordMiddleware("a", &calls)))
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"ctx","id":1}`))
+		assert.Equal(t, []string{"a before", "a after"}, calls)
+	})
+
+	t.Run("InjectedHandler", func(t *testing.T) {
+		var calls []string
+		server := newTestServer()
+		err := server.SetInjectedHandler("injected", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+			return request.NewSuccessResponse(true)
+		}, jsonrpc.WithMiddleware(recordMiddleware("a", &calls)))
+		assert.NoError(t, err)
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"injected","id":1}`))
+		assert.Equal(t, []string{"a before", "a after"}, calls)
+	})
+}
+
+func TestWithDefaultState(t *testing.T) {
+	server := newTestServer()
+	server.SetHandler("region", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponse(request.State("region"))
+	}, jsonrpc.WithDefaultState(jsonrpc.State{"region": "us"}))
+
+	responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"region","id":1}`))
+	assert.Equal(t, "us", responses[0].Result())
+
+	responses = server.HandleWithState([]byte(`{"jsonrpc":"2.0","method":"region","id":1}`), jsonrpc.State{"region": "eu"})
+	assert.Equal(t, "eu", responses[0].Result())
+}
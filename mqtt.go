@@ -0,0 +1,44 @@
+package jsonrpc
+
+import "strings"
+
+// MQTTPublisher publishes a payload to an MQTT topic.
+type MQTTPublisher func(topic string, payload []byte) error
+
+// DefaultMQTTResponseTopic derives the response topic from the topic a request
+// was received on. A topic ending with "/request" will have that replaced with
+// "/response", otherwise "/response" is appended:
+//
+//     devices/abc/rpc/request -> devices/abc/rpc/response
+//     devices/abc/rpc         -> devices/abc/rpc/response
+//
+func DefaultMQTTResponseTopic(requestTopic string) string {
+	return strings.TrimSuffix(requestTopic, "/request") + "/response"
+}
+
+// HandleMQTT handles a single message received on an MQTT topic and publishes
+// the responses to the response topic for that client. It is intended to be
+// called from the message callback of an MQTT client that has subscribed to a
+// request topic pattern, such as "devices/+/rpc/request".
+//
+// If responseTopic is nil then DefaultMQTTResponseTopic is used. The topic the
+// request was received on is available to handlers through State:
+//
+//     request.State("mqtt.topic") // string
+//
+// Nothing is published for notifications.
+func (server *SimpleServer) HandleMQTT(topic string, payload []byte, publish MQTTPublisher, responseTopic func(requestTopic string) string) error {
+	if responseTopic == nil {
+		responseTopic = DefaultMQTTResponseTopic
+	}
+
+	data := server.HandleMessage(payload, State{
+		"mqtt.topic": topic,
+		transportKey: TransportMQTT,
+	})
+	if data == nil {
+		return nil
+	}
+
+	return publish(responseTopic(topic), data)
+}
@@ -0,0 +1,68 @@
+package jsonrpc_test
+
+import (
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultMQTTResponseTopic(t *testing.T) {
+	assert.Equal(t, "devices/abc/rpc/response",
+		jsonrpc.DefaultMQTTResponseTopic("devices/abc/rpc/request"))
+	assert.Equal(t, "devices/abc/rpc/response",
+		jsonrpc.DefaultMQTTResponseTopic("devices/abc/rpc"))
+}
+
+func TestSimpleServer_HandleMQTT(t *testing.T) {
+	t.Run("Response", func(t *testing.T) {
+		published := map[string]string{}
+		err := newTestServer().HandleMQTT("devices/abc/rpc/request",
+			[]byte(`{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}`),
+			func(topic string, payload []byte) error {
+				published[topic] = string(payload)
+
+				return nil
+			}, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"devices/abc/rpc/response": `{"jsonrpc":"2.0","id":1,"result":7}`,
+		}, published)
+	})
+
+	t.Run("Notification", func(t *testing.T) {
+		published := map[string]string{}
+		err := newTestServer().HandleMQTT("devices/abc/rpc/request",
+			[]byte(`{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4]}`),
+			func(topic string, payload []byte) error {
+				published[topic] = string(payload)
+
+				return nil
+			}, nil)
+
+		assert.NoError(t, err)
+		assert.Empty(t, published)
+	})
+
+	t.Run("State", func(t *testing.T) {
+		server := jsonrpc.NewSimpleServer()
+		server.SetHandler("topic", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+			return request.NewSuccessResponse(request.State("mqtt.topic"))
+		})
+
+		var response string
+		err := server.HandleMQTT("a/b",
+			[]byte(`{"jsonrpc": "2.0", "method": "topic", "id": 1}`),
+			func(topic string, payload []byte) error {
+				response = string(payload)
+
+				return nil
+			}, func(requestTopic string) string {
+				return "replies"
+			})
+
+		assert.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":"a/b"}`, response)
+	})
+}
@@ -0,0 +1,56 @@
+package jsonrpc
+
+import "io"
+
+// NATSSubscription receives the messages published to a NATS subject. It is
+// usually a small wrapper around a *nats.Subscription, see the README.
+type NATSSubscription interface {
+	// NextMessage blocks until the next message is received. io.EOF should be
+	// returned once the subscription has been closed.
+	NextMessage() (subject, reply string, data []byte, err error)
+}
+
+// NATSPublisher publishes a message to a subject. *nats.Conn implements this
+// without any changes.
+type NATSPublisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// ServeNATS handles the messages of a subscription until it is closed. The
+// response for each message is published to its reply subject, which is how
+// nats.Conn.Request (and other NATS clients) wait for a reply.
+//
+// Nothing is published for notifications or when the message does not have a
+// reply subject. The subject of the message is available to handlers through
+// State:
+//
+//     request.State("nats.subject") // string
+//
+// Subscriptions that use a queue group can be served by more than one process
+// to share the load.
+//
+// ServeNATS returns nil when NextMessage returns io.EOF, otherwise it returns
+// the first error from receiving or publishing.
+func (server *SimpleServer) ServeNATS(subscription NATSSubscription, publisher NATSPublisher) error {
+	for {
+		subject, reply, data, err := subscription.NextMessage()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		response := server.HandleMessage(data, State{
+			"nats.subject": subject,
+			transportKey:   TransportNATS,
+		})
+
+		if response != nil && reply != "" {
+			if err := publisher.Publish(reply, response); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package jsonrpc_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+type testNATSMessage struct {
+	subject, reply, data string
+}
+
+type testNATSSubscription struct {
+	messages []testNATSMessage
+	err      error
+}
+
+func (subscription *testNATSSubscription) NextMessage() (string, string, []byte, error) {
+	if len(subscription.messages) == 0 {
+		return "", "", nil, subscription.err
+	}
+
+	msg := subscription.messages[0]
+	subscription.messages = subscription.messages[1:]
+
+	return msg.subject, msg.reply, []byte(msg.data), nil
+}
+
+type testNATSPublisher struct {
+	published map[string]string
+	err       error
+}
+
+func (publisher *testNATSPublisher) Publish(subject string, data []byte) error {
+	if publisher.err != nil {
+		return publisher.err
+	}
+
+	publisher.published[subject] = string(data)
+
+	return nil
+}
+
+func TestSimpleServer_ServeNATS(t *testing.T) {
+	t.Run("PublishesReplies", func(t *testing.T) {
+		server := newTestServer()
+		server.SetHandler("subject", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+			return request.NewSuccessResponse([]interface{}{request.State("nats.subject"), jsonrpc.RequestTransport(request)})
+		})
+
+		subscription := &testNATSSubscription{
+			messages: []testNATSMessage{
+				{"rpc", "_INBOX.1", `{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}`},
+				{"rpc", "_INBOX.2", `[{"jsonrpc": "2.0", "method": "sum", "params": [1], "id": 2}]`},
+				{"rpc", "_INBOX.3", `{"jsonrpc": "2.0", "method": "notify_hello", "params": [7]}`},
+				{"rpc", "", `{"jsonrpc": "2.0", "method": "sum", "params": [1,2], "id": 4}`},
+				{"rpc.a", "_INBOX.5", `{"jsonrpc": "2.0", "method": "subject", "id": 5}`},
+			},
+			err: io.EOF,
+		}
+		publisher := &testNATSPublisher{published: map[string]string{}}
+		err := server.ServeNATS(subscription, publisher)
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"_INBOX.1": `{"jsonrpc":"2.0","id":1,"result":7}`,
+			"_INBOX.2": `[{"jsonrpc":"2.0","id":2,"result":1}]`,
+			"_INBOX.5": `{"jsonrpc":"2.0","id":5,"result":["rpc.a","nats"]}`,
+		}, publisher.published)
+		assert.Equal(t, jsonrpc.TransportStats{Payloads: 5, SuccessResponses: 4}, server.TransportStats()[jsonrpc.TransportNATS])
+	})
+
+	t.Run("ReceiveError", func(t *testing.T) {
+		subscription := &testNATSSubscription{err: errors.New("closed")}
+		err := newTestServer().ServeNATS(subscription, &testNATSPublisher{})
+
+		assert.EqualError(t, err, "closed")
+	})
+
+	t.Run("PublishError", func(t *testing.T) {
+		subscription := &testNATSSubscription{
+			messages: []testNATSMessage{
+				{"rpc", "_INBOX.1", `{"jsonrpc": "2.0", "method": "sum", "params": [1], "id": 1}`},
+			},
+			err: io.EOF,
+		}
+		err := newTestServer().ServeNATS(subscription, &testNATSPublisher{err: errors.New("no responders")})
+
+		assert.EqualError(t, err, "no responders")
+	})
+}
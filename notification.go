@@ -0,0 +1,139 @@
+package jsonrpc
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// NotificationHandler handles notifications for a method. Notifications do
+// not receive a response, so there is nothing to return.
+type NotificationHandler func(ctx context.Context, request Request)
+
+// SetNotificationHandler will register (or replace) a handler for
+// notifications (requests without an id) sent to a method. This is better
+// suited to event style methods than a RequestHandler because it does not
+// need to create a response that would be thrown away:
+//
+//     server.SetNotificationHandler("userLoggedIn", func(ctx context.Context, request jsonrpc.Request) {
+//         audit.Record(request.Params())
+//     })
+//
+// The handler is run in its own goroutine, so the server does not wait for it
+// to finish. ctx has the values of the context of the request (see
+// SetContextHandler), but it is not cancelled when the request is finished.
+// A panic in the handler is recovered. Notifications are counted in
+// TotalRequests, but not as a success or error. The middleware added with Use
+// runs before the handler, and can stop it from being called (see Use).
+//
+// Notifications are dropped while the server is in maintenance mode (see
+// SetMaintenance). Each handler holds one of the slots of
+// SetMaxConcurrentRequests until it finishes, and the notification is dropped
+// if a slot is not available in time.
+//
+// A method can also have a handler set with SetHandler, which will receive the
+// requests that have an id. Otherwise, requests with an id receive an Invalid
+// request error. A nil handler removes the notification handler.
+func (server *SimpleServer) SetNotificationHandler(methodName string, handler NotificationHandler) {
+	if handler != nil {
+		server.mustCheckMethodName(methodName)
+	}
+
+	server.handlerMutex.Lock()
+	defer server.handlerMutex.Unlock()
+
+	if handler == nil {
+		delete(server.notificationHandlers, methodName)
+		return
+	}
+
+	if server.notificationHandlers == nil {
+		server.notificationHandlers = make(map[string]NotificationHandler)
+	}
+
+	server.notificationHandlers[methodName] = handler
+}
+
+func (server *SimpleServer) notificationHandler(methodName string) NotificationHandler {
+	server.handlerMutex.RLock()
+	defer server.handlerMutex.RUnlock()
+
+	return server.notificationHandlers[methodName]
+}
+
+// dispatchNotification starts the NotificationHandler for the request and
+// returns true, if there is one.
+func (server *SimpleServer) dispatchNotification(request RequestResponder) bool {
+	if request.Id() != nil || request.Version() != "2.0" {
+		return false
+	}
+
+	handler := server.notificationHandler(request.Method())
+	if handler == nil {
+		return false
+	}
+
+	// There is no response to send back, so a notification that is rejected
+	// is dropped.
+	if server.inMaintenance(request.Method()) {
+		server.auditRejected(request, request.NewErrorResponse(ServerError,
+			"Server is in maintenance mode."), AuditCheck{
+			Name:    "maintenance",
+			Allowed: false,
+		})
+
+		return true
+	}
+
+	// The slot is held until the handler finishes, so that notifications
+	// cannot start more goroutines than SetMaxConcurrentRequests allows.
+	request, release := server.acquireSlot(request)
+	if release == nil {
+		server.auditRejected(request, request.NewErrorResponse(ServerError,
+			"Server is overloaded."))
+
+		return true
+	}
+
+	atomic.AddUint64(&server.totalRequests, 1)
+	atomic.AddUint64(&server.currentActiveRequests, 1)
+
+	var trail *auditTrail
+	if server.auditSink != nil {
+		trail = &auditTrail{request: request}
+		request = WithState(request, State{auditKey: trail})
+	}
+
+	// The middleware added with Use runs first, in the same way as for
+	// requests. Its response is only used for auditing.
+	run := chainMiddleware(func(request RequestResponder) Response {
+		handler(context.WithoutCancel(RequestContext(request)), request)
+
+		return request.NewSuccessResponse(nil)
+	}, server.middleware)
+
+	go func() {
+		start := server.now()
+		var response Response
+
+		defer func() {
+			if r := recover(); r != nil {
+				server.reportPanic(request, r)
+			}
+
+			// response is nil after a panic, which is audited as an error.
+			if trail != nil {
+				trail.mutex.Lock()
+				server.audit(request.Method(), nil, trail.request, start,
+					trail.checks, response)
+				trail.mutex.Unlock()
+			}
+
+			atomic.AddUint64(&server.currentActiveRequests, ^uint64(0))
+			release()
+		}()
+
+		response = run(request)
+	}()
+
+	return true
+}
@@ -0,0 +1,192 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimpleServer_SetNotificationHandler(t *testing.T) {
+	t.Run("Notification", func(t *testing.T) {
+		server := newTestServer()
+		received := make(chan interface{}, 1)
+		server.SetNotificationHandler("event", func(ctx context.Context, request jsonrpc.Request) {
+			received <- request.Params()
+		})
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"event","params":[1]}`))
+		assert.Empty(t, responses)
+
+		select {
+		case params := <-received:
+			assert.Equal(t, []interface{}{1.0}, params)
+		case <-time.After(time.Second):
+			t.Fatal("notification was not handled")
+		}
+
+		assert.Equal(t, uint64(1), server.TotalPayloads())
+		assert.Equal(t, uint64(1), server.TotalRequests())
+		assert.Equal(t, uint64(0), server.TotalNotificationSuccesses())
+		assert.Equal(t, uint64(0), server.TotalNotificationErrors())
+	})
+
+	t.Run("DoesNotBlock", func(t *testing.T) {
+		server := newTestServer()
+		release := make(chan bool)
+		defer close(release)
+		server.SetNotificationHandler("event", func(ctx context.Context, request jsonrpc.Request) {
+			<-release
+		})
+
+		responses := server.Handle([]byte(`[
+			{"jsonrpc":"2.0","method":"event"},
+			{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}
+		]`))
+
+		assert.Equal(t, `[{"jsonrpc":"2.0","id":1,"result":3}]`, responses.String())
+		assert.Equal(t, uint64(1), server.CurrentActiveRequests())
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		server := newTestServer()
+		done := make(chan bool)
+		server.SetNotificationHandler("event", func(ctx context.Context, request jsonrpc.Request) {
+			defer close(done)
+			panic("oops")
+		})
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"event"}`))
+		<-done
+
+		assert.Eventually(t, func() bool {
+			return server.CurrentActiveRequests() == 0
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("RequestWithId", func(t *testing.T) {
+		server := newTestServer()
+		server.SetNotificationHandler("event", func(ctx context.Context, request jsonrpc.Request) {})
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"event","id":1}`))
+		assert.Equal(t, jsonrpc.InvalidRequest, responses[0].ErrorCode())
+		assert.Equal(t, "Method only accepts notifications.", responses[0].ErrorMessage())
+		assert.Contains(t, server.Methods(), "event")
+	})
+
+	t.Run("WithRequestHandler", func(t *testing.T) {
+		server := newTestServer()
+		notified := make(chan bool, 1)
+		server.SetNotificationHandler("sum", func(ctx context.Context, request jsonrpc.Request) {
+			notified <- true
+		})
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+		assert.Equal(t, 3.0, responses[0].Result())
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2]}`))
+		assert.True(t, <-notified)
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		server := newTestServer()
+		received := make(chan context.Context, 1)
+		server.SetNotificationHandler("event", func(ctx context.Context, request jsonrpc.Request) {
+			received <- ctx
+		})
+
+		ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "foo"))
+		server.HandleContext(ctx, []byte(`{"jsonrpc":"2.0","method":"event"}`))
+		cancel()
+
+		handlerCtx := <-received
+		assert.Equal(t, "foo", handlerCtx.Value(ctxKey{}))
+		assert.NoError(t, handlerCtx.Err())
+	})
+
+	t.Run("Middleware", func(t *testing.T) {
+		server := newTestServer()
+		received := make(chan interface{}, 1)
+		server.Use(func(next jsonrpc.RequestHandler) jsonrpc.RequestHandler {
+			return func(request jsonrpc.RequestResponder) jsonrpc.Response {
+				return next(jsonrpc.WithState(request, jsonrpc.State{"user": "bob"}))
+			}
+		})
+		server.SetNotificationHandler("event", func(ctx context.Context, request jsonrpc.Request) {
+			received <- request.State("user")
+		})
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"event"}`))
+
+		select {
+		case user := <-received:
+			assert.Equal(t, "bob", user)
+		case <-time.After(time.Second):
+			t.Fatal("notification was not handled")
+		}
+	})
+
+	t.Run("MiddlewareRejects", func(t *testing.T) {
+		server := newTestServer()
+		called := make(chan bool, 1)
+		server.Use(func(next jsonrpc.RequestHandler) jsonrpc.RequestHandler {
+			return func(request jsonrpc.RequestResponder) jsonrpc.Response {
+				return request.NewErrorResponse(jsonrpc.ServerError, "Unauthorized.")
+			}
+		})
+		server.SetNotificationHandler("event", func(ctx context.Context, request jsonrpc.Request) {
+			called <- true
+		})
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"event"}`))
+		assert.Eventually(t, func() bool {
+			return server.CurrentActiveRequests() == 0
+		}, time.Second, time.Millisecond)
+		assert.Len(t, called, 0)
+	})
+
+	t.Run("Maintenance", func(t *testing.T) {
+		server := newTestServer()
+		called := make(chan bool, 1)
+		server.SetMaintenance(true)
+		server.SetNotificationHandler("event", func(ctx context.Context, request jsonrpc.Request) {
+			called <- true
+		})
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"event"}`))
+		assert.Equal(t, uint64(0), server.TotalRequests())
+		assert.Len(t, called, 0)
+	})
+
+	t.Run("Overloaded", func(t *testing.T) {
+		server := newTestServer()
+		server.SetMaxConcurrentRequests(1, 0)
+		release := make(chan bool)
+		started := make(chan bool, 2)
+		server.SetNotificationHandler("event", func(ctx context.Context, request jsonrpc.Request) {
+			started <- true
+			<-release
+		})
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"event"}`))
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"event"}`))
+		<-started
+		close(release)
+
+		assert.Eventually(t, func() bool {
+			return server.CurrentActiveRequests() == 0
+		}, time.Second, time.Millisecond)
+		assert.Equal(t, uint64(1), server.TotalRequests())
+		assert.Len(t, started, 0)
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		server := newTestServer()
+		server.SetNotificationHandler("event", func(ctx context.Context, request jsonrpc.Request) {})
+		server.SetNotificationHandler("event", nil)
+
+		assert.NotContains(t, server.Methods(), "event")
+	})
+}
@@ -0,0 +1,147 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+type openRPCDocument struct {
+	OpenRPC string          `json:"openrpc,omitempty"`
+	Info    *openRPCInfo    `json:"info,omitempty"`
+	Methods []openRPCMethod `json:"methods"`
+}
+
+type openRPCInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openRPCMethod struct {
+	Name        string                `json:"name"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Params      []openRPCContentDescr `json:"params"`
+	Result      *openRPCContentDescr  `json:"result,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+type openRPCContentDescr struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	Required    bool          `json:"required,omitempty"`
+	Schema      openRPCSchema `json:"schema"`
+}
+
+type openRPCSchema struct {
+	Type interface{} `json:"type,omitempty"`
+	Ref  string      `json:"$ref,omitempty"`
+}
+
+// newOpenRPCSchema is the opposite of typeName. Types that are not JSON types
+// are references to a schema with the same name.
+func newOpenRPCSchema(typeName string) openRPCSchema {
+	switch typeName {
+	case "":
+		return openRPCSchema{}
+
+	case "string", "number", "integer", "boolean", "object", "array", "null":
+		return openRPCSchema{Type: typeName}
+	}
+
+	return openRPCSchema{Ref: "#/components/schemas/" + typeName}
+}
+
+// typeName returns the type of the schema in the same form as ParamInfo.Type.
+// A reference such as "#/components/schemas/User" becomes "User". Schemas
+// that allow more than one type are not checked, so the type is empty.
+func (schema openRPCSchema) typeName() string {
+	if t, ok := schema.Type.(string); ok {
+		return t
+	}
+
+	if schema.Ref != "" {
+		return schema.Ref[strings.LastIndex(schema.Ref, "/")+1:]
+	}
+
+	return ""
+}
+
+// ParseOpenRPC reads the methods from an OpenRPC document. Only the parts of
+// the document that can be described by MethodInfo are used.
+func ParseOpenRPC(r io.Reader) ([]MethodInfo, error) {
+	var document openRPCDocument
+	if err := json.NewDecoder(r).Decode(&document); err != nil {
+		return nil, err
+	}
+
+	methods := make([]MethodInfo, len(document.Methods))
+	for i, method := range document.Methods {
+		description := method.Description
+		if description == "" {
+			description = method.Summary
+		}
+
+		info := MethodInfo{
+			Name:        method.Name,
+			Description: description,
+			Deprecated:  method.Deprecated,
+		}
+
+		for _, param := range method.Params {
+			info.Params = append(info.Params, ParamInfo{
+				Name:        param.Name,
+				Type:        param.Schema.typeName(),
+				Description: param.Description,
+				Required:    param.Required,
+			})
+		}
+
+		if method.Result != nil {
+			info.Result = method.Result.Schema.typeName()
+		}
+
+		methods[i] = info
+	}
+
+	return methods, nil
+}
+
+// newOpenRPCDocument describes methods as an OpenRPC document. This is the
+// opposite of ParseOpenRPC.
+func newOpenRPCDocument(methods []MethodInfo) openRPCDocument {
+	document := openRPCDocument{
+		OpenRPC: "1.2.6",
+		Info:    &openRPCInfo{Title: "JSON-RPC", Version: "1.0.0"},
+		Methods: make([]openRPCMethod, len(methods)),
+	}
+
+	for i, info := range methods {
+		method := openRPCMethod{
+			Name:        info.Name,
+			Description: info.Description,
+			Params:      []openRPCContentDescr{},
+			Deprecated:  info.Deprecated,
+		}
+
+		for _, param := range info.Params {
+			method.Params = append(method.Params, openRPCContentDescr{
+				Name:        param.Name,
+				Description: param.Description,
+				Required:    param.Required,
+				Schema:      newOpenRPCSchema(param.Type),
+			})
+		}
+
+		if info.Result != "" {
+			method.Result = &openRPCContentDescr{
+				Name:   "result",
+				Schema: newOpenRPCSchema(info.Result),
+			}
+		}
+
+		document.Methods[i] = method
+	}
+
+	return document
+}
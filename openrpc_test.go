@@ -0,0 +1,60 @@
+package jsonrpc_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+const testOpenRPC = `{
+	"openrpc": "1.2.6",
+	"info": {"title": "Example", "version": "1.0.0"},
+	"methods": [
+		{
+			"name": "getUser",
+			"summary": "Get a user.",
+			"params": [
+				{"name": "id", "required": true, "schema": {"type": "integer"}},
+				{"name": "fields", "schema": {"type": "array"}}
+			],
+			"result": {"name": "user", "schema": {"$ref": "#/components/schemas/User"}}
+		},
+		{
+			"name": "ping",
+			"description": "Checks the server.",
+			"deprecated": true,
+			"params": [],
+			"result": {"name": "pong", "schema": {"type": ["string", "null"]}}
+		}
+	]
+}`
+
+func TestParseOpenRPC(t *testing.T) {
+	methods, err := jsonrpc.ParseOpenRPC(strings.NewReader(testOpenRPC))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []jsonrpc.MethodInfo{
+		{
+			Name:        "getUser",
+			Description: "Get a user.",
+			Params: []jsonrpc.ParamInfo{
+				{Name: "id", Type: "integer", Required: true},
+				{Name: "fields", Type: "array"},
+			},
+			Result: "User",
+		},
+		{
+			Name:        "ping",
+			Description: "Checks the server.",
+			Deprecated:  true,
+		},
+	}, methods)
+}
+
+func TestParseOpenRPC_Invalid(t *testing.T) {
+	_, err := jsonrpc.ParseOpenRPC(strings.NewReader(`{"methods":`))
+
+	assert.Error(t, err)
+}
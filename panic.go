@@ -0,0 +1,34 @@
+package jsonrpc
+
+import (
+	"runtime/debug"
+)
+
+// PanicHook is called with a panic that was recovered from a handler. See
+// OnPanic.
+type PanicHook func(request Request, recovered interface{}, stack []byte)
+
+// OnPanic sets a function that is called when a panic is recovered, so that it
+// can be logged or reported to an error tracker:
+//
+//     server.OnPanic(func(request jsonrpc.Request, recovered interface{}, stack []byte) {
+//         log.Printf("panic in %s: %v\n%s", request.Method(), recovered, stack)
+//     })
+//
+// stack is the stack trace of the goroutine that panicked. The client still
+// receives a ServerError without any details. This includes panics in
+// middleware, raw handlers, notification handlers, shadow handlers and
+// background cache refreshes.
+//
+// The hook is called synchronously on the goroutine that panicked, so it should
+// not block. A nil hook stops reporting panics.
+func (server *SimpleServer) OnPanic(hook PanicHook) {
+	server.panicHook = hook
+}
+
+// reportPanic must be called from the deferred function that recovered.
+func (server *SimpleServer) reportPanic(request Request, recovered interface{}) {
+	if server.panicHook != nil {
+		server.panicHook(request, recovered, debug.Stack())
+	}
+}
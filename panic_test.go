@@ -0,0 +1,99 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+type recoveredPanic struct {
+	method    string
+	recovered interface{}
+	stack     string
+}
+
+func recordPanics(server *jsonrpc.SimpleServer) chan recoveredPanic {
+	panics := make(chan recoveredPanic, 1)
+	server.OnPanic(func(request jsonrpc.Request, recovered interface{}, stack []byte) {
+		panics <- recoveredPanic{request.Method(), recovered, string(stack)}
+	})
+
+	return panics
+}
+
+func TestSimpleServer_OnPanic(t *testing.T) {
+	server := newTestServer()
+	panics := recordPanics(server)
+
+	responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"panic","id":1}`))
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"Server error"}}`, responses[0].String())
+
+	p := <-panics
+	assert.Equal(t, "panic", p.method)
+	assert.Equal(t, "uh-oh!", p.recovered)
+	assert.Contains(t, p.stack, "jsonrpc_test.forcePanic")
+
+	t.Run("Middleware", func(t *testing.T) {
+		server := newTestServer()
+		panics := recordPanics(server)
+		server.Use(func(next jsonrpc.RequestHandler) jsonrpc.RequestHandler {
+			return func(request jsonrpc.RequestResponder) jsonrpc.Response {
+				panic("middleware")
+			}
+		})
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1],"id":1}`))
+		assert.Equal(t, "middleware", (<-panics).recovered)
+	})
+
+	t.Run("MaxDuration", func(t *testing.T) {
+		server := newTestServer()
+		panics := recordPanics(server)
+		server.SetLimits("panic", jsonrpc.Limits{MaxDuration: time.Second})
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"panic","id":1}`))
+		assert.Equal(t, jsonrpc.ServerError, responses[0].ErrorCode())
+		assert.Equal(t, "uh-oh!", (<-panics).recovered)
+	})
+
+	t.Run("Notification", func(t *testing.T) {
+		server := newTestServer()
+		panics := recordPanics(server)
+		server.SetNotificationHandler("log", func(ctx context.Context, request jsonrpc.Request) {
+			panic("notification")
+		})
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"log"}`))
+		assert.Equal(t, recoveredPanic{"log", "notification", ""}, withoutStack(<-panics))
+	})
+
+	t.Run("Raw", func(t *testing.T) {
+		server := newTestServer()
+		panics := recordPanics(server)
+		server.SetRawHandler("raw", func(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, *jsonrpc.RPCError) {
+			panic("raw")
+		})
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"raw","params":[1],"id":1}`))
+		assert.Equal(t, jsonrpc.ServerError, responses[0].ErrorCode())
+		assert.Equal(t, recoveredPanic{"raw", "raw", ""}, withoutStack(<-panics))
+	})
+
+	t.Run("NoHook", func(t *testing.T) {
+		server.OnPanic(nil)
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"panic","id":1}`))
+		assert.Equal(t, jsonrpc.ServerError, responses[0].ErrorCode())
+		assert.Len(t, panics, 0)
+	})
+}
+
+func withoutStack(p recoveredPanic) recoveredPanic {
+	p.stack = ""
+
+	return p
+}
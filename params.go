@@ -0,0 +1,227 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+var (
+	// ErrMissingParam is returned (wrapped) by the typed param accessors when
+	// the param was not provided.
+	ErrMissingParam = errors.New("Missing param")
+
+	// ErrInvalidParamType is returned (wrapped) by the typed param accessors
+	// when the param was provided with the wrong type.
+	ErrInvalidParamType = errors.New("Invalid param type")
+)
+
+// ParamReader provides access to individual params of a request without
+// type switching on Params:
+//
+//     func sayHello(request jsonrpc.RequestResponder) jsonrpc.Response {
+//         name, err := request.ParamString("name")
+//         if err != nil {
+//             return request.NewErrorResponse(jsonrpc.InvalidParams, err.Error())
+//         }
+//
+//         return request.NewSuccessResponse("Hello, " + name)
+//     }
+//
+// Param and the typed variants read named params (an object).
+// PositionalParam reads positional params (an array) and starts at 0.
+//
+// BindParams decodes all of the params into target, which must be a pointer. It
+// returns nil if the params were decoded, otherwise an InvalidParams response
+// that can be returned from the handler:
+//
+//     var params struct {
+//         Name string `json:"name"`
+//     }
+//     if response := request.BindParams(&params); response != nil {
+//         return response
+//     }
+//
+// Named params are decoded like any JSON object. Positional params are assigned
+// to the fields of a struct in the order they are declared (skipping fields
+// that are not encoded), or can be decoded into a slice or array. The decoded
+// params are then checked with the Validator of the server (see SetValidator
+// and TagValidator).
+type ParamReader interface {
+	Param(name string) (interface{}, bool)
+	PositionalParam(i int) (interface{}, bool)
+	ParamString(name string) (string, error)
+	ParamInt(name string) (int, error)
+	ParamFloat(name string) (float64, error)
+	BindParams(target interface{}) Response
+}
+
+// decodedParams returns the params as they would be decoded from JSON. Params
+// of requests created in Go may be any type that can be encoded.
+func (request *request) decodedParams() interface{} {
+	switch params := request.RequestParams.(type) {
+	case map[string]interface{}, []interface{}, nil:
+		return params
+	}
+
+	return decodedResult(request.RequestParams)
+}
+
+func (request *request) Param(name string) (interface{}, bool) {
+	params, ok := request.decodedParams().(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	value, ok := params[name]
+
+	return value, ok
+}
+
+func (request *request) PositionalParam(i int) (interface{}, bool) {
+	params, ok := request.decodedParams().([]interface{})
+	if !ok || i < 0 || i >= len(params) {
+		return nil, false
+	}
+
+	return params[i], true
+}
+
+func (request *request) param(name string) (interface{}, error) {
+	value, ok := request.Param(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrMissingParam, name)
+	}
+
+	return value, nil
+}
+
+func (request *request) ParamString(name string) (string, error) {
+	value, err := request.param(name)
+	if err != nil {
+		return "", err
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%w: %s must be a string", ErrInvalidParamType, name)
+	}
+
+	return s, nil
+}
+
+func (request *request) ParamInt(name string) (int, error) {
+	value, err := request.param(name)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n := value.(type) {
+	case int:
+		return n, nil
+
+	case int64:
+		return int(n), nil
+
+	case float64:
+		if n == math.Trunc(n) && !math.IsInf(n, 0) {
+			return int(n), nil
+		}
+
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			return int(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %s must be an integer", ErrInvalidParamType, name)
+}
+
+func (request *request) ParamFloat(name string) (float64, error) {
+	value, err := request.param(name)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n := value.(type) {
+	case float64:
+		return n, nil
+
+	case int:
+		return float64(n), nil
+
+	case int64:
+		return float64(n), nil
+
+	case json.Number:
+		if f, err := n.Float64(); err == nil {
+			return f, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %s must be a number", ErrInvalidParamType, name)
+}
+
+func (request *request) BindParams(target interface{}) Response {
+	return bindRequestParams(request, target, TagValidator{})
+}
+
+func bindParams(params, target interface{}) error {
+	if params == nil {
+		return nil
+	}
+
+	t := reflect.TypeOf(target)
+	if positional, ok := params.([]interface{}); ok && t.Elem().Kind() == reflect.Struct {
+		names := fieldNames(t.Elem())
+		if len(positional) > len(names) {
+			return fmt.Errorf("expected at most %d params but got %d", len(names), len(positional))
+		}
+
+		named := make(map[string]interface{}, len(positional))
+		for i, value := range positional {
+			named[names[i]] = value
+		}
+		params = named
+	}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, target)
+}
+
+// fieldNames returns the JSON names of the fields of a struct that are encoded,
+// in the order they are declared.
+func fieldNames(t reflect.Type) (names []string) {
+	for i := 0; i < t.NumField(); i++ {
+		if name, ok := fieldName(t.Field(i)); ok {
+			names = append(names, name)
+		}
+	}
+
+	return
+}
+
+// fieldName returns the JSON name of a field. false is returned if the field is
+// not encoded.
+func fieldName(field reflect.StructField) (string, bool) {
+	if field.PkgPath != "" {
+		return "", false
+	}
+
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, true
+}
@@ -0,0 +1,163 @@
+package jsonrpc_test
+
+import (
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequest_Param(t *testing.T) {
+	request, err := jsonrpc.NewRequestFromJSON([]byte(`{"jsonrpc":"2.0","method":"sayHello","params":{"name":"Bob","age":42},"id":1}`))
+	assert.NoError(t, err)
+
+	name, ok := request.Param("name")
+	assert.True(t, ok)
+	assert.Equal(t, "Bob", name)
+
+	_, ok = request.Param("missing")
+	assert.False(t, ok)
+
+	_, ok = request.PositionalParam(0)
+	assert.False(t, ok)
+}
+
+func TestRequest_PositionalParam(t *testing.T) {
+	request, err := jsonrpc.NewRequestFromJSON([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,"two"],"id":1}`))
+	assert.NoError(t, err)
+
+	value, ok := request.PositionalParam(1)
+	assert.True(t, ok)
+	assert.Equal(t, "two", value)
+
+	for _, i := range []int{-1, 2} {
+		_, ok = request.PositionalParam(i)
+		assert.False(t, ok)
+	}
+
+	_, ok = request.Param("name")
+	assert.False(t, ok)
+}
+
+func TestRequest_ParamString(t *testing.T) {
+	request := jsonrpc.NewRequestResponder("2.0", 1, "sayHello", map[string]interface{}{
+		"name": "Bob",
+		"age":  42,
+	})
+
+	name, err := request.ParamString("name")
+	assert.NoError(t, err)
+	assert.Equal(t, "Bob", name)
+
+	_, err = request.ParamString("age")
+	assert.ErrorIs(t, err, jsonrpc.ErrInvalidParamType)
+	assert.EqualError(t, err, "Invalid param type: age must be a string")
+
+	_, err = request.ParamString("missing")
+	assert.ErrorIs(t, err, jsonrpc.ErrMissingParam)
+	assert.EqualError(t, err, "Missing param: missing")
+}
+
+func TestRequest_ParamInt(t *testing.T) {
+	request, err := jsonrpc.NewRequestFromJSON([]byte(`{"jsonrpc":"2.0","method":"m","params":{"a":42,"b":1.5,"c":"3"},"id":1}`))
+	assert.NoError(t, err)
+
+	a, err := request.ParamInt("a")
+	assert.NoError(t, err)
+	assert.Equal(t, 42, a)
+
+	for _, name := range []string{"b", "c"} {
+		_, err = request.ParamInt(name)
+		assert.ErrorIs(t, err, jsonrpc.ErrInvalidParamType)
+	}
+
+	_, err = request.ParamInt("d")
+	assert.ErrorIs(t, err, jsonrpc.ErrMissingParam)
+}
+
+func TestRequest_ParamFloat(t *testing.T) {
+	type params struct {
+		A float64 `json:"a"`
+		B string  `json:"b"`
+	}
+
+	// Params created in Go are read as they would be decoded from JSON.
+	request := jsonrpc.NewRequestResponder("2.0", 1, "m", params{A: 1.5, B: "x"})
+
+	a, err := request.ParamFloat("a")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, a)
+
+	_, err = request.ParamFloat("b")
+	assert.EqualError(t, err, "Invalid param type: b must be a number")
+}
+
+func TestRequest_ParamWithState(t *testing.T) {
+	request := jsonrpc.WithState(jsonrpc.NewRequestResponder("2.0", 1, "m", []interface{}{"x"}), jsonrpc.State{"user": "bob"})
+
+	value, ok := request.PositionalParam(0)
+	assert.True(t, ok)
+	assert.Equal(t, "x", value)
+}
+
+func TestRequest_BindParams(t *testing.T) {
+	type sumParams struct {
+		A       float64 `json:"a"`
+		B       float64 `json:"b"`
+		Ignored string  `json:"-"`
+		Round   bool
+	}
+
+	for name, test := range map[string]struct {
+		params   string
+		expected sumParams
+		message  string
+	}{
+		"Named":           {`{"a":1,"b":2}`, sumParams{A: 1, B: 2}, ""},
+		"Positional":      {`[1,2,true]`, sumParams{A: 1, B: 2, Round: true}, ""},
+		"PositionalShort": {`[1]`, sumParams{A: 1}, ""},
+		"TooManyParams":   {`[1,2,true,4]`, sumParams{}, "Invalid params: expected at most 3 params but got 4"},
+		"WrongType":       {`{"a":"1"}`, sumParams{}, "Invalid params: json: cannot unmarshal string into Go struct field sumParams.a of type float64"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			request, err := jsonrpc.NewRequestFromJSON([]byte(`{"jsonrpc":"2.0","method":"sum","params":` + test.params + `,"id":1}`))
+			assert.NoError(t, err)
+
+			var params sumParams
+			response := request.BindParams(&params)
+
+			if test.message == "" {
+				assert.Nil(t, response)
+				assert.Equal(t, test.expected, params)
+			} else {
+				assert.Equal(t, jsonrpc.InvalidParams, response.ErrorCode())
+				assert.Equal(t, test.message, response.ErrorMessage())
+				assert.Equal(t, 1.0, response.Id())
+			}
+		})
+	}
+
+	t.Run("Slice", func(t *testing.T) {
+		request := jsonrpc.NewRequestResponder("2.0", 1, "sum", []interface{}{1, 2, 3})
+
+		var params []int
+		assert.Nil(t, request.BindParams(&params))
+		assert.Equal(t, []int{1, 2, 3}, params)
+	})
+
+	t.Run("NoParams", func(t *testing.T) {
+		request := jsonrpc.NewRequestResponder("2.0", 1, "sum", nil)
+
+		params := sumParams{A: 5}
+		assert.Nil(t, request.BindParams(&params))
+		assert.Equal(t, sumParams{A: 5}, params)
+	})
+
+	t.Run("NotAPointer", func(t *testing.T) {
+		request := jsonrpc.NewRequestResponder("2.0", 1, "sum", []interface{}{1})
+
+		response := request.BindParams(sumParams{})
+		assert.Equal(t, jsonrpc.ServerError, response.ErrorCode())
+		assert.Equal(t, "BindParams target must be a pointer", response.ErrorMessage())
+	})
+}
@@ -0,0 +1,83 @@
+package jsonrpc
+
+import (
+	"html/template"
+	"net/http"
+)
+
+var playgroundTemplate = template.Must(template.New("playground").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>JSON-RPC Playground</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+textarea, pre { width: 100%; box-sizing: border-box; font-family: monospace; }
+textarea { height: 12em; }
+pre { background: #f4f4f4; padding: 1em; min-height: 6em; white-space: pre-wrap; }
+li { cursor: pointer; color: #06c; }
+</style>
+</head>
+<body>
+<h1>JSON-RPC Playground</h1>
+<h2>Methods</h2>
+<ul>
+{{range .Methods}}<li onclick="selectMethod(this.textContent)">{{.}}</li>
+{{else}}<p>There are no methods.</p>
+{{end}}</ul>
+<h2>Request</h2>
+<textarea id="request">{"jsonrpc": "2.0", "method": "", "params": [], "id": 1}</textarea>
+<button onclick="send()">Send</button>
+<h2>Response</h2>
+<pre id="response"></pre>
+<script>
+var endpoint = {{.Endpoint}};
+
+function selectMethod(method) {
+	document.getElementById("request").value = JSON.stringify(
+		{jsonrpc: "2.0", method: method, params: [], id: 1}, null, 2);
+}
+
+function send() {
+	var output = document.getElementById("response");
+	output.textContent = "...";
+	fetch(endpoint, {
+		method: "POST",
+		headers: {"Content-Type": "application/json"},
+		body: document.getElementById("request").value
+	}).then(function (response) {
+		return response.text();
+	}).then(function (text) {
+		try {
+			output.textContent = JSON.stringify(JSON.parse(text), null, 2);
+		} catch (e) {
+			output.textContent = text || "(no response)";
+		}
+	}).catch(function (e) {
+		output.textContent = e.toString();
+	});
+}
+</script>
+</body>
+</html>
+`))
+
+// PlaygroundHandler returns a http.Handler that serves an interactive page for
+// exploring the server during development. The page lists all of the methods
+// and allows requests to be edited and sent to endpoint, which is the path
+// that the server itself is mounted on:
+//
+//     http.Handle("/rpc", server)
+//     http.Handle("/rpc/playground", server.PlaygroundHandler("/rpc"))
+//
+// The playground exposes every method of the server so it should not be
+// mounted in production.
+func (server *SimpleServer) PlaygroundHandler(endpoint string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		playgroundTemplate.Execute(w, map[string]interface{}{
+			"Methods":  server.Methods(),
+			"Endpoint": endpoint,
+		})
+	})
+}
@@ -0,0 +1,20 @@
+package jsonrpc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimpleServer_PlaygroundHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	newTestServer().PlaygroundHandler("/rpc").ServeHTTP(w,
+		httptest.NewRequest(http.MethodGet, "/rpc/playground", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `<li onclick="selectMethod(this.textContent)">get_data</li>`)
+	assert.Contains(t, w.Body.String(), `var endpoint = "/rpc";`)
+}
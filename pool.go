@@ -0,0 +1,47 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+)
+
+// Buffers larger than this are not returned to the pool, so that one large
+// response does not hold on to the memory forever.
+const maxPooledBufferSize = 64 << 10
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// encodePooled returns the bytes written by encode, using a buffer from the
+// pool. The returned bytes are a copy, so they are safe to keep.
+func encodePooled(encode func(buffer *bytes.Buffer) error) ([]byte, error) {
+	buffer := bufferPool.Get().(*bytes.Buffer)
+	buffer.Reset()
+	defer func() {
+		if buffer.Cap() <= maxPooledBufferSize {
+			bufferPool.Put(buffer)
+		}
+	}()
+
+	if err := encode(buffer); err != nil {
+		return nil, err
+	}
+
+	return append([]byte(nil), buffer.Bytes()...), nil
+}
+
+// encodeJSON writes v to the buffer in the same way as json.Marshal.
+func encodeJSON(buffer *bytes.Buffer, v interface{}) error {
+	if err := json.NewEncoder(buffer).Encode(v); err != nil {
+		return err
+	}
+
+	// Encode always adds a new line.
+	buffer.Truncate(buffer.Len() - 1)
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package jsonrpc
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Queue is a source of payloads and a destination for responses, such as a
+// Redis list or pub/sub channel. See ServeQueue.
+type Queue interface {
+	// Receive blocks until the next payload is available. io.EOF should be
+	// returned when there will be no more payloads.
+	Receive() ([]byte, error)
+
+	// Send delivers a single encoded response to key.
+	Send(key string, response []byte) error
+}
+
+// TimedQueue is a Queue that knows when each payload was added, so that the
+// time it waited is measured separately from the time taken to handle it. See
+// RequestTimings.
+type TimedQueue interface {
+	Queue
+
+	// ReceiveTimed is the same as Receive, but also returns the time that the
+	// payload was added to the queue.
+	ReceiveTimed() (payload []byte, queuedAt time.Time, err error)
+}
+
+// DefaultResponseKey is used by ServeQueue when no other function is provided.
+// It returns keys like "jsonrpc:response:123".
+func DefaultResponseKey(id interface{}) string {
+	return fmt.Sprintf("jsonrpc:response:%v", id)
+}
+
+// ServeQueue handles payloads from a queue until Receive returns an error. Each
+// response is sent to the key returned by responseKey for the ID of the
+// request. This allows worker style deployments where the callers wait on the
+// key for their own request ID. If responseKey is nil then DefaultResponseKey
+// is used.
+//
+// Each response in a batch is sent individually. Responses that do not have an
+// ID (such as a Parse error) cannot be routed to a caller and are discarded.
+//
+// If the queue is a TimedQueue, the time each request waited in the queue is
+// recorded. See RequestTimings.
+//
+// ServeQueue returns nil if Receive returns io.EOF.
+func (server *SimpleServer) ServeQueue(queue Queue, responseKey func(id interface{}) string) error {
+	if responseKey == nil {
+		responseKey = DefaultResponseKey
+	}
+
+	timedQueue, _ := queue.(TimedQueue)
+
+	for {
+		var payload []byte
+		var err error
+		state := State{transportKey: TransportQueue}
+
+		if timedQueue != nil {
+			var queuedAt time.Time
+			payload, queuedAt, err = timedQueue.ReceiveTimed()
+			state[queuedAtKey] = queuedAt
+		} else {
+			payload, err = queue.Receive()
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		for _, response := range server.HandleWithState(payload, state) {
+			if response.Id() == nil {
+				continue
+			}
+
+			if err := queue.Send(responseKey(response.Id()), response.Bytes()); err != nil {
+				return err
+			}
+		}
+	}
+}
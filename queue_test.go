@@ -0,0 +1,81 @@
+package jsonrpc_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testQueue struct {
+	payloads []string
+	sent     map[string]string
+	sendErr  error
+}
+
+func (queue *testQueue) Receive() ([]byte, error) {
+	if len(queue.payloads) == 0 {
+		return nil, io.EOF
+	}
+
+	payload := queue.payloads[0]
+	queue.payloads = queue.payloads[1:]
+
+	return []byte(payload), nil
+}
+
+func (queue *testQueue) Send(key string, response []byte) error {
+	if queue.sendErr != nil {
+		return queue.sendErr
+	}
+
+	queue.sent[key] = string(response)
+
+	return nil
+}
+
+func TestSimpleServer_ServeQueue(t *testing.T) {
+	t.Run("DefaultResponseKey", func(t *testing.T) {
+		queue := &testQueue{
+			payloads: []string{
+				`{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}`,
+				`[{"jsonrpc": "2.0", "method": "sum", "params": [1], "id": "a"}, {"jsonrpc": "2.0", "method": "sum", "params": [2]}]`,
+				`{"jsonrpc": "2.0", "method"`,
+			},
+			sent: map[string]string{},
+		}
+		err := newTestServer().ServeQueue(queue, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"jsonrpc:response:1": `{"jsonrpc":"2.0","id":1,"result":7}`,
+			"jsonrpc:response:a": `{"jsonrpc":"2.0","id":"a","result":1}`,
+		}, queue.sent)
+	})
+
+	t.Run("CustomResponseKey", func(t *testing.T) {
+		queue := &testQueue{
+			payloads: []string{`{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}`},
+			sent:     map[string]string{},
+		}
+		err := newTestServer().ServeQueue(queue, func(id interface{}) string {
+			return "responses"
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"responses": `{"jsonrpc":"2.0","id":1,"result":7}`,
+		}, queue.sent)
+	})
+
+	t.Run("SendError", func(t *testing.T) {
+		queue := &testQueue{
+			payloads: []string{`{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}`},
+			sendErr:  errors.New("connection lost"),
+		}
+		err := newTestServer().ServeQueue(queue, nil)
+
+		assert.EqualError(t, err, "connection lost")
+	})
+}
@@ -0,0 +1,241 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimit restricts how often a method can be called. See SetRateLimit.
+type RateLimit struct {
+	// Limit is the number of calls allowed in each window.
+	Limit int
+
+	// Window is the length of each window. The count of calls starts again at
+	// the beginning of each window. The default is one second.
+	Window time.Duration
+
+	// Key splits the calls into groups that are limited separately, such as
+	// by user or API key. If it is nil then all calls to the method share the
+	// same limit.
+	Key func(request Request) string
+}
+
+// RateLimiter counts calls for a RateLimit. The default is a
+// MemoryRateLimiter, which only counts the calls made to one server. A
+// RateLimiter backed by a shared store (such as RedisRateLimiter) enforces the
+// same limits across many servers. See SetRateLimiter.
+type RateLimiter interface {
+	// Allow counts a call for key and returns false if there have been more
+	// than limit calls for key in the current window. Windows start at
+	// multiples of window since the Unix epoch, so that every server agrees on
+	// when they start.
+	Allow(key string, limit int, window time.Duration) (bool, error)
+}
+
+// SetRateLimit limits the number of calls to a method. Calls over the limit
+// receive a ServerError with the message "Rate limit exceeded.":
+//
+//     server.SetRateLimit("search", jsonrpc.RateLimit{
+//         Limit:  100,
+//         Window: time.Minute,
+//         Key: func(request jsonrpc.Request) string {
+//             return request.State("userId").(string)
+//         },
+//     })
+//
+// A Limit of zero removes the rate limit.
+func (server *SimpleServer) SetRateLimit(methodName string, rateLimit RateLimit) {
+	if rateLimit.Limit <= 0 {
+		delete(server.rateLimits, methodName)
+		return
+	}
+
+	if rateLimit.Window <= 0 {
+		rateLimit.Window = time.Second
+	}
+
+	if server.rateLimits == nil {
+		server.rateLimits = make(map[string]RateLimit)
+	}
+
+	if server.rateLimiter == nil {
+		server.rateLimiter = &MemoryRateLimiter{Clock: serverClock{server}}
+	}
+
+	server.rateLimits[methodName] = rateLimit
+}
+
+// SetRateLimiter replaces the RateLimiter used for all rate limits. A nil
+// limiter uses a new MemoryRateLimiter.
+//
+// If the limiter returns an error the call is allowed, so that an outage of
+// the store does not also stop the server.
+func (server *SimpleServer) SetRateLimiter(limiter RateLimiter) {
+	if limiter == nil {
+		limiter = &MemoryRateLimiter{Clock: serverClock{server}}
+	}
+
+	server.rateLimiter = limiter
+}
+
+// rateLimit returns an error response if the request is over its rate limit.
+func (server *SimpleServer) rateLimit(request RequestResponder) Response {
+	rateLimit, ok := server.rateLimits[request.Method()]
+	if !ok {
+		return nil
+	}
+
+	key := request.Method()
+	if rateLimit.Key != nil {
+		key += ":" + rateLimit.Key(request)
+	}
+
+	allowed, err := server.rateLimiter.Allow(key, rateLimit.Limit, rateLimit.Window)
+	if err != nil || allowed {
+		return nil
+	}
+
+	return request.NewErrorResponse(ServerError, "Rate limit exceeded.")
+}
+
+// ErrInvalidRateWindow is returned by a RateLimiter when the window is not
+// positive.
+var ErrInvalidRateWindow = errors.New("Rate limit window must be positive")
+
+// MemoryRateLimiter is a RateLimiter that keeps the counts in memory.
+type MemoryRateLimiter struct {
+	// Clock is used to find the current window. If it is nil the system clock
+	// is used.
+	Clock Clock
+
+	mutex     sync.Mutex
+	windows   map[string]rateWindow
+	lastSweep time.Time
+}
+
+type rateWindow struct {
+	start time.Time
+	end   time.Time
+	count int
+}
+
+func (limiter *MemoryRateLimiter) Allow(key string, limit int, window time.Duration) (bool, error) {
+	if window <= 0 {
+		return false, ErrInvalidRateWindow
+	}
+
+	now := time.Now()
+	if limiter.Clock != nil {
+		now = limiter.Clock.Now()
+	}
+
+	start := time.Unix(0, now.UnixNano()/int64(window)*int64(window))
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	if limiter.windows == nil {
+		limiter.windows = make(map[string]rateWindow)
+	}
+
+	// Forget the keys that have not been used for a while, so that limits by
+	// user do not grow forever.
+	if now.Sub(limiter.lastSweep) >= window {
+		for k, w := range limiter.windows {
+			if !now.Before(w.end) {
+				delete(limiter.windows, k)
+			}
+		}
+		limiter.lastSweep = now
+	}
+
+	w := limiter.windows[key]
+	if !w.start.Equal(start) {
+		w = rateWindow{start: start, end: start.Add(window)}
+	}
+
+	w.count++
+	limiter.windows[key] = w
+
+	return w.count <= limit, nil
+}
+
+// RedisEvaler runs a Lua script on a Redis server. It is the only part of a
+// Redis client that RedisRateLimiter needs, so that this package does not
+// depend on one. Most clients only need a small adapter, see the README.
+type RedisEvaler interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// RedisRateLimiter is a RateLimiter that counts calls in Redis, so that the
+// same limits are enforced across many servers. Each window is a key that
+// expires at the end of the window:
+//
+//     server.SetRateLimiter(&jsonrpc.RedisRateLimiter{Client: redisEvaler{client}})
+type RedisRateLimiter struct {
+	// Client runs the script that counts the calls. It is required.
+	Client RedisEvaler
+
+	// Prefix is added to the keys. The default is "jsonrpc:ratelimit:".
+	Prefix string
+
+	// Timeout limits how long each call to Redis can take. The default of
+	// zero has no timeout, other than the timeouts of the client.
+	Timeout time.Duration
+
+	// Clock is used to find the current window. If it is nil the system clock
+	// is used.
+	Clock Clock
+}
+
+// rateLimitScript increments the count of a window, setting it to expire the
+// first time the window is used.
+const rateLimitScript = `local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count`
+
+func (limiter *RedisRateLimiter) Allow(key string, limit int, window time.Duration) (bool, error) {
+	if window <= 0 {
+		return false, ErrInvalidRateWindow
+	}
+
+	now := time.Now()
+	if limiter.Clock != nil {
+		now = limiter.Clock.Now()
+	}
+
+	prefix := limiter.Prefix
+	if prefix == "" {
+		prefix = "jsonrpc:ratelimit:"
+	}
+
+	ctx := context.Background()
+	if limiter.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limiter.Timeout)
+		defer cancel()
+	}
+
+	windowKey := fmt.Sprintf("%s%s:%d", prefix, key, now.UnixNano()/int64(window))
+	result, err := limiter.Client.Eval(ctx, rateLimitScript, []string{windowKey}, window.Milliseconds())
+	if err != nil {
+		return false, err
+	}
+
+	var count int64
+	switch n := result.(type) {
+	case int64:
+		count = n
+	case int:
+		count = int64(n)
+	default:
+		return false, fmt.Errorf("Unexpected result from Redis: %v", result)
+	}
+
+	return count <= int64(limit), nil
+}
@@ -0,0 +1,212 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/elliotchance/jsonrpc/jsonrpctest"
+	"github.com/stretchr/testify/assert"
+)
+
+type rateLimiterFunc func(key string, limit int, window time.Duration) (bool, error)
+
+func (fn rateLimiterFunc) Allow(key string, limit int, window time.Duration) (bool, error) {
+	return fn(key, limit, window)
+}
+
+func callSum(server *jsonrpc.SimpleServer, state jsonrpc.State) int {
+	responses := server.HandleWithState([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`), state)
+
+	return responses[0].ErrorCode()
+}
+
+func TestSimpleServer_SetRateLimit(t *testing.T) {
+	t.Run("Window", func(t *testing.T) {
+		clock := jsonrpctest.NewClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+		server := newTestServer()
+		server.SetClock(clock)
+		server.SetRateLimit("sum", jsonrpc.RateLimit{Limit: 2, Window: time.Minute})
+
+		assert.Equal(t, jsonrpc.Success, callSum(server, nil))
+		assert.Equal(t, jsonrpc.Success, callSum(server, nil))
+		assert.Equal(t, jsonrpc.ServerError, callSum(server, nil))
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+		assert.Equal(t, "Rate limit exceeded.", responses[0].ErrorMessage())
+
+		// Other methods are not limited.
+		responses = server.Handle([]byte(`{"jsonrpc":"2.0","method":"subtract","params":[3,2],"id":1}`))
+		assert.Equal(t, jsonrpc.Success, responses[0].ErrorCode())
+
+		clock.Advance(time.Minute)
+		assert.Equal(t, jsonrpc.Success, callSum(server, nil))
+	})
+
+	t.Run("DefaultWindow", func(t *testing.T) {
+		clock := jsonrpctest.NewClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+		server := newTestServer()
+		server.SetClock(clock)
+		server.SetRateLimit("sum", jsonrpc.RateLimit{Limit: 1})
+
+		assert.Equal(t, jsonrpc.Success, callSum(server, nil))
+		assert.Equal(t, jsonrpc.ServerError, callSum(server, nil))
+
+		clock.Advance(time.Second)
+		assert.Equal(t, jsonrpc.Success, callSum(server, nil))
+	})
+
+	t.Run("Key", func(t *testing.T) {
+		server := newTestServer()
+		server.SetRateLimit("sum", jsonrpc.RateLimit{
+			Limit:  1,
+			Window: time.Hour,
+			Key: func(request jsonrpc.Request) string {
+				return request.State("user").(string)
+			},
+		})
+
+		assert.Equal(t, jsonrpc.Success, callSum(server, jsonrpc.State{"user": "a"}))
+		assert.Equal(t, jsonrpc.Success, callSum(server, jsonrpc.State{"user": "b"}))
+		assert.Equal(t, jsonrpc.ServerError, callSum(server, jsonrpc.State{"user": "a"}))
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		server := newTestServer()
+		server.SetRateLimit("sum", jsonrpc.RateLimit{Limit: 1, Window: time.Hour})
+		server.SetRateLimit("sum", jsonrpc.RateLimit{})
+
+		assert.Equal(t, jsonrpc.Success, callSum(server, nil))
+		assert.Equal(t, jsonrpc.Success, callSum(server, nil))
+	})
+}
+
+func TestSimpleServer_SetRateLimiter(t *testing.T) {
+	t.Run("Custom", func(t *testing.T) {
+		var keys []string
+		server := newTestServer()
+		server.SetRateLimiter(rateLimiterFunc(func(key string, limit int, window time.Duration) (bool, error) {
+			keys = append(keys, key)
+			assert.Equal(t, 5, limit)
+			assert.Equal(t, time.Second, window)
+
+			return false, nil
+		}))
+		server.SetRateLimit("sum", jsonrpc.RateLimit{
+			Limit:  5,
+			Window: time.Second,
+			Key: func(request jsonrpc.Request) string {
+				return "abc"
+			},
+		})
+
+		assert.Equal(t, jsonrpc.ServerError, callSum(server, nil))
+		assert.Equal(t, []string{"sum:abc"}, keys)
+	})
+
+	t.Run("ErrorAllows", func(t *testing.T) {
+		server := newTestServer()
+		server.SetRateLimiter(rateLimiterFunc(func(key string, limit int, window time.Duration) (bool, error) {
+			return false, errors.New("connection refused")
+		}))
+		server.SetRateLimit("sum", jsonrpc.RateLimit{Limit: 1, Window: time.Second})
+
+		assert.Equal(t, jsonrpc.Success, callSum(server, nil))
+	})
+}
+
+func TestMemoryRateLimiter(t *testing.T) {
+	clock := jsonrpctest.NewClock(time.Unix(90, 0))
+	limiter := &jsonrpc.MemoryRateLimiter{Clock: clock}
+
+	allow := func(key string) bool {
+		allowed, err := limiter.Allow(key, 1, time.Minute)
+		assert.NoError(t, err)
+
+		return allowed
+	}
+
+	assert.True(t, allow("a"))
+	assert.False(t, allow("a"))
+	assert.True(t, allow("b"))
+
+	// Windows start on multiples of the window since the Unix epoch.
+	clock.Set(time.Unix(119, 0))
+	assert.False(t, allow("a"))
+	clock.Set(time.Unix(120, 0))
+	assert.True(t, allow("a"))
+}
+
+func TestMemoryRateLimiter_InvalidWindow(t *testing.T) {
+	limiter := &jsonrpc.MemoryRateLimiter{}
+
+	_, err := limiter.Allow("a", 1, 0)
+	assert.ErrorIs(t, err, jsonrpc.ErrInvalidRateWindow)
+}
+
+// fakeRedis runs the rate limit script against a map.
+type fakeRedis struct {
+	counts  map[string]int64
+	expires map[string]interface{}
+	err     error
+}
+
+func (redis *fakeRedis) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	if redis.err != nil {
+		return nil, redis.err
+	}
+
+	redis.counts[keys[0]]++
+	if redis.counts[keys[0]] == 1 {
+		redis.expires[keys[0]] = args[0]
+	}
+
+	return redis.counts[keys[0]], nil
+}
+
+func TestRedisRateLimiter(t *testing.T) {
+	clock := jsonrpctest.NewClock(time.Unix(90, 0))
+	redis := &fakeRedis{counts: map[string]int64{}, expires: map[string]interface{}{}}
+	limiter := &jsonrpc.RedisRateLimiter{Client: redis, Clock: clock}
+
+	allow := func(key string) bool {
+		allowed, err := limiter.Allow(key, 1, time.Minute)
+		assert.NoError(t, err)
+
+		return allowed
+	}
+
+	assert.True(t, allow("a"))
+	assert.False(t, allow("a"))
+	assert.True(t, allow("b"))
+
+	clock.Set(time.Unix(120, 0))
+	assert.True(t, allow("a"))
+
+	assert.Equal(t, map[string]interface{}{
+		"jsonrpc:ratelimit:a:1": int64(60000),
+		"jsonrpc:ratelimit:b:1": int64(60000),
+		"jsonrpc:ratelimit:a:2": int64(60000),
+	}, redis.expires)
+
+	t.Run("Prefix", func(t *testing.T) {
+		limiter := &jsonrpc.RedisRateLimiter{Client: redis, Clock: clock, Prefix: "rl:"}
+		limiter.Allow("a", 1, time.Minute)
+
+		assert.Equal(t, int64(1), redis.counts["rl:a:2"])
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		failing := &jsonrpc.RedisRateLimiter{Client: &fakeRedis{err: errors.New("down")}}
+
+		_, err := failing.Allow("a", 1, time.Minute)
+		assert.EqualError(t, err, "down")
+	})
+
+	t.Run("InvalidWindow", func(t *testing.T) {
+		_, err := limiter.Allow("a", 1, 0)
+		assert.ErrorIs(t, err, jsonrpc.ErrInvalidRateWindow)
+	})
+}
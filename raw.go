@@ -0,0 +1,202 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+)
+
+// RawHandler handles a method without decoding the params or encoding the
+// result. The params are passed exactly as they were received (nil if they
+// were omitted) and the result must be valid JSON. If an error is returned
+// then the result is ignored.
+//
+// This is useful for proxying and performance critical methods where the
+// server should not touch the payloads at all. ctx is the context of the
+// request, see SetContextHandler.
+type RawHandler func(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, *RPCError)
+
+// rawRequest is only decoded enough to dispatch to a RawHandler.
+type rawRequest struct {
+	Version string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	Id      interface{}     `json:"id"`
+}
+
+// SetRawHandler will register (or replace) a RawHandler for a method. It
+// replaces any handler set with SetHandler for the same method.
+//
+// Raw handlers still go through maintenance mode, SetMaxConcurrentRequests,
+// the middleware added with Use, SetRateLimit and SetDefaultState. They are
+// counted in the stats and audited (see SetAudit), and are matched by
+// SetCompliance rules such as CaseInsensitiveMethods.
+//
+// Raw handlers bypass the features that need the decoded request: caching,
+// collapsing duplicates, SetValidator, limits, chaos and fault injection,
+// schema drift detection and shadowing. Method patterns do not apply to them.
+func (server *SimpleServer) SetRawHandler(methodName string, handler RawHandler) {
+	server.mustCheckMethodName(methodName)
+
+	registry := server.handlerRegistry()
+
+	server.handlerMutex.Lock()
+	defer server.handlerMutex.Unlock()
+
+	if server.rawHandlers == nil {
+		server.rawHandlers = make(map[string]RawHandler)
+	}
+
+	registry.Remove(methodName)
+	delete(server.methodMiddleware, methodName)
+	server.setMethodPattern(methodName, false)
+	server.setFoldedMethod(methodName, true)
+	server.rawHandlers[methodName] = handler
+}
+
+// handleRaw handles the request if it is for a method with a RawHandler. false
+// is returned if the request must be handled normally.
+//
+// The request goes through the same checks as other requests, but only the
+// ones that do not need the decoded params.
+func (server *SimpleServer) handleRaw(jsonRequest []byte, state State) (Responses, bool) {
+	server.handlerMutex.RLock()
+	hasRawHandlers := len(server.rawHandlers) > 0
+	server.handlerMutex.RUnlock()
+
+	if !hasRawHandlers {
+		return nil, false
+	}
+
+	var raw rawRequest
+	if json.Unmarshal(jsonRequest, &raw) != nil || raw.Version != "2.0" {
+		return nil, false
+	}
+
+	handler, ok := server.rawHandler(raw.Method)
+	if !ok {
+		raw.Method = server.normalizeMethod(raw.Method)
+		handler, ok = server.rawHandler(raw.Method)
+	}
+
+	if !ok {
+		return nil, false
+	}
+
+	server.start()
+
+	var request RequestResponder = NewRequestResponderWithState(raw.Version,
+		raw.Id, raw.Method, raw.Params, state)
+	if len(server.defaultState) > 0 {
+		request = &defaultStateRequest{
+			RequestResponder: request,
+			defaults:         server.defaultState,
+		}
+	}
+
+	response := server.callRaw(handler, raw, request)
+
+	switch {
+	case raw.Id == nil && response.ErrorCode() == Success:
+		atomic.AddUint64(&server.totalSuccessNotifications, 1)
+	case raw.Id == nil:
+		atomic.AddUint64(&server.totalErrorNotifications, 1)
+	case response.ErrorCode() == Success:
+		atomic.AddUint64(&server.totalSuccessResponses, 1)
+	default:
+		atomic.AddUint64(&server.totalErrorResponses, 1)
+	}
+
+	server.recentErrors.record(server.now(), raw.Method, response)
+
+	responses := Responses{}
+	appendResponses(&responses, response)
+
+	return responses, true
+}
+
+func (server *SimpleServer) rawHandler(methodName string) (RawHandler, bool) {
+	server.handlerMutex.RLock()
+	defer server.handlerMutex.RUnlock()
+
+	handler, ok := server.rawHandlers[methodName]
+
+	return handler, ok
+}
+
+// callRaw runs the maintenance check, concurrency limit, middleware and rate
+// limit for a raw request before calling handler.
+func (server *SimpleServer) callRaw(handler RawHandler, raw rawRequest, request RequestResponder) (response Response) {
+	if server.inMaintenance(raw.Method) {
+		response = request.NewErrorResponse(ServerError, "Server is in maintenance mode.")
+		server.auditRejected(request, response, AuditCheck{
+			Name:    "maintenance",
+			Allowed: false,
+		})
+
+		return response
+	}
+
+	request, release := server.acquireSlot(request)
+	if release == nil {
+		response = request.NewErrorResponse(ServerError, "Server is overloaded.")
+		server.auditRejected(request, response)
+
+		return response
+	}
+	defer release()
+
+	atomic.AddUint64(&server.totalRequests, 1)
+	atomic.AddUint64(&server.currentActiveRequests, 1)
+	defer atomic.AddUint64(&server.currentActiveRequests, ^uint64(0))
+
+	run := server.auditHandler(chainMiddleware(func(request RequestResponder) Response {
+		auditReached(request)
+
+		if response := server.rateLimit(request); response != nil {
+			return response
+		}
+
+		result, rpcErr := server.callRawHandler(RequestContext(request), handler, raw)
+		if rpcErr != nil {
+			return NewErrorResponseWithData(raw.Id, rpcErr.Code, rpcErr.Message,
+				rpcErr.Data)
+		}
+
+		return NewSuccessResponse(raw.Id, result)
+	}, server.middleware))
+
+	defer func() {
+		if r := recover(); r != nil {
+			server.reportPanic(request, r)
+			response = nil
+		}
+
+		if response == nil {
+			response = request.NewErrorResponse(ServerError, "")
+		}
+	}()
+
+	return run(request)
+}
+
+func (server *SimpleServer) callRawHandler(ctx context.Context, handler RawHandler, request rawRequest) (result json.RawMessage, rpcErr *RPCError) {
+	defer func() {
+		if r := recover(); r != nil {
+			server.reportPanic(NewRequestResponder(request.Version, request.Id,
+				request.Method, request.Params), r)
+
+			result, rpcErr = nil, &RPCError{
+				Code:    ServerError,
+				Message: ErrorMessageForCode(ServerError),
+			}
+		}
+	}()
+
+	result, rpcErr = handler(ctx, request.Method, request.Params)
+	if rpcErr == nil && result == nil {
+		result = json.RawMessage("null")
+	}
+
+	return
+}
@@ -0,0 +1,198 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRawServer() *jsonrpc.SimpleServer {
+	server := jsonrpc.NewSimpleServer()
+	server.SetRawHandler("echo", func(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, *jsonrpc.RPCError) {
+		return params, nil
+	})
+	server.SetRawHandler("fail", func(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, *jsonrpc.RPCError) {
+		return nil, &jsonrpc.RPCError{Code: 123, Message: "Nope.", Data: "extra"}
+	})
+	server.SetRawHandler("panic", func(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, *jsonrpc.RPCError) {
+		panic("oops")
+	})
+
+	return server
+}
+
+func TestSimpleServer_SetRawHandler(t *testing.T) {
+	server := newRawServer()
+
+	t.Run("ParamsArePassedThrough", func(t *testing.T) {
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"echo","params":{"b": [1, 2.50]},"id":1}`))
+
+		assert.Equal(t, `[{"jsonrpc":"2.0","id":1,"result":{"b":[1,2.50]}}]`,
+			responses.String())
+	})
+
+	t.Run("BatchParamsArePassedThrough", func(t *testing.T) {
+		responses := server.Handle([]byte(`[{"jsonrpc":"2.0","method":"echo","params":[9007199254740993, 2.50],"id":1}]`))
+
+		assert.Equal(t, `[{"jsonrpc":"2.0","id":1,"result":[9007199254740993,2.50]}]`,
+			responses.String())
+	})
+
+	t.Run("MissingParams", func(t *testing.T) {
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"echo","id":"a"}`))
+
+		assert.Equal(t, `[{"jsonrpc":"2.0","id":"a","result":null}]`,
+			responses.String())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"fail","id":1}`))
+
+		assert.Equal(t, 123, responses[0].ErrorCode())
+		assert.Equal(t, "Nope.", responses[0].ErrorMessage())
+		assert.Equal(t, "extra", responses[0].ErrorData())
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"panic","id":1}`))
+
+		assert.Equal(t, jsonrpc.ServerError, responses[0].ErrorCode())
+	})
+
+	t.Run("Notification", func(t *testing.T) {
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"echo","params":[1]}`))
+
+		assert.Empty(t, responses)
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		responses := server.Handle([]byte(`[{"jsonrpc":"2.0","method":"echo","params":[1],"id":1},{"jsonrpc":"2.0","method":"missing","id":2}]`))
+
+		assert.Len(t, responses, 2)
+		assert.Equal(t, jsonrpc.Success, responses[0].ErrorCode())
+		assert.Equal(t, jsonrpc.MethodNotFound, responses[1].ErrorCode())
+	})
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		responses := server.Handle([]byte(`{"jsonrpc":"1.0","method":"echo","id":1}`))
+
+		assert.Equal(t, jsonrpc.InvalidRequest, responses[0].ErrorCode())
+	})
+
+	t.Run("Methods", func(t *testing.T) {
+		assert.Equal(t, []string{"echo", "fail", "panic"}, server.Methods())
+	})
+}
+
+func TestSimpleServer_SetRawHandlerReplacesHandler(t *testing.T) {
+	server := newRawServer()
+	server.SetHandler("echo", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponse("decoded")
+	})
+
+	responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"echo","params":[1],"id":1}`))
+
+	assert.Equal(t, "decoded", responses[0].Result())
+}
+
+func TestSimpleServer_SetRawHandlerChecks(t *testing.T) {
+	t.Run("Middleware", func(t *testing.T) {
+		server := newRawServer()
+		server.Use(func(next jsonrpc.RequestHandler) jsonrpc.RequestHandler {
+			return func(request jsonrpc.RequestResponder) jsonrpc.Response {
+				if request.Method() == "fail" {
+					return request.NewErrorResponse(jsonrpc.ServerError, "Denied.")
+				}
+
+				return next(request)
+			}
+		})
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"fail","id":1}`))
+		assert.Equal(t, "Denied.", responses[0].ErrorMessage())
+
+		responses = server.Handle([]byte(`{"jsonrpc":"2.0","method":"echo","params":[1],"id":1}`))
+		assert.Equal(t, `[{"jsonrpc":"2.0","id":1,"result":[1]}]`, responses.String())
+	})
+
+	t.Run("MiddlewarePanic", func(t *testing.T) {
+		server := newRawServer()
+		server.Use(func(next jsonrpc.RequestHandler) jsonrpc.RequestHandler {
+			return func(request jsonrpc.RequestResponder) jsonrpc.Response {
+				panic("oops")
+			}
+		})
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"echo","id":1}`))
+		assert.Equal(t, jsonrpc.ServerError, responses[0].ErrorCode())
+	})
+
+	t.Run("DefaultState", func(t *testing.T) {
+		server := jsonrpc.NewSimpleServer()
+		server.SetDefaultState(jsonrpc.State{"region": "eu"})
+		server.SetRawHandler("region", func(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, *jsonrpc.RPCError) {
+			result, _ := json.Marshal(jsonrpc.ContextState(ctx, "region"))
+
+			return result, nil
+		})
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"region","id":1}`))
+		assert.Equal(t, `[{"jsonrpc":"2.0","id":1,"result":"eu"}]`, responses.String())
+	})
+
+	t.Run("Maintenance", func(t *testing.T) {
+		server := newRawServer()
+		server.SetMaintenance(true)
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"echo","id":1}`))
+		assert.Equal(t, "Server is in maintenance mode.", responses[0].ErrorMessage())
+	})
+
+	t.Run("RateLimit", func(t *testing.T) {
+		server := newRawServer()
+		server.SetRateLimit("echo", jsonrpc.RateLimit{Limit: 1, Window: time.Hour})
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"echo","id":1}`))
+		assert.Equal(t, jsonrpc.Success, responses[0].ErrorCode())
+
+		responses = server.Handle([]byte(`{"jsonrpc":"2.0","method":"echo","id":2}`))
+		assert.Equal(t, "Rate limit exceeded.", responses[0].ErrorMessage())
+	})
+
+	t.Run("Overloaded", func(t *testing.T) {
+		started, finish := make(chan struct{}), make(chan struct{})
+		server := jsonrpc.NewSimpleServer()
+		server.SetMaxConcurrentRequests(1, 0)
+		server.SetRawHandler("block", func(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, *jsonrpc.RPCError) {
+			close(started)
+			<-finish
+
+			return nil, nil
+		})
+
+		done := make(chan struct{})
+		go func() {
+			server.Handle([]byte(`{"jsonrpc":"2.0","method":"block","id":1}`))
+			close(done)
+		}()
+		<-started
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"block","id":2}`))
+		assert.Equal(t, "Server is overloaded.", responses[0].ErrorMessage())
+
+		close(finish)
+		<-done
+	})
+
+	t.Run("CaseInsensitive", func(t *testing.T) {
+		server := newRawServer()
+		server.SetCompliance(jsonrpc.Compliance{CaseInsensitiveMethods: true})
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"ECHO","params":[1],"id":1}`))
+		assert.Equal(t, `[{"jsonrpc":"2.0","id":1,"result":[1]}]`, responses.String())
+	})
+}
@@ -0,0 +1,121 @@
+package jsonrpc
+
+import (
+	"sort"
+	"sync"
+)
+
+// HandlerRegistry stores the handlers set with SetHandler and resolves the
+// handler for each request. The default is a MemoryHandlerRegistry. Another
+// registry can load handlers from somewhere else, such as a database, service
+// discovery or plugins that are loaded the first time they are used. See
+// SetHandlerRegistry.
+//
+// A HandlerRegistry must be safe to use concurrently.
+type HandlerRegistry interface {
+	// Get returns the handler for a method, or nil if there isn't one.
+	Get(methodName string) RequestHandler
+
+	// Set registers (or replaces) the handler for a method.
+	Set(methodName string, handler RequestHandler)
+
+	// Remove unregisters the handler for a method. It is not an error if the
+	// method does not have a handler.
+	Remove(methodName string)
+
+	// List returns the names of all of the methods that have a handler, in
+	// any order.
+	List() []string
+}
+
+// SetHandlerRegistry replaces the registry used for handlers. Handlers that
+// were already set are not copied to the new registry. A nil registry uses a
+// new MemoryHandlerRegistry.
+//
+// Methods in the registry that contain wildcards (see SetHandler), or that
+// differ by case (see Compliance.CaseInsensitiveMethods), are only matched if
+// they are in the registry when it is set, or are added with SetHandler.
+func (server *SimpleServer) SetHandlerRegistry(registry HandlerRegistry) {
+	if registry == nil {
+		registry = &MemoryHandlerRegistry{}
+	}
+
+	methods := registry.List()
+
+	server.handlerMutex.Lock()
+	defer server.handlerMutex.Unlock()
+
+	server.handlers = registry
+	server.methodPatterns = nil
+	server.foldedMethods = nil
+	for _, methodName := range methods {
+		server.indexMethod(methodName, true)
+	}
+}
+
+// handlerRegistry returns the registry, creating the default one if needed.
+func (server *SimpleServer) handlerRegistry() HandlerRegistry {
+	server.handlerMutex.RLock()
+	registry := server.handlers
+	server.handlerMutex.RUnlock()
+
+	if registry != nil {
+		return registry
+	}
+
+	server.handlerMutex.Lock()
+	defer server.handlerMutex.Unlock()
+
+	if server.handlers == nil {
+		server.handlers = &MemoryHandlerRegistry{}
+	}
+
+	return server.handlers
+}
+
+// MemoryHandlerRegistry is a HandlerRegistry that keeps the handlers in
+// memory. The zero value is ready to use.
+type MemoryHandlerRegistry struct {
+	mutex    sync.RWMutex
+	handlers map[string]RequestHandler
+}
+
+func (registry *MemoryHandlerRegistry) Get(methodName string) RequestHandler {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	return registry.handlers[methodName]
+}
+
+func (registry *MemoryHandlerRegistry) Set(methodName string, handler RequestHandler) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	if registry.handlers == nil {
+		registry.handlers = make(map[string]RequestHandler)
+	}
+
+	registry.handlers[methodName] = handler
+}
+
+func (registry *MemoryHandlerRegistry) Remove(methodName string) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	delete(registry.handlers, methodName)
+}
+
+// List returns the method names sorted alphabetically.
+func (registry *MemoryHandlerRegistry) List() []string {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	methods := make([]string, 0, len(registry.handlers))
+	for methodName := range registry.handlers {
+		methods = append(methods, methodName)
+	}
+
+	sort.Strings(methods)
+
+	return methods
+}
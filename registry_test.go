@@ -0,0 +1,95 @@
+package jsonrpc_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// pluginRegistry loads the handlers for "plugin." methods the first time they
+// are used.
+type pluginRegistry struct {
+	jsonrpc.MemoryHandlerRegistry
+
+	mutex  sync.Mutex
+	loaded []string
+}
+
+func (registry *pluginRegistry) Get(methodName string) jsonrpc.RequestHandler {
+	if handler := registry.MemoryHandlerRegistry.Get(methodName); handler != nil {
+		return handler
+	}
+
+	if !strings.HasPrefix(methodName, "plugin.") {
+		return nil
+	}
+
+	registry.mutex.Lock()
+	registry.loaded = append(registry.loaded, methodName)
+	registry.mutex.Unlock()
+
+	handler := func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponse(methodName)
+	}
+	registry.Set(methodName, handler)
+
+	return handler
+}
+
+func TestSimpleServer_SetHandlerRegistry(t *testing.T) {
+	registry := &pluginRegistry{}
+	registry.Set("device.*.status", wildcardsHandler("status"))
+
+	server := newTestServer()
+	server.SetHandlerRegistry(registry)
+
+	// The handlers of the previous registry are not copied.
+	assert.Nil(t, server.GetHandler("sum"))
+
+	server.SetHandler("sum", sum)
+	assert.Equal(t, []string{"device.*.status", "sum"}, registry.List())
+
+	for i := 0; i < 2; i++ {
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"plugin.hello","id":1}`))
+		assert.Equal(t, "plugin.hello", responses[0].Result())
+	}
+	assert.Equal(t, []string{"plugin.hello"}, registry.loaded)
+
+	responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"device.abc.status","id":1}`))
+	assert.Equal(t, []string{"abc"}, responses[0].Result().(map[string]interface{})["wildcards"])
+
+	assert.Equal(t, []string{"device.*.status", "plugin.hello", "sum"}, server.Methods())
+
+	assert.True(t, server.RemoveHandler("sum"))
+	assert.Nil(t, registry.Get("sum"))
+
+	responses = server.Handle([]byte(`{"jsonrpc":"2.0","method":"other","id":1}`))
+	assert.Equal(t, jsonrpc.MethodNotFound, responses[0].ErrorCode())
+}
+
+func TestSimpleServer_SetHandlerRegistry_Nil(t *testing.T) {
+	server := newTestServer()
+	server.SetHandlerRegistry(nil)
+
+	assert.Equal(t, []string{}, server.Methods())
+}
+
+func TestMemoryHandlerRegistry(t *testing.T) {
+	var registry jsonrpc.MemoryHandlerRegistry
+
+	assert.Nil(t, registry.Get("sum"))
+	assert.Equal(t, []string{}, registry.List())
+
+	registry.Set("sum", sum)
+	registry.Set("subtract", subtract)
+	assert.NotNil(t, registry.Get("sum"))
+	assert.Equal(t, []string{"subtract", "sum"}, registry.List())
+
+	registry.Remove("sum")
+	registry.Remove("missing")
+	assert.Nil(t, registry.Get("sum"))
+	assert.Equal(t, []string{"subtract"}, registry.List())
+}
@@ -2,12 +2,14 @@ package jsonrpc
 
 import (
 	"crypto/md5"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math/rand"
 	"strconv"
 	"errors"
+	"time"
 )
 
 // Provides immutable information about a request.
@@ -25,8 +27,60 @@ type Request interface {
 
 // State can be optionally provided with Handle requests to pass extra state to
 // the handler for that individual request.
+//
+// State is layered. The State provided to HandleWithState is the bottom layer
+// and is shared (read-only) by all of the requests in a batch. Middleware can
+// add a layer with WithState, which is only visible to the handlers that
+// receive the new request (downstream), never to the caller (upstream) or
+// other requests in the same batch.
 type State map[string]interface{}
 
+// copy returns a shallow copy of the state so that it cannot be changed by the
+// original owner.
+func (state State) copy() State {
+	c := make(State, len(state))
+	for key, value := range state {
+		c[key] = value
+	}
+
+	return c
+}
+
+// layeredRequest adds a State layer on top of another request.
+type layeredRequest struct {
+	RequestResponder
+	state State
+}
+
+func (request *layeredRequest) State(key string) interface{} {
+	if value, ok := request.state[key]; ok {
+		return value
+	}
+
+	return request.RequestResponder.State(key)
+}
+
+// WithState returns a request with extra State on top of the State of the
+// original request. Values in state take precedence over existing values with
+// the same key. The original request is not modified. This is how middleware
+// should pass values to the handler:
+//
+//     func auth(next jsonrpc.RequestHandler) jsonrpc.RequestHandler {
+//         return func(request jsonrpc.RequestResponder) jsonrpc.Response {
+//             user := ...
+//
+//             return next(jsonrpc.WithState(request, jsonrpc.State{"user": user}))
+//         }
+//     }
+//
+// state is copied so changing it after calling WithState has no effect.
+func WithState(request RequestResponder, state State) RequestResponder {
+	return &layeredRequest{
+		RequestResponder: request,
+		state:            state.copy(),
+	}
+}
+
 // Allows a request to produce responses. These are convenience functions so
 // that the request ID (an potentially version) are set correctly in the
 // response.
@@ -34,7 +88,9 @@ type State map[string]interface{}
 // You can use the similarly named functions if this interface is not available.
 type Responder interface {
 	NewSuccessResponse(result interface{}) Response
+	NewSuccessResponseWithMaxAge(result interface{}, maxAge time.Duration) Response
 	NewErrorResponse(code int, message string) Response
+	NewErrorResponseWithData(code int, message string, data interface{}) Response
 	NewServerErrorResponse(err error) Response
 }
 
@@ -43,6 +99,7 @@ type Responder interface {
 type RequestResponder interface {
 	Request
 	Responder
+	ParamReader
 }
 
 // A JSON-RPC request object.
@@ -78,10 +135,18 @@ func (request *request) NewSuccessResponse(result interface{}) Response {
 	return NewSuccessResponse(request.Id(), result)
 }
 
+func (request *request) NewSuccessResponseWithMaxAge(result interface{}, maxAge time.Duration) Response {
+	return NewSuccessResponseWithMaxAge(request.Id(), result, maxAge)
+}
+
 func (request *request) NewErrorResponse(code int, message string) Response {
 	return NewErrorResponse(request.Id(), code, message)
 }
 
+func (request *request) NewErrorResponseWithData(code int, message string, data interface{}) Response {
+	return NewErrorResponseWithData(request.Id(), code, message, data)
+}
+
 func (request *request) NewServerErrorResponse(err error) Response {
 	return NewServerErrorResponse(request.Id(), err)
 }
@@ -124,6 +189,31 @@ func GenerateRequestId() string {
 	return hex.EncodeToString(hash[:])
 }
 
+// RequestHash returns a hash of the method and params of a request. Requests
+// with the same method and params always have the same hash, even in different
+// processes, regardless of their id, version or State. This is the key used by
+// the result cache and for collapsing duplicate requests, and it can be used
+// for idempotency keys or audit logs:
+//
+//     key := "idempotency:" + jsonrpc.RequestHash(request)
+//
+// Params are compared by their JSON encoding, so object keys can be in any
+// order and params created in Go (such as a struct) have the same hash as the
+// equivalent decoded JSON. An empty string is returned if the params cannot be
+// encoded.
+func RequestHash(request Request) string {
+	// json.Marshal always encodes maps with sorted keys so this is safe to use
+	// as the canonical form of the params.
+	params, err := json.Marshal(decodedResult(request.Params()))
+	if err != nil {
+		return ""
+	}
+
+	hash := sha256.Sum256([]byte(request.Method() + "\x00" + string(params)))
+
+	return hex.EncodeToString(hash[:])
+}
+
 // The bytes representation of a request will be the JSON encoded value. This
 // JSON is expected to be a perfectly valid JSON-RPC request.
 func (request *request) Bytes() []byte {
@@ -138,7 +228,9 @@ func (request *request) Bytes() []byte {
 	return b
 }
 
-func newRequestResponderFromJSON(jsonRequest []byte, isPartOfBatch bool, state State) (RequestResponder, interface{}, int, string) {
+// newRequestResponderFromJSON decodes a single request. A missing "jsonrpc"
+// member is only allowed (as an empty version) if allowMissingVersion is true.
+func newRequestResponderFromJSON(jsonRequest []byte, isPartOfBatch, allowMissingVersion bool, state State) (RequestResponder, interface{}, int, string) {
 	var requestMap map[string]interface{}
 	err := json.Unmarshal(jsonRequest, &requestMap)
 	if err != nil {
@@ -156,6 +248,9 @@ func newRequestResponderFromJSON(jsonRequest []byte, isPartOfBatch bool, state S
 	}
 
 	// Catch some type errors before creating the real request.
+	if _, ok := requestMap["jsonrpc"]; !ok && allowMissingVersion {
+		requestMap["jsonrpc"] = ""
+	}
 	if _, ok := requestMap["jsonrpc"].(string); !ok {
 		return nil, requestMap["id"],
 			InvalidRequest, "Version (jsonrpc) must be a string."
@@ -178,7 +273,7 @@ func NewRequestFromJSON(data []byte) (RequestResponder, error) {
 		return nil, errors.New("Empty input")
 	}
 
-	r, _, _, errMessage := newRequestResponderFromJSON(data, false, nil)
+	r, _, _, errMessage := newRequestResponderFromJSON(data, false, false, nil)
 	if errMessage != "" {
 		return nil, errors.New(errMessage)
 	}
@@ -130,3 +130,66 @@ func TestNewRequestsFromJSON(t *testing.T) {
 		assert.Nil(t, r)
 	})
 }
+
+func TestWithState(t *testing.T) {
+	request := jsonrpc.NewRequestResponderWithState("2.0", 123, "foo", nil,
+		jsonrpc.State{"a": 1, "b": 2})
+
+	state := jsonrpc.State{"b": 3, "c": 4}
+	layered := jsonrpc.WithState(request, state)
+	state["c"] = 5
+
+	t.Run("Downstream", func(t *testing.T) {
+		assert.Equal(t, 1, layered.State("a"))
+		assert.Equal(t, 3, layered.State("b"))
+		assert.Equal(t, 4, layered.State("c"))
+	})
+
+	t.Run("Upstream", func(t *testing.T) {
+		assert.Equal(t, 2, request.State("b"))
+		assert.Nil(t, request.State("c"))
+	})
+
+	t.Run("Request", func(t *testing.T) {
+		assert.Equal(t, request.Bytes(), layered.Bytes())
+		assert.Equal(t, 123, layered.NewSuccessResponse(nil).Id())
+	})
+}
+
+func TestRequestHash(t *testing.T) {
+	request, err := jsonrpc.NewRequestFromJSON([]byte(`{"jsonrpc":"2.0","method":"getUser","params":{"name":"bob","age":42},"id":1}`))
+	assert.NoError(t, err)
+
+	hash := jsonrpc.RequestHash(request)
+
+	// The hash must never change, even between versions.
+	assert.Equal(t, "e26e740b1ce44b280fbca5209f2675153333e09c0dd6b71365abd3fd86b2d297", hash)
+
+	t.Run("SameParams", func(t *testing.T) {
+		for _, other := range []jsonrpc.Request{
+			jsonrpc.NewRequestResponder("2.0", 2, "getUser", map[string]interface{}{"age": 42, "name": "bob"}),
+			jsonrpc.NewRequestResponder("1.0", nil, "getUser", struct {
+				Name string `json:"name"`
+				Age  int    `json:"age"`
+			}{"bob", 42}),
+			jsonrpc.WithState(request, jsonrpc.State{"user": "alice"}),
+		} {
+			assert.Equal(t, hash, jsonrpc.RequestHash(other))
+		}
+	})
+
+	t.Run("DifferentRequest", func(t *testing.T) {
+		for _, other := range []jsonrpc.Request{
+			jsonrpc.NewRequestResponder("2.0", 1, "getUser", map[string]interface{}{"name": "bob"}),
+			jsonrpc.NewRequestResponder("2.0", 1, "getUsers", map[string]interface{}{"age": 42, "name": "bob"}),
+			jsonrpc.NewRequestResponder("2.0", 1, "getUser", []interface{}{"bob", 42}),
+		} {
+			assert.NotEqual(t, hash, jsonrpc.RequestHash(other))
+		}
+	})
+
+	t.Run("UnencodableParams", func(t *testing.T) {
+		request := jsonrpc.NewRequestResponder("2.0", 1, "getUser", make(chan int))
+		assert.Equal(t, "", jsonrpc.RequestHash(request))
+	})
+}
@@ -0,0 +1,119 @@
+package jsonrpc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The prefix of the methods that are reserved by the JSON-RPC specification
+// for rpc-internal methods and extensions.
+const reservedPrefix = "rpc."
+
+// ErrReservedMethod is returned (or panicked with) when a handler is set for a
+// method that starts with "rpc.". See SetAllowReservedMethods.
+var ErrReservedMethod = errors.New("Method names that start with \"rpc.\" are reserved")
+
+// SetAllowReservedMethods allows handlers to be set for methods that start with
+// "rpc.". These are reserved by the JSON-RPC specification, so setting a
+// handler for one of them panics (or returns ErrReservedMethod from
+// SetInjectedHandler) unless this is enabled. It is only needed to implement
+// an extension or to replace a built-in method.
+//
+// Requests for reserved methods are handled by the server itself:
+//
+//     rpc.discover  Returns an OpenRPC document of every method. This must
+//                   be enabled with SetDiscoverMethod.
+//
+//     rpc.ping      Returns "pong". This is used as a health check, see
+//                   CircuitBreaker. This must be enabled with SetPingMethod.
+//
+//     rpc.trace     Returns the request as the handler received it. This
+//                   must be enabled with SetTraceMethod.
+//
+//     rpc.compression
+//                   Negotiates compression as the first request on a
+//                   connection. See SetCompressionCodecs.
+//
+// Other reserved methods receive a Method not found error, including those
+// that are in a HandlerRegistry (see SetHandlerRegistry). Handlers with a
+// wildcard (such as "*.discover") never match a reserved method, unless they
+// are allowed. Built-in methods run the middleware added with Use, so they can
+// be protected in the same way as any other method.
+func (server *SimpleServer) SetAllowReservedMethods(allowed bool) {
+	server.allowReservedMethods = allowed
+}
+
+func isReservedMethod(methodName string) bool {
+	return strings.HasPrefix(methodName, reservedPrefix)
+}
+
+// checkMethodName returns ErrReservedMethod if a handler cannot be set for the
+// method.
+func (server *SimpleServer) checkMethodName(methodName string) error {
+	if isReservedMethod(methodName) && !server.allowReservedMethods {
+		return fmt.Errorf("%w: %s", ErrReservedMethod, methodName)
+	}
+
+	return nil
+}
+
+// mustCheckMethodName panics if a handler cannot be set for the method. This is
+// always a mistake in the program, in the same way as registering a pattern
+// twice with an http.ServeMux.
+func (server *SimpleServer) mustCheckMethodName(methodName string) {
+	if err := server.checkMethodName(methodName); err != nil {
+		panic(err)
+	}
+}
+
+// SetDiscoverMethod enables the built-in "rpc.discover" method. It returns an
+// OpenRPC document of every method, from their SetMethodInfo, which a Client
+// can load with LoadOpenRPC.
+//
+// The document describes every method of the server, so rpc.discover is
+// disabled by default. When it is disabled the method does not exist.
+func (server *SimpleServer) SetDiscoverMethod(enabled bool) {
+	server.discoverMethod = enabled
+}
+
+// SetPingMethod enables the built-in "rpc.ping" method, which returns "pong".
+// It is used by CircuitBreaker with HealthCheck to test an endpoint without
+// sending a real request.
+//
+// rpc.ping is disabled by default. When it is disabled the method does not
+// exist.
+func (server *SimpleServer) SetPingMethod(enabled bool) {
+	server.pingMethod = enabled
+}
+
+// builtinHandler returns the handler for a reserved method that is handled by
+// the server itself, or nil.
+func (server *SimpleServer) builtinHandler(methodName string) RequestHandler {
+	switch methodName {
+	case "rpc.discover":
+		if server.discoverMethod {
+			return server.rpcDiscover
+		}
+
+	case "rpc.ping":
+		if server.pingMethod {
+			return rpcPing
+		}
+
+	case traceMethodName:
+		if server.traceMethod {
+			return server.rpcTrace
+		}
+	}
+
+	return nil
+}
+
+func (server *SimpleServer) rpcDiscover(request RequestResponder) Response {
+	return request.NewSuccessResponse(newOpenRPCDocument(server.catalog()))
+}
+
+func rpcPing(request RequestResponder) Response {
+	return request.NewSuccessResponse("pong")
+}
@@ -0,0 +1,171 @@
+package jsonrpc_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimpleServer_SetHandlerReserved(t *testing.T) {
+	server := jsonrpc.NewSimpleServer()
+
+	assert.PanicsWithError(t, `Method names that start with "rpc." are reserved: rpc.foo`, func() {
+		server.SetHandler("rpc.foo", sum)
+	})
+	assert.Panics(t, func() {
+		server.SetRawHandler("rpc.foo", func(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, *jsonrpc.RPCError) {
+			return nil, nil
+		})
+	})
+	assert.Panics(t, func() {
+		server.Group("rpc.").SetHandler("foo", sum)
+	})
+
+	err := server.SetInjectedHandler("rpc.foo", sum)
+	assert.ErrorIs(t, err, jsonrpc.ErrReservedMethod)
+
+	// Similar names are not reserved.
+	server.SetHandler("rpcfoo", sum)
+	server.SetHandler("foo.rpc.bar", sum)
+
+	assert.Equal(t, []string{"foo.rpc.bar", "rpcfoo"}, server.Methods())
+}
+
+func TestSimpleServer_SetAllowReservedMethods(t *testing.T) {
+	server := jsonrpc.NewSimpleServer()
+	server.SetAllowReservedMethods(true)
+	server.SetHandler("rpc.foo", sum)
+	server.SetHandler("rpc.discover", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponse("custom")
+	})
+
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":3}`,
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"rpc.foo","params":[1,2],"id":1}`))[0].String())
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":"custom"}`,
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"rpc.discover","id":1}`))[0].String())
+}
+
+func TestSimpleServer_ReservedMethodRouting(t *testing.T) {
+	server := jsonrpc.NewSimpleServer()
+	server.SetHandler("*.foo", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponse(jsonrpc.MethodWildcards(request))
+	})
+
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":["a"]}`,
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"a.foo","id":1}`))[0].String())
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"Method not found"}}`,
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"rpc.foo","id":1}`))[0].String())
+
+	t.Run("Allowed", func(t *testing.T) {
+		server.SetAllowReservedMethods(true)
+		defer server.SetAllowReservedMethods(false)
+
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":["rpc"]}`,
+			server.Handle([]byte(`{"jsonrpc":"2.0","method":"rpc.foo","id":1}`))[0].String())
+	})
+}
+
+func TestSimpleServer_ReservedMethodRegistry(t *testing.T) {
+	registry := &jsonrpc.MemoryHandlerRegistry{}
+	registry.Set("rpc.foo", sum)
+	registry.Set("rpc.discover", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponse("custom")
+	})
+
+	server := jsonrpc.NewSimpleServer()
+	server.SetHandlerRegistry(registry)
+
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"Method not found"}}`,
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"rpc.foo","params":[1,2],"id":1}`))[0].String())
+	assert.NotEqual(t, "custom",
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"rpc.discover","id":1}`))[0].Result())
+
+	t.Run("Allowed", func(t *testing.T) {
+		server.SetAllowReservedMethods(true)
+		defer server.SetAllowReservedMethods(false)
+
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":3}`,
+			server.Handle([]byte(`{"jsonrpc":"2.0","method":"rpc.foo","params":[1,2],"id":1}`))[0].String())
+		assert.Equal(t, "custom",
+			server.Handle([]byte(`{"jsonrpc":"2.0","method":"rpc.discover","id":1}`))[0].Result())
+	})
+}
+
+func TestSimpleServer_RPCDiscover(t *testing.T) {
+	server := jsonrpc.NewSimpleServer()
+	server.SetDiscoverMethod(true)
+	server.SetHandler("sum", sum)
+	server.SetHandler("getUser", sum)
+
+	sumInfo := jsonrpc.MethodInfo{
+		Name:        "sum",
+		Description: "Adds numbers.",
+		Params: []jsonrpc.ParamInfo{
+			{Name: "a", Type: "number", Required: true},
+			{Name: "b", Type: "number", Description: "Defaults to 0."},
+		},
+		Result: "number",
+	}
+	server.SetMethodInfo("sum", sumInfo)
+	server.SetMethodInfo("getUser", jsonrpc.MethodInfo{Result: "User", Deprecated: true})
+
+	responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"rpc.discover","id":1}`))
+	document, err := json.Marshal(responses[0].Result())
+	assert.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"openrpc": "1.2.6",
+		"info": {"title": "JSON-RPC", "version": "1.0.0"},
+		"methods": [
+			{
+				"name": "getUser",
+				"params": [],
+				"result": {"name": "result", "schema": {"$ref": "#/components/schemas/User"}},
+				"deprecated": true
+			},
+			{
+				"name": "sum",
+				"description": "Adds numbers.",
+				"params": [
+					{"name": "a", "required": true, "schema": {"type": "number"}},
+					{"name": "b", "description": "Defaults to 0.", "schema": {"type": "number"}}
+				],
+				"result": {"name": "result", "schema": {"type": "number"}}
+			}
+		]
+	}`, string(document))
+
+	methods, err := jsonrpc.ParseOpenRPC(bytes.NewReader(document))
+	assert.NoError(t, err)
+	assert.Equal(t, []jsonrpc.MethodInfo{
+		{Name: "getUser", Result: "User", Deprecated: true},
+		sumInfo,
+	}, methods)
+}
+
+func TestSimpleServer_SetDiscoverMethod(t *testing.T) {
+	server := jsonrpc.NewSimpleServer()
+	server.SetHandler("sum", sum)
+
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"Method not found"}}`,
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"rpc.discover","id":1}`))[0].String())
+
+	server.SetDiscoverMethod(true)
+	assert.Equal(t, jsonrpc.Success,
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"rpc.discover","id":1}`))[0].ErrorCode())
+}
+
+func TestSimpleServer_SetPingMethod(t *testing.T) {
+	server := jsonrpc.NewSimpleServer()
+
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"Method not found"}}`,
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"rpc.ping","id":1}`))[0].String())
+
+	server.SetPingMethod(true)
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":"pong"}`,
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"rpc.ping","id":1}`))[0].String())
+}
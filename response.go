@@ -1,8 +1,10 @@
 package jsonrpc
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 const (
@@ -48,6 +50,7 @@ const (
 //     } else {
 //         // response.ErrorCode()
 //         // response.ErrorMessage()
+//         // response.ErrorData()
 //     }
 //
 type Response interface {
@@ -56,6 +59,7 @@ type Response interface {
 	Result() interface{}
 	ErrorCode() int
 	ErrorMessage() string
+	ErrorData() interface{}
 
 	// Serialization
 	fmt.Stringer
@@ -67,17 +71,25 @@ type Responses []Response
 // A JSON-RPC error is made up of a code and a message. It is acceptable for the
 // message to be empty - the server will replace it with the generic message
 // returned from ErrorMessageForCode().
+//
+// Data is optional and may contain any extra information about the error.
 type errorResponse struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
 }
 
 // A JSON-RPC response object.
 type response struct {
-	ResponseVersion string         `json:"jsonrpc"`
+	ResponseVersion string         `json:"jsonrpc,omitempty"`
 	ResponseId      interface{}    `json:"id"`
 	ResponseResult  interface{}    `json:"result,omitempty"`
 	ResponseError   *errorResponse `json:"error,omitempty"`
+
+	// maxAge is only meaningful when hasMaxAge is true. It is not part of the
+	// JSON-RPC response. See ResponseMaxAge.
+	maxAge    time.Duration
+	hasMaxAge bool
 }
 
 func (response *response) Version() string {
@@ -108,14 +120,44 @@ func (response *response) ErrorMessage() string {
 	return response.ResponseError.Message
 }
 
+func (response *response) ErrorData() interface{} {
+	if response.ResponseError == nil {
+		return nil
+	}
+
+	return response.ResponseError.Data
+}
+
 // The string representation of a response will be the JSON encoded value. This
 // JSON is expected to be a perfectly valid JSON-RPC response.
 func (response *response) String() string {
 	return string(response.Bytes())
 }
 
+// plainResponse does not have the MarshalJSON method so that it can be encoded
+// without recursing forever.
+type plainResponse response
+
+// MarshalJSON encodes the response, honoring any ResultMarshaler in the result.
+func (response *response) MarshalJSON() ([]byte, error) {
+	return encodePooled(response.encode)
+}
+
+// encode writes the JSON of the response to the buffer.
+func (response *response) encode(buffer *bytes.Buffer) error {
+	result, err := marshalResult(response.ResponseResult)
+	if err != nil {
+		return err
+	}
+
+	plain := plainResponse(*response)
+	plain.ResponseResult = result
+
+	return encodeJSON(buffer, &plain)
+}
+
 func (response *response) Bytes() []byte {
-	b, err := json.Marshal(response)
+	b, err := encodePooled(response.encode)
 	if err != nil {
 		// I don't know what would cause this situation. There is nothing we can
 		// do except return an empty string (which would not occur in any
@@ -156,6 +198,19 @@ func NewSuccessResponse(id interface{}, result interface{}) Response {
 // not contain sensitive details (such as passwords). You may provide an empty
 // string for message to use the message from ErrorMessageForCode() instead.
 func NewErrorResponse(id interface{}, code int, message string) Response {
+	return NewErrorResponseWithData(id, code, message, nil)
+}
+
+// NewErrorResponseWithData is the same as NewErrorResponse, but the error also
+// contains data. This is useful for structured details that the client can act
+// on, such as which params were invalid or when to retry:
+//
+//     return jsonrpc.NewErrorResponseWithData(id, jsonrpc.ServerError,
+//         "Too busy.", map[string]interface{}{"retryAfter": 30})
+//
+// The data can be any value that can be encoded as JSON. A nil data is not
+// included in the response.
+func NewErrorResponseWithData(id interface{}, code int, message string, data interface{}) Response {
 	if message == "" {
 		message = ErrorMessageForCode(code)
 	}
@@ -166,6 +221,7 @@ func NewErrorResponse(id interface{}, code int, message string) Response {
 		ResponseError: &errorResponse{
 			Code:    code,
 			Message: message,
+			Data:    data,
 		},
 	}
 }
@@ -213,7 +269,7 @@ func (responses Responses) String() string {
 }
 
 func (responses Responses) Bytes() []byte {
-	b, err := json.Marshal(responses)
+	b, err := encodePooled(responses.encode)
 	if err != nil {
 		// I don't know what would cause this situation. I really don't
 		// want to panic, so just return a different string instead.
@@ -223,6 +279,35 @@ func (responses Responses) Bytes() []byte {
 	return b
 }
 
+// encode writes the JSON array of the responses to the buffer.
+func (responses Responses) encode(buffer *bytes.Buffer) error {
+	if responses == nil {
+		buffer.WriteString("null")
+		return nil
+	}
+
+	buffer.WriteByte('[')
+	for i, r := range responses {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		var err error
+		if response, ok := r.(*response); ok && response != nil {
+			err = response.encode(buffer)
+		} else {
+			err = encodeJSON(buffer, r)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+	buffer.WriteByte(']')
+
+	return nil
+}
+
 func NewResponsesFromJSON(data []byte) (Responses, error) {
 	if data[0] == '[' {
 		rawResponses := []*response{}
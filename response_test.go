@@ -21,3 +21,67 @@ func TestResponses_Bytes(t *testing.T) {
 		"[{\"jsonrpc\":\"2.0\",\"id\":\"foo\",\"result\":\"bar\"}]",
 		string(responses.Bytes()))
 }
+
+func TestResponse_ErrorData(t *testing.T) {
+	assert.Nil(t, jsonrpc.NewSuccessResponse("foo", "bar").ErrorData())
+	assert.Nil(t, jsonrpc.NewErrorResponse("foo", jsonrpc.InvalidParams, "").ErrorData())
+}
+
+func TestNewErrorResponseWithData(t *testing.T) {
+	data := map[string]interface{}{"retryAfter": 30}
+
+	t.Run("Function", func(t *testing.T) {
+		response := jsonrpc.NewErrorResponseWithData("foo", jsonrpc.ServerError, "Too busy.", data)
+
+		assert.Equal(t, data, response.ErrorData())
+		assert.Equal(t,
+			`{"jsonrpc":"2.0","id":"foo","error":{"code":-32000,"message":"Too busy.","data":{"retryAfter":30}}}`,
+			response.String())
+	})
+
+	t.Run("Responder", func(t *testing.T) {
+		request := jsonrpc.NewRequestResponder("2.0", 1, "foo", nil)
+		response := request.NewErrorResponseWithData(jsonrpc.InvalidParams, "", data)
+
+		assert.Equal(t, 1, response.Id())
+		assert.Equal(t, "Invalid params", response.ErrorMessage())
+		assert.Equal(t, data, response.ErrorData())
+	})
+
+	t.Run("NilData", func(t *testing.T) {
+		response := jsonrpc.NewErrorResponseWithData("foo", jsonrpc.ServerError, "Oops.", nil)
+
+		assert.Equal(t, jsonrpc.NewErrorResponse("foo", jsonrpc.ServerError, "Oops."), response)
+	})
+}
+
+func benchmarkResponses(n int) jsonrpc.Responses {
+	responses := make(jsonrpc.Responses, n)
+	for i := range responses {
+		responses[i] = jsonrpc.NewSuccessResponse(i, map[string]interface{}{
+			"name":  "Bob",
+			"age":   42,
+			"roles": []interface{}{"admin", "user"},
+		})
+	}
+
+	return responses
+}
+
+func BenchmarkResponse_Bytes(b *testing.B) {
+	response := benchmarkResponses(1)[0]
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		response.Bytes()
+	}
+}
+
+func BenchmarkResponses_Bytes(b *testing.B) {
+	responses := benchmarkResponses(100)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		responses.Bytes()
+	}
+}
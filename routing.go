@@ -0,0 +1,208 @@
+package jsonrpc
+
+import (
+	"sort"
+	"strings"
+)
+
+// State key for the method segments matched by the wildcards of a pattern.
+const methodWildcardsKey = "jsonrpc.methodWildcards"
+
+// methodPattern is a method name registered with SetHandler that contains
+// wildcards, such as "device.*.status".
+type methodPattern struct {
+	name      string
+	segments  []string
+	wildcards int
+}
+
+// isMethodPattern reports whether a method name contains a "*" segment.
+func isMethodPattern(methodName string) bool {
+	for _, segment := range strings.Split(methodName, ".") {
+		if segment == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// match returns the segments matched by the wildcards, or false if the method
+// does not match the pattern.
+func (pattern methodPattern) match(segments []string, caseInsensitive bool) ([]string, bool) {
+	if len(segments) != len(pattern.segments) {
+		return nil, false
+	}
+
+	wildcards := make([]string, 0, pattern.wildcards)
+	for i, segment := range pattern.segments {
+		switch {
+		case segment == "*":
+			wildcards = append(wildcards, segments[i])
+
+		case segment == segments[i]:
+
+		case caseInsensitive && strings.EqualFold(segment, segments[i]):
+
+		default:
+			return nil, false
+		}
+	}
+
+	return wildcards, true
+}
+
+// indexMethod adds (or removes) methodName from the indexes used by route and
+// normalizeMethod. handlerMutex must be locked.
+func (server *SimpleServer) indexMethod(methodName string, add bool) {
+	server.setMethodPattern(methodName, add)
+	server.setFoldedMethod(methodName, add)
+}
+
+// setFoldedMethod adds (or removes) methodName from the names that only
+// differ by case. They are kept sorted so that the same one is always chosen.
+// handlerMutex must be locked.
+func (server *SimpleServer) setFoldedMethod(methodName string, add bool) {
+	key := strings.ToLower(methodName)
+	names := make([]string, 0, len(server.foldedMethods[key])+1)
+	for _, name := range server.foldedMethods[key] {
+		if name != methodName {
+			names = append(names, name)
+		}
+	}
+
+	if add {
+		names = append(names, methodName)
+		sort.Strings(names)
+	}
+
+	if len(names) == 0 {
+		delete(server.foldedMethods, key)
+		return
+	}
+
+	if server.foldedMethods == nil {
+		server.foldedMethods = make(map[string][]string)
+	}
+
+	server.foldedMethods[key] = names
+}
+
+// setMethodPattern adds (or removes) the pattern for methodName. Patterns
+// with fewer wildcards are matched first. handlerMutex must be locked.
+func (server *SimpleServer) setMethodPattern(methodName string, add bool) {
+	// A new slice is made because route reads it without the lock.
+	patterns := make([]methodPattern, 0, len(server.methodPatterns)+1)
+	for _, pattern := range server.methodPatterns {
+		if pattern.name != methodName {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	if add && isMethodPattern(methodName) {
+		segments := strings.Split(methodName, ".")
+		pattern := methodPattern{name: methodName, segments: segments}
+		for _, segment := range segments {
+			if segment == "*" {
+				pattern.wildcards++
+			}
+		}
+
+		patterns = append(patterns, pattern)
+	}
+
+	sort.SliceStable(patterns, func(i, j int) bool {
+		if patterns[i].wildcards != patterns[j].wildcards {
+			return patterns[i].wildcards < patterns[j].wildcards
+		}
+
+		return patterns[i].name < patterns[j].name
+	})
+
+	server.methodPatterns = patterns
+}
+
+// route finds the handler for a method. An exact name is preferred over a
+// pattern. The name the handler was registered with is returned, along with
+// the segments matched by the wildcards (if any).
+//
+// A HandlerRegistry can contain reserved methods that were not set with
+// SetHandler, so they are ignored unless reserved methods are allowed.
+func (server *SimpleServer) route(methodName string) (RequestHandler, string, []string) {
+	registry := server.handlerRegistry()
+	reserved := isReservedMethod(methodName)
+	if handler := registry.Get(methodName); handler != nil && (!reserved || server.allowReservedMethods) {
+		return handler, methodName, nil
+	}
+
+	if reserved {
+		if handler := server.builtinHandler(methodName); handler != nil || !server.allowReservedMethods {
+			return handler, methodName, nil
+		}
+	}
+
+	server.handlerMutex.RLock()
+	patterns := server.methodPatterns
+	server.handlerMutex.RUnlock()
+
+	segments := strings.Split(methodName, ".")
+	for _, pattern := range patterns {
+		if wildcards, ok := pattern.match(segments, server.compliance.CaseInsensitiveMethods); ok {
+			if handler := registry.Get(pattern.name); handler != nil {
+				return handler, pattern.name, wildcards
+			}
+		}
+	}
+
+	return nil, methodName, nil
+}
+
+// normalizeMethod returns the name of the method that should be called,
+// following the Compliance of the server. This is the name of a registered
+// handler when it only differs by case.
+func (server *SimpleServer) normalizeMethod(methodName string) string {
+	compliance := server.compliance
+	if compliance.TrimMethodWhitespace {
+		methodName = strings.TrimSpace(methodName)
+	}
+
+	if !compliance.CaseInsensitiveMethods {
+		return methodName
+	}
+
+	if server.handlerRegistry().Get(methodName) != nil {
+		return methodName
+	}
+
+	server.handlerMutex.RLock()
+	names := server.foldedMethods[strings.ToLower(methodName)]
+	server.handlerMutex.RUnlock()
+
+	if len(names) > 0 {
+		return names[0]
+	}
+
+	return methodName
+}
+
+// renamedRequest is a request for a different method name. See
+// normalizeMethod.
+type renamedRequest struct {
+	RequestResponder
+	method string
+}
+
+func (request *renamedRequest) Method() string {
+	return request.method
+}
+
+// MethodWildcards returns the segments of the method name that were matched
+// by the wildcards of the pattern the handler was registered with (see
+// SetHandler). For example, "device.abc123.status" matched by
+// "device.*.status" returns []string{"abc123"}. It returns nil if the handler
+// was registered with an exact name.
+func MethodWildcards(request Request) []string {
+	wildcards, _ := request.State(methodWildcardsKey).([]string)
+
+	return wildcards
+}
@@ -0,0 +1,74 @@
+package jsonrpc_test
+
+import (
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func wildcardsHandler(name string) jsonrpc.RequestHandler {
+	return func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponse(map[string]interface{}{
+			"handler":   name,
+			"wildcards": jsonrpc.MethodWildcards(request),
+		})
+	}
+}
+
+func TestSimpleServer_SetHandler_Pattern(t *testing.T) {
+	server := jsonrpc.NewSimpleServer()
+	server.SetHandler("device.*.status", wildcardsHandler("status"))
+	server.SetHandler("device.*.*", wildcardsHandler("any"))
+	server.SetHandler("device.main.status", wildcardsHandler("main"))
+
+	for _, test := range []struct {
+		method    string
+		handler   string
+		wildcards []string
+	}{
+		{"device.abc.status", "status", []string{"abc"}},
+		{"device.abc.reset", "any", []string{"abc", "reset"}},
+		{"device.main.status", "main", nil},
+	} {
+		t.Run(test.method, func(t *testing.T) {
+			response := server.HandleRequest(jsonrpc.NewRequestResponder("2.0", 1, test.method, nil))[0]
+			result := response.Result().(map[string]interface{})
+
+			assert.Equal(t, test.handler, result["handler"])
+			assert.Equal(t, test.wildcards, result["wildcards"])
+		})
+	}
+
+	t.Run("NotMatched", func(t *testing.T) {
+		for _, method := range []string{"device.abc", "device.abc.status.extra", "sensor.abc.status"} {
+			response := server.HandleRequest(jsonrpc.NewRequestResponder("2.0", 1, method, nil))[0]
+			assert.Equal(t, jsonrpc.MethodNotFound, response.ErrorCode(), method)
+		}
+	})
+
+	t.Run("RemoveHandler", func(t *testing.T) {
+		server := jsonrpc.NewSimpleServer()
+		server.SetHandler("device.*.status", wildcardsHandler("status"))
+		assert.True(t, server.RemoveHandler("device.*.status"))
+
+		response := server.HandleRequest(jsonrpc.NewRequestResponder("2.0", 1, "device.abc.status", nil))[0]
+		assert.Equal(t, jsonrpc.MethodNotFound, response.ErrorCode())
+	})
+
+	t.Run("WithMiddleware", func(t *testing.T) {
+		var calls []string
+		server := jsonrpc.NewSimpleServer()
+		server.SetHandler("device.*.status", wildcardsHandler("status"),
+			jsonrpc.WithMiddleware(recordMiddleware("device", &calls)))
+
+		server.HandleRequest(jsonrpc.NewRequestResponder("2.0", 1, "device.abc.status", nil))
+		assert.Equal(t, []string{"device before", "device after"}, calls)
+	})
+}
+
+func TestMethodWildcards(t *testing.T) {
+	request := jsonrpc.NewRequestResponder("2.0", 1, "sum", nil)
+
+	assert.Nil(t, jsonrpc.MethodWildcards(request))
+}
@@ -0,0 +1,231 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// SchemaDrift describes a difference between the traffic received for a method
+// and the params or result declared with SetMethodInfo.
+type SchemaDrift struct {
+	Method string
+
+	// Field is the name of the param, or "result".
+	Field string
+
+	// Expected and Observed are the declared and actual JSON types. Expected
+	// is empty for a param that was not declared and Observed is empty for a
+	// required param that was missing.
+	Expected string
+	Observed string
+}
+
+func (drift SchemaDrift) String() string {
+	switch {
+	case drift.Expected == "":
+		return fmt.Sprintf("%s: undeclared %s (%s)", drift.Method, drift.Field, drift.Observed)
+
+	case drift.Observed == "":
+		return fmt.Sprintf("%s: missing required %s", drift.Method, drift.Field)
+	}
+
+	return fmt.Sprintf("%s: %s expected %s but was %s", drift.Method,
+		drift.Field, drift.Expected, drift.Observed)
+}
+
+type schemaDriftDetector struct {
+	sampleRate float64
+	hook       func(drift SchemaDrift)
+}
+
+// OnSchemaDrift samples requests and compares their params and results with
+// the MethodInfo of the method. The hook is called for each difference found.
+// This catches clients that rely on undocumented params (or responses that no
+// longer match the documentation) before a refactor breaks them.
+//
+// sampleRate is the fraction of requests that are checked, between 0 and 1.
+// Only methods that have MethodInfo are checked. The params are only checked if
+// the MethodInfo has Params, and the result is only checked if Result is set.
+//
+// Types are only compared when the declared type is one of the JSON types:
+// "string", "number", "integer", "boolean", "array", "object" or "null". Other
+// types, such as "User", are not checked.
+//
+// The hook is called before the response is sent, so it should be fast.
+// Passing a nil hook disables detection.
+func (server *SimpleServer) OnSchemaDrift(sampleRate float64, hook func(drift SchemaDrift)) {
+	if hook == nil {
+		server.schemaDrift = nil
+		return
+	}
+
+	server.schemaDrift = &schemaDriftDetector{
+		sampleRate: sampleRate,
+		hook:       hook,
+	}
+}
+
+func (server *SimpleServer) detectSchemaDrift(request Request, response Response) {
+	detector := server.schemaDrift
+	if detector == nil || rand.Float64() >= detector.sampleRate {
+		return
+	}
+
+	info, ok := server.methodInfo[request.Method()]
+	if !ok {
+		return
+	}
+
+	drifts := paramsDrift(info, request.Params())
+	if info.Result != "" && response != nil && response.ErrorCode() == Success {
+		observed := jsonType(decodedResult(response.Result()))
+		if !typeMatches(info.Result, observed) {
+			drifts = append(drifts, SchemaDrift{
+				Field:    "result",
+				Expected: info.Result,
+				Observed: observed,
+			})
+		}
+	}
+
+	for _, drift := range drifts {
+		drift.Method = info.Name
+		detector.hook(drift)
+	}
+}
+
+func paramsDrift(info MethodInfo, params interface{}) (drifts []SchemaDrift) {
+	if len(info.Params) == 0 {
+		return nil
+	}
+
+	check := func(param ParamInfo, value interface{}, present bool) {
+		observed := jsonType(value)
+		switch {
+		case !present:
+			if param.Required {
+				drifts = append(drifts, SchemaDrift{
+					Field:    param.Name,
+					Expected: param.Type,
+				})
+			}
+
+		case !typeMatches(param.Type, observed):
+			drifts = append(drifts, SchemaDrift{
+				Field:    param.Name,
+				Expected: param.Type,
+				Observed: observed,
+			})
+		}
+	}
+
+	switch p := params.(type) {
+	case []interface{}:
+		for i, param := range info.Params {
+			if i < len(p) {
+				check(param, p[i], true)
+			} else {
+				check(param, nil, false)
+			}
+		}
+
+		for i := len(info.Params); i < len(p); i++ {
+			drifts = append(drifts, SchemaDrift{
+				Field:    fmt.Sprintf("[%d]", i),
+				Observed: jsonType(p[i]),
+			})
+		}
+
+	case map[string]interface{}:
+		declared := map[string]bool{}
+		for _, param := range info.Params {
+			declared[param.Name] = true
+			value, present := p[param.Name]
+			check(param, value, present)
+		}
+
+		for _, name := range sortedKeys(p) {
+			if !declared[name] {
+				drifts = append(drifts, SchemaDrift{
+					Field:    name,
+					Observed: jsonType(p[name]),
+				})
+			}
+		}
+
+	default:
+		for _, param := range info.Params {
+			check(param, nil, false)
+		}
+	}
+
+	return drifts
+}
+
+// decodedResult returns the result as it will be seen by the client, since
+// handlers may return any type that can be encoded.
+func decodedResult(result interface{}) interface{} {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return result
+	}
+
+	var decoded interface{}
+	if json.Unmarshal(data, &decoded) != nil {
+		return result
+	}
+
+	return decoded
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// jsonType returns the JSON type of a decoded value.
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+
+	return "unknown"
+}
+
+// typeMatches reports whether the observed JSON type satisfies the declared
+// type. Declared types that are not JSON types always match.
+func typeMatches(declared, observed string) bool {
+	switch strings.ToLower(declared) {
+	case "number":
+		return observed == "number" || observed == "integer"
+	case "bool":
+		return observed == "boolean"
+	case "string", "integer", "boolean", "array", "object", "null":
+		return strings.ToLower(declared) == observed
+	}
+
+	return true
+}
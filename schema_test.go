@@ -0,0 +1,80 @@
+package jsonrpc_test
+
+import (
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimpleServer_OnSchemaDrift(t *testing.T) {
+	server := jsonrpc.NewSimpleServer()
+	server.SetHandler("echo", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponse(request.Params())
+	})
+	server.SetHandler("undocumented", notifyHello)
+	server.SetMethodInfo("echo", jsonrpc.MethodInfo{
+		Params: []jsonrpc.ParamInfo{
+			{Name: "name", Type: "string", Required: true},
+			{Name: "age", Type: "integer"},
+			{Name: "user", Type: "User"},
+		},
+		Result: "array",
+	})
+
+	var drifts []string
+	server.OnSchemaDrift(1, func(drift jsonrpc.SchemaDrift) {
+		drifts = append(drifts, drift.String())
+	})
+
+	for testName, test := range map[string]struct {
+		request string
+		drifts  []string
+	}{
+		"Valid": {
+			`{"jsonrpc":"2.0","method":"echo","params":["bob",30,{}],"id":1}`,
+			nil,
+		},
+		"Named": {
+			`{"jsonrpc":"2.0","method":"echo","params":{"name":"bob","age":1.5,"extra":true},"id":1}`,
+			[]string{
+				"echo: age expected integer but was number",
+				"echo: undeclared extra (boolean)",
+				"echo: result expected array but was object",
+			},
+		},
+		"Positional": {
+			`{"jsonrpc":"2.0","method":"echo","params":[123,30,null,"x"],"id":1}`,
+			[]string{
+				"echo: name expected string but was integer",
+				"echo: undeclared [3] (string)",
+			},
+		},
+		"MissingRequired": {
+			`{"jsonrpc":"2.0","method":"echo","id":1}`,
+			[]string{
+				"echo: missing required name",
+				"echo: result expected array but was null",
+			},
+		},
+		"Undocumented": {
+			`{"jsonrpc":"2.0","method":"undocumented","params":{"foo":1},"id":1}`,
+			nil,
+		},
+	} {
+		t.Run(testName, func(t *testing.T) {
+			drifts = nil
+			server.Handle([]byte(test.request))
+			assert.Equal(t, test.drifts, drifts)
+		})
+	}
+
+	t.Run("NotSampled", func(t *testing.T) {
+		drifts = nil
+		server.OnSchemaDrift(0, func(drift jsonrpc.SchemaDrift) {
+			drifts = append(drifts, drift.String())
+		})
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"echo","id":1}`))
+		assert.Nil(t, drifts)
+	})
+}
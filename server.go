@@ -2,6 +2,9 @@ package jsonrpc
 
 import (
 	"encoding/json"
+	"reflect"
+	"sort"
+	"sync"
 	"time"
 	"sync/atomic"
 )
@@ -10,15 +13,184 @@ import (
 type RequestHandler func(RequestResponder) Response
 
 type Server interface {
-	SetHandler(methodName string, handler RequestHandler)
+	SetHandler(methodName string, handler RequestHandler, options ...HandlerOption)
 	HandleRequest(request RequestResponder) Responses
 	Handle(jsonRequest []byte) Responses
 	HandleWithState(jsonRequest []byte, state State) Responses
 	GetHandler(methodName string) RequestHandler
 }
 
+// SimpleServer is the JSON-RPC server. It is usually created with
+// NewSimpleServer, but the zero value is also ready to use so that it can be
+// embedded in other structs:
+//
+//     type App struct {
+//         jsonrpc.SimpleServer
+//         DB *sql.DB
+//     }
+//
+//     var app App
+//     app.SetHandler("sum", sum)
+//
+// The only difference is that Uptime for the zero value is measured from when
+// it is first used, rather than from when it was created.
 type SimpleServer struct {
-	requestHandlers map[string]RequestHandler
+	// handlerMutex protects handlers, methodPatterns, foldedMethods,
+	// rawHandlers, notificationHandlers and methodMiddleware so that handlers
+	// can be changed while requests are being handled.
+	handlerMutex   sync.RWMutex
+	methodPatterns []methodPattern
+	framing        Framing
+
+	// The registered method names by their lower case name. See
+	// normalizeMethod.
+	foldedMethods map[string][]string
+
+	// See SetHandlerRegistry
+	handlers HandlerRegistry
+
+	// See SetRawHandler
+	rawHandlers map[string]RawHandler
+
+	// See SetNotificationHandler
+	notificationHandlers map[string]NotificationHandler
+
+	// See Use and WithMiddleware
+	middleware       []Middleware
+	methodMiddleware map[string][]Middleware
+
+	// See SetVersionNegotiator
+	versionNegotiator VersionNegotiator
+
+	// See SetCollapseDuplicates
+	collapsedMethods map[string]bool
+	flights          flightGroup
+
+	// See SetCache
+	cache resultCache
+
+	// See EventsHandler
+	events eventStreams
+
+	// See SetSubscriptionStore
+	subscriptionMutex sync.Mutex
+	subscriptionStore SubscriptionStore
+
+	// See SetBroker
+	broker       Broker
+	brokerOrigin string
+
+	// See SetWireCapture
+	wireCapture wireCaptures
+
+	// See SetCompliance
+	compliance Compliance
+
+	// See SetBatchErrorPositions
+	batchErrorPositions bool
+
+	// See SetDebug
+	debug bool
+
+	// See SetHTTPGet
+	httpGet bool
+
+	// See SetMaxHTTPBodySize
+	maxHTTPBodySize int64
+
+	// See SetHTTPBatchStreaming
+	httpBatchStreaming bool
+
+	// See SetMetricsPath
+	metricsPath string
+
+	// See SetAllowReservedMethods
+	allowReservedMethods bool
+
+	// See SetDiscoverMethod, SetPingMethod and SetTraceMethod
+	discoverMethod bool
+	pingMethod     bool
+	traceMethod    bool
+
+	// See SetValidator
+	validator Validator
+
+	// See SetDefaultState
+	defaultState State
+
+	// See OnPanic
+	panicHook PanicHook
+
+	// See SetBatchWorkers
+	batchWorkers int
+
+	// See SetOrderedConnections
+	orderedConnections bool
+
+	// See SetBatchBudget
+	batchBudget BatchBudget
+
+	// See SetMaxBatchSize
+	maxBatchSize int
+
+	// See TransportStats
+	transportStats transportStats
+
+	// See SetMaxConcurrentRequests
+	concurrencySlots   chan struct{}
+	concurrencyTimeout time.Duration
+
+	// See SetCORS
+	cors *CORS
+
+	// See SetMethodInfo
+	methodInfo map[string]MethodInfo
+
+	// See Provide
+	providers map[reflect.Type]reflect.Value
+
+	// See SetClock
+	clock Clock
+
+	// See SetChaos
+	chaos *Chaos
+
+	// See SetFault
+	faults methodFaults
+
+	// See OnSchemaDrift
+	schemaDrift *schemaDriftDetector
+
+	// See SetShadow
+	shadows shadows
+
+	// See SetCompressionThreshold
+	responseSizes responseSizes
+
+	// See SetCompressionCodecs
+	compressionCodecs []CompressionCodec
+
+	// See SetRateLimit
+	rateLimits  map[string]RateLimit
+	rateLimiter RateLimiter
+
+	// See SetSLO
+	slos map[string]*sloTracker
+
+	// See SetAudit
+	auditSink   AuditSink
+	auditCaller func(request Request) string
+
+	// See SetMaintenance
+	maintenance int32
+
+	// See EnableAdmin
+	adminNames   map[string]bool
+	recentErrors recentErrors
+
+	// See SetLimits
+	limits               map[string]Limits
+	totalLimitViolations uint64
 
 	// See StatReporter
 	totalPayloads             uint64
@@ -28,16 +200,142 @@ type SimpleServer struct {
 	totalSuccessNotifications uint64
 	totalErrorNotifications   uint64
 	startTime                 time.Time
+	startOnce                 sync.Once
 	currentActiveRequests     uint64
+
+	// See RequestTimings
+	queuedRequests   uint64
+	totalQueueWait   int64
+	maxQueueWait     int64
+	handledRequests  uint64
+	totalHandlerTime int64
+}
+
+// SetHandler will register (or replace) a handler for a method. Options, such
+// as WithMiddleware, only apply to this method.
+//
+// A segment (separated by ".") of "*" is a wildcard that matches any single
+// segment. This allows a family of methods to share one handler:
+//
+//     server.SetHandler("device.*.status", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+//         deviceID := jsonrpc.MethodWildcards(request)[0]
+//
+//         // ...
+//     })
+//
+// A method that has its own handler is always preferred over a pattern. Then
+// the pattern with the fewest wildcards is used, and finally the pattern that
+// sorts first. Other features that are configured per method, such as
+// SetRateLimit and SetLimits, use the name of the method that was called
+// rather than the pattern. Patterns do not apply to raw or notification
+// handlers.
+//
+// Handlers can be set and removed (see RemoveHandler) while requests are being
+// handled.
+//
+// Methods that start with "rpc." are reserved and SetHandler panics with
+// ErrReservedMethod for them. See SetAllowReservedMethods.
+func (server *SimpleServer) SetHandler(methodName string, handler RequestHandler, options ...HandlerOption) {
+	server.mustCheckMethodName(methodName)
+
+	registry := server.handlerRegistry()
+
+	server.handlerMutex.Lock()
+	defer server.handlerMutex.Unlock()
+
+	delete(server.rawHandlers, methodName)
+	registry.Set(methodName, handler)
+	server.indexMethod(methodName, true)
+	server.setHandlerOptions(methodName, options)
 }
 
-// SetHandler will register (or replace) a handler for a method.
-func (server *SimpleServer) SetHandler(methodName string, handler RequestHandler) {
-	server.requestHandlers[methodName] = handler
+// RemoveHandler unregisters all of the handlers for a method, including raw
+// and notification handlers, and the middleware added with WithMiddleware.
+// Requests for the method will receive a Method not found error. Requests that
+// are already being handled are not affected.
+//
+// It returns false if the method did not have a handler.
+func (server *SimpleServer) RemoveHandler(methodName string) bool {
+	registry := server.handlerRegistry()
+
+	server.handlerMutex.Lock()
+	defer server.handlerMutex.Unlock()
+
+	hasRequest := registry.Get(methodName) != nil
+	_, hasRaw := server.rawHandlers[methodName]
+	_, hasNotification := server.notificationHandlers[methodName]
+
+	registry.Remove(methodName)
+	delete(server.rawHandlers, methodName)
+	delete(server.notificationHandlers, methodName)
+	delete(server.methodMiddleware, methodName)
+	server.indexMethod(methodName, false)
+
+	return hasRequest || hasRaw || hasNotification
 }
 
 func (server *SimpleServer) GetHandler(methodName string) RequestHandler {
-	return server.requestHandlers[methodName]
+	return server.handlerRegistry().Get(methodName)
+}
+
+// Methods returns the names of all the methods that have a handler, sorted
+// alphabetically.
+func (server *SimpleServer) Methods() []string {
+	methods := server.handlerRegistry().List()
+	hasHandler := make(map[string]bool, len(methods))
+	for _, methodName := range methods {
+		hasHandler[methodName] = true
+	}
+
+	server.handlerMutex.RLock()
+	defer server.handlerMutex.RUnlock()
+
+	for methodName := range server.rawHandlers {
+		methods = append(methods, methodName)
+	}
+	for methodName := range server.notificationHandlers {
+		if !hasHandler[methodName] {
+			methods = append(methods, methodName)
+		}
+	}
+
+	sort.Strings(methods)
+
+	return methods
+}
+
+// MethodsPage returns up to limit method names (in the same order as Methods)
+// that come after cursor. Use an empty cursor to start at the beginning. next
+// is the cursor for the following page, or empty if there are no more methods:
+//
+//     cursor := ""
+//     for {
+//         methods, next := server.MethodsPage(cursor, 100)
+//         // ...
+//
+//         if next == "" {
+//             break
+//         }
+//         cursor = next
+//     }
+//
+// The cursor is the last method name of the page, so paging is stable even if
+// methods are added or removed between calls. A limit of zero or less returns
+// all of the remaining methods.
+func (server *SimpleServer) MethodsPage(cursor string, limit int) (methods []string, next string) {
+	all := server.Methods()
+	start := sort.SearchStrings(all, cursor)
+	if start < len(all) && all[start] == cursor && cursor != "" {
+		start++
+	}
+
+	methods = all[start:]
+	if limit > 0 && len(methods) > limit {
+		methods = methods[:limit]
+		next = methods[limit-1]
+	}
+
+	return methods, next
 }
 
 // Requests can be handled two ways, but creating and passing a request
@@ -71,7 +369,19 @@ func (server *SimpleServer) GetHandler(methodName string) RequestHandler {
 // Handle() returns an array of Response interfaces to allow batch processing.
 // The "Batch Requests" second explains this in more detail.
 func (server *SimpleServer) HandleRequest(request RequestResponder) (responses Responses) {
-	server.totalPayloads += 1
+	server.start()
+	atomic.AddUint64(&server.totalPayloads, 1)
+
+	if len(server.defaultState) > 0 {
+		request = &defaultStateRequest{
+			RequestResponder: request,
+			defaults:         server.defaultState,
+		}
+	}
+
+	if server.dispatchNotification(request) {
+		return Responses{}
+	}
 
 	responses = make(Responses, 0)
 	var response Response
@@ -79,40 +389,88 @@ func (server *SimpleServer) HandleRequest(request RequestResponder) (responses R
 	// Always recover from a panic and send it back as an error.
 	defer func(id interface{}) {
 		if r := recover(); r != nil {
+			server.reportPanic(request, r)
 			response = request.NewErrorResponse(ServerError, "")
 		}
 
 		// Track responses.
 		if id == nil {
 			if response.ErrorCode() == Success {
-				server.totalSuccessNotifications += 1
+				atomic.AddUint64(&server.totalSuccessNotifications, 1)
 			} else {
-				server.totalErrorNotifications += 1
+				atomic.AddUint64(&server.totalErrorNotifications, 1)
 			}
 		} else {
 			if response.ErrorCode() == Success {
-				server.totalSuccessResponses += 1
+				atomic.AddUint64(&server.totalSuccessResponses, 1)
 			} else {
-				server.totalErrorResponses += 1
+				atomic.AddUint64(&server.totalErrorResponses, 1)
 			}
 		}
 
+		server.recentErrors.record(server.now(), request.Method(), response)
 		appendResponses(&responses, response)
 	}(request.Id())
 
-	// We only support 2.0 right now.
+	if methodName := server.normalizeMethod(request.Method()); methodName != request.Method() {
+		request = &renamedRequest{request, methodName}
+	}
+
+	if server.validator != nil {
+		request = &validatingRequest{request, server.validator}
+	}
+
+	handler, handlerName, wildcards := server.route(request.Method())
+	if wildcards != nil {
+		request = WithState(request, State{methodWildcardsKey: wildcards})
+	}
+
+	if handler != nil {
+		recordPayloadMethod(request, handlerName)
+	}
+
+	// Other versions are only supported through SetVersionNegotiator.
 	if request.Version() != "2.0" {
-		response = request.NewErrorResponse(InvalidRequest, "Version is not 2.0.")
+		var ok bool
+		handler, ok = server.negotiateVersion(request)
+		if !ok {
+			response = request.NewErrorResponse(InvalidRequest, "Version is not 2.0.")
+			server.auditRejected(request, response)
+			return
+		}
+	}
+
+	if handler == nil && server.notificationHandler(request.Method()) != nil {
+		response = request.NewErrorResponse(InvalidRequest,
+			"Method only accepts notifications.")
+		server.auditRejected(request, response)
 		return
 	}
 
-	handler := server.requestHandlers[request.Method()]
 	if handler == nil {
 		response = request.NewErrorResponse(MethodNotFound, "")
+		server.auditRejected(request, response)
 		return
 	}
 
-	server.totalRequests += 1
+	if server.inMaintenance(request.Method()) {
+		response = request.NewErrorResponse(ServerError, "Server is in maintenance mode.")
+		server.auditRejected(request, response, AuditCheck{
+			Name:    "maintenance",
+			Allowed: false,
+		})
+		return
+	}
+
+	request, release := server.acquireSlot(request)
+	if release == nil {
+		response = request.NewErrorResponse(ServerError, "Server is overloaded.")
+		server.auditRejected(request, response)
+		return
+	}
+	defer release()
+
+	atomic.AddUint64(&server.totalRequests, 1)
 
 	defer func() {
 		// I know this seems a little crazy, but it's the correct way to
@@ -121,27 +479,106 @@ func (server *SimpleServer) HandleRequest(request RequestResponder) (responses R
 	}()
 
 	atomic.AddUint64(&server.currentActiveRequests, 1)
-	response = handler(request)
+
+	request = server.observeQueueWait(request)
+
+	start := server.now()
+	defer func() {
+		server.observeHandlerTime(server.now().Sub(start))
+		server.observeSLO(request.Method(), start, response)
+	}()
+
+	response = server.auditHandler(server.applyMiddleware(handlerName, server.pipeline(handler)))(request)
+	if response == nil {
+		response = request.NewErrorResponse(ServerError, "")
+	}
 
 	return
 }
 
-func (server *SimpleServer) handleSingle(jsonRequest []byte, isPartOfBatch bool, state State) Responses {
-	request, id, errCode, errMessage :=
-		newRequestResponderFromJSON(jsonRequest, isPartOfBatch, state)
+// pipeline returns a handler that calls handler with all of the features of
+// the server, such as rate limits and caching.
+func (server *SimpleServer) pipeline(handler RequestHandler) RequestHandler {
+	return func(request RequestResponder) Response {
+		auditReached(request)
+
+		if response := server.rateLimit(request); response != nil {
+			return response
+		}
+
+		if server.chaos != nil {
+			if response := server.chaos.inject(request); response != nil {
+				return response
+			}
+		}
+
+		if !server.isAdminMethod(request.Method()) {
+			if response := server.faults.inject(request); response != nil {
+				return response
+			}
+		}
+
+		response := server.callHandler(server.limitHandler(request.Method(), handler), request)
+		server.detectSchemaDrift(request, response)
+		server.shadowRequest(request, response)
+
+		return response
+	}
+}
+
+// handleSingle processes a single request. position is the index of the
+// request in a batch, or -1 if the request is not part of a batch.
+func (server *SimpleServer) handleSingle(jsonRequest []byte, position int, state State) Responses {
+	isPartOfBatch := position >= 0
+	errCode, errMessage := server.checkCompliance(jsonRequest)
+
+	// The id is not echoed back when the request breaks the compliance rules,
+	// since it may be the id itself that is invalid.
+	var request RequestResponder
+	var id interface{}
+	if errCode == Success {
+		if responses, ok := server.handleRaw(jsonRequest, state); ok {
+			return responses
+		}
+
+		request, id, errCode, errMessage =
+			newRequestResponderFromJSON(jsonRequest, isPartOfBatch,
+				server.versionNegotiator != nil, state)
+	}
 
 	if errCode != Success {
-		server.totalErrorResponses += 1
+		atomic.AddUint64(&server.totalErrorResponses, 1)
 
-		responses := Responses{}
-		appendResponses(&responses, NewErrorResponse(id, errCode, errMessage))
-		return responses
+		if errCode == ParseError && !isPartOfBatch && server.compliance.ParseErrorEchoesId {
+			id = recoverId(jsonRequest)
+		}
+
+		data := map[string]interface{}{}
+		if isPartOfBatch && server.batchErrorPositions {
+			data["position"] = position
+		}
+
+		if errCode == ParseError && server.debug {
+			for key, value := range parseErrorPosition(jsonRequest) {
+				data[key] = value
+			}
+		}
+
+		// An invalid request is always sent back, even if the id is null
+		// because it could not be determined.
+		response := NewErrorResponse(id, errCode, errMessage)
+		if len(data) > 0 {
+			response = NewErrorResponseWithData(id, errCode, errMessage, data)
+		}
+		server.recentErrors.record(server.now(), "", response)
+
+		return Responses{response}
 	}
 
 	// HandleRequest will increment the totalPayloads because it is part of the
 	// public API. However, here we are calling it from a private API so correct
 	// its value.
-	server.totalPayloads -= 1
+	atomic.AddUint64(&server.totalPayloads, ^uint64(0))
 
 	return server.HandleRequest(request)
 }
@@ -180,52 +617,115 @@ func appendResponses(responses *Responses, response Response) {
 // processed (whether single requests or batch) in a are non-deterministic and
 // should be considered to be run all at the same time.
 func (server *SimpleServer) HandleWithState(jsonRequest []byte, state State) Responses {
-	server.totalPayloads += 1
+	responses, _ := server.handlePayload(jsonRequest, state)
+
+	return responses
+}
 
-	responses := make(Responses, 0)
+// handlePayload is HandleWithState that also returns what was learned about
+// the payload while it was parsed, so that it does not need to be parsed again
+// to send the responses back.
+func (server *SimpleServer) handlePayload(jsonRequest []byte, state State) (responses Responses, info payloadInfo) {
+	server.start()
+	atomic.AddUint64(&server.totalPayloads, 1)
+
+	// The state is shared by all requests in a batch, which may be handled
+	// concurrently. A private copy ensures that it cannot be changed while
+	// they are running.
+	state = state.copy()
+
+	server.observePayload(state)
+	defer func() {
+		server.observeTransportResponses(state, responses)
+	}()
+
+	var methods *payloadMethods
+	if server.responseSizes.enabled() {
+		methods = &payloadMethods{}
+		state[payloadMethodsKey] = methods
+		defer func() {
+			info.methods = methods.methods
+		}()
+	}
+
+	responses = make(Responses, 0)
 
 	// Check for a batch request.
-	var batchRequest []interface{}
+	// Each request is kept as raw JSON so that it can be parsed on its own.
+	var batchRequest []json.RawMessage
 	err := json.Unmarshal(jsonRequest, &batchRequest)
 	if err == nil {
+		info.isBatch = true
+		info.batchSize = len(batchRequest)
+
 		// It is a batch request, make sure it is not empty. Normally I wouldn't
 		// care and happily return an empty array of results back but the
 		// JSON-RPC spec says this is an invalid request.
 		if len(batchRequest) == 0 {
-			server.totalErrorResponses += 1
+			if server.compliance.EmptyBatchReturnsEmptyArray {
+				return responses, info
+			}
+
+			atomic.AddUint64(&server.totalErrorResponses, 1)
 
 			return Responses{NewErrorResponse(nil, InvalidRequest,
-				"Batch is empty.")}
+				"Batch is empty.")}, info
 		}
 
-		// Validate each of the requests because some of them may be good and
-		// some invalid.
-		for _, probableRequest := range batchRequest {
-			// We have to marshall each request back to JSON, then treat each
-			// one as an independent request.
-			rawMessage, err := json.Marshal(probableRequest)
-			if err != nil {
-				// This condition should not be possible since we have already
-				// unmarshalled this object once. Still, better to be safe than
-				// sorry.
-				response := NewErrorResponse(nil, ParseError, err.Error())
-				responses = append(responses, response)
-				continue
-			}
+		if server.batchTooLarge(len(batchRequest)) {
+			atomic.AddUint64(&server.totalErrorResponses, 1)
 
-			results := server.handleSingle(rawMessage, true, state)
-			for _, response := range results {
-				appendResponses(&responses, response)
-			}
+			return Responses{NewErrorResponseWithData(nil, InvalidRequest,
+				"Batch is too large.", map[string]interface{}{
+					"maxBatchSize": server.maxBatchSize,
+				})}, info
 		}
+
+		// Validate each of the requests because some of them may be good and
+		// some invalid.
+		responses = append(responses, server.handleBatch(batchRequest, state)...)
 	} else {
-		results := server.handleSingle(jsonRequest, false, state)
-		for _, response := range results {
-			appendResponses(&responses, response)
-		}
+		responses = append(responses,
+			server.handleSingle(jsonRequest, -1, state)...)
 	}
 
-	return responses
+	return responses, info
+}
+
+// SetBatchErrorPositions will include the position of invalid requests in a
+// batch as the error data. This allows clients to tell which requests of a
+// large batch were malformed, since the id of a malformed request cannot be
+// known. For example, the batch:
+//
+//     [{"jsonrpc": "2.0", "method": "sum", "params": [1], "id": 1}, "foo"]
+//
+// Will contain the error:
+//
+//     {"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Invalid request","data":{"position":1}}}
+//
+// Positions start at 0.
+func (server *SimpleServer) SetBatchErrorPositions(enabled bool) {
+	server.batchErrorPositions = enabled
+}
+
+// SetDefaultState provides State values to every request, so that values such
+// as the name of the environment or the build version do not need to be passed
+// to every HandleWithState call:
+//
+//     server.SetDefaultState(jsonrpc.State{
+//         "env":     "production",
+//         "version": buildVersion,
+//     })
+//
+// The State of a request always takes precedence over the defaults. The
+// defaults are seen by middleware and notification handlers (through
+// ContextState), but not by raw handlers. WithDefaultState only provides
+// values for keys that are not in these defaults.
+//
+// state is copied so changing it after calling SetDefaultState has no effect.
+// It replaces any previous defaults.
+func (server *SimpleServer) SetDefaultState(state State) {
+	server.defaultState = state.copy()
 }
 
 func (server *SimpleServer) Handle(jsonRequest []byte) Responses {
@@ -235,14 +735,20 @@ func (server *SimpleServer) Handle(jsonRequest []byte) Responses {
 // Example:
 //
 //     func sayHello(request jsonrpc.RequestResponder) jsonrpc.Response {
-//         return request.NewSuccessResponse("Hello, " + request.Param("name").(string))
+//         name, _ := request.ParamString("name")
+//
+//         return request.NewSuccessResponse("Hello, " + name)
 //     }
 //
 //     server := jsonrpc.NewSimpleServer()
 //     server.SetHandler("sayHello", sayHello)
 func NewSimpleServer() *SimpleServer {
 	return &SimpleServer{
-		requestHandlers: make(map[string]RequestHandler),
-		startTime:       time.Now(),
+		handlers:         &MemoryHandlerRegistry{},
+		collapsedMethods: make(map[string]bool),
+		methodInfo:       make(map[string]MethodInfo),
+		providers:        make(map[reflect.Type]reflect.Value),
+		clock:            systemClock{},
+		startTime:        time.Now(),
 	}
 }
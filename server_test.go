@@ -1,14 +1,18 @@
 package jsonrpc_test
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"github.com/elliotchance/jsonrpc"
 	"math/rand"
 	"reflect"
 	"regexp"
+	"sync"
 	"testing"
 	"github.com/stretchr/testify/assert"
 	"fmt"
+	"time"
 )
 
 func TestErrorMessageForCode(t *testing.T) {
@@ -449,13 +453,11 @@ var specTests = map[string]struct {
 func TestJSONRPCSpecification(t *testing.T) {
 	for testName, test := range specTests {
 		t.Run(testName, func(t *testing.T) {
-			if testName == "rpc call with positional parameters 1" {
-				server := newTestServer()
-				responses := server.Handle([]byte(test.j))
+			server := newTestServer()
+			responses := server.Handle([]byte(test.j))
 
-				if !reflect.DeepEqual(responses, test.r) {
-					t.Errorf("TestJSONRPCSpecification:\n%v\n%v", responses, test.r)
-				}
+			if !reflect.DeepEqual(responses, test.r) {
+				t.Errorf("TestJSONRPCSpecification:\n%v\n%v", responses, test.r)
 			}
 		})
 	}
@@ -549,6 +551,27 @@ func TestStatefulRequestWithKey(t *testing.T) {
 	assert.Equal(t, "bar", responses[0].Result())
 }
 
+func TestStatefulRequestBatchIsolation(t *testing.T) {
+	server := jsonrpc.NewSimpleServer()
+	server.SetHandler("layer", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		if request.Id() == 1.0 {
+			request = jsonrpc.WithState(request, jsonrpc.State{"foo": "baz"})
+		}
+
+		return handlerWithState(request)
+	})
+
+	state := jsonrpc.State{"foo": "bar"}
+	responses := server.HandleWithState([]byte(`[
+		{"jsonrpc": "2.0", "method": "layer", "id": 1},
+		{"jsonrpc": "2.0", "method": "layer", "id": 2}
+	]`), state)
+
+	assert.Equal(t, "baz", responses[0].Result())
+	assert.Equal(t, "bar", responses[1].Result())
+	assert.Equal(t, jsonrpc.State{"foo": "bar"}, state)
+}
+
 func TestSimpleServerIsAServer(t *testing.T) {
 	server := newTestServer()
 	assert.Implements(t, (*jsonrpc.Server)(nil), server)
@@ -626,3 +649,187 @@ func TestNewResponsesFromJSONWithSingleResponseErrorIsCompatible(t *testing.T) {
 	assert.Equal(t, jsonrpc.InternalError, responses[0].ErrorCode())
 	assert.Equal(t, "bar", responses[0].ErrorMessage())
 }
+
+func TestSimpleServer_SetBatchErrorPositions(t *testing.T) {
+	batch := `[{"jsonrpc": "2.0", "method": "sum", "params": [1], "id": 1}, "foo", 1]`
+
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		responses := newTestServer().Handle([]byte(batch))
+
+		assert.Nil(t, responses[1].ErrorData())
+		assert.Nil(t, responses[2].ErrorData())
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		server := newTestServer()
+		server.SetBatchErrorPositions(true)
+		responses := server.Handle([]byte(batch))
+
+		assert.Nil(t, responses[0].ErrorData())
+		assert.Equal(t, map[string]interface{}{"position": 1}, responses[1].ErrorData())
+		assert.Equal(t, map[string]interface{}{"position": 2}, responses[2].ErrorData())
+		assert.Equal(t, `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Invalid request","data":{"position":1}}}`,
+			responses[1].String())
+	})
+
+	t.Run("SingleRequest", func(t *testing.T) {
+		server := newTestServer()
+		server.SetBatchErrorPositions(true)
+		responses := server.Handle([]byte(`"foo"`))
+
+		assert.Nil(t, responses[0].ErrorData())
+	})
+}
+
+func TestSimpleServer_Methods(t *testing.T) {
+	assert.Equal(t, []string{}, jsonrpc.NewSimpleServer().Methods())
+	assert.Equal(t, []string{
+		"get_data", "handlerWithState", "hangUntilChannel", "notify_hello",
+		"panic", "subtract", "sum",
+	}, newTestServer().Methods())
+}
+
+func TestSimpleServer_RemoveHandler(t *testing.T) {
+	server := newTestServer()
+	server.SetNotificationHandler("sum", func(ctx context.Context, request jsonrpc.Request) {})
+	server.SetRawHandler("raw", func(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, *jsonrpc.RPCError) {
+		return json.RawMessage(`true`), nil
+	})
+
+	assert.True(t, server.RemoveHandler("sum"))
+	assert.True(t, server.RemoveHandler("raw"))
+	assert.False(t, server.RemoveHandler("sum"))
+	assert.False(t, server.RemoveHandler("missing"))
+
+	assert.Nil(t, server.GetHandler("sum"))
+	assert.NotContains(t, server.Methods(), "sum")
+	assert.NotContains(t, server.Methods(), "raw")
+
+	responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+	assert.Equal(t, jsonrpc.MethodNotFound, responses[0].ErrorCode())
+
+	responses = server.Handle([]byte(`{"jsonrpc":"2.0","method":"raw","id":1}`))
+	assert.Equal(t, jsonrpc.MethodNotFound, responses[0].ErrorCode())
+
+	responses = server.Handle([]byte(`{"jsonrpc":"2.0","method":"subtract","params":[3,2],"id":1}`))
+	assert.Equal(t, 1.0, responses[0].Result())
+}
+
+func TestSimpleServer_RemoveHandler_Concurrent(t *testing.T) {
+	server := newTestServer()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"toggle","id":1}`))
+				code := responses[0].ErrorCode()
+				assert.True(t, code == jsonrpc.Success || code == jsonrpc.MethodNotFound)
+			}
+		}()
+	}
+
+	passThrough := func(next jsonrpc.RequestHandler) jsonrpc.RequestHandler {
+		return next
+	}
+
+	for j := 0; j < 100; j++ {
+		server.SetHandler("toggle", sum, jsonrpc.WithMiddleware(passThrough))
+		server.RemoveHandler("toggle")
+	}
+
+	wg.Wait()
+}
+
+func TestSimpleServer_MethodsPage(t *testing.T) {
+	server := newTestServer()
+
+	methods, next := server.MethodsPage("", 3)
+	assert.Equal(t, []string{"get_data", "handlerWithState", "hangUntilChannel"}, methods)
+	assert.Equal(t, "hangUntilChannel", next)
+
+	// Methods added before the cursor do not affect the next page.
+	server.SetHandler("a", sum)
+
+	methods, next = server.MethodsPage(next, 3)
+	assert.Equal(t, []string{"notify_hello", "panic", "subtract"}, methods)
+	assert.Equal(t, "subtract", next)
+
+	methods, next = server.MethodsPage(next, 3)
+	assert.Equal(t, []string{"sum"}, methods)
+	assert.Equal(t, "", next)
+
+	methods, next = server.MethodsPage("", 0)
+	assert.Len(t, methods, 8)
+	assert.Equal(t, "", next)
+
+	// The cursor does not need to be an existing method.
+	methods, _ = server.MethodsPage("r", 1)
+	assert.Equal(t, []string{"subtract"}, methods)
+}
+
+func TestSimpleServer_ZeroValue(t *testing.T) {
+	type app struct {
+		jsonrpc.SimpleServer
+		name string
+	}
+
+	var a app
+	a.SetHandler("sum", sum)
+	a.SetMethodInfo("sum", jsonrpc.MethodInfo{Result: "number"})
+	a.SetCollapseDuplicates("sum", true)
+	assert.NoError(t, a.Provide(func() string { return "app" }))
+	assert.NoError(t, a.SetInjectedHandler("name", func(request jsonrpc.RequestResponder, name string) jsonrpc.Response {
+		return request.NewSuccessResponse(name)
+	}))
+
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":3}`,
+		a.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))[0].String())
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":"app"}`,
+		a.Handle([]byte(`{"jsonrpc":"2.0","method":"name","id":1}`))[0].String())
+	assert.Equal(t, jsonrpc.MethodInfo{Name: "sum", Result: "number"}, a.MethodInfo("sum"))
+
+	uptime := a.Uptime()
+	assert.True(t, uptime >= 0 && uptime < time.Minute, "uptime %v", uptime)
+}
+
+func TestSimpleServer_SetDefaultState(t *testing.T) {
+	server := jsonrpc.NewSimpleServer()
+	server.SetHandler("env", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponse([]interface{}{request.State("env"), request.State("version")})
+	})
+
+	defaults := jsonrpc.State{"env": "production", "version": "1.2.3"}
+	server.SetDefaultState(defaults)
+	defaults["env"] = "changed"
+
+	request := []byte(`{"jsonrpc":"2.0","method":"env","id":1}`)
+
+	t.Run("Handle", func(t *testing.T) {
+		responses := server.Handle(request)
+		assert.Equal(t, []interface{}{"production", "1.2.3"}, responses[0].Result())
+	})
+
+	t.Run("HandleWithState", func(t *testing.T) {
+		responses := server.HandleWithState(request, jsonrpc.State{"env": "staging"})
+		assert.Equal(t, []interface{}{"staging", "1.2.3"}, responses[0].Result())
+	})
+
+	t.Run("HandleRequest", func(t *testing.T) {
+		responses := server.HandleRequest(jsonrpc.NewRequestResponder("2.0", 1, "env", nil))
+		assert.Equal(t, []interface{}{"production", "1.2.3"}, responses[0].Result())
+	})
+
+	t.Run("Notification", func(t *testing.T) {
+		env := make(chan interface{}, 1)
+		server.SetNotificationHandler("log", func(ctx context.Context, request jsonrpc.Request) {
+			env <- jsonrpc.ContextState(ctx, "env")
+		})
+
+		server.Handle([]byte(`{"jsonrpc":"2.0","method":"log"}`))
+		assert.Equal(t, "production", <-env)
+	})
+}
@@ -0,0 +1,278 @@
+package jsonrpc
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Shadow sends a copy of the requests for a method to another handler so that
+// a new implementation can be compared against the existing one with real
+// traffic before it replaces it. See SetShadow.
+type Shadow struct {
+	// Handler is the new implementation. Its responses are only compared,
+	// they are never sent to the client.
+	Handler RequestHandler
+
+	// FloatTolerance is the largest difference between two numbers that are
+	// still considered equal.
+	FloatTolerance float64
+
+	// TimeTolerance is the largest difference between two RFC 3339 timestamps
+	// that are still considered equal.
+	TimeTolerance time.Duration
+
+	// IgnoreFields are object keys (at any depth) that are not compared, such
+	// as "requestId" or "generatedAt".
+	IgnoreFields []string
+
+	// OnMismatch is called when the responses are different. Each difference
+	// describes the path and the two values, for example:
+	//
+	//     result.total: 12 != 13
+	//
+	// It may be nil if only the ShadowStats are needed.
+	OnMismatch func(request Request, differences []string)
+}
+
+// ShadowStats counts the comparisons for a method.
+type ShadowStats struct {
+	Compared   uint64
+	Mismatched uint64
+}
+
+// MismatchRate is the fraction of compared responses that were different.
+func (stats ShadowStats) MismatchRate() float64 {
+	if stats.Compared == 0 {
+		return 0
+	}
+
+	return float64(stats.Mismatched) / float64(stats.Compared)
+}
+
+type shadowState struct {
+	shadow     Shadow
+	ignore     map[string]bool
+	compared   uint64
+	mismatched uint64
+}
+
+type shadows struct {
+	mutex    sync.Mutex
+	byMethod map[string]*shadowState
+}
+
+// SetShadow sends a copy of every request for a method to shadow.Handler in the
+// background, after the real handler has responded. The responses are compared
+// and differences are reported through shadow.OnMismatch and ShadowStats:
+//
+//     server.SetShadow("getUser", &jsonrpc.Shadow{
+//         Handler:       getUserV2,
+//         TimeTolerance: time.Second,
+//         OnMismatch: func(request jsonrpc.Request, differences []string) {
+//             log.Println(request, differences)
+//         },
+//     })
+//
+// The context of the shadow request (see RequestContext) is not cancelled when
+// the real request is finished.
+//
+// Only use shadowing for methods that have no side effects, since both handlers
+// will be called. Setting a nil shadow stops shadowing and resets the stats.
+func (server *SimpleServer) SetShadow(methodName string, shadow *Shadow) {
+	server.shadows.mutex.Lock()
+	defer server.shadows.mutex.Unlock()
+
+	if shadow == nil {
+		delete(server.shadows.byMethod, methodName)
+		return
+	}
+
+	if server.shadows.byMethod == nil {
+		server.shadows.byMethod = make(map[string]*shadowState)
+	}
+
+	state := &shadowState{
+		shadow: *shadow,
+		ignore: map[string]bool{},
+	}
+	for _, field := range shadow.IgnoreFields {
+		state.ignore[field] = true
+	}
+
+	server.shadows.byMethod[methodName] = state
+}
+
+// ShadowStats returns the comparisons made for a method since SetShadow was
+// called.
+func (server *SimpleServer) ShadowStats(methodName string) ShadowStats {
+	server.shadows.mutex.Lock()
+	state := server.shadows.byMethod[methodName]
+	server.shadows.mutex.Unlock()
+
+	if state == nil {
+		return ShadowStats{}
+	}
+
+	return ShadowStats{
+		Compared:   atomic.LoadUint64(&state.compared),
+		Mismatched: atomic.LoadUint64(&state.mismatched),
+	}
+}
+
+// shadowRequest runs the shadow handler for the request, if there is one.
+func (server *SimpleServer) shadowRequest(request RequestResponder, response Response) {
+	server.shadows.mutex.Lock()
+	state := server.shadows.byMethod[request.Method()]
+	server.shadows.mutex.Unlock()
+
+	if state == nil || response == nil {
+		return
+	}
+
+	// The shadow usually finishes after the response has been sent.
+	request = detachRequest(request)
+
+	go func() {
+		var shadowResponse Response
+		func() {
+			// A panic in the shadow is a mismatch, not a crash.
+			defer func() {
+				if r := recover(); r != nil {
+					server.reportPanic(request, r)
+					shadowResponse = request.NewErrorResponse(ServerError, "")
+				}
+			}()
+
+			shadowResponse = state.shadow.Handler(request)
+		}()
+
+		differences := state.diff("", comparableResponse(response),
+			comparableResponse(shadowResponse))
+
+		atomic.AddUint64(&state.compared, 1)
+		if len(differences) > 0 {
+			atomic.AddUint64(&state.mismatched, 1)
+
+			if state.shadow.OnMismatch != nil {
+				state.shadow.OnMismatch(request, differences)
+			}
+		}
+	}()
+}
+
+// comparableResponse returns the parts of a response that are compared, in
+// the same form that the client would decode them.
+func comparableResponse(response Response) map[string]interface{} {
+	if response == nil {
+		return nil
+	}
+
+	if response.ErrorCode() == Success {
+		return map[string]interface{}{
+			"result": decodedResult(response.Result()),
+		}
+	}
+
+	return map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    float64(response.ErrorCode()),
+			"message": response.ErrorMessage(),
+			"data":    decodedResult(response.ErrorData()),
+		},
+	}
+}
+
+// diff returns the differences between two decoded JSON values.
+func (state *shadowState) diff(path string, a, b interface{}) (differences []string) {
+	different := func() []string {
+		return []string{fmt.Sprintf("%s: %v != %v", path, a, b)}
+	}
+
+	switch a := a.(type) {
+	case map[string]interface{}:
+		bMap, ok := b.(map[string]interface{})
+		if !ok {
+			return different()
+		}
+
+		keys := map[string]bool{}
+		for key := range a {
+			keys[key] = true
+		}
+		for key := range bMap {
+			keys[key] = true
+		}
+
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			if !state.ignore[key] {
+				sorted = append(sorted, key)
+			}
+		}
+		sort.Strings(sorted)
+
+		for _, key := range sorted {
+			keyPath := key
+			if path != "" {
+				keyPath = path + "." + key
+			}
+
+			differences = append(differences, state.diff(keyPath, a[key], bMap[key])...)
+		}
+
+		return differences
+
+	case []interface{}:
+		bSlice, ok := b.([]interface{})
+		if !ok || len(a) != len(bSlice) {
+			return different()
+		}
+
+		for i := range a {
+			differences = append(differences,
+				state.diff(fmt.Sprintf("%s[%d]", path, i), a[i], bSlice[i])...)
+		}
+
+		return differences
+
+	case float64:
+		if bFloat, ok := b.(float64); ok && math.Abs(a-bFloat) <= state.shadow.FloatTolerance {
+			return nil
+		}
+
+		return different()
+
+	case string:
+		bString, ok := b.(string)
+		if ok && a == bString {
+			return nil
+		}
+
+		if ok && state.shadow.TimeTolerance > 0 {
+			aTime, aErr := time.Parse(time.RFC3339Nano, a)
+			bTime, bErr := time.Parse(time.RFC3339Nano, bString)
+			if aErr == nil && bErr == nil {
+				delta := aTime.Sub(bTime)
+				if delta < 0 {
+					delta = -delta
+				}
+
+				if delta <= state.shadow.TimeTolerance {
+					return nil
+				}
+			}
+		}
+
+		return different()
+	}
+
+	if a != b {
+		return different()
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimpleServer_SetShadow(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	result := func(total float64, at time.Time, id string) map[string]interface{} {
+		return map[string]interface{}{
+			"total":     total,
+			"at":        at.Format(time.RFC3339),
+			"requestId": id,
+		}
+	}
+
+	server := jsonrpc.NewSimpleServer()
+	server.SetHandler("report", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponse(result(10, now, "a"))
+	})
+
+	mismatches := make(chan []string, 10)
+	shadow := func(handler jsonrpc.RequestHandler) *jsonrpc.Shadow {
+		return &jsonrpc.Shadow{
+			Handler:        handler,
+			FloatTolerance: 0.01,
+			TimeTolerance:  time.Second,
+			IgnoreFields:   []string{"requestId"},
+			OnMismatch: func(request jsonrpc.Request, differences []string) {
+				mismatches <- differences
+			},
+		}
+	}
+
+	handle := func() {
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"report","id":1}`))
+		assert.Equal(t, 10.0, responses[0].Result().(map[string]interface{})["total"])
+	}
+
+	waitForCompared := func(n uint64) {
+		for i := 0; i < 100 && server.ShadowStats("report").Compared < n; i++ {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	t.Run("Equivalent", func(t *testing.T) {
+		server.SetShadow("report", shadow(func(request jsonrpc.RequestResponder) jsonrpc.Response {
+			return request.NewSuccessResponse(result(10.005, now.Add(time.Second), "b"))
+		}))
+
+		handle()
+		waitForCompared(1)
+		assert.Equal(t, jsonrpc.ShadowStats{Compared: 1}, server.ShadowStats("report"))
+		assert.Len(t, mismatches, 0)
+	})
+
+	t.Run("Different", func(t *testing.T) {
+		server.SetShadow("report", shadow(func(request jsonrpc.RequestResponder) jsonrpc.Response {
+			return request.NewSuccessResponse(result(11, now.Add(time.Minute), "a"))
+		}))
+
+		handle()
+		waitForCompared(1)
+		assert.Equal(t, []string{
+			"result.at: 2020-01-02T03:04:05Z != 2020-01-02T03:05:05Z",
+			"result.total: 10 != 11",
+		}, <-mismatches)
+		assert.Equal(t, 1.0, server.ShadowStats("report").MismatchRate())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		server.SetShadow("report", shadow(forcePanic))
+
+		handle()
+		waitForCompared(1)
+		assert.Equal(t, []string{
+			"error: <nil> != map[code:-32000 data:<nil> message:Server error]",
+			"result: map[at:2020-01-02T03:04:05Z requestId:a total:10] != <nil>",
+		}, <-mismatches)
+	})
+
+	t.Run("OutlivesRequest", func(t *testing.T) {
+		server.SetShadow("report", shadow(func(request jsonrpc.RequestResponder) jsonrpc.Response {
+			time.Sleep(5 * time.Millisecond)
+			if err := jsonrpc.RequestContext(request).Err(); err != nil {
+				return request.NewErrorResponse(jsonrpc.ServerError, err.Error())
+			}
+
+			return request.NewSuccessResponse(result(10, now, "b"))
+		}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		server.HandleContext(ctx, []byte(`{"jsonrpc":"2.0","method":"report","id":1}`))
+		cancel()
+
+		waitForCompared(1)
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, jsonrpc.ShadowStats{Compared: 1}, server.ShadowStats("report"))
+		assert.Len(t, mismatches, 0)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		server.SetShadow("report", nil)
+
+		handle()
+		assert.Equal(t, jsonrpc.ShadowStats{}, server.ShadowStats("report"))
+	})
+}
@@ -0,0 +1,123 @@
+package jsonrpc
+
+import (
+	"sync"
+)
+
+// A flight is a single execution of a handler that may be shared by many
+// identical requests.
+type flight struct {
+	done     sync.WaitGroup
+	response Response
+}
+
+// flightGroup collapses concurrent calls with the same key into a single call.
+type flightGroup struct {
+	mutex   sync.Mutex
+	flights map[string]*flight
+}
+
+// do runs fn for the key, unless there is already a call in progress for the
+// key. In that case it will wait for the existing call to finish and return the
+// same response.
+//
+// If fn panics the panic is passed up to the original caller and all of the
+// other callers receive nil.
+func (group *flightGroup) do(key string, fn func() Response) Response {
+	group.mutex.Lock()
+	if group.flights == nil {
+		group.flights = make(map[string]*flight)
+	}
+
+	if f, ok := group.flights[key]; ok {
+		group.mutex.Unlock()
+		f.done.Wait()
+
+		return f.response
+	}
+
+	f := new(flight)
+	f.done.Add(1)
+	group.flights[key] = f
+	group.mutex.Unlock()
+
+	defer func() {
+		group.mutex.Lock()
+		delete(group.flights, key)
+		group.mutex.Unlock()
+
+		f.done.Done()
+	}()
+
+	f.response = fn()
+
+	return f.response
+}
+
+// SetCollapseDuplicates enables (or disables) collapsing of concurrent
+// requests for a method. When enabled, requests that have the same method and
+// params while an identical request is already being handled will not call the
+// handler again. Instead they will wait for the result of the original request.
+//
+// This should only be used for idempotent methods, such as reading data. The
+// State of the duplicate requests is not considered so it is not suitable for
+// methods that depend on State.
+func (server *SimpleServer) SetCollapseDuplicates(methodName string, collapse bool) {
+	if collapse {
+		if server.collapsedMethods == nil {
+			server.collapsedMethods = make(map[string]bool)
+		}
+
+		server.collapsedMethods[methodName] = true
+	} else {
+		delete(server.collapsedMethods, methodName)
+	}
+}
+
+// callCollapsed runs the handler for a request, collapsing duplicate requests
+// if it has been enabled for the method.
+func (server *SimpleServer) callCollapsed(handler RequestHandler, request RequestResponder) Response {
+	if !server.collapsedMethods[request.Method()] {
+		return handler(request)
+	}
+
+	key, ok := requestKey(request)
+	if !ok {
+		return handler(request)
+	}
+
+	response := server.flights.do(key, func() Response {
+		return handler(request)
+	})
+
+	// The original handler panicked.
+	if response == nil {
+		return request.NewErrorResponse(ServerError, "")
+	}
+
+	return copyResponse(request, response)
+}
+
+// requestKey returns a key that is the same for all requests with the same
+// method and params. false is returned if the params cannot be encoded.
+func requestKey(request Request) (string, bool) {
+	key := RequestHash(request)
+
+	return key, key != ""
+}
+
+// copyResponse creates a new response for the request with the same result
+// or error as response. This is needed when a response is shared between
+// requests because each caller needs to receive their own ID.
+func copyResponse(request RequestResponder, response Response) Response {
+	if response.ErrorCode() == Success {
+		if maxAge, ok := ResponseMaxAge(response); ok {
+			return request.NewSuccessResponseWithMaxAge(response.Result(), maxAge)
+		}
+
+		return request.NewSuccessResponse(response.Result())
+	}
+
+	return request.NewErrorResponseWithData(response.ErrorCode(),
+		response.ErrorMessage(), response.ErrorData())
+}
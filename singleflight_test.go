@@ -0,0 +1,118 @@
+package jsonrpc_test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCollapsingTestServer(calls *uint64, release chan bool) *jsonrpc.SimpleServer {
+	server := jsonrpc.NewSimpleServer()
+	server.SetHandler("slow", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		atomic.AddUint64(calls, 1)
+		<-release
+
+		return request.NewSuccessResponse(request.Params())
+	})
+
+	return server
+}
+
+// waitForActiveRequests blocks until n requests are inflight. There is a short
+// grace period afterwards to allow the requests to reach the handler.
+func waitForActiveRequests(server *jsonrpc.SimpleServer, n uint64) {
+	for server.CurrentActiveRequests() != n {
+		time.Sleep(time.Millisecond)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+}
+
+func TestSimpleServer_SetCollapseDuplicates(t *testing.T) {
+	t.Run("IdenticalRequestsAreCollapsed", func(t *testing.T) {
+		calls := uint64(0)
+		release := make(chan bool)
+		server := newCollapsingTestServer(&calls, release)
+		server.SetCollapseDuplicates("slow", true)
+
+		wg := sync.WaitGroup{}
+		responses := make([]jsonrpc.Responses, 5)
+		for i := range responses {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				responses[i] = server.Handle([]byte(fmt.Sprintf(
+					`{"jsonrpc": "2.0", "method": "slow", "params": {"a": 1, "b": 2}, "id": %d}`, i)))
+			}(i)
+		}
+
+		waitForActiveRequests(server, 5)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, uint64(1), calls)
+		for i, r := range responses {
+			assert.Equal(t, float64(i), r[0].Id())
+			assert.Equal(t, map[string]interface{}{"a": 1.0, "b": 2.0}, r[0].Result())
+		}
+	})
+
+	t.Run("DifferentParamsAreNotCollapsed", func(t *testing.T) {
+		calls := uint64(0)
+		release := make(chan bool)
+		server := newCollapsingTestServer(&calls, release)
+		server.SetCollapseDuplicates("slow", true)
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 3; i += 1 {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				server.Handle([]byte(fmt.Sprintf(
+					`{"jsonrpc": "2.0", "method": "slow", "params": [%d], "id": 1}`, i)))
+			}(i)
+		}
+
+		waitForActiveRequests(server, 3)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, uint64(3), calls)
+	})
+
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		calls := uint64(0)
+		release := make(chan bool)
+		server := newCollapsingTestServer(&calls, release)
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 3; i += 1 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				server.Handle([]byte(`{"jsonrpc": "2.0", "method": "slow", "params": [1], "id": 1}`))
+			}()
+		}
+
+		waitForActiveRequests(server, 3)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, uint64(3), calls)
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		server := newTestServer()
+		server.SetCollapseDuplicates("panic", true)
+		responses := server.Handle([]byte(`{"jsonrpc": "2.0", "method": "panic", "id": 1}`))
+
+		assert.Equal(t, jsonrpc.Responses{
+			jsonrpc.NewErrorResponse(float64(1), jsonrpc.ServerError, ""),
+		}, responses)
+	})
+}
@@ -0,0 +1,218 @@
+package jsonrpc
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// The number of buckets that each SLO window is divided into. The window
+// slides forward one bucket at a time.
+const sloBuckets = 60
+
+// SLO is a service level objective for a method. A call is good if it responds
+// successfully within Latency. See SetSLO.
+type SLO struct {
+	// Latency is the longest a good call can take. Zero means that only
+	// errors count against the objective.
+	Latency time.Duration
+
+	// Objective is the fraction of calls that should be good, such as 0.999.
+	Objective float64
+
+	// Windows are the lengths of time to calculate the compliance and burn
+	// rate over. The default is one hour.
+	Windows []time.Duration
+
+	// OnBurnRate is called when the burn rate of a window reaches
+	// BurnRateThreshold. It is not called again for that window until the
+	// burn rate has dropped below the threshold. It is called while handling
+	// the request, so it should not block.
+	OnBurnRate        func(status SLOStatus)
+	BurnRateThreshold float64
+}
+
+// SLOStatus is the compliance of a method with its SLO over one window.
+type SLOStatus struct {
+	Method    string
+	Window    time.Duration
+	Objective float64
+
+	// Total is the number of calls in the window. Good is the number of calls
+	// that met the SLO.
+	Total uint64
+	Good  uint64
+}
+
+// Compliance is the fraction of calls that were good. It is 1 when there have
+// not been any calls.
+func (status SLOStatus) Compliance() float64 {
+	if status.Total == 0 {
+		return 1
+	}
+
+	return float64(status.Good) / float64(status.Total)
+}
+
+// BurnRate is how fast the error budget is being used. A burn rate of 1 uses
+// the whole error budget over the window. Anything above 1 will break the SLO
+// if it continues.
+// An Objective of 1 has no error budget, so the burn rate is infinite if there
+// have been any bad calls.
+func (status SLOStatus) BurnRate() float64 {
+	if status.Objective >= 1 {
+		if status.Good < status.Total {
+			return math.Inf(1)
+		}
+
+		return 0
+	}
+
+	return (1 - status.Compliance()) / (1 - status.Objective)
+}
+
+type sloWindow struct {
+	length  time.Duration
+	buckets [sloBuckets]sloBucket
+	burning bool
+}
+
+type sloBucket struct {
+	start time.Time
+	total uint64
+	good  uint64
+}
+
+type sloTracker struct {
+	method  string
+	slo     SLO
+	mutex   sync.Mutex
+	windows []*sloWindow
+}
+
+// SetSLO tracks the compliance of a method with an SLO. The compliance and
+// burn rate of each window are available from SLOStatus, and OnBurnRate can
+// alert when the error budget is being used too quickly:
+//
+//     server.SetSLO("getUser", jsonrpc.SLO{
+//         Latency:           100 * time.Millisecond,
+//         Objective:         0.999,
+//         Windows:           []time.Duration{5 * time.Minute, time.Hour},
+//         BurnRateThreshold: 14.4,
+//         OnBurnRate: func(status jsonrpc.SLOStatus) {
+//             alert("%s is burning its error budget", status.Method)
+//         },
+//     })
+//
+// An Objective of zero removes the SLO.
+func (server *SimpleServer) SetSLO(methodName string, slo SLO) {
+	if slo.Objective <= 0 {
+		delete(server.slos, methodName)
+		return
+	}
+
+	if len(slo.Windows) == 0 {
+		slo.Windows = []time.Duration{time.Hour}
+	}
+
+	tracker := &sloTracker{method: methodName, slo: slo}
+	for _, length := range slo.Windows {
+		tracker.windows = append(tracker.windows, &sloWindow{length: length})
+	}
+
+	if server.slos == nil {
+		server.slos = make(map[string]*sloTracker)
+	}
+
+	server.slos[methodName] = tracker
+}
+
+// SLOStatus returns the status of each window of the SLO for a method, in the
+// same order as the Windows of the SLO. It is nil if the method does not have
+// an SLO.
+func (server *SimpleServer) SLOStatus(methodName string) []SLOStatus {
+	tracker, ok := server.slos[methodName]
+	if !ok {
+		return nil
+	}
+
+	now := server.now()
+
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	statuses := make([]SLOStatus, len(tracker.windows))
+	for i, window := range tracker.windows {
+		statuses[i] = tracker.status(window, now)
+	}
+
+	return statuses
+}
+
+// observeSLO records a call that started at start. A nil response is a call
+// that panicked.
+func (server *SimpleServer) observeSLO(methodName string, start time.Time, response Response) {
+	tracker, ok := server.slos[methodName]
+	if !ok {
+		return
+	}
+
+	now := server.now()
+	good := response != nil && response.ErrorCode() == Success &&
+		(tracker.slo.Latency == 0 || now.Sub(start) <= tracker.slo.Latency)
+
+	var alerts []SLOStatus
+
+	tracker.mutex.Lock()
+	for _, window := range tracker.windows {
+		bucketLength := int64(window.length / sloBuckets)
+		if bucketLength <= 0 {
+			bucketLength = 1
+		}
+
+		bucketStart := now.UnixNano() / bucketLength * bucketLength
+		bucket := &window.buckets[(bucketStart/bucketLength)%sloBuckets]
+		if bucket.start.UnixNano() != bucketStart {
+			*bucket = sloBucket{start: time.Unix(0, bucketStart)}
+		}
+
+		bucket.total++
+		if good {
+			bucket.good++
+		}
+
+		if tracker.slo.OnBurnRate == nil {
+			continue
+		}
+
+		status := tracker.status(window, now)
+		burning := status.BurnRate() >= tracker.slo.BurnRateThreshold
+		if burning && !window.burning {
+			alerts = append(alerts, status)
+		}
+		window.burning = burning
+	}
+	tracker.mutex.Unlock()
+
+	for _, status := range alerts {
+		tracker.slo.OnBurnRate(status)
+	}
+}
+
+// status must be called while holding the mutex.
+func (tracker *sloTracker) status(window *sloWindow, now time.Time) SLOStatus {
+	status := SLOStatus{
+		Method:    tracker.method,
+		Window:    window.length,
+		Objective: tracker.slo.Objective,
+	}
+
+	for _, bucket := range window.buckets {
+		if bucket.total > 0 && now.Sub(bucket.start) < window.length {
+			status.Total += bucket.total
+			status.Good += bucket.good
+		}
+	}
+
+	return status
+}
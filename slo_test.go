@@ -0,0 +1,119 @@
+package jsonrpc_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/elliotchance/jsonrpc/jsonrpctest"
+	"github.com/stretchr/testify/assert"
+)
+
+// newSLOServer returns a server with a "work" method that takes as many
+// milliseconds (on the clock) as its first param, or fails if it is negative.
+func newSLOServer(clock *jsonrpctest.Clock) *jsonrpc.SimpleServer {
+	server := jsonrpc.NewSimpleServer()
+	server.SetClock(clock)
+	server.SetHandler("work", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		ms := request.Params().([]interface{})[0].(float64)
+		if ms < 0 {
+			return request.NewErrorResponse(jsonrpc.ServerError, "")
+		}
+
+		clock.Advance(time.Duration(ms) * time.Millisecond)
+
+		return request.NewSuccessResponse(true)
+	})
+
+	return server
+}
+
+func work(server *jsonrpc.SimpleServer, ms int) {
+	server.HandleRequest(jsonrpc.NewRequestResponder("2.0", 1, "work", []interface{}{float64(ms)}))
+}
+
+func TestSimpleServer_SetSLO(t *testing.T) {
+	t.Run("Compliance", func(t *testing.T) {
+		clock := jsonrpctest.NewClock(time.Unix(0, 0))
+		server := newSLOServer(clock)
+		server.SetSLO("work", jsonrpc.SLO{
+			Latency:   100 * time.Millisecond,
+			Objective: 0.9,
+			Windows:   []time.Duration{time.Minute, time.Hour},
+		})
+
+		work(server, 10)
+		work(server, 100)
+		work(server, 101)
+		work(server, -1)
+
+		statuses := server.SLOStatus("work")
+		if assert.Len(t, statuses, 2) {
+			assert.Equal(t, jsonrpc.SLOStatus{
+				Method:    "work",
+				Window:    time.Minute,
+				Objective: 0.9,
+				Total:     4,
+				Good:      2,
+			}, statuses[0])
+			assert.Equal(t, 0.5, statuses[0].Compliance())
+			assert.InDelta(t, 5.0, statuses[0].BurnRate(), 0.0001)
+			assert.Equal(t, time.Hour, statuses[1].Window)
+		}
+
+		// The calls slide out of the shorter window.
+		clock.Advance(2 * time.Minute)
+		work(server, 0)
+
+		statuses = server.SLOStatus("work")
+		assert.Equal(t, uint64(1), statuses[0].Total)
+		assert.Equal(t, 1.0, statuses[0].Compliance())
+		assert.Equal(t, 0.0, statuses[0].BurnRate())
+		assert.Equal(t, uint64(5), statuses[1].Total)
+	})
+
+	t.Run("OnBurnRate", func(t *testing.T) {
+		clock := jsonrpctest.NewClock(time.Unix(0, 0))
+		server := newSLOServer(clock)
+
+		var alerts []jsonrpc.SLOStatus
+		server.SetSLO("work", jsonrpc.SLO{
+			Objective:         0.5,
+			Windows:           []time.Duration{time.Minute},
+			BurnRateThreshold: 1,
+			OnBurnRate: func(status jsonrpc.SLOStatus) {
+				alerts = append(alerts, status)
+			},
+		})
+
+		work(server, 0)
+		work(server, -1)
+		work(server, -1)
+		assert.Len(t, alerts, 1)
+
+		// The alert is not repeated until the burn rate has recovered.
+		work(server, -1)
+		assert.Len(t, alerts, 1)
+
+		clock.Advance(time.Hour)
+		work(server, 0)
+		work(server, -1)
+		assert.Len(t, alerts, 2)
+		assert.Equal(t, uint64(2), alerts[1].Total)
+	})
+
+	t.Run("NoErrorBudget", func(t *testing.T) {
+		status := jsonrpc.SLOStatus{Objective: 1, Total: 2, Good: 1}
+		assert.True(t, math.IsInf(status.BurnRate(), 1))
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		server := newSLOServer(jsonrpctest.NewClock(time.Unix(0, 0)))
+		server.SetSLO("work", jsonrpc.SLO{Objective: 0.9})
+		assert.Len(t, server.SLOStatus("work"), 1)
+
+		server.SetSLO("work", jsonrpc.SLO{})
+		assert.Nil(t, server.SLOStatus("work"))
+	})
+}
@@ -0,0 +1,47 @@
+package jsonrpc
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Keys used by RequestAttrs and ResponseAttrs. Using the same keys across all
+// services makes it easy to search and aggregate RPC logs.
+const (
+	LogKeyMethod      = "rpc.method"
+	LogKeyId          = "rpc.id"
+	LogKeyCode        = "rpc.code"
+	LogKeyDurationMs  = "rpc.duration_ms"
+	LogKeyQueueWaitMs = "rpc.queue_wait_ms"
+)
+
+// RequestAttrs returns the slog attributes that describe a request:
+//
+//     logger.LogAttrs(ctx, slog.LevelInfo, "rpc request",
+//         jsonrpc.RequestAttrs(request)...)
+//
+// If the request waited in a queue the time is included, separately from the
+// duration in ResponseAttrs. See RequestQueueWait.
+func RequestAttrs(request Request) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String(LogKeyMethod, request.Method()),
+		slog.Any(LogKeyId, request.Id()),
+	}
+
+	if wait, ok := request.State(queueWaitKey).(time.Duration); ok {
+		attrs = append(attrs, slog.Float64(LogKeyQueueWaitMs, float64(wait)/float64(time.Millisecond)))
+	}
+
+	return attrs
+}
+
+// ResponseAttrs returns the slog attributes that describe a response and how
+// long it took to produce. The code will be Success (0) for successful
+// responses.
+func ResponseAttrs(response Response, duration time.Duration) []slog.Attr {
+	return []slog.Attr{
+		slog.Any(LogKeyId, response.Id()),
+		slog.Int(LogKeyCode, response.ErrorCode()),
+		slog.Float64(LogKeyDurationMs, float64(duration)/float64(time.Millisecond)),
+	}
+}
@@ -0,0 +1,41 @@
+package jsonrpc_test
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestAttrs(t *testing.T) {
+	request := jsonrpc.NewRequestResponder("2.0", 123, "foo", nil)
+
+	assert.Equal(t, []slog.Attr{
+		slog.String("rpc.method", "foo"),
+		slog.Any("rpc.id", 123),
+	}, jsonrpc.RequestAttrs(request))
+}
+
+func TestResponseAttrs(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		response := jsonrpc.NewSuccessResponse(123, "foo")
+
+		assert.Equal(t, []slog.Attr{
+			slog.Any("rpc.id", 123),
+			slog.Int("rpc.code", 0),
+			slog.Float64("rpc.duration_ms", 1.5),
+		}, jsonrpc.ResponseAttrs(response, 1500*time.Microsecond))
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		response := jsonrpc.NewErrorResponse(123, jsonrpc.InvalidParams, "")
+
+		assert.Equal(t, []slog.Attr{
+			slog.Any("rpc.id", 123),
+			slog.Int("rpc.code", jsonrpc.InvalidParams),
+			slog.Float64("rpc.duration_ms", 2),
+		}, jsonrpc.ResponseAttrs(response, 2*time.Millisecond))
+	})
+}
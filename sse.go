@@ -0,0 +1,170 @@
+package jsonrpc
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+)
+
+var (
+	// ErrClientNotConnected is returned from NotifyClient when there are no
+	// event streams open for the client.
+	ErrClientNotConnected = errors.New("Client is not connected")
+
+	// ErrClientQueueFull is returned from NotifyClient when the client is not
+	// reading notifications fast enough.
+	ErrClientQueueFull = errors.New("Client notification queue is full")
+)
+
+// The number of notifications that can be waiting to be sent to an individual
+// event stream.
+const eventStreamQueueSize = 64
+
+// eventStreams keeps track of the open Server-Sent Events streams for each
+// client.
+type eventStreams struct {
+	mutex   sync.Mutex
+	streams map[string]map[chan []byte]bool
+}
+
+func (events *eventStreams) add(clientID string) chan []byte {
+	events.mutex.Lock()
+	defer events.mutex.Unlock()
+
+	if events.streams == nil {
+		events.streams = make(map[string]map[chan []byte]bool)
+	}
+
+	if events.streams[clientID] == nil {
+		events.streams[clientID] = make(map[chan []byte]bool)
+	}
+
+	stream := make(chan []byte, eventStreamQueueSize)
+	events.streams[clientID][stream] = true
+
+	return stream
+}
+
+// disconnect closes all of the streams for a client and returns the number
+// of streams that were closed.
+func (events *eventStreams) disconnect(clientID string) int {
+	events.mutex.Lock()
+	defer events.mutex.Unlock()
+
+	streams := events.streams[clientID]
+	for stream := range streams {
+		close(stream)
+	}
+	delete(events.streams, clientID)
+
+	return len(streams)
+}
+
+func (events *eventStreams) remove(clientID string, stream chan []byte) {
+	events.mutex.Lock()
+	defer events.mutex.Unlock()
+
+	delete(events.streams[clientID], stream)
+	if len(events.streams[clientID]) == 0 {
+		delete(events.streams, clientID)
+	}
+}
+
+// EventsHandler returns a http.Handler that streams notifications sent with
+// NotifyClient to a client using Server-Sent Events. identify returns the
+// client ID for the request, usually from credentials that it has checked:
+//
+//     http.Handle("/rpc", server)
+//     http.Handle("/rpc/events", server.EventsHandler(func(r *http.Request) (string, error) {
+//         user, err := sessions.User(r)
+//         if err != nil {
+//             return "", err
+//         }
+//
+//         return user.ID, nil
+//     }))
+//
+// identify must not trust a client ID sent by the client without checking it,
+// since every open stream for a client ID receives the notifications for that
+// client. The request is rejected with 401 Unauthorized if identify returns an
+// error or an empty client ID.
+//
+// Each notification is sent as the data of a single event. The stream is
+// closed when the client disconnects.
+func (server *SimpleServer) EventsHandler(identify func(r *http.Request) (string, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, err := identify(r)
+		if err != nil || clientID == "" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		stream := server.events.add(clientID)
+		defer server.events.remove(clientID, stream)
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+
+			case notification, ok := <-stream:
+				if !ok {
+					return
+				}
+
+				if _, err := w.Write([]byte("data: " + string(notification) + "\n\n")); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	})
+}
+
+// NotifyClient sends a JSON-RPC notification to all of the open event streams
+// for a client. See EventsHandler.
+//
+// The notification is queued and sent in the background. ErrClientNotConnected
+// is returned if the client does not have an open event stream and
+// ErrClientQueueFull if a stream has too many notifications waiting to be sent.
+func (server *SimpleServer) NotifyClient(clientID, method string, params interface{}) error {
+	return server.notifyClient(clientID, NewRequestResponder("2.0", nil, method, params).Bytes())
+}
+
+func (server *SimpleServer) notifyClient(clientID string, notification []byte) error {
+	events := &server.events
+	events.mutex.Lock()
+	defer events.mutex.Unlock()
+
+	if len(events.streams[clientID]) == 0 {
+		return ErrClientNotConnected
+	}
+
+	var err error
+	for stream := range events.streams[clientID] {
+		select {
+		case stream <- notification:
+		default:
+			err = ErrClientQueueFull
+		}
+	}
+
+	return err
+}
+
+// DisconnectClient closes all of the open event streams for a client and
+// returns the number of streams that were closed. See EventsHandler.
+func (server *SimpleServer) DisconnectClient(clientID string) int {
+	return server.events.disconnect(clientID)
+}
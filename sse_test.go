@@ -0,0 +1,117 @@
+package jsonrpc_test
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// identifyClient uses the basic auth username as the client ID.
+func identifyClient(r *http.Request) (string, error) {
+	clientID, password, ok := r.BasicAuth()
+	if !ok || password != "secret" {
+		return "", errors.New("invalid credentials")
+	}
+
+	return clientID, nil
+}
+
+func openEventStream(url, clientID string) (*http.Response, error) {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.SetBasicAuth(clientID, "secret")
+
+	return http.DefaultClient.Do(request)
+}
+
+func TestSimpleServer_EventsHandler(t *testing.T) {
+	server := newTestServer()
+	httpServer := httptest.NewServer(server.EventsHandler(identifyClient))
+	defer httpServer.Close()
+
+	t.Run("MissingClient", func(t *testing.T) {
+		response, err := http.Get(httpServer.URL)
+		assert.NoError(t, err)
+		response.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	})
+
+	t.Run("QueryIsNotTrusted", func(t *testing.T) {
+		response, err := http.Get(httpServer.URL + "?client=abc")
+		assert.NoError(t, err)
+		response.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	})
+
+	t.Run("InvalidCredentials", func(t *testing.T) {
+		request, err := http.NewRequest(http.MethodGet, httpServer.URL, nil)
+		assert.NoError(t, err)
+		request.SetBasicAuth("abc", "wrong")
+
+		response, err := http.DefaultClient.Do(request)
+		assert.NoError(t, err)
+		response.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+		assert.Equal(t, jsonrpc.ErrClientNotConnected, server.NotifyClient("abc", "update", nil))
+	})
+
+	t.Run("NotifyClient", func(t *testing.T) {
+		response, err := openEventStream(httpServer.URL, "abc")
+		assert.NoError(t, err)
+		defer response.Body.Close()
+
+		assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+		// The stream is registered after the headers are sent.
+		assert.Eventually(t, func() bool {
+			return server.NotifyClient("abc", "update", []int{1, 2}) == nil
+		}, time.Second, time.Millisecond)
+
+		line, err := bufio.NewReader(response.Body).ReadString('\n')
+		assert.NoError(t, err)
+		assert.Equal(t, `data: {"jsonrpc":"2.0","method":"update","params":[1,2],"id":null}`+"\n", line)
+	})
+}
+
+func TestSimpleServer_NotifyClient(t *testing.T) {
+	server := newTestServer()
+	err := server.NotifyClient("abc", "update", nil)
+
+	assert.Equal(t, jsonrpc.ErrClientNotConnected, err)
+}
+
+func TestSimpleServer_DisconnectClient(t *testing.T) {
+	server := newTestServer()
+	httpServer := httptest.NewServer(server.EventsHandler(identifyClient))
+	defer httpServer.Close()
+
+	assert.Equal(t, 0, server.DisconnectClient("abc"))
+
+	response, err := openEventStream(httpServer.URL, "abc")
+	assert.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Eventually(t, func() bool {
+		return server.NotifyClient("abc", "update", nil) == nil
+	}, time.Second, time.Millisecond)
+
+	assert.Equal(t, 1, server.DisconnectClient("abc"))
+	assert.Equal(t, jsonrpc.ErrClientNotConnected, server.NotifyClient("abc", "update", nil))
+
+	// The stream ends after the notification that was already queued.
+	_, err = io.ReadAll(response.Body)
+	assert.NoError(t, err)
+}
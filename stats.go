@@ -85,7 +85,9 @@ func (server *SimpleServer) TotalNotificationErrors() uint64 {
 }
 
 func (server *SimpleServer) Uptime() time.Duration {
-	return time.Now().Sub(server.startTime)
+	server.start()
+
+	return server.now().Sub(server.startTime)
 }
 
 func (server *SimpleServer) CurrentActiveRequests() uint64 {
@@ -0,0 +1,72 @@
+package jsonrpc
+
+import "io"
+
+// MessageStream is a bidirectional stream of discrete messages, such as a gRPC
+// stream or a WebSocket. Unlike ServeConn, the stream itself separates the
+// payloads so no framing is needed.
+type MessageStream interface {
+	// Recv blocks until the next payload is received. io.EOF should be
+	// returned when the other side has finished sending.
+	Recv() ([]byte, error)
+
+	// Send sends the responses for a single payload.
+	Send(data []byte) error
+}
+
+// ServeStream handles payloads from a stream until it ends. The responses for
+// each payload are sent back as a single message. Payloads that only contain
+// notifications do not send anything back.
+//
+// This can be used to bridge the JSONRPC service in proto/jsonrpc.proto with a
+// small adapter around the generated stream:
+//
+//     type grpcStream struct {
+//         pb.JSONRPC_StreamServer
+//     }
+//
+//     func (s grpcStream) Recv() ([]byte, error) {
+//         payload, err := s.JSONRPC_StreamServer.Recv()
+//         if err != nil {
+//             return nil, err
+//         }
+//
+//         return payload.Data, nil
+//     }
+//
+//     func (s grpcStream) Send(data []byte) error {
+//         return s.JSONRPC_StreamServer.Send(&pb.Payload{Data: data})
+//     }
+//
+//     func (s *service) Stream(stream pb.JSONRPC_StreamServer) error {
+//         return server.ServeStream(grpcStream{stream})
+//     }
+//
+// The transport of the requests (see RequestTransport) is TransportStream,
+// unless the stream has a Transport() string method.
+//
+// ServeStream returns nil when Recv returns io.EOF.
+func (server *SimpleServer) ServeStream(stream MessageStream) error {
+	transport := TransportStream
+	if named, ok := stream.(interface{ Transport() string }); ok {
+		transport = named.Transport()
+	}
+	state := server.connState(transport)
+
+	for {
+		payload, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if data := server.HandleMessage(payload, state); data != nil {
+			if err := stream.Send(data); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package jsonrpc_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testStream struct {
+	received []string
+	sent     []string
+	recvErr  error
+}
+
+func (stream *testStream) Recv() ([]byte, error) {
+	if len(stream.received) == 0 {
+		if stream.recvErr != nil {
+			return nil, stream.recvErr
+		}
+
+		return nil, io.EOF
+	}
+
+	payload := stream.received[0]
+	stream.received = stream.received[1:]
+
+	return []byte(payload), nil
+}
+
+func (stream *testStream) Send(data []byte) error {
+	stream.sent = append(stream.sent, string(data))
+
+	return nil
+}
+
+func TestSimpleServer_ServeStream(t *testing.T) {
+	t.Run("EOF", func(t *testing.T) {
+		stream := &testStream{
+			received: []string{
+				`{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": 1}`,
+				`{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4]}`,
+				`[{"jsonrpc": "2.0", "method": "sum", "params": [1,2], "id": 2}]`,
+			},
+		}
+		err := newTestServer().ServeStream(stream)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			`{"jsonrpc":"2.0","id":1,"result":7}`,
+			`[{"jsonrpc":"2.0","id":2,"result":3}]`,
+		}, stream.sent)
+	})
+
+	t.Run("RecvError", func(t *testing.T) {
+		stream := &testStream{recvErr: errors.New("stream reset")}
+		err := newTestServer().ServeStream(stream)
+
+		assert.EqualError(t, err, "stream reset")
+	})
+}
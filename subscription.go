@@ -0,0 +1,222 @@
+package jsonrpc
+
+import (
+	"sort"
+	"sync"
+)
+
+// Subscription is a client that receives the notifications broadcast to a
+// topic. See Subscribe.
+type Subscription struct {
+	ClientID string `json:"clientId"`
+	Topic    string `json:"topic"`
+
+	// Cursor is the position in the topic that the client has received up
+	// to, such as the id of the last event. It is not used by the server, but
+	// allows a client that reconnects to resume from where it left off. See
+	// SetSubscriptionCursor.
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// SubscriptionStore keeps the subscriptions of a server. The default is a
+// MemorySubscriptionStore, which loses the subscriptions when the server
+// restarts. A store backed by a database (such as Redis) allows subscriptions
+// to survive restarts, and to be shared by many servers so that a client can
+// reconnect to any of them. See SetSubscriptionStore.
+type SubscriptionStore interface {
+	// Save adds the subscription, or replaces the subscription with the same
+	// ClientID and Topic.
+	Save(subscription Subscription) error
+
+	// Delete removes a subscription. It is not an error if the subscription
+	// does not exist.
+	Delete(clientID, topic string) error
+
+	// Subscribers returns all of the subscriptions for a topic.
+	Subscribers(topic string) ([]Subscription, error)
+
+	// ClientSubscriptions returns all of the subscriptions for a client.
+	ClientSubscriptions(clientID string) ([]Subscription, error)
+}
+
+// SetSubscriptionStore replaces the store used for subscriptions. A nil store
+// uses a new MemorySubscriptionStore.
+func (server *SimpleServer) SetSubscriptionStore(store SubscriptionStore) {
+	server.subscriptionMutex.Lock()
+	defer server.subscriptionMutex.Unlock()
+
+	server.subscriptionStore = store
+}
+
+func (server *SimpleServer) subscriptions() SubscriptionStore {
+	server.subscriptionMutex.Lock()
+	defer server.subscriptionMutex.Unlock()
+
+	if server.subscriptionStore == nil {
+		server.subscriptionStore = &MemorySubscriptionStore{}
+	}
+
+	return server.subscriptionStore
+}
+
+// Subscribe adds a client to a topic so that it receives the notifications
+// sent with Broadcast. Clients are the same as for NotifyClient, so they
+// receive the notifications through an event stream. Usually this is called
+// from a handler:
+//
+//     server.SetHandler("subscribe", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+//         clientID := request.State("clientId").(string)
+//         topic := request.Params().([]interface{})[0].(string)
+//
+//         if err := server.Subscribe(clientID, topic); err != nil {
+//             return request.NewServerErrorResponse(err)
+//         }
+//
+//         return request.NewSuccessResponse(true)
+//     })
+//
+// Subscribing again keeps the existing cursor.
+func (server *SimpleServer) Subscribe(clientID, topic string) error {
+	store := server.subscriptions()
+	existing, err := store.ClientSubscriptions(clientID)
+	if err != nil {
+		return err
+	}
+
+	for _, subscription := range existing {
+		if subscription.Topic == topic {
+			return nil
+		}
+	}
+
+	return store.Save(Subscription{ClientID: clientID, Topic: topic})
+}
+
+// Unsubscribe removes a client from a topic.
+func (server *SimpleServer) Unsubscribe(clientID, topic string) error {
+	return server.subscriptions().Delete(clientID, topic)
+}
+
+// SetSubscriptionCursor records how far through a topic the client has
+// received. See Subscription.Cursor.
+func (server *SimpleServer) SetSubscriptionCursor(clientID, topic, cursor string) error {
+	return server.subscriptions().Save(Subscription{
+		ClientID: clientID,
+		Topic:    topic,
+		Cursor:   cursor,
+	})
+}
+
+// ClientSubscriptions returns the subscriptions of a client, such as to send
+// the events that were missed when a client reconnects.
+func (server *SimpleServer) ClientSubscriptions(clientID string) ([]Subscription, error) {
+	return server.subscriptions().ClientSubscriptions(clientID)
+}
+
+// Broadcast sends a notification to all of the clients subscribed to a topic.
+// It returns the number of clients connected to this server that the
+// notification was sent to.
+//
+// Subscribers that are not connected or are not reading notifications fast
+// enough are skipped. If there is a Broker, the notification is also sent to
+// the subscribers connected to other servers. See SetBroker.
+func (server *SimpleServer) Broadcast(topic, method string, params interface{}) (int, error) {
+	notification := NewRequestResponder("2.0", nil, method, params).Bytes()
+
+	sent, err := server.broadcast(topic, notification)
+	if err != nil {
+		return sent, err
+	}
+
+	return sent, server.publish(topic, notification)
+}
+
+// broadcast sends the notification to the subscribers connected to this
+// server.
+func (server *SimpleServer) broadcast(topic string, notification []byte) (int, error) {
+	subscribers, err := server.subscriptions().Subscribers(topic)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, subscription := range subscribers {
+		if server.notifyClient(subscription.ClientID, notification) == nil {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// MemorySubscriptionStore is a SubscriptionStore that keeps the subscriptions
+// in memory.
+type MemorySubscriptionStore struct {
+	mutex         sync.Mutex
+	subscriptions map[string]map[string]Subscription
+}
+
+func (store *MemorySubscriptionStore) Save(subscription Subscription) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.subscriptions == nil {
+		store.subscriptions = make(map[string]map[string]Subscription)
+	}
+
+	topics := store.subscriptions[subscription.ClientID]
+	if topics == nil {
+		topics = make(map[string]Subscription)
+		store.subscriptions[subscription.ClientID] = topics
+	}
+
+	topics[subscription.Topic] = subscription
+
+	return nil
+}
+
+func (store *MemorySubscriptionStore) Delete(clientID, topic string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	delete(store.subscriptions[clientID], topic)
+	if len(store.subscriptions[clientID]) == 0 {
+		delete(store.subscriptions, clientID)
+	}
+
+	return nil
+}
+
+func (store *MemorySubscriptionStore) Subscribers(topic string) ([]Subscription, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	var subscribers []Subscription
+	for _, topics := range store.subscriptions {
+		if subscription, ok := topics[topic]; ok {
+			subscribers = append(subscribers, subscription)
+		}
+	}
+
+	sort.Slice(subscribers, func(i, j int) bool {
+		return subscribers[i].ClientID < subscribers[j].ClientID
+	})
+
+	return subscribers, nil
+}
+
+func (store *MemorySubscriptionStore) ClientSubscriptions(clientID string) ([]Subscription, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	var subscriptions []Subscription
+	for _, subscription := range store.subscriptions[clientID] {
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].Topic < subscriptions[j].Topic
+	})
+
+	return subscriptions, nil
+}
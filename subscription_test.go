@@ -0,0 +1,91 @@
+package jsonrpc_test
+
+import (
+	"bufio"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimpleServer_Subscribe(t *testing.T) {
+	server := newTestServer()
+
+	assert.NoError(t, server.Subscribe("abc", "prices"))
+	assert.NoError(t, server.Subscribe("abc", "news"))
+	assert.NoError(t, server.SetSubscriptionCursor("abc", "prices", "42"))
+
+	// Subscribing again keeps the cursor.
+	assert.NoError(t, server.Subscribe("abc", "prices"))
+
+	subscriptions, err := server.ClientSubscriptions("abc")
+	assert.NoError(t, err)
+	assert.Equal(t, []jsonrpc.Subscription{
+		{ClientID: "abc", Topic: "news"},
+		{ClientID: "abc", Topic: "prices", Cursor: "42"},
+	}, subscriptions)
+
+	assert.NoError(t, server.Unsubscribe("abc", "news"))
+	assert.NoError(t, server.Unsubscribe("abc", "missing"))
+
+	subscriptions, err = server.ClientSubscriptions("abc")
+	assert.NoError(t, err)
+	assert.Equal(t, []jsonrpc.Subscription{
+		{ClientID: "abc", Topic: "prices", Cursor: "42"},
+	}, subscriptions)
+}
+
+func TestSimpleServer_SetSubscriptionStore(t *testing.T) {
+	// Two servers that share a store, such as behind a load balancer.
+	store := &jsonrpc.MemorySubscriptionStore{}
+	server1 := newTestServer()
+	server1.SetSubscriptionStore(store)
+	server2 := newTestServer()
+	server2.SetSubscriptionStore(store)
+
+	assert.NoError(t, server1.Subscribe("abc", "prices"))
+
+	subscriptions, err := server2.ClientSubscriptions("abc")
+	assert.NoError(t, err)
+	assert.Equal(t, []jsonrpc.Subscription{
+		{ClientID: "abc", Topic: "prices"},
+	}, subscriptions)
+
+	// The client is connected to the second server.
+	httpServer := httptest.NewServer(server2.EventsHandler(identifyClient))
+	defer httpServer.Close()
+
+	response, err := openEventStream(httpServer.URL, "abc")
+	assert.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Eventually(t, func() bool {
+		sent, err := server2.Broadcast("prices", "priceChanged", []float64{1.5})
+		return err == nil && sent == 1
+	}, time.Second, time.Millisecond)
+
+	sent, err := server1.Broadcast("prices", "priceChanged", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	line, err := bufio.NewReader(response.Body).ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, `data: {"jsonrpc":"2.0","method":"priceChanged","params":[1.5],"id":null}`+"\n", line)
+}
+
+func TestSimpleServer_Broadcast(t *testing.T) {
+	server := newTestServer()
+
+	sent, err := server.Broadcast("prices", "priceChanged", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	// Subscribers that are not connected are skipped.
+	assert.NoError(t, server.Subscribe("abc", "prices"))
+
+	sent, err = server.Broadcast("prices", "priceChanged", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+}
@@ -0,0 +1,96 @@
+package jsonrpc
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// State keys for the time a request was queued, and how long it waited.
+const (
+	queuedAtKey  = "jsonrpc.queuedAt"
+	queueWaitKey = "jsonrpc.queueWait"
+)
+
+// RequestTimings separates the time requests spend waiting in a queue from
+// the time spent handling them, so that a saturated server can be told apart
+// from slow handlers. See SimpleServer.RequestTimings.
+type RequestTimings struct {
+	// Queued is the number of requests that waited in a queue, such as a
+	// TimedQueue, before being handled.
+	Queued         uint64
+	TotalQueueWait time.Duration
+	MaxQueueWait   time.Duration
+
+	// Handled is the number of requests that called a handler. The handler
+	// time includes middleware.
+	Handled          uint64
+	TotalHandlerTime time.Duration
+}
+
+// AverageQueueWait is the mean time that queued requests waited.
+func (timings RequestTimings) AverageQueueWait() time.Duration {
+	if timings.Queued == 0 {
+		return 0
+	}
+
+	return timings.TotalQueueWait / time.Duration(timings.Queued)
+}
+
+// AverageHandlerTime is the mean time taken to handle a request.
+func (timings RequestTimings) AverageHandlerTime() time.Duration {
+	if timings.Handled == 0 {
+		return 0
+	}
+
+	return timings.TotalHandlerTime / time.Duration(timings.Handled)
+}
+
+// RequestTimings returns the queue and handler times of all requests since
+// the server started.
+func (server *SimpleServer) RequestTimings() RequestTimings {
+	return RequestTimings{
+		Queued:           atomic.LoadUint64(&server.queuedRequests),
+		TotalQueueWait:   time.Duration(atomic.LoadInt64(&server.totalQueueWait)),
+		MaxQueueWait:     time.Duration(atomic.LoadInt64(&server.maxQueueWait)),
+		Handled:          atomic.LoadUint64(&server.handledRequests),
+		TotalHandlerTime: time.Duration(atomic.LoadInt64(&server.totalHandlerTime)),
+	}
+}
+
+// RequestQueueWait returns how long the request waited in a queue before it
+// was handled. It is zero if the request was not queued.
+func RequestQueueWait(request Request) time.Duration {
+	wait, _ := request.State(queueWaitKey).(time.Duration)
+
+	return wait
+}
+
+// observeQueueWait records the queue wait of the request, if it was queued.
+func (server *SimpleServer) observeQueueWait(request RequestResponder) RequestResponder {
+	queuedAt, ok := request.State(queuedAtKey).(time.Time)
+	if !ok {
+		return request
+	}
+
+	wait := server.now().Sub(queuedAt)
+	if wait < 0 {
+		wait = 0
+	}
+
+	atomic.AddUint64(&server.queuedRequests, 1)
+	atomic.AddInt64(&server.totalQueueWait, int64(wait))
+
+	for {
+		max := atomic.LoadInt64(&server.maxQueueWait)
+		if int64(wait) <= max || atomic.CompareAndSwapInt64(&server.maxQueueWait, max, int64(wait)) {
+			break
+		}
+	}
+
+	return WithState(request, State{queueWaitKey: wait})
+}
+
+func (server *SimpleServer) observeHandlerTime(duration time.Duration) {
+	atomic.AddUint64(&server.handledRequests, 1)
+	atomic.AddInt64(&server.totalHandlerTime, int64(duration))
+}
@@ -0,0 +1,93 @@
+package jsonrpc_test
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/elliotchance/jsonrpc/jsonrpctest"
+	"github.com/stretchr/testify/assert"
+)
+
+type timedQueue struct {
+	testQueue
+	queuedAt []time.Time
+}
+
+func (queue *timedQueue) ReceiveTimed() ([]byte, time.Time, error) {
+	payload, err := queue.Receive()
+	if err == io.EOF {
+		return nil, time.Time{}, err
+	}
+
+	queuedAt := queue.queuedAt[0]
+	queue.queuedAt = queue.queuedAt[1:]
+
+	return payload, queuedAt, nil
+}
+
+func TestSimpleServer_RequestTimings(t *testing.T) {
+	clock := jsonrpctest.NewClock(time.Unix(1000, 0))
+	server := newTestServer()
+	server.SetClock(clock)
+
+	var attrs [][]slog.Attr
+	server.SetHandler("slow", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		attrs = append(attrs, jsonrpc.RequestAttrs(request))
+		clock.Advance(time.Second)
+
+		return request.NewSuccessResponse(jsonrpc.RequestQueueWait(request).String())
+	})
+
+	queue := &timedQueue{
+		testQueue: testQueue{
+			payloads: []string{
+				`{"jsonrpc":"2.0","method":"slow","id":1}`,
+				`{"jsonrpc":"2.0","method":"slow","id":2}`,
+			},
+			sent: map[string]string{},
+		},
+		queuedAt: []time.Time{
+			time.Unix(997, 0),
+			time.Unix(1000, 0),
+		},
+	}
+
+	assert.Equal(t, jsonrpc.RequestTimings{}, server.RequestTimings())
+	assert.NoError(t, server.ServeQueue(queue, nil))
+
+	// The second request waited while the first was handled.
+	assert.Equal(t, map[string]string{
+		"jsonrpc:response:1": `{"jsonrpc":"2.0","id":1,"result":"3s"}`,
+		"jsonrpc:response:2": `{"jsonrpc":"2.0","id":2,"result":"1s"}`,
+	}, queue.sent)
+
+	timings := server.RequestTimings()
+	assert.Equal(t, jsonrpc.RequestTimings{
+		Queued:           2,
+		TotalQueueWait:   4 * time.Second,
+		MaxQueueWait:     3 * time.Second,
+		Handled:          2,
+		TotalHandlerTime: 2 * time.Second,
+	}, timings)
+	assert.Equal(t, 2*time.Second, timings.AverageQueueWait())
+	assert.Equal(t, time.Second, timings.AverageHandlerTime())
+
+	assert.Equal(t, []slog.Attr{
+		slog.String("rpc.method", "slow"),
+		slog.Any("rpc.id", 1.0),
+		slog.Float64("rpc.queue_wait_ms", 3000),
+	}, attrs[0])
+}
+
+func TestRequestTimings_NotQueued(t *testing.T) {
+	server := newTestServer()
+	server.Handle([]byte(`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`))
+
+	timings := server.RequestTimings()
+	assert.Equal(t, uint64(0), timings.Queued)
+	assert.Equal(t, time.Duration(0), timings.AverageQueueWait())
+	assert.Equal(t, uint64(1), timings.Handled)
+}
@@ -0,0 +1,131 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// TokenProvider supplies the bearer tokens sent by a Client in the
+// Authorization header. See SetTokenProvider.
+type TokenProvider interface {
+	// Token returns the current token. It is called for every request so it
+	// should cache the token.
+	Token(ctx context.Context) (string, error)
+
+	// Refresh is called when the server rejects the current token. It must
+	// return a new token, which will also be returned by Token from now on.
+	Refresh(ctx context.Context) (string, error)
+}
+
+// SetTokenProvider sends a bearer token from the provider with every request.
+// If the server responds with 401 Unauthorized, the token is refreshed and the
+// request is sent again (once).
+//
+// Tokens are sent in the "Authorization" header, so they are only used by
+// transports that use HTTP. A nil provider stops sending tokens.
+func (client *Client) SetTokenProvider(provider TokenProvider) {
+	client.tokens = provider
+}
+
+// authenticate is the last ClientInvoker. It adds the token to the request
+// before sending it.
+func (client *Client) authenticate(ctx context.Context, request Request) (Response, error) {
+	var response Response
+	err := client.authorize(ctx, func(ctx context.Context) (err error) {
+		response, err = client.send(ctx, request)
+
+		return err
+	})
+
+	return response, err
+}
+
+// authorize calls send with the token added to ctx. If the server responds
+// with 401 Unauthorized then the token is refreshed and send is called again.
+func (client *Client) authorize(ctx context.Context, send func(ctx context.Context) error) error {
+	if client.tokens == nil {
+		return send(ctx)
+	}
+
+	token, err := client.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = send(withBearerToken(ctx, token))
+
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		return err
+	}
+
+	token, err = client.tokens.Refresh(ctx)
+	if err != nil {
+		return err
+	}
+
+	return send(withBearerToken(ctx, token))
+}
+
+func withBearerToken(ctx context.Context, token string) context.Context {
+	header := HeaderFromContext(ctx).Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Authorization", "Bearer "+token)
+
+	return context.WithValue(ctx, headerKey{}, header)
+}
+
+// RefreshingTokenProvider is a TokenProvider that caches the token returned by
+// a function, and calls the function again to refresh it. This is enough for
+// most OAuth flows:
+//
+//     client.SetTokenProvider(&jsonrpc.RefreshingTokenProvider{
+//         Fetch: func(ctx context.Context) (string, error) {
+//             token, err := oauthConfig.Token(ctx)
+//             if err != nil {
+//                 return "", err
+//             }
+//
+//             return token.AccessToken, nil
+//         },
+//     })
+type RefreshingTokenProvider struct {
+	Fetch func(ctx context.Context) (string, error)
+
+	mutex sync.Mutex
+	token string
+}
+
+func (provider *RefreshingTokenProvider) Token(ctx context.Context) (string, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	if provider.token != "" {
+		return provider.token, nil
+	}
+
+	return provider.fetch(ctx)
+}
+
+func (provider *RefreshingTokenProvider) Refresh(ctx context.Context) (string, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	return provider.fetch(ctx)
+}
+
+// fetch must be called with the mutex held.
+func (provider *RefreshingTokenProvider) fetch(ctx context.Context) (string, error) {
+	token, err := provider.Fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	provider.token = token
+
+	return token, nil
+}
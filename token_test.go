@@ -0,0 +1,72 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_SetTokenProvider(t *testing.T) {
+	validToken := "token-2"
+	var received []string
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "Bearer "+validToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		newTestServer().ServeHTTP(w, r)
+	}))
+	defer httpServer.Close()
+
+	fetches := 0
+	client := jsonrpc.NewHTTPClient(httpServer.URL)
+	client.SetTokenProvider(&jsonrpc.RefreshingTokenProvider{
+		Fetch: func(ctx context.Context) (string, error) {
+			fetches++
+			if fetches > 3 {
+				return "", errors.New("refresh failed")
+			}
+
+			return fmt.Sprintf("token-%d", fetches), nil
+		},
+	})
+
+	t.Run("Refresh", func(t *testing.T) {
+		response, err := client.Call("sum", []int{1, 2})
+		assert.NoError(t, err)
+		assert.Equal(t, 3.0, response.Result())
+		assert.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, received)
+	})
+
+	t.Run("Cached", func(t *testing.T) {
+		received = nil
+		_, err := client.Call("sum", []int{1, 2})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Bearer token-2"}, received)
+		assert.Equal(t, 2, fetches)
+	})
+
+	t.Run("OnlyRetriesOnce", func(t *testing.T) {
+		received = nil
+		validToken = "token-99"
+
+		_, err := client.Call("sum", []int{1, 2})
+		var statusErr *jsonrpc.HTTPStatusError
+		assert.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusUnauthorized, statusErr.StatusCode)
+		assert.Equal(t, []string{"Bearer token-2", "Bearer token-3"}, received)
+	})
+
+	t.Run("RefreshFails", func(t *testing.T) {
+		_, err := client.Call("sum", []int{1, 2})
+		assert.EqualError(t, err, "refresh failed")
+	})
+}
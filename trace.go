@@ -0,0 +1,109 @@
+package jsonrpc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const traceMethodName = "rpc.trace"
+
+// SetTraceMethod enables the built-in "rpc.trace" method. It returns the
+// request exactly as a handler would receive it, which is useful for client
+// developers to debug what the server understood from their request:
+//
+//     {"jsonrpc":"2.0","method":"rpc.trace","params":{"name":"bob"},"id":1}
+//
+// returns:
+//
+//     {
+//         "jsonrpc": "2.0",
+//         "method": "rpc.trace",
+//         "params": {"name": "bob"},
+//         "id": 1,
+//         "transport": "http",
+//         "state": {"user": "*myapp.User"}
+//     }
+//
+// The request has already been through the same middleware (see Use) and
+// rewrites (see SetCompliance) as any other method, so State added by
+// middleware is included. The values of the State are redacted and only their
+// types are returned. The internal State of the server is not included.
+//
+// rpc.trace is disabled by default. When it is disabled the method does not
+// exist.
+func (server *SimpleServer) SetTraceMethod(enabled bool) {
+	server.traceMethod = enabled
+}
+
+func (server *SimpleServer) rpcTrace(request RequestResponder) Response {
+	state := map[string]interface{}{}
+	for _, key := range stateKeys(request) {
+		if strings.HasPrefix(key, internalStatePrefix) {
+			continue
+		}
+
+		if value := request.State(key); value != nil {
+			state[key] = fmt.Sprintf("%T", value)
+		}
+	}
+
+	trace := map[string]interface{}{
+		"jsonrpc": request.Version(),
+		"method":  request.Method(),
+		"params":  request.Params(),
+		"id":      request.Id(),
+		"state":   state,
+	}
+
+	if transport := RequestTransport(request); transport != "" {
+		trace["transport"] = transport
+	}
+
+	return request.NewSuccessResponse(trace)
+}
+
+// The prefix of the State keys that are used by the server itself.
+const internalStatePrefix = "jsonrpc."
+
+// stateKeys returns the sorted keys of all the layers of State of the request.
+// Requests that were not created by this package do not have any keys.
+func stateKeys(request Request) []string {
+	seen := map[string]bool{}
+	collectStateKeys(request, seen)
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func collectStateKeys(r Request, seen map[string]bool) {
+	switch request := r.(type) {
+	case *request:
+		for key := range request.requestState {
+			seen[key] = true
+		}
+
+	case *layeredRequest:
+		for key := range request.state {
+			seen[key] = true
+		}
+		collectStateKeys(request.RequestResponder, seen)
+
+	case *defaultStateRequest:
+		for key := range request.defaults {
+			seen[key] = true
+		}
+		collectStateKeys(request.RequestResponder, seen)
+
+	case *renamedRequest:
+		collectStateKeys(request.RequestResponder, seen)
+
+	case *validatingRequest:
+		collectStateKeys(request.RequestResponder, seen)
+	}
+}
@@ -0,0 +1,82 @@
+package jsonrpc_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimpleServer_SetTraceMethod(t *testing.T) {
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		server := newTestServer()
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"rpc.trace","id":1}`))
+
+		require.Len(t, responses, 1)
+		assert.Equal(t, jsonrpc.MethodNotFound, responses[0].ErrorCode())
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		server := newTestServer()
+		server.SetTraceMethod(true)
+		server.Use(func(next jsonrpc.RequestHandler) jsonrpc.RequestHandler {
+			return func(request jsonrpc.RequestResponder) jsonrpc.Response {
+				return next(jsonrpc.WithState(request, jsonrpc.State{"role": 42}))
+			}
+		})
+
+		responses := server.HandleWithState(
+			[]byte(`{"jsonrpc":"2.0","method":"rpc.trace","params":{"name":"bob"},"id":1}`),
+			jsonrpc.State{"user": "secret", "empty": nil})
+
+		require.Len(t, responses, 1)
+		data, err := json.Marshal(responses[0].Result())
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"jsonrpc": "2.0",
+			"method": "rpc.trace",
+			"params": {"name": "bob"},
+			"id": 1,
+			"state": {"role": "int", "user": "string"}
+		}`, string(data))
+	})
+
+	t.Run("Transport", func(t *testing.T) {
+		server := newTestServer()
+		server.SetTraceMethod(true)
+
+		responses := server.HandleWithState(
+			[]byte(`{"jsonrpc":"2.0","method":"rpc.trace","id":1}`),
+			jsonrpc.State{"jsonrpc.transport": jsonrpc.TransportTCP})
+
+		require.Len(t, responses, 1)
+		data, err := json.Marshal(responses[0].Result())
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"jsonrpc": "2.0",
+			"method": "rpc.trace",
+			"params": null,
+			"id": 1,
+			"transport": "tcp",
+			"state": {}
+		}`, string(data))
+	})
+
+	t.Run("Middleware", func(t *testing.T) {
+		server := newTestServer()
+		server.SetTraceMethod(true)
+		server.Use(func(next jsonrpc.RequestHandler) jsonrpc.RequestHandler {
+			return func(request jsonrpc.RequestResponder) jsonrpc.Response {
+				return request.NewErrorResponse(jsonrpc.ServerError, "Unauthorized")
+			}
+		})
+
+		responses := server.Handle([]byte(`{"jsonrpc":"2.0","method":"rpc.trace","id":1}`))
+
+		require.Len(t, responses, 1)
+		assert.Equal(t, "Unauthorized", responses[0].ErrorMessage())
+	})
+}
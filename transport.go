@@ -0,0 +1,143 @@
+package jsonrpc
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// The State key for the transport that a request was received on.
+const transportKey = "jsonrpc.transport"
+
+// The transports that requests can be received on. See RequestTransport.
+const (
+	TransportHTTP      = "http"
+	TransportWebSocket = "ws"
+	TransportTCP       = "tcp"
+	TransportUnix      = "unix"
+	TransportStdio     = "stdio"
+	TransportStream    = "stream"
+	TransportAMQP      = "amqp"
+	TransportMQTT      = "mqtt"
+	TransportQueue     = "queue"
+	TransportNATS      = "nats"
+)
+
+// RequestTransport returns the transport that the request was received on,
+// such as TransportHTTP or TransportTCP. It is empty for requests that were
+// passed to Handle (or HandleWithState) directly.
+//
+// A MessageStream (see ServeStream) is TransportStream unless it has a
+// Transport() string method. This allows a WebSocket adapter to report
+// TransportWebSocket, for example.
+func RequestTransport(request Request) string {
+	transport, _ := request.State(transportKey).(string)
+
+	return transport
+}
+
+// TransportStats are the statistics for a single transport. See
+// SimpleServer.TransportStats.
+type TransportStats struct {
+	// Payloads is the number of payloads (a single request or a batch)
+	// received on the transport.
+	Payloads uint64
+
+	// SuccessResponses and ErrorResponses are the responses sent back, in the
+	// same way as TotalSuccessResponses and TotalErrorResponses.
+	SuccessResponses uint64
+	ErrorResponses   uint64
+}
+
+type transportCounters struct {
+	payloads         uint64
+	successResponses uint64
+	errorResponses   uint64
+}
+
+// transportStats holds a *transportCounters for each transport.
+type transportStats struct {
+	counters sync.Map
+}
+
+// TransportStats returns the statistics of each transport that has received
+// a payload, keyed by the name of the transport.
+func (server *SimpleServer) TransportStats() map[string]TransportStats {
+	stats := make(map[string]TransportStats)
+	server.transportStats.counters.Range(func(key, value interface{}) bool {
+		counters := value.(*transportCounters)
+		stats[key.(string)] = TransportStats{
+			Payloads:         atomic.LoadUint64(&counters.payloads),
+			SuccessResponses: atomic.LoadUint64(&counters.successResponses),
+			ErrorResponses:   atomic.LoadUint64(&counters.errorResponses),
+		}
+
+		return true
+	})
+
+	return stats
+}
+
+// transports returns the names of the transports with statistics, sorted.
+func (server *SimpleServer) transports() []string {
+	var transports []string
+	server.transportStats.counters.Range(func(key, value interface{}) bool {
+		transports = append(transports, key.(string))
+
+		return true
+	})
+	sort.Strings(transports)
+
+	return transports
+}
+
+func (server *SimpleServer) transportCounters(state State) *transportCounters {
+	transport, _ := state[transportKey].(string)
+	if transport == "" {
+		return nil
+	}
+
+	counters, _ := server.transportStats.counters.LoadOrStore(transport, &transportCounters{})
+
+	return counters.(*transportCounters)
+}
+
+// observePayload counts a payload received on the transport in state.
+func (server *SimpleServer) observePayload(state State) {
+	if counters := server.transportCounters(state); counters != nil {
+		atomic.AddUint64(&counters.payloads, 1)
+	}
+}
+
+// observeTransportResponses counts the responses sent on the transport in
+// state.
+func (server *SimpleServer) observeTransportResponses(state State, responses Responses) {
+	counters := server.transportCounters(state)
+	if counters == nil {
+		return
+	}
+
+	for _, response := range responses {
+		if response.ErrorCode() == Success {
+			atomic.AddUint64(&counters.successResponses, 1)
+		} else {
+			atomic.AddUint64(&counters.errorResponses, 1)
+		}
+	}
+}
+
+// connTransport returns the transport of a connection passed to ServeConn.
+func connTransport(conn interface{}) string {
+	if conn, ok := conn.(net.Conn); ok {
+		switch conn.LocalAddr().Network() {
+		case "unix":
+			return TransportUnix
+
+		case "tcp", "tcp4", "tcp6":
+			return TransportTCP
+		}
+	}
+
+	return TransportStream
+}
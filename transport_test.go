@@ -0,0 +1,137 @@
+package jsonrpc_test
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+const transportRequest = `{"jsonrpc":"2.0","method":"transport","id":1}`
+
+func transportServer() *jsonrpc.SimpleServer {
+	server := jsonrpc.NewSimpleServer()
+	server.SetHandler("transport", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponse(jsonrpc.RequestTransport(request))
+	})
+
+	return server
+}
+
+type namedStream struct {
+	testStream
+}
+
+func (stream *namedStream) Transport() string {
+	return jsonrpc.TransportWebSocket
+}
+
+func callOverConn(t *testing.T, network, address string) string {
+	conn, err := net.Dial(network, address)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(transportRequest + "\n"))
+	assert.NoError(t, err)
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	assert.NoError(t, err)
+
+	return strings.TrimSpace(line)
+}
+
+func TestRequestTransport(t *testing.T) {
+	server := transportServer()
+
+	t.Run("Handle", func(t *testing.T) {
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":""}`,
+			server.Handle([]byte(transportRequest))[0].String())
+	})
+
+	t.Run("HTTP", func(t *testing.T) {
+		httpServer := httptest.NewServer(server)
+		defer httpServer.Close()
+
+		response, err := http.Post(httpServer.URL, "application/json", strings.NewReader(transportRequest))
+		assert.NoError(t, err)
+		defer response.Body.Close()
+
+		var body bytes.Buffer
+		body.ReadFrom(response.Body)
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":"http"}`, strings.TrimSpace(body.String()))
+	})
+
+	t.Run("TCP", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		defer listener.Close()
+		go server.Serve(listener)
+
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":"tcp"}`,
+			callOverConn(t, "tcp", listener.Addr().String()))
+	})
+
+	t.Run("Unix", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rpc.sock")
+		listener, err := net.Listen("unix", path)
+		assert.NoError(t, err)
+		defer listener.Close()
+		go server.Serve(listener)
+
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":"unix"}`,
+			callOverConn(t, "unix", path))
+	})
+
+	t.Run("Stdio", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, server.ServeStdio(strings.NewReader(transportRequest+"\n"), &out))
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":"stdio"}`, strings.TrimSpace(out.String()))
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		stream := &testStream{received: []string{transportRequest}}
+		assert.NoError(t, server.ServeStream(stream))
+		assert.Equal(t, []string{`{"jsonrpc":"2.0","id":1,"result":"stream"}`}, stream.sent)
+
+		named := &namedStream{testStream{received: []string{transportRequest}}}
+		assert.NoError(t, server.ServeStream(named))
+		assert.Equal(t, []string{`{"jsonrpc":"2.0","id":1,"result":"ws"}`}, named.sent)
+	})
+}
+
+func TestSimpleServer_TransportStats(t *testing.T) {
+	server := transportServer()
+
+	var out bytes.Buffer
+	input := transportRequest + "\n" +
+		`[{"jsonrpc":"2.0","method":"transport","id":2},{"jsonrpc":"2.0","method":"missing","id":3}]` + "\n" +
+		`{"jsonrpc":"2.0","method":"transport"}` + "\n"
+	assert.NoError(t, server.ServeStdio(strings.NewReader(input), &out))
+
+	stream := &testStream{received: []string{`{"jsonrpc":"2.0","method":"missing","id":1}`}}
+	assert.NoError(t, server.ServeStream(stream))
+
+	// Requests handled directly are not counted for any transport.
+	server.Handle([]byte(transportRequest))
+
+	assert.Equal(t, map[string]jsonrpc.TransportStats{
+		"stdio":  {Payloads: 3, SuccessResponses: 2, ErrorResponses: 1},
+		"stream": {Payloads: 1, ErrorResponses: 1},
+	}, server.TransportStats())
+
+	var metrics bytes.Buffer
+	assert.NoError(t, server.WriteMetrics(&metrics))
+	assert.Contains(t, metrics.String(), `# TYPE jsonrpc_transport_payloads_total counter
+jsonrpc_transport_payloads_total{transport="stdio"} 3
+jsonrpc_transport_payloads_total{transport="stream"} 1
+`)
+	assert.Contains(t, metrics.String(), `jsonrpc_transport_responses_total{transport="stdio",result="error"} 1
+`)
+}
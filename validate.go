@@ -0,0 +1,254 @@
+package jsonrpc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Validator checks the params decoded by BindParams. See SetValidator.
+//
+// If Validate returns ValidationErrors, the request receives an Invalid params
+// error that lists them. Any other error is treated as a mistake in the program
+// and the request receives a ServerError.
+type Validator interface {
+	Validate(params interface{}) error
+}
+
+// FieldError is a single field that failed validation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors is all of the fields that failed validation. It is also
+// sent as the data of the Invalid params error:
+//
+//     {"code":-32602,"message":"Invalid params: name is required; age must be at least 18",
+//      "data":[{"field":"name","rule":"required","message":"name is required"}, ...]}
+type ValidationErrors []FieldError
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Message
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// TagValidator is the default Validator. It checks the "validate" tag of the
+// fields of a struct, which is a comma separated list of rules:
+//
+//     type createUser struct {
+//         Name string `json:"name" validate:"required,max=64"`
+//         Age  int    `json:"age" validate:"min=18"`
+//         Role string `json:"role" validate:"oneof=admin user"`
+//     }
+//
+// The rules are:
+//
+//     required   The field must not be the zero value (or nil).
+//     min=N      Numbers must be at least N. Strings, slices and maps must have
+//                a length of at least N.
+//     max=N      The same as min, but the most that is allowed.
+//     oneof=A B  The field must be one of the space separated values.
+//
+// Nested structs (and pointers to them) are also validated and their fields
+// are named like "address.city", using the JSON names. min, max and oneof are
+// not checked for nil pointers, so they can be used for optional fields.
+type TagValidator struct{}
+
+func (validator TagValidator) Validate(params interface{}) error {
+	var errs ValidationErrors
+	if err := validateStruct(reflect.ValueOf(params), "", &errs); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func validateStruct(value reflect.Value, prefix string, errs *ValidationErrors) error {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := fieldName(field)
+		if !ok {
+			continue
+		}
+		name = prefix + name
+
+		fieldValue := value.Field(i)
+		if tag := field.Tag.Get("validate"); tag != "" {
+			for _, rule := range strings.Split(tag, ",") {
+				message, err := checkRule(fieldValue, name, rule)
+				if err != nil {
+					return err
+				}
+
+				if message != "" {
+					ruleName := strings.SplitN(rule, "=", 2)[0]
+					*errs = append(*errs, FieldError{Field: name, Rule: ruleName, Message: message})
+				}
+			}
+		}
+
+		if err := validateStruct(fieldValue, name+".", errs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkRule returns the message if the value does not pass the rule. An error
+// is returned if the rule itself is not valid.
+func checkRule(value reflect.Value, name, rule string) (string, error) {
+	ruleName, arg := rule, ""
+	if i := strings.Index(rule, "="); i >= 0 {
+		ruleName, arg = rule[:i], rule[i+1:]
+	}
+
+	if ruleName == "required" {
+		if value.IsZero() {
+			return name + " is required", nil
+		}
+
+		return "", nil
+	}
+
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return "", nil
+		}
+		value = value.Elem()
+	}
+
+	switch ruleName {
+	case "min", "max":
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return "", fmt.Errorf("Invalid validation rule for %s: %s", name, rule)
+		}
+
+		size, isLength, ok := valueSize(value)
+		if !ok {
+			return "", fmt.Errorf("Invalid validation rule for %s: %s", name, rule)
+		}
+
+		tooSmall := ruleName == "min" && size < limit
+		tooBig := ruleName == "max" && size > limit
+		if !tooSmall && !tooBig {
+			return "", nil
+		}
+
+		bound := "at least"
+		if tooBig {
+			bound = "at most"
+		}
+		if isLength {
+			return fmt.Sprintf("%s must have a length of %s %s", name, bound, arg), nil
+		}
+
+		return fmt.Sprintf("%s must be %s %s", name, bound, arg), nil
+
+	case "oneof":
+		options := strings.Fields(arg)
+		actual := fmt.Sprint(value.Interface())
+		for _, option := range options {
+			if actual == option {
+				return "", nil
+			}
+		}
+
+		return fmt.Sprintf("%s must be one of %s", name, strings.Join(options, ", ")), nil
+	}
+
+	return "", fmt.Errorf("Unknown validation rule for %s: %s", name, rule)
+}
+
+// valueSize returns the number (or length) that min and max are compared to.
+func valueSize(value reflect.Value) (size float64, isLength bool, ok bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), false, true
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), false, true
+
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), false, true
+
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return float64(value.Len()), true, true
+	}
+
+	return 0, false, false
+}
+
+// SetValidator replaces the Validator used by BindParams for the requests
+// handled by the server. A nil validator uses TagValidator. This allows
+// another validation library to be used:
+//
+//     type playgroundValidator struct {
+//         validate *validator.Validate
+//     }
+//
+//     func (v playgroundValidator) Validate(params interface{}) error {
+//         err := v.validate.Struct(params)
+//         // Convert the errors to jsonrpc.ValidationErrors...
+//     }
+//
+//     server.SetValidator(playgroundValidator{validator.New()})
+func (server *SimpleServer) SetValidator(validator Validator) {
+	server.validator = validator
+}
+
+// validatingRequest uses the Validator of the server for BindParams.
+type validatingRequest struct {
+	RequestResponder
+	validator Validator
+}
+
+func (request *validatingRequest) BindParams(target interface{}) Response {
+	return bindRequestParams(request, target, request.validator)
+}
+
+func bindRequestParams(request RequestResponder, target interface{}, validator Validator) Response {
+	// This is a mistake in the handler rather than the params.
+	if t := reflect.TypeOf(target); t == nil || t.Kind() != reflect.Ptr {
+		return request.NewServerErrorResponse(errors.New("BindParams target must be a pointer"))
+	}
+
+	if err := bindParams(decodedResult(request.Params()), target); err != nil {
+		return request.NewErrorResponse(InvalidParams, "Invalid params: "+err.Error())
+	}
+
+	err := validator.Validate(target)
+	if errs, ok := err.(ValidationErrors); ok {
+		return request.NewErrorResponseWithData(InvalidParams, "Invalid params: "+errs.Error(), errs)
+	}
+	if err != nil {
+		return request.NewServerErrorResponse(err)
+	}
+
+	return nil
+}
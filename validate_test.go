@@ -0,0 +1,126 @@
+package jsonrpc_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type createUser struct {
+	Name    string   `json:"name" validate:"required,max=8"`
+	Age     int      `json:"age" validate:"min=18,max=130"`
+	Role    string   `json:"role" validate:"oneof=admin user"`
+	Tags    []string `json:"tags" validate:"max=2"`
+	Score   *float64 `json:"score" validate:"min=0.5"`
+	Address *address `json:"address"`
+}
+
+func TestTagValidator(t *testing.T) {
+	score := 0.25
+
+	for name, test := range map[string]struct {
+		params   createUser
+		expected jsonrpc.ValidationErrors
+	}{
+		"Valid": {
+			createUser{Name: "bob", Age: 42, Role: "user"},
+			nil,
+		},
+		"Invalid": {
+			createUser{Age: 12, Role: "owner", Tags: []string{"a", "b", "c"}, Score: &score, Address: &address{}},
+			jsonrpc.ValidationErrors{
+				{Field: "name", Rule: "required", Message: "name is required"},
+				{Field: "age", Rule: "min", Message: "age must be at least 18"},
+				{Field: "role", Rule: "oneof", Message: "role must be one of admin, user"},
+				{Field: "tags", Rule: "max", Message: "tags must have a length of at most 2"},
+				{Field: "score", Rule: "min", Message: "score must be at least 0.5"},
+				{Field: "address.city", Rule: "required", Message: "address.city is required"},
+			},
+		},
+		"TooLong": {
+			createUser{Name: "bartholomew", Age: 131, Role: "admin"},
+			jsonrpc.ValidationErrors{
+				{Field: "name", Rule: "max", Message: "name must have a length of at most 8"},
+				{Field: "age", Rule: "max", Message: "age must be at most 130"},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := jsonrpc.TagValidator{}.Validate(&test.params)
+			if test.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, test.expected, err)
+			}
+		})
+	}
+
+	t.Run("UnknownRule", func(t *testing.T) {
+		params := struct {
+			Name string `validate:"email"`
+		}{}
+
+		err := jsonrpc.TagValidator{}.Validate(&params)
+		assert.EqualError(t, err, "Unknown validation rule for Name: email")
+	})
+}
+
+func TestRequest_BindParamsValidation(t *testing.T) {
+	request, err := jsonrpc.NewRequestFromJSON([]byte(`{"jsonrpc":"2.0","method":"createUser","params":{"age":12,"role":"user"},"id":1}`))
+	assert.NoError(t, err)
+
+	var params createUser
+	response := request.BindParams(&params)
+
+	assert.Equal(t, jsonrpc.InvalidParams, response.ErrorCode())
+	assert.Equal(t, "Invalid params: name is required; age must be at least 18", response.ErrorMessage())
+	assert.Equal(t, jsonrpc.ValidationErrors{
+		{Field: "name", Rule: "required", Message: "name is required"},
+		{Field: "age", Rule: "min", Message: "age must be at least 18"},
+	}, response.ErrorData())
+}
+
+type validatorFunc func(params interface{}) error
+
+func (fn validatorFunc) Validate(params interface{}) error {
+	return fn(params)
+}
+
+func TestSimpleServer_SetValidator(t *testing.T) {
+	server := jsonrpc.NewSimpleServer()
+	server.SetHandler("createUser", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		var params createUser
+		if response := request.BindParams(&params); response != nil {
+			return response
+		}
+
+		return request.NewSuccessResponse(params.Name)
+	}, jsonrpc.WithMiddleware(func(next jsonrpc.RequestHandler) jsonrpc.RequestHandler {
+		return func(request jsonrpc.RequestResponder) jsonrpc.Response {
+			return next(jsonrpc.WithState(request, jsonrpc.State{"user": "bob"}))
+		}
+	}))
+
+	request := []byte(`{"jsonrpc":"2.0","method":"createUser","params":{"name":"alice","role":"user"},"id":1}`)
+
+	// The default validator rejects the age.
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"Invalid params: age must be at least 18","data":[{"field":"age","rule":"min","message":"age must be at least 18"}]}}`,
+		server.Handle(request)[0].String())
+
+	server.SetValidator(validatorFunc(func(params interface{}) error {
+		return nil
+	}))
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":"alice"}`, server.Handle(request)[0].String())
+
+	server.SetValidator(validatorFunc(func(params interface{}) error {
+		return errors.New("validator is down")
+	}))
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"validator is down"}}`,
+		server.Handle(request)[0].String())
+}
@@ -0,0 +1,57 @@
+package jsonrpc
+
+// VersionNegotiator chooses how to handle a request that does not declare
+// "jsonrpc": "2.0". version is empty if the request does not have a "jsonrpc"
+// member, as with JSON-RPC 1.0.
+//
+// It returns the handler for the request, and false if the version is not
+// supported. An unsupported version is rejected with an InvalidRequest error
+// and a nil handler for a supported version is a MethodNotFound error.
+type VersionNegotiator func(version string, request RequestResponder) (RequestHandler, bool)
+
+// SetVersionNegotiator allows requests for other versions of the protocol,
+// instead of rejecting them with "Version is not 2.0.". For example, to handle
+// JSON-RPC 1.0 requests with the same handlers:
+//
+//     server.SetVersionNegotiator(func(version string, request jsonrpc.RequestResponder) (jsonrpc.RequestHandler, bool) {
+//         if version == "" || version == "1.0" {
+//             return server.GetHandler(request.Method()), true
+//         }
+//
+//         return nil, false
+//     })
+//
+// The negotiated handler is used in the same way as any other handler, so it
+// can also select a vendor specific implementation. Responses created by the
+// request (such as with request.NewSuccessResponse) declare the same version
+// as the request. There is no "jsonrpc" member when the version is empty.
+//
+// Passing nil restores the default of only allowing "2.0".
+func (server *SimpleServer) SetVersionNegotiator(negotiator VersionNegotiator) {
+	server.versionNegotiator = negotiator
+}
+
+// negotiateVersion returns the handler for a request that is not "2.0", and
+// false if the version is not supported.
+func (server *SimpleServer) negotiateVersion(request RequestResponder) (RequestHandler, bool) {
+	if server.versionNegotiator == nil {
+		return nil, false
+	}
+
+	handler, ok := server.versionNegotiator(request.Version(), request)
+	if !ok || handler == nil {
+		return nil, ok
+	}
+
+	return func(request RequestResponder) Response {
+		result := handler(request)
+		if r, ok := result.(*response); ok {
+			versioned := *r
+			versioned.ResponseVersion = request.Version()
+
+			return &versioned
+		}
+
+		return result
+	}, true
+}
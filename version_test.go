@@ -0,0 +1,79 @@
+package jsonrpc_test
+
+import (
+	"testing"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimpleServer_SetVersionNegotiator(t *testing.T) {
+	server := newTestServer()
+	server.SetHandler("vendorSum", func(request jsonrpc.RequestResponder) jsonrpc.Response {
+		return request.NewSuccessResponse("vendor")
+	})
+
+	var versions []string
+	server.SetVersionNegotiator(func(version string, request jsonrpc.RequestResponder) (jsonrpc.RequestHandler, bool) {
+		versions = append(versions, version)
+
+		switch version {
+		case "", "1.0":
+			return server.GetHandler(request.Method()), true
+
+		case "acme-1":
+			return server.GetHandler("vendorSum"), true
+		}
+
+		return nil, false
+	})
+
+	for _, test := range []struct {
+		name, request, expected string
+	}{
+		{
+			"MissingVersion",
+			`{"method":"sum","params":[1,2],"id":1}`,
+			`[{"id":1,"result":3}]`,
+		},
+		{
+			"Version1",
+			`{"jsonrpc":"1.0","method":"sum","params":[1,2],"id":1}`,
+			`[{"jsonrpc":"1.0","id":1,"result":3}]`,
+		},
+		{
+			"VendorVersion",
+			`{"jsonrpc":"acme-1","method":"sum","params":[1,2],"id":1}`,
+			`[{"jsonrpc":"acme-1","id":1,"result":"vendor"}]`,
+		},
+		{
+			"Rejected",
+			`{"jsonrpc":"3.0","method":"sum","params":[1,2],"id":1}`,
+			`[{"jsonrpc":"2.0","id":1,"error":{"code":-32600,"message":"Version is not 2.0."}}]`,
+		},
+		{
+			"Version2IsNotNegotiated",
+			`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`,
+			`[{"jsonrpc":"2.0","id":1,"result":3}]`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, server.Handle([]byte(test.request)).String())
+		})
+	}
+
+	assert.Equal(t, []string{"", "1.0", "acme-1", "3.0"}, versions)
+
+	t.Run("MethodNotFound", func(t *testing.T) {
+		responses := server.Handle([]byte(`{"jsonrpc":"1.0","method":"foo","id":1}`))
+
+		assert.Equal(t, jsonrpc.MethodNotFound, responses[0].ErrorCode())
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		server.SetVersionNegotiator(nil)
+		responses := server.Handle([]byte(`{"method":"sum","params":[1,2],"id":1}`))
+
+		assert.Equal(t, "Version (jsonrpc) must be a string.", responses[0].ErrorMessage())
+	})
+}
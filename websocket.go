@@ -0,0 +1,440 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WebSocketConn is a single WebSocket connection used by WebSocketTransport.
+// This package does not depend on a WebSocket library, see the README for an
+// adapter for github.com/coder/websocket.
+//
+// Each JSON-RPC payload is sent and received as a single text message. All of
+// the methods must return when ctx is cancelled. ReadMessage is called by a
+// single goroutine, at the same time as WriteMessage and Ping are called by
+// others.
+type WebSocketConn interface {
+	ReadMessage(ctx context.Context) ([]byte, error)
+	WriteMessage(ctx context.Context, data []byte) error
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// WebSocketDialer opens a new connection to the server.
+type WebSocketDialer func(ctx context.Context) (WebSocketConn, error)
+
+// ReplayPolicy decides what happens to a request that was in flight when the
+// connection was lost.
+type ReplayPolicy int
+
+const (
+	// FailInFlight returns an error for the request. The request may or may
+	// not have been processed by the server.
+	FailInFlight ReplayPolicy = iota
+
+	// ReplayInFlight sends the request again once the connection has been
+	// reestablished. This is only safe if all of the methods called are
+	// idempotent.
+	ReplayInFlight
+)
+
+// ErrWebSocketClosed is returned by WebSocketTransport after Close has been
+// called.
+var ErrWebSocketClosed = errors.New("WebSocket transport is closed")
+
+// WebSocketTransport sends payloads over a WebSocket. The connection is opened
+// when the first request is sent and is automatically reopened if it is lost.
+//
+// Many calls can be in flight at the same time. The responses are matched to
+// their requests by id, in the same way as ConnTransport. A goroutine reads
+// from the connection while it is open, so requests from the server (see
+// OnServerRequest) are received and pings are answered between calls.
+type WebSocketTransport struct {
+	// Dial opens a new connection. It is required.
+	Dial WebSocketDialer
+
+	// PingInterval is how often a ping is sent while the connection is idle.
+	// If a ping fails the connection is closed and will be reopened by the
+	// next request. Zero disables pings.
+	PingInterval time.Duration
+
+	// MinBackoff and MaxBackoff control the delay between attempts to
+	// reconnect. The delay starts at MinBackoff and doubles after each failed
+	// attempt up to MaxBackoff. The defaults are 100ms and 10s.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// MaxReconnects is the number of times a request will try to reconnect
+	// before giving up. The default of zero will keep trying until the
+	// context of the request is done.
+	MaxReconnects int
+
+	// Replay is the policy for requests that were in flight when the
+	// connection was lost.
+	Replay ReplayPolicy
+
+	// MaxReplays is the number of times a request will be sent again with
+	// ReplayInFlight before its error is returned. A request that always
+	// breaks the connection would otherwise be sent forever. The backoff
+	// between replays is the same as for reconnecting. The default is 3.
+	MaxReplays int
+
+	mutex         sync.Mutex
+	session       *webSocketSession
+	closed        bool
+	serverRequest func(request Request)
+	disconnected  func(err error)
+}
+
+// webSocketSession is an open connection and the calls that are waiting for a
+// response on it.
+type webSocketSession struct {
+	conn       WebSocketConn
+	cancel     context.CancelFunc
+	writeMutex sync.Mutex
+
+	// pending and err are guarded by the mutex of the transport. err is set
+	// once the connection has been lost.
+	pending map[string]chan []byte
+	err     error
+}
+
+func (transport *WebSocketTransport) RoundTrip(ctx context.Context, payload []byte, expectResponse bool) ([]byte, error) {
+	backoff := transport.minBackoff()
+	for replays := 0; ; replays++ {
+		session, err := transport.connect(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := transport.send(ctx, session, payload, expectResponse)
+		if err == nil {
+			return data, nil
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		// Only requests that were lost with the connection are replayed.
+		if transport.Replay != ReplayInFlight || !transport.lost(session) ||
+			replays >= transport.maxReplays() {
+			return nil, err
+		}
+
+		if err := transport.wait(ctx, &backoff); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (transport *WebSocketTransport) maxReplays() int {
+	if transport.MaxReplays <= 0 {
+		return 3
+	}
+
+	return transport.MaxReplays
+}
+
+func (transport *WebSocketTransport) minBackoff() time.Duration {
+	if transport.MinBackoff <= 0 {
+		return 100 * time.Millisecond
+	}
+
+	return transport.MinBackoff
+}
+
+// wait sleeps for backoff (unless ctx is done first) and then doubles it, up
+// to MaxBackoff.
+func (transport *WebSocketTransport) wait(ctx context.Context, backoff *time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+
+	case <-time.After(*backoff):
+	}
+
+	maxBackoff := transport.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 10 * time.Second
+	}
+
+	*backoff *= 2
+	if *backoff > maxBackoff {
+		*backoff = maxBackoff
+	}
+
+	return nil
+}
+
+func (transport *WebSocketTransport) send(ctx context.Context, session *webSocketSession, payload []byte, expectResponse bool) ([]byte, error) {
+	var key string
+	var response chan []byte
+	if expectResponse {
+		var ok bool
+		key, ok = payloadKey(payload)
+		if !ok {
+			return nil, errors.New("Cannot find the id of the request")
+		}
+
+		response = make(chan []byte, 1)
+
+		transport.mutex.Lock()
+		if session.err != nil {
+			transport.mutex.Unlock()
+
+			return nil, session.err
+		}
+		if _, ok := session.pending[key]; ok {
+			transport.mutex.Unlock()
+
+			return nil, fmt.Errorf("Request %s is already in flight", key)
+		}
+		session.pending[key] = response
+		transport.mutex.Unlock()
+
+		// The request is no longer waiting when this returns (including when
+		// ctx is done), so a late response will be discarded.
+		defer func() {
+			transport.mutex.Lock()
+			delete(session.pending, key)
+			transport.mutex.Unlock()
+		}()
+	}
+
+	session.writeMutex.Lock()
+	err := session.conn.WriteMessage(ctx, payload)
+	session.writeMutex.Unlock()
+
+	if err != nil {
+		// Part of the payload may have been written, so nothing else can be
+		// sent on the connection.
+		transport.disconnect(session, err)
+
+		return nil, err
+	}
+
+	if !expectResponse {
+		return nil, nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+
+	case data, ok := <-response:
+		if !ok {
+			transport.mutex.Lock()
+			defer transport.mutex.Unlock()
+
+			return nil, session.err
+		}
+
+		return data, nil
+	}
+}
+
+// readMessages delivers each response to the call waiting for it, and each
+// request from the server to the OnServerRequest handler, until the
+// connection is lost.
+func (transport *WebSocketTransport) readMessages(ctx context.Context, session *webSocketSession) {
+	for {
+		data, err := session.conn.ReadMessage(ctx)
+		if err != nil {
+			transport.disconnect(session, err)
+
+			return
+		}
+
+		if request, ok := serverRequest(data); ok {
+			transport.mutex.Lock()
+			handler := transport.serverRequest
+			transport.mutex.Unlock()
+
+			if handler != nil {
+				handler(request)
+			}
+
+			continue
+		}
+
+		key, ok := payloadKey(data)
+
+		transport.mutex.Lock()
+		response, found := session.pending[key]
+
+		// An error for a request that could not be parsed will not have an
+		// id. It can only be delivered if there is one request waiting.
+		if !ok && len(session.pending) == 1 {
+			for key, response = range session.pending {
+				found = true
+			}
+		}
+
+		if found {
+			delete(session.pending, key)
+			response <- data
+		}
+		transport.mutex.Unlock()
+	}
+}
+
+// lost returns true if the connection of the session has been lost.
+func (transport *WebSocketTransport) lost(session *webSocketSession) bool {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	return session.err != nil
+}
+
+// OnServerRequest sets the handler for requests sent by the server. Requests
+// are received whenever the connection is open. The handler is called by the
+// goroutine that reads the connection, so responses are not received until it
+// returns.
+func (transport *WebSocketTransport) OnServerRequest(handler func(request Request)) {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	transport.serverRequest = handler
+}
+
+// OnDisconnect sets the function that is called each time the connection is
+// lost or closed. A new connection will not have the subscriptions of the
+// previous connection.
+func (transport *WebSocketTransport) OnDisconnect(handler func(err error)) {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	transport.disconnected = handler
+}
+
+// connect returns the open connection, or opens a new connection with backoff.
+func (transport *WebSocketTransport) connect(ctx context.Context) (*webSocketSession, error) {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	if transport.closed {
+		return nil, ErrWebSocketClosed
+	}
+
+	if transport.session != nil {
+		return transport.session, nil
+	}
+
+	backoff := transport.minBackoff()
+	for attempt := 0; ; attempt++ {
+		conn, err := transport.Dial(ctx)
+		if err == nil {
+			sessionCtx, cancel := context.WithCancel(context.Background())
+			session := &webSocketSession{
+				conn:    conn,
+				cancel:  cancel,
+				pending: make(map[string]chan []byte),
+			}
+			transport.session = session
+
+			go transport.readMessages(sessionCtx, session)
+			transport.startPings(sessionCtx, session)
+
+			return session, nil
+		}
+
+		if transport.MaxReconnects > 0 && attempt >= transport.MaxReconnects {
+			return nil, err
+		}
+
+		if err := transport.wait(ctx, &backoff); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// disconnect closes the connection of the session because of err. The calls
+// that are waiting for a response receive err. It does nothing if the session
+// has already been disconnected.
+func (transport *WebSocketTransport) disconnect(session *webSocketSession, err error) {
+	transport.mutex.Lock()
+	if session.err != nil {
+		transport.mutex.Unlock()
+
+		return
+	}
+
+	session.err = err
+	for key, response := range session.pending {
+		close(response)
+		delete(session.pending, key)
+	}
+
+	if transport.session == session {
+		transport.session = nil
+	}
+	disconnected := transport.disconnected
+	transport.mutex.Unlock()
+
+	session.cancel()
+	session.conn.Close()
+
+	if disconnected != nil {
+		disconnected(err)
+	}
+}
+
+// startPings sends pings in the background while the connection is idle,
+// until ctx is cancelled.
+func (transport *WebSocketTransport) startPings(ctx context.Context, session *webSocketSession) {
+	if transport.PingInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(transport.PingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-ticker.C:
+				// A call waiting for a response already shows the connection
+				// is alive.
+				transport.mutex.Lock()
+				busy := len(session.pending) > 0
+				transport.mutex.Unlock()
+
+				if busy {
+					continue
+				}
+
+				pingCtx, cancel := context.WithTimeout(ctx, transport.PingInterval)
+				err := session.conn.Ping(pingCtx)
+				cancel()
+
+				if err != nil && ctx.Err() == nil {
+					transport.disconnect(session, err)
+
+					return
+				}
+			}
+		}
+	}()
+}
+
+// Close closes the connection. The transport cannot be used after it is
+// closed.
+func (transport *WebSocketTransport) Close() error {
+	transport.mutex.Lock()
+	transport.closed = true
+	session := transport.session
+	transport.mutex.Unlock()
+
+	if session != nil {
+		transport.disconnect(session, ErrWebSocketClosed)
+	}
+
+	return nil
+}
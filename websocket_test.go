@@ -0,0 +1,360 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elliotchance/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeWebSocket is a WebSocketConn connected directly to a server.
+type fakeWebSocket struct {
+	server    *jsonrpc.SimpleServer
+	responses chan []byte
+	pongs     chan struct{}
+
+	mutex     sync.Mutex
+	failWrite bool
+	failPing  bool
+	closed    bool
+}
+
+func (conn *fakeWebSocket) ReadMessage(ctx context.Context) ([]byte, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+
+		case data := <-conn.responses:
+			// A nil message is a pong. Like a real WebSocket, it is only
+			// received while the connection is being read.
+			if data == nil {
+				conn.pongs <- struct{}{}
+				continue
+			}
+
+			return data, nil
+		}
+	}
+}
+
+func (conn *fakeWebSocket) WriteMessage(ctx context.Context, data []byte) error {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	if conn.failWrite || conn.closed {
+		return errors.New("connection lost")
+	}
+
+	if response := conn.server.HandleMessage(data, nil); response != nil {
+		conn.responses <- response
+	}
+
+	return nil
+}
+
+func (conn *fakeWebSocket) Ping(ctx context.Context) error {
+	conn.mutex.Lock()
+	failPing := conn.failPing
+	conn.mutex.Unlock()
+
+	if failPing {
+		return errors.New("no pong")
+	}
+
+	select {
+	case conn.responses <- nil:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-conn.pongs:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (conn *fakeWebSocket) Close() error {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	conn.closed = true
+
+	return nil
+}
+
+// fakeWebSocketDialer records each connection that is opened. The first
+// failures dials will return an error.
+type fakeWebSocketDialer struct {
+	mutex    sync.Mutex
+	conns    []*fakeWebSocket
+	failures int
+
+	// failWrites makes every connection fail when it is written to.
+	failWrites bool
+}
+
+func (dialer *fakeWebSocketDialer) dial(ctx context.Context) (jsonrpc.WebSocketConn, error) {
+	dialer.mutex.Lock()
+	defer dialer.mutex.Unlock()
+
+	if dialer.failures > 0 {
+		dialer.failures--
+
+		return nil, errors.New("connection refused")
+	}
+
+	conn := &fakeWebSocket{
+		server:    newTestServer(),
+		responses: make(chan []byte, 1),
+		pongs:     make(chan struct{}, 1),
+		failWrite: dialer.failWrites,
+	}
+	dialer.conns = append(dialer.conns, conn)
+
+	return conn, nil
+}
+
+func (dialer *fakeWebSocketDialer) last() *fakeWebSocket {
+	dialer.mutex.Lock()
+	defer dialer.mutex.Unlock()
+
+	return dialer.conns[len(dialer.conns)-1]
+}
+
+func (dialer *fakeWebSocketDialer) dials() int {
+	dialer.mutex.Lock()
+	defer dialer.mutex.Unlock()
+
+	return len(dialer.conns)
+}
+
+func TestWebSocketTransport(t *testing.T) {
+	newClient := func(transport *jsonrpc.WebSocketTransport) (*jsonrpc.Client, *fakeWebSocketDialer) {
+		dialer := &fakeWebSocketDialer{}
+		transport.Dial = dialer.dial
+		transport.MinBackoff = time.Millisecond
+
+		return jsonrpc.NewClient(transport), dialer
+	}
+
+	sum := func(t *testing.T, client *jsonrpc.Client) {
+		response, err := client.Call("sum", []int{1, 2})
+		assert.NoError(t, err)
+		assert.Equal(t, 3.0, response.Result())
+	}
+
+	t.Run("Call", func(t *testing.T) {
+		client, dialer := newClient(&jsonrpc.WebSocketTransport{})
+		sum(t, client)
+		assert.NoError(t, client.Notify("notify_hello", nil))
+		sum(t, client)
+		assert.Equal(t, 1, dialer.dials())
+	})
+
+	t.Run("FailInFlight", func(t *testing.T) {
+		client, dialer := newClient(&jsonrpc.WebSocketTransport{})
+		sum(t, client)
+
+		dialer.last().failWrite = true
+		_, err := client.Call("sum", []int{1, 2})
+		assert.EqualError(t, err, "connection lost")
+		assert.True(t, dialer.last().closed)
+
+		sum(t, client)
+		assert.Equal(t, 2, dialer.dials())
+	})
+
+	t.Run("ReplayInFlight", func(t *testing.T) {
+		client, dialer := newClient(&jsonrpc.WebSocketTransport{
+			Replay: jsonrpc.ReplayInFlight,
+		})
+		sum(t, client)
+
+		dialer.last().failWrite = true
+		sum(t, client)
+		assert.Equal(t, 2, dialer.dials())
+	})
+
+	t.Run("MaxReplays", func(t *testing.T) {
+		client, dialer := newClient(&jsonrpc.WebSocketTransport{
+			Replay:     jsonrpc.ReplayInFlight,
+			MaxReplays: 2,
+		})
+
+		// Every connection fails when the request is written.
+		dialer.failWrites = true
+		start := time.Now()
+		_, err := client.Call("sum", []int{1, 2})
+		assert.EqualError(t, err, "connection lost")
+		assert.Equal(t, 3, dialer.dials())
+
+		// There is a backoff of 1ms and then 2ms between the replays.
+		assert.GreaterOrEqual(t, time.Since(start), 3*time.Millisecond)
+	})
+
+	t.Run("DefaultMaxReplays", func(t *testing.T) {
+		client, dialer := newClient(&jsonrpc.WebSocketTransport{
+			Replay: jsonrpc.ReplayInFlight,
+		})
+
+		dialer.failWrites = true
+		_, err := client.Call("sum", []int{1, 2})
+		assert.EqualError(t, err, "connection lost")
+		assert.Equal(t, 4, dialer.dials())
+	})
+
+	t.Run("Backoff", func(t *testing.T) {
+		transport := &jsonrpc.WebSocketTransport{}
+		client, dialer := newClient(transport)
+		dialer.failures = 3
+
+		sum(t, client)
+		assert.Equal(t, 1, dialer.dials())
+	})
+
+	t.Run("MaxReconnects", func(t *testing.T) {
+		client, dialer := newClient(&jsonrpc.WebSocketTransport{
+			MaxReconnects: 2,
+		})
+		dialer.failures = 3
+
+		_, err := client.Call("sum", []int{1, 2})
+		assert.EqualError(t, err, "connection refused")
+	})
+
+	t.Run("ContextDuringBackoff", func(t *testing.T) {
+		client, dialer := newClient(&jsonrpc.WebSocketTransport{})
+		dialer.failures = 1000
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := client.CallContext(ctx, "sum", []int{1, 2})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("Ping", func(t *testing.T) {
+		client, dialer := newClient(&jsonrpc.WebSocketTransport{
+			PingInterval: time.Millisecond,
+		})
+		sum(t, client)
+
+		conn := dialer.last()
+		conn.mutex.Lock()
+		conn.failPing = true
+		conn.mutex.Unlock()
+
+		for i := 0; i < 100; i++ {
+			conn.mutex.Lock()
+			closed := conn.closed
+			conn.mutex.Unlock()
+
+			if closed {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		sum(t, client)
+		assert.Equal(t, 2, dialer.dials())
+	})
+
+	t.Run("PingWhileIdle", func(t *testing.T) {
+		client, dialer := newClient(&jsonrpc.WebSocketTransport{
+			PingInterval: 5 * time.Millisecond,
+		})
+		sum(t, client)
+
+		// The pongs are received without any calls reading the connection.
+		time.Sleep(50 * time.Millisecond)
+		sum(t, client)
+		assert.Equal(t, 1, dialer.dials())
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		client, dialer := newClient(&jsonrpc.WebSocketTransport{})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sum(t, client)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 1, dialer.dials())
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		transport := &jsonrpc.WebSocketTransport{}
+		client, _ := newClient(transport)
+		sum(t, client)
+
+		assert.NoError(t, transport.Close())
+		_, err := client.Call("sum", []int{1, 2})
+		assert.ErrorIs(t, err, jsonrpc.ErrWebSocketClosed)
+	})
+}
+
+func TestWebSocketTransport_OnServerRequest(t *testing.T) {
+	dialer := &fakeWebSocketDialer{}
+	client := jsonrpc.NewClient(&jsonrpc.WebSocketTransport{Dial: dialer.dial})
+
+	notifications := make(chan interface{}, 1)
+	client.OnNotification("update", func(params interface{}) {
+		notifications <- params
+	})
+
+	_, err := client.Call("sum", []int{1})
+	assert.NoError(t, err)
+
+	// The notification is received while there are no calls.
+	dialer.last().responses <- []byte(`{"jsonrpc":"2.0","method":"update","params":{"a":1}}`)
+
+	select {
+	case params := <-notifications:
+		assert.Equal(t, map[string]interface{}{"a": 1.0}, params)
+	case <-time.After(time.Second):
+		t.Fatal("notification was not received")
+	}
+
+	response, err := client.Call("sum", []int{1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, response.Result())
+}
+
+func TestWebSocketTransport_OnDisconnect(t *testing.T) {
+	dialer := &fakeWebSocketDialer{}
+	transport := &jsonrpc.WebSocketTransport{Dial: dialer.dial}
+	client := jsonrpc.NewClient(transport)
+
+	var errs []error
+	transport.OnDisconnect(func(err error) {
+		errs = append(errs, err)
+	})
+
+	_, err := client.Call("sum", []int{1})
+	assert.NoError(t, err)
+
+	dialer.last().failWrite = true
+	_, err = client.Call("sum", []int{1, 2})
+	assert.Error(t, err)
+
+	_, err = client.Call("sum", []int{1})
+	assert.NoError(t, err)
+	assert.NoError(t, transport.Close())
+
+	if assert.Len(t, errs, 2) {
+		assert.EqualError(t, errs[0], "connection lost")
+		assert.Equal(t, jsonrpc.ErrWebSocketClosed, errs[1])
+	}
+}